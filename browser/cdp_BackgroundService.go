@@ -6,8 +6,6 @@ import (
 	"fmt"
 	"log"
 	"time"
-
-	"github.com/gorilla/websocket"
 )
 
 // ----------------------------------------------- BackgroundService.clearEvents  -----------------------------------------------
@@ -29,8 +27,8 @@ func CDPBackgroundServiceClearEvents(service string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -42,7 +40,7 @@ func CDPBackgroundServiceClearEvents(service string) (string, error) {
     }`, reqID, service)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 BackgroundService.clearEvents 请求失败: %w", err)
 	}
@@ -56,7 +54,7 @@ func CDPBackgroundServiceClearEvents(service string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -104,8 +102,8 @@ func CDPBackgroundServiceSetRecording(shouldRecord bool, service string) (string
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -118,7 +116,7 @@ func CDPBackgroundServiceSetRecording(shouldRecord bool, service string) (string
     }`, reqID, shouldRecord, service)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 BackgroundService.setRecording 请求失败: %w", err)
 	}
@@ -132,7 +130,7 @@ func CDPBackgroundServiceSetRecording(shouldRecord bool, service string) (string
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -179,8 +177,8 @@ func CDPBackgroundServiceStartObserving(service string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -192,7 +190,7 @@ func CDPBackgroundServiceStartObserving(service string) (string, error) {
     }`, reqID, service)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 BackgroundService.startObserving 请求失败: %w", err)
 	}
@@ -206,7 +204,7 @@ func CDPBackgroundServiceStartObserving(service string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -253,8 +251,8 @@ func CDPBackgroundServiceStopObserving(service string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -266,7 +264,7 @@ func CDPBackgroundServiceStopObserving(service string) (string, error) {
     }`, reqID, service)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 BackgroundService.stopObserving 请求失败: %w", err)
 	}
@@ -280,7 +278,7 @@ func CDPBackgroundServiceStopObserving(service string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
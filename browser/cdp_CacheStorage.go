@@ -6,8 +6,6 @@ import (
 	"fmt"
 	"log"
 	"time"
-
-	"github.com/gorilla/websocket"
 )
 
 // -----------------------------------------------  CacheStorage.deleteCache  -----------------------------------------------
@@ -28,8 +26,8 @@ func CDPCacheStorageDeleteCache(cacheID string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建带参数的请求消息
 	message := fmt.Sprintf(`{
@@ -41,7 +39,7 @@ func CDPCacheStorageDeleteCache(cacheID string) (string, error) {
     }`, reqID, cacheID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 deleteCache 请求失败: %w", err)
 	}
@@ -55,7 +53,7 @@ func CDPCacheStorageDeleteCache(cacheID string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -184,8 +182,8 @@ func CDPCacheStorageDeleteEntry(cacheID, request string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建带参数的请求消息
 	message := fmt.Sprintf(`{
@@ -198,7 +196,7 @@ func CDPCacheStorageDeleteEntry(cacheID, request string) (string, error) {
     }`, reqID, cacheID, request)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 deleteEntry 请求失败: %w", err)
 	}
@@ -212,7 +210,7 @@ func CDPCacheStorageDeleteEntry(cacheID, request string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -340,8 +338,8 @@ func CDPCacheStorageRequestCachedResponse(cacheID, request string) (string, erro
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建带参数的请求消息
 	message := fmt.Sprintf(`{
@@ -354,7 +352,7 @@ func CDPCacheStorageRequestCachedResponse(cacheID, request string) (string, erro
     }`, reqID, cacheID, request)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 requestCachedResponse 请求失败: %w", err)
 	}
@@ -368,7 +366,7 @@ func CDPCacheStorageRequestCachedResponse(cacheID, request string) (string, erro
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -535,8 +533,8 @@ func CDPCacheStorageRequestCacheNames() (struct {
 		}{}, fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建请求消息
 	message := fmt.Sprintf(`{
@@ -545,7 +543,7 @@ func CDPCacheStorageRequestCacheNames() (struct {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return struct {
 			CacheIds []string `json:"cacheIds"`
@@ -561,7 +559,7 @@ func CDPCacheStorageRequestCacheNames() (struct {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return struct {
 					CacheIds []string `json:"cacheIds"`
@@ -708,8 +706,8 @@ func CDPCacheStorageRequestEntries(cacheID string, skipCount, pageSize int, path
 		return RequestEntriesResult{}, fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建请求参数
 	params := map[string]interface{}{
@@ -736,7 +734,7 @@ func CDPCacheStorageRequestEntries(cacheID string, skipCount, pageSize int, path
     }`, reqID, paramsBytes)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return RequestEntriesResult{}, fmt.Errorf("发送请求失败: %w", err)
 	}
@@ -750,7 +748,7 @@ func CDPCacheStorageRequestEntries(cacheID string, skipCount, pageSize int, path
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return RequestEntriesResult{}, fmt.Errorf("消息队列已关闭")
 			}
@@ -7,8 +7,6 @@ import (
 	"log"
 	"strings"
 	"time"
-
-	"github.com/gorilla/websocket"
 )
 
 // -----------------------------------------------  DOM.describeNode  -----------------------------------------------
@@ -32,8 +30,8 @@ func CDPDOMDescribeNode(nodeID int, depth int, pierce bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -47,7 +45,7 @@ func CDPDOMDescribeNode(nodeID int, depth int, pierce bool) (string, error) {
     }`, reqID, nodeID, depth, pierce)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 DOM.describeNode 请求失败: %w", err)
 	}
@@ -61,7 +59,7 @@ func CDPDOMDescribeNode(nodeID int, depth int, pierce bool) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -228,8 +226,8 @@ func CDPDOMDisable() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -238,7 +236,7 @@ func CDPDOMDisable() (string, error) {
     }`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 DOM.disable 请求失败: %w", err)
 	}
@@ -252,7 +250,7 @@ func CDPDOMDisable() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -358,8 +356,8 @@ func CDPDOMEnable() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -368,7 +366,7 @@ func CDPDOMEnable() (string, error) {
     }`, reqID)
 
 	// 发送请求
-	err := chromeInstance.NowTabWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.NowTabWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 DOM.enable 请求失败: %w", err)
 	}
@@ -382,7 +380,7 @@ func CDPDOMEnable() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -487,8 +485,8 @@ func CDPDOMFocus(nodeID int) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -500,7 +498,7 @@ func CDPDOMFocus(nodeID int) (string, error) {
     }`, reqID, nodeID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 DOM.focus 请求失败: %w", err)
 	}
@@ -514,7 +512,7 @@ func CDPDOMFocus(nodeID int) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -625,8 +623,8 @@ func CDPDOMGetAttributes(nodeID int) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -638,7 +636,7 @@ func CDPDOMGetAttributes(nodeID int) (string, error) {
     }`, reqID, nodeID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 DOM.getAttributes 请求失败: %w", err)
 	}
@@ -652,7 +650,7 @@ func CDPDOMGetAttributes(nodeID int) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -883,8 +881,8 @@ func CDPDOMGetBoxModel(nodeID int) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -896,7 +894,7 @@ func CDPDOMGetBoxModel(nodeID int) (string, error) {
     }`, reqID, nodeID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 DOM.getBoxModel 请求失败: %w", err)
 	}
@@ -910,7 +908,7 @@ func CDPDOMGetBoxModel(nodeID int) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1210,8 +1208,8 @@ func CDPDOMGetDocument(depth int, pierce bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -1224,7 +1222,7 @@ func CDPDOMGetDocument(depth int, pierce bool) (string, error) {
     }`, reqID, depth, pierce)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 DOM.getDocument 请求失败: %w", err)
 	}
@@ -1238,7 +1236,7 @@ func CDPDOMGetDocument(depth int, pierce bool) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1305,6 +1303,65 @@ type BackendNode struct {
 	BackendNodeID int    `json:"backendNodeId"`
 }
 
+// DOMGetDocumentPierce 获取当前操作的tab(已切换frame时为该frame)穿透shadow root的完整DOM树
+// 与 CDPDOMGetDocument 不同, 这里在tab的session上执行, 可直接用于元素匹配
+func DOMGetDocumentPierce() (*Node, error) {
+	if !DefaultNowTab(false) {
+		return nil, notReadyErr()
+	}
+	sessionId, _, err := nowFrameContext()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := cdpCall(chromeInstance.NowTabWSConn, sessionId, "DOM.getDocument", map[string]any{
+		"depth":  -1,
+		"pierce": true,
+	}, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	rootJson, err := json.Marshal(res["root"])
+	if err != nil {
+		return nil, err
+	}
+	root := &Node{}
+	if err = json.Unmarshal(rootJson, root); err != nil {
+		return nil, fmt.Errorf("解析 DOM.getDocument 回复失败: %w", err)
+	}
+
+	// 同进程的iframe在父页面的树中, 取该frame的文档
+	if frameId := NowFrame(); frameId != "" {
+		if doc := findFrameDocument(root, frameId); doc != nil {
+			return doc, nil
+		}
+	}
+	return root, nil
+}
+
+func findFrameDocument(node *Node, frameId string) *Node {
+	if node.FrameID == frameId && node.ContentDocument != nil {
+		return node.ContentDocument
+	}
+	if node.ContentDocument != nil {
+		if doc := findFrameDocument(node.ContentDocument, frameId); doc != nil {
+			return doc
+		}
+	}
+	for i := range node.Children {
+		if doc := findFrameDocument(&node.Children[i], frameId); doc != nil {
+			return doc
+		}
+	}
+	for i := range node.ShadowRoots {
+		if doc := findFrameDocument(&node.ShadowRoots[i], frameId); doc != nil {
+			return doc
+		}
+	}
+	return nil
+}
+
 /*
 
 
@@ -1837,8 +1894,8 @@ func CDPDOMGetNodeForLocation(x, y int, includeUserAgentShadowDOM, ignorePointer
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -1853,7 +1910,7 @@ func CDPDOMGetNodeForLocation(x, y int, includeUserAgentShadowDOM, ignorePointer
     }`, reqID, x, y, includeUserAgentShadowDOM, ignorePointerEventsNone)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 DOM.getNodeForLocation 请求失败: %w", err)
 	}
@@ -1867,7 +1924,7 @@ func CDPDOMGetNodeForLocation(x, y int, includeUserAgentShadowDOM, ignorePointer
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2288,8 +2345,8 @@ func CDPDOMGetOuterHTML(nodeID int) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -2301,7 +2358,7 @@ func CDPDOMGetOuterHTML(nodeID int) (string, error) {
     }`, reqID, nodeID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 DOM.getOuterHTML 请求失败: %w", err)
 	}
@@ -2315,7 +2372,7 @@ func CDPDOMGetOuterHTML(nodeID int) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2950,8 +3007,8 @@ func CDPDOMHideHighlight() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -2960,7 +3017,7 @@ func CDPDOMHideHighlight() (string, error) {
     }`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 DOM.hideHighlight 请求失败: %w", err)
 	}
@@ -2974,7 +3031,7 @@ func CDPDOMHideHighlight() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -3370,8 +3427,8 @@ func CDPDOMHighlightNode(params string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
         "id": %d,
         "method": "DOM.highlightNode",
@@ -3379,7 +3436,7 @@ func CDPDOMHighlightNode(params string) (string, error) {
     }`, reqID, params)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 DOM.highlightNode 请求失败: %w", err)
 	}
@@ -3393,7 +3450,7 @@ func CDPDOMHighlightNode(params string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -3913,8 +3970,8 @@ func CDPDOMHighlightRect(params string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	// 构建消息
 	message := fmt.Sprintf(`{
         "id": %d,
@@ -3923,7 +3980,7 @@ func CDPDOMHighlightRect(params string) (string, error) {
     }`, reqID, params)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 DOM.highlightRect 请求失败: %w", err)
 	}
@@ -3937,7 +3994,7 @@ func CDPDOMHighlightRect(params string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -4506,8 +4563,8 @@ func CDPDOMMoveTo(nodeID, targetNodeID, insertBeforeNodeID int) (string, error)
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -4521,7 +4578,7 @@ func CDPDOMMoveTo(nodeID, targetNodeID, insertBeforeNodeID int) (string, error)
     }`, reqID, nodeID, targetNodeID, insertBeforeNodeID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 DOM.moveTo 请求失败: %w", err)
 	}
@@ -4535,7 +4592,7 @@ func CDPDOMMoveTo(nodeID, targetNodeID, insertBeforeNodeID int) (string, error)
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -5106,8 +5163,8 @@ func CDPDOMQuerySelector(nodeID int, selector string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -5120,7 +5177,7 @@ func CDPDOMQuerySelector(nodeID int, selector string) (string, error) {
     }`, reqID, nodeID, selector)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 DOM.querySelector 请求失败: %w", err)
 	}
@@ -5134,7 +5191,7 @@ func CDPDOMQuerySelector(nodeID int, selector string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -5800,8 +5857,8 @@ func CDPDOMQuerySelectorAll(nodeID int, selector string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -5814,7 +5871,7 @@ func CDPDOMQuerySelectorAll(nodeID int, selector string) (string, error) {
     }`, reqID, nodeID, selector)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 DOM.querySelectorAll 请求失败: %w", err)
 	}
@@ -5828,7 +5885,7 @@ func CDPDOMQuerySelectorAll(nodeID int, selector string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -6626,8 +6683,8 @@ func CDPDOMRemoveAttribute(nodeID int, name string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -6640,7 +6697,7 @@ func CDPDOMRemoveAttribute(nodeID int, name string) (string, error) {
     }`, reqID, nodeID, name)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 DOM.removeAttribute 请求失败: %w", err)
 	}
@@ -6654,7 +6711,7 @@ func CDPDOMRemoveAttribute(nodeID int, name string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -7315,8 +7372,8 @@ func CDPDOMRemoveNode(nodeID int) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -7328,7 +7385,7 @@ func CDPDOMRemoveNode(nodeID int) (string, error) {
     }`, reqID, nodeID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 DOM.removeNode 请求失败: %w", err)
 	}
@@ -7342,7 +7399,7 @@ func CDPDOMRemoveNode(nodeID int) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -8213,8 +8270,8 @@ func CDPDOMRequestChildNodes(nodeID, depth int, pierce bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -8228,7 +8285,7 @@ func CDPDOMRequestChildNodes(nodeID, depth int, pierce bool) (string, error) {
     }`, reqID, nodeID, depth, pierce)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 DOM.requestChildNodes 请求失败: %w", err)
 	}
@@ -8242,7 +8299,7 @@ func CDPDOMRequestChildNodes(nodeID, depth int, pierce bool) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -8954,8 +9011,8 @@ func CDPDOMRequestNode(backendNodeID int) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -8967,7 +9024,7 @@ func CDPDOMRequestNode(backendNodeID int) (string, error) {
     }`, reqID, backendNodeID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 DOM.requestNode 请求失败: %w", err)
 	}
@@ -8981,7 +9038,7 @@ func CDPDOMRequestNode(backendNodeID int) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -9751,8 +9808,8 @@ func CDPDOMResolveNode(nodeID, backendNodeID int, objectGroup string, executionC
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息参数
 	params := map[string]interface{}{
@@ -9784,7 +9841,7 @@ func CDPDOMResolveNode(nodeID, backendNodeID int, objectGroup string, executionC
     }`, reqID, string(paramsJSON))
 
 	// 发送请求
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 DOM.resolveNode 请求失败: %w", err)
 	}
@@ -9798,7 +9855,7 @@ func CDPDOMResolveNode(nodeID, backendNodeID int, objectGroup string, executionC
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -10691,8 +10748,8 @@ func CDPDOMScrollIntoViewIfNeeded(nodeID int, rect *DOMRect) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息参数
 	params := map[string]interface{}{
@@ -10716,7 +10773,7 @@ func CDPDOMScrollIntoViewIfNeeded(nodeID int, rect *DOMRect) (string, error) {
     }`, reqID, string(paramsJSON))
 
 	// 发送请求
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 DOM.scrollIntoViewIfNeeded 请求失败: %w", err)
 	}
@@ -10730,7 +10787,7 @@ func CDPDOMScrollIntoViewIfNeeded(nodeID int, rect *DOMRect) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -11486,8 +11543,8 @@ func CDPDOMSetAttributesAsText(nodeID int, text, name string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息参数
 	params := map[string]interface{}{
@@ -11512,7 +11569,7 @@ func CDPDOMSetAttributesAsText(nodeID int, text, name string) (string, error) {
     }`, reqID, string(paramsJSON))
 
 	// 发送请求
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 DOM.setAttributesAsText 请求失败: %w", err)
 	}
@@ -11526,7 +11583,7 @@ func CDPDOMSetAttributesAsText(nodeID int, text, name string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -12342,8 +12399,8 @@ func CDPDOMSetAttributeValue(nodeID int, name, value string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -12357,7 +12414,7 @@ func CDPDOMSetAttributeValue(nodeID int, name, value string) (string, error) {
     }`, reqID, nodeID, name, value)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 DOM.setAttributeValue 请求失败: %w", err)
 	}
@@ -12371,7 +12428,7 @@ func CDPDOMSetAttributeValue(nodeID int, name, value string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -13258,8 +13315,8 @@ func CDPDOMSetFileInputFiles(nodeID int, files []string, backendNodeID int) (str
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息参数
 	params := map[string]interface{}{
@@ -13284,7 +13341,7 @@ func CDPDOMSetFileInputFiles(nodeID int, files []string, backendNodeID int) (str
     }`, reqID, string(paramsJSON))
 
 	// 发送请求
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 DOM.setFileInputFiles 请求失败: %w", err)
 	}
@@ -13298,7 +13355,7 @@ func CDPDOMSetFileInputFiles(nodeID int, files []string, backendNodeID int) (str
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -14262,8 +14319,8 @@ func CDPDOMSetNodeName(nodeID int, name string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -14276,7 +14333,7 @@ func CDPDOMSetNodeName(nodeID int, name string) (string, error) {
     }`, reqID, nodeID, name)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 DOM.setNodeName 请求失败: %w", err)
 	}
@@ -14290,7 +14347,7 @@ func CDPDOMSetNodeName(nodeID int, name string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -15208,8 +15265,8 @@ func CDPDOMSetNodeValue(nodeID int, value string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -15222,7 +15279,7 @@ func CDPDOMSetNodeValue(nodeID int, value string) (string, error) {
     }`, reqID, nodeID, value)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 DOM.setNodeValue 请求失败: %w", err)
 	}
@@ -15236,7 +15293,7 @@ func CDPDOMSetNodeValue(nodeID int, value string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -16244,8 +16301,8 @@ func CDPDOMSetOuterHTML(nodeID int, outerHTML string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -16258,7 +16315,7 @@ func CDPDOMSetOuterHTML(nodeID int, outerHTML string) (string, error) {
     }`, reqID, nodeID, escapeString(outerHTML))
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 DOM.setOuterHTML 请求失败: %w", err)
 	}
@@ -16272,7 +16329,7 @@ func CDPDOMSetOuterHTML(nodeID int, outerHTML string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -6,8 +6,6 @@ import (
 	"fmt"
 	"log"
 	"time"
-
-	"github.com/gorilla/websocket"
 )
 
 // -----------------------------------------------  DOMDebugger.getEventListeners  -----------------------------------------------
@@ -28,8 +26,8 @@ func CDPDOMDebuggerGetEventListeners(objectID string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -41,7 +39,7 @@ func CDPDOMDebuggerGetEventListeners(objectID string) (string, error) {
     }`, reqID, objectID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getEventListeners 请求失败: %w", err)
 	}
@@ -55,7 +53,7 @@ func CDPDOMDebuggerGetEventListeners(objectID string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -134,8 +132,8 @@ func CDPDOMDebuggerRemoveDOMBreakpoint(nodeID int, breakpointType string) (strin
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -148,7 +146,7 @@ func CDPDOMDebuggerRemoveDOMBreakpoint(nodeID int, breakpointType string) (strin
     }`, reqID, nodeID, breakpointType)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 removeDOMBreakpoint 请求失败: %w", err)
 	}
@@ -162,7 +160,7 @@ func CDPDOMDebuggerRemoveDOMBreakpoint(nodeID int, breakpointType string) (strin
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -238,8 +236,8 @@ func CDPDOMDebuggerRemoveEventListenerBreakpoint(eventName string) (string, erro
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -251,7 +249,7 @@ func CDPDOMDebuggerRemoveEventListenerBreakpoint(eventName string) (string, erro
     }`, reqID, eventName)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 removeEventListenerBreakpoint 请求失败: %w", err)
 	}
@@ -265,7 +263,7 @@ func CDPDOMDebuggerRemoveEventListenerBreakpoint(eventName string) (string, erro
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -354,8 +352,8 @@ func CDPDOMDebuggerRemoveXHRBreakpoint(url string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	params := fmt.Sprintf(`"id": %d, "method": "DOMDebugger.removeXHRBreakpoint"`, reqID)
@@ -368,7 +366,7 @@ func CDPDOMDebuggerRemoveXHRBreakpoint(url string) (string, error) {
 	message := fmt.Sprintf(`{ %s }`, params)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 removeXHRBreakpoint 请求失败: %w", err)
 	}
@@ -382,7 +380,7 @@ func CDPDOMDebuggerRemoveXHRBreakpoint(url string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -495,8 +493,8 @@ func CDPDOMDebuggerSetDOMBreakpoint(nodeID int, breakpointType string) (string,
 		return "", fmt.Errorf("无效的断点类型: %s，可选值: subtree-modified, attribute-modified, node-removed", breakpointType)
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -509,7 +507,7 @@ func CDPDOMDebuggerSetDOMBreakpoint(nodeID int, breakpointType string) (string,
     }`, reqID, nodeID, breakpointType)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setDOMBreakpoint 请求失败: %w", err)
 	}
@@ -523,7 +521,7 @@ func CDPDOMDebuggerSetDOMBreakpoint(nodeID int, breakpointType string) (string,
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -629,8 +627,8 @@ func CDPDOMDebuggerSetEventListenerBreakpoint(eventName string) (string, error)
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -642,7 +640,7 @@ func CDPDOMDebuggerSetEventListenerBreakpoint(eventName string) (string, error)
     }`, reqID, eventName)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setEventListenerBreakpoint 请求失败: %w", err)
 	}
@@ -656,7 +654,7 @@ func CDPDOMDebuggerSetEventListenerBreakpoint(eventName string) (string, error)
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -776,8 +774,8 @@ func CDPDOMDebuggerSetXHRBreakpoint(urlPattern string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -789,7 +787,7 @@ func CDPDOMDebuggerSetXHRBreakpoint(urlPattern string) (string, error) {
     }`, reqID, urlPattern)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setXHRBreakpoint 请求失败: %w", err)
 	}
@@ -803,7 +801,7 @@ func CDPDOMDebuggerSetXHRBreakpoint(urlPattern string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
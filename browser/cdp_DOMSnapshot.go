@@ -8,8 +8,6 @@ import (
 	"strconv"
 	"strings"
 	"time"
-
-	"github.com/gorilla/websocket"
 )
 
 // CDPDOMSnapshotCaptureSnapshot 捕获DOM结构快照
@@ -44,8 +42,8 @@ func CDPDOMSnapshotCaptureSnapshot(options ...DOMSnapshotOption) (string, error)
 		option(config)
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数对象
 	params := map[string]interface{}{
@@ -76,7 +74,7 @@ func CDPDOMSnapshotCaptureSnapshot(options ...DOMSnapshotOption) (string, error)
 
 	fmt.Println("发送快照消息  -> ", message)
 
-	err = chromeInstance.NowTabWSConn.WriteMessage(websocket.TextMessage, messageBytes)
+	err = wsWrite(chromeInstance.NowTabWSConn, messageBytes)
 	if err != nil {
 		log.Println("发送 DOMSnapshot.captureSnapshot 失败:", err)
 		return "", err
@@ -88,7 +86,7 @@ func CDPDOMSnapshotCaptureSnapshot(options ...DOMSnapshotOption) (string, error)
 	defer timer.Stop()
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1039,14 +1037,14 @@ func CDPDOMSnapshotDisable() (string, error) {
 	if chromeInstance.NowTabWSConn == nil {
 		return "", fmt.Errorf("NowTabWSConn 未连接，无法调用 DOMSnapshot.disable")
 	}
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "DOMSnapshot.disable"
 	}`, reqID)
 
-	err := chromeInstance.NowTabWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.NowTabWSConn, []byte(message))
 	if err != nil {
 		log.Println("发送 DOMSnapshot.disable 失败:", err)
 		return "", err
@@ -1058,7 +1056,7 @@ func CDPDOMSnapshotDisable() (string, error) {
 	defer timer.Stop()
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1081,14 +1079,14 @@ func CDPDOMSnapshotEnable() (string, error) {
 	if chromeInstance.NowTabWSConn == nil {
 		return "", fmt.Errorf("NowTabWSConn 未连接，无法调用 DOMSnapshot.enable")
 	}
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "DOMSnapshot.enable"
 	}`, reqID)
 
-	err := chromeInstance.NowTabWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.NowTabWSConn, []byte(message))
 	if err != nil {
 		log.Println("发送 DOMSnapshot.enable 失败:", err)
 		return "", err
@@ -1100,7 +1098,7 @@ func CDPDOMSnapshotEnable() (string, error) {
 	defer timer.Stop()
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -6,8 +6,6 @@ import (
 	"log"
 	"strings"
 	"time"
-
-	"github.com/gorilla/websocket"
 )
 
 // CDPDOMStorageClear 清除指定存储区域的所有数据
@@ -26,8 +24,8 @@ func CDPDOMStorageClear(storageId DOMStorageId) (string, error) {
 	if chromeInstance.BrowserWSConn == nil {
 		return "", fmt.Errorf("BrowserWSConn 未连接，无法调用 DOMStorage.clear")
 	}
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "DOMStorage.clear",
@@ -39,7 +37,7 @@ func CDPDOMStorageClear(storageId DOMStorageId) (string, error) {
 		}
 	}`, reqID, storageId.SecurityOrigin, storageId.IsLocalStorage)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		log.Println("发送 DOMStorage.clear 失败:", err)
 		return "", err
@@ -51,7 +49,7 @@ func CDPDOMStorageClear(storageId DOMStorageId) (string, error) {
 	defer timer.Stop()
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -129,14 +127,14 @@ func CDPDOMStorageDisable() (string, error) {
 	if chromeInstance.BrowserWSConn == nil {
 		return "", fmt.Errorf("BrowserWSConn 未连接，无法调用 DOMStorage.disable")
 	}
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "DOMStorage.disable"
 	}`, reqID)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		log.Println("发送 DOMStorage.disable 失败:", err)
 		return "", err
@@ -148,7 +146,7 @@ func CDPDOMStorageDisable() (string, error) {
 	defer timer.Stop()
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -171,14 +169,14 @@ func CDPDOMStorageEnable() (string, error) {
 	if chromeInstance.BrowserWSConn == nil {
 		return "", fmt.Errorf("BrowserWSConn 未连接，无法调用 DOMStorage.enable")
 	}
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "DOMStorage.enable"
 	}`, reqID)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		log.Println("发送 DOMStorage.enable 失败:", err)
 		return "", err
@@ -190,7 +188,7 @@ func CDPDOMStorageEnable() (string, error) {
 	defer timer.Stop()
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -219,8 +217,8 @@ func CDPDOMStorageGetDOMStorageItems(storageId DOMStorageId) (string, error) {
 	if chromeInstance.BrowserWSConn == nil {
 		return "", fmt.Errorf("BrowserWSConn 未连接，无法调用 DOMStorage.getDOMStorageItems")
 	}
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "DOMStorage.getDOMStorageItems",
@@ -232,7 +230,7 @@ func CDPDOMStorageGetDOMStorageItems(storageId DOMStorageId) (string, error) {
 		}
 	}`, reqID, storageId.SecurityOrigin, storageId.IsLocalStorage)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		log.Println("发送 DOMStorage.getDOMStorageItems 失败:", err)
 		return "", err
@@ -244,7 +242,7 @@ func CDPDOMStorageGetDOMStorageItems(storageId DOMStorageId) (string, error) {
 	defer timer.Stop()
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -277,8 +275,8 @@ func CDPDOMStorageRemoveDOMStorageItem(storageId DOMStorageId, key string) (stri
 	if chromeInstance.BrowserWSConn == nil {
 		return "", fmt.Errorf("BrowserWSConn 未连接，无法调用 DOMStorage.removeDOMStorageItem")
 	}
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "DOMStorage.removeDOMStorageItem",
@@ -291,7 +289,7 @@ func CDPDOMStorageRemoveDOMStorageItem(storageId DOMStorageId, key string) (stri
 		}
 	}`, reqID, storageId.SecurityOrigin, storageId.IsLocalStorage, key)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		log.Println("发送 DOMStorage.removeDOMStorageItem 失败:", err)
 		return "", err
@@ -303,7 +301,7 @@ func CDPDOMStorageRemoveDOMStorageItem(storageId DOMStorageId, key string) (stri
 	defer timer.Stop()
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -337,8 +335,8 @@ func CDPDOMStorageSetDOMStorageItem(storageId DOMStorageId, key, value string) (
 	if chromeInstance.BrowserWSConn == nil {
 		return "", fmt.Errorf("BrowserWSConn 未连接，无法调用 DOMStorage.setDOMStorageItem")
 	}
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 转义特殊字符
 	escapedKey := strings.ReplaceAll(key, `"`, `\"`)
@@ -357,7 +355,7 @@ func CDPDOMStorageSetDOMStorageItem(storageId DOMStorageId, key, value string) (
 		}
 	}`, reqID, storageId.SecurityOrigin, storageId.IsLocalStorage, escapedKey, escapedValue)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		log.Println("发送 DOMStorage.setDOMStorageItem 失败:", err)
 		return "", err
@@ -369,7 +367,7 @@ func CDPDOMStorageSetDOMStorageItem(storageId DOMStorageId, key, value string) (
 	defer timer.Stop()
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
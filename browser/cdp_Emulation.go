@@ -8,8 +8,6 @@ import (
 	"math"
 	"strings"
 	"time"
-
-	"github.com/gorilla/websocket"
 )
 
 // -----------------------------------------------  Emulation.clearDeviceMetricsOverride  -----------------------------------------------
@@ -30,8 +28,8 @@ func CDPEmulationClearDeviceMetricsOverride() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -40,7 +38,7 @@ func CDPEmulationClearDeviceMetricsOverride() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 clearDeviceMetricsOverride 请求失败: %w", err)
 	}
@@ -54,7 +52,7 @@ func CDPEmulationClearDeviceMetricsOverride() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -100,8 +98,8 @@ func CDPEmulationClearGeolocationOverride() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -110,7 +108,7 @@ func CDPEmulationClearGeolocationOverride() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 clearGeolocationOverride 请求失败: %w", err)
 	}
@@ -124,7 +122,7 @@ func CDPEmulationClearGeolocationOverride() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -170,8 +168,8 @@ func CDPEmulationClearIdleOverride() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -180,7 +178,7 @@ func CDPEmulationClearIdleOverride() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 clearIdleOverride 请求失败: %w", err)
 	}
@@ -194,7 +192,7 @@ func CDPEmulationClearIdleOverride() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -250,8 +248,8 @@ func CDPEmulationSetCPUThrottlingRate(rate float64) (string, error) {
 		return "", fmt.Errorf("CPU限制率不能大于100: %f", rate)
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -263,7 +261,7 @@ func CDPEmulationSetCPUThrottlingRate(rate float64) (string, error) {
 	}`, reqID, rate)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setCPUThrottlingRate 请求失败: %w", err)
 	}
@@ -277,7 +275,7 @@ func CDPEmulationSetCPUThrottlingRate(rate float64) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -808,8 +806,8 @@ func CDPEmulationSetDefaultBackgroundColorOverride(params string) (string, error
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	message := fmt.Sprintf(`{
 		"id": %d,
@@ -817,7 +815,7 @@ func CDPEmulationSetDefaultBackgroundColorOverride(params string) (string, error
 		"params": %s
 	}`, reqID, params)
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setDefaultBackgroundColorOverride 请求失败: %w", err)
 	}
@@ -831,7 +829,7 @@ func CDPEmulationSetDefaultBackgroundColorOverride(params string) (string, error
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -882,14 +880,14 @@ func CDPEmulationSetDeviceMetricsOverride(params string) (string, error) {
 	if chromeInstance.BrowserWSConn == nil {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "Emulation.setDeviceMetricsOverride",
 		"params": %s
 	}`, reqID, params)
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setDeviceMetricsOverride 请求失败: %w", err)
 	}
@@ -899,7 +897,7 @@ func CDPEmulationSetDeviceMetricsOverride(params string) (string, error) {
 	defer timer.Stop()
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -948,14 +946,14 @@ func CDPEmulationSetEmulatedMedia(params string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "Emulation.setEmulatedMedia",
 		"params": %s
 	}`, reqID, params)
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setEmulatedMedia 请求失败: %w", err)
 	}
@@ -966,7 +964,7 @@ func CDPEmulationSetEmulatedMedia(params string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1014,14 +1012,14 @@ func CDPEmulationSetEmulatedOSTextScale(params string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "Emulation.setEmulatedOSTextScale",
 		"params": %s
 	}`, reqID, params)
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setEmulatedOSTextScale 请求失败: %w", err)
 	}
@@ -1035,7 +1033,7 @@ func CDPEmulationSetEmulatedOSTextScale(params string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1089,8 +1087,8 @@ func CDPEmulationSetEmulatedVisionDeficiency(params string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "Emulation.setEmulatedVisionDeficiency",
@@ -1098,7 +1096,7 @@ func CDPEmulationSetEmulatedVisionDeficiency(params string) (string, error) {
 	}`, reqID, params)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setEmulatedVisionDeficiency 请求失败: %w", err)
 	}
@@ -1112,7 +1110,7 @@ func CDPEmulationSetEmulatedVisionDeficiency(params string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1168,8 +1166,8 @@ func CDPEmulationSetGeolocationOverride(params string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -1179,7 +1177,7 @@ func CDPEmulationSetGeolocationOverride(params string) (string, error) {
 	}`, reqID, params)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setGeolocationOverride 请求失败: %w", err)
 	}
@@ -1193,7 +1191,7 @@ func CDPEmulationSetGeolocationOverride(params string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1245,14 +1243,14 @@ func CDPEmulationSetIdleOverride(params string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "Emulation.setIdleOverride",
 		"params": %s
 	}`, reqID, params)
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setIdleOverride 请求失败: %w", err)
 	}
@@ -1266,7 +1264,7 @@ func CDPEmulationSetIdleOverride(params string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1317,14 +1315,14 @@ func CDPEmulationSetScriptExecutionDisabled(params string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "Emulation.setScriptExecutionDisabled",
 		"params": %s
 	}`, reqID, params)
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setScriptExecutionDisabled 请求失败: %w", err)
 	}
@@ -1338,7 +1336,7 @@ func CDPEmulationSetScriptExecutionDisabled(params string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1397,8 +1395,8 @@ func CDPEmulationSetTimezoneOverride(params string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	// 构建消息
 	message := fmt.Sprintf(`{
 		"id": %d,
@@ -1407,7 +1405,7 @@ func CDPEmulationSetTimezoneOverride(params string) (string, error) {
 	}`, reqID, params)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setTimezoneOverride 请求失败: %w", err)
 	}
@@ -1421,7 +1419,7 @@ func CDPEmulationSetTimezoneOverride(params string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1473,14 +1471,14 @@ func CDPEmulationSetTouchEmulationEnabled(params string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "Emulation.setTouchEmulationEnabled",
 		"params": %s
 	}`, reqID, params)
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setTouchEmulationEnabled 请求失败: %w", err)
 	}
@@ -1491,7 +1489,7 @@ func CDPEmulationSetTouchEmulationEnabled(params string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1543,8 +1541,8 @@ func CDPEmulationSetUserAgentOverride(params string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "Emulation.setUserAgentOverride",
@@ -1552,7 +1550,7 @@ func CDPEmulationSetUserAgentOverride(params string) (string, error) {
 	}`, reqID, params)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setUserAgentOverride 请求失败: %w", err)
 	}
@@ -1566,7 +1564,7 @@ func CDPEmulationSetUserAgentOverride(params string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
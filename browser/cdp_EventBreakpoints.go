@@ -6,8 +6,6 @@ import (
 	"fmt"
 	"log"
 	"time"
-
-	"github.com/gorilla/websocket"
 )
 
 // -----------------------------------------------  EventBreakpoints.disable  -----------------------------------------------
@@ -37,8 +35,8 @@ func EventBreakpointsDisable() (string, error) {
 	}
 
 	// 生成请求ID
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	// EventBreakpoints.disable 方法不需要参数
@@ -51,7 +49,7 @@ func EventBreakpointsDisable() (string, error) {
 	log.Printf("[DEBUG] 发送 CDP 消息: %s", message)
 
 	// 发送WebSocket请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 EventBreakpoints.disable 请求失败: %w", err)
 	}
@@ -64,7 +62,7 @@ func EventBreakpointsDisable() (string, error) {
 	// 监听消息队列获取响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -129,8 +127,8 @@ func EventBreakpointsRemoveInstrumentationBreakpoint(eventName string) (string,
 	}
 
 	// 生成请求ID
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -145,7 +143,7 @@ func EventBreakpointsRemoveInstrumentationBreakpoint(eventName string) (string,
 	log.Printf("[DEBUG] 发送 CDP 消息: %s", message)
 
 	// 发送WebSocket请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 EventBreakpoints.removeInstrumentationBreakpoint 请求失败: %w", err)
 	}
@@ -158,7 +156,7 @@ func EventBreakpointsRemoveInstrumentationBreakpoint(eventName string) (string,
 	// 监听消息队列获取响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -225,8 +223,8 @@ func EventBreakpointsSetInstrumentationBreakpoint(eventName string) (string, err
 	}
 
 	// 生成请求ID
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -241,7 +239,7 @@ func EventBreakpointsSetInstrumentationBreakpoint(eventName string) (string, err
 	log.Printf("[DEBUG] 发送 CDP 消息: %s", message)
 
 	// 发送WebSocket请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 EventBreakpoints.setInstrumentationBreakpoint 请求失败: %w", err)
 	}
@@ -254,7 +252,7 @@ func EventBreakpointsSetInstrumentationBreakpoint(eventName string) (string, err
 	// 监听消息队列获取响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -8,8 +8,6 @@ import (
 	"os"
 	"strings"
 	"time"
-
-	"github.com/gorilla/websocket"
 )
 
 // -----------------------------------------------  Extensions.clearStorageItems  -----------------------------------------------
@@ -33,8 +31,8 @@ func CDPExtensionsClearStorageItems(extensionID string, storageTypes []string, o
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建存储类型数组
 	storageTypesJSON, err := json.Marshal(storageTypes)
@@ -69,7 +67,7 @@ func CDPExtensionsClearStorageItems(extensionID string, storageTypes []string, o
 	message = strings.ReplaceAll(message, ",\n        }", "\n        }")
 
 	// 发送请求
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 clearStorageItems 请求失败: %w", err)
 	}
@@ -83,7 +81,7 @@ func CDPExtensionsClearStorageItems(extensionID string, storageTypes []string, o
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -256,8 +254,8 @@ func CDPExtensionsGetExtensions() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -266,7 +264,7 @@ func CDPExtensionsGetExtensions() (string, error) {
     }`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getExtensions 请求失败: %w", err)
 	}
@@ -280,7 +278,7 @@ func CDPExtensionsGetExtensions() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -328,8 +326,8 @@ func CDPExtensionsGetStorageItems(extensionID string, storageTypes []string) (st
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建存储类型数组
 	storageTypesJSON, err := json.Marshal(storageTypes)
@@ -348,7 +346,7 @@ func CDPExtensionsGetStorageItems(extensionID string, storageTypes []string) (st
     }`, reqID, extensionID, string(storageTypesJSON))
 
 	// 发送请求
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getStorageItems 请求失败: %w", err)
 	}
@@ -362,7 +360,7 @@ func CDPExtensionsGetStorageItems(extensionID string, storageTypes []string) (st
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -701,8 +699,8 @@ func CDPExtensionsLoadUnpacked(path string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -714,7 +712,7 @@ func CDPExtensionsLoadUnpacked(path string) (string, error) {
     }`, reqID, path)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 loadUnpacked 请求失败: %w", err)
 	}
@@ -728,7 +726,7 @@ func CDPExtensionsLoadUnpacked(path string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -777,8 +775,8 @@ func CDPExtensionsRemoveStorageItems(extensionID string, storageTypes []string,
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建存储类型数组
 	storageTypesJSON, err := json.Marshal(storageTypes)
@@ -813,7 +811,7 @@ func CDPExtensionsRemoveStorageItems(extensionID string, storageTypes []string,
 	message = strings.ReplaceAll(message, ",\n        }", "\n        }")
 
 	// 发送请求
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 removeStorageItems 请求失败: %w", err)
 	}
@@ -827,7 +825,7 @@ func CDPExtensionsRemoveStorageItems(extensionID string, storageTypes []string,
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1217,8 +1215,8 @@ func CDPExtensionsSetStorageItems(extensionID string, storageItems []StorageItem
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建存储项数组
 	storageItemsJSON, err := json.Marshal(storageItems)
@@ -1237,7 +1235,7 @@ func CDPExtensionsSetStorageItems(extensionID string, storageItems []StorageItem
     }`, reqID, extensionID, string(storageItemsJSON))
 
 	// 发送请求
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setStorageItems 请求失败: %w", err)
 	}
@@ -1251,7 +1249,7 @@ func CDPExtensionsSetStorageItems(extensionID string, storageItems []StorageItem
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1889,8 +1887,8 @@ func CDPExtensionsTriggerAction(extensionID, actionName string, parameters map[s
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数
 	var parametersJSON string
@@ -1919,7 +1917,7 @@ func CDPExtensionsTriggerAction(extensionID, actionName string, parameters map[s
 	message = strings.ReplaceAll(message, ",\n        }", "\n        }")
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 triggerAction 请求失败: %w", err)
 	}
@@ -1933,7 +1931,7 @@ func CDPExtensionsTriggerAction(extensionID, actionName string, parameters map[s
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2378,8 +2376,8 @@ func CDPExtensionsUninstall(extensionID string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -2391,7 +2389,7 @@ func CDPExtensionsUninstall(extensionID string) (string, error) {
     }`, reqID, extensionID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 uninstall 请求失败: %w", err)
 	}
@@ -2405,7 +2403,7 @@ func CDPExtensionsUninstall(extensionID string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
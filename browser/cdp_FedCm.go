@@ -7,8 +7,6 @@ import (
 	"log"
 	"strings"
 	"time"
-
-	"github.com/gorilla/websocket"
 )
 
 // -----------------------------------------------  FedCm.clickDialogButton  -----------------------------------------------
@@ -31,8 +29,8 @@ func CDPFedCMClickDialogButton(dialogID string, buttonIndex int) (string, error)
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -45,7 +43,7 @@ func CDPFedCMClickDialogButton(dialogID string, buttonIndex int) (string, error)
     }`, reqID, dialogID, buttonIndex)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 FedCM.clickDialogButton 请求失败: %w", err)
 	}
@@ -59,7 +57,7 @@ func CDPFedCMClickDialogButton(dialogID string, buttonIndex int) (string, error)
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -210,8 +208,8 @@ func CDPFedCMDisable() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -220,7 +218,7 @@ func CDPFedCMDisable() (string, error) {
     }`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 FedCM.disable 请求失败: %w", err)
 	}
@@ -234,7 +232,7 @@ func CDPFedCMDisable() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -280,8 +278,8 @@ func CDPFedCMDismissDialog(dialogID string, trigger string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -294,7 +292,7 @@ func CDPFedCMDismissDialog(dialogID string, trigger string) (string, error) {
     }`, reqID, dialogID, trigger)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 FedCM.dismissDialog 请求失败: %w", err)
 	}
@@ -308,7 +306,7 @@ func CDPFedCMDismissDialog(dialogID string, trigger string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -354,8 +352,8 @@ func CDPFedCMEnable() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -364,7 +362,7 @@ func CDPFedCMEnable() (string, error) {
     }`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 FedCM.enable 请求失败: %w", err)
 	}
@@ -378,7 +376,7 @@ func CDPFedCMEnable() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -427,8 +425,8 @@ func CDPFedCMOpenUrl(configURL, accountURL, loginURL string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数
 	var paramsJSON strings.Builder
@@ -457,7 +455,7 @@ func CDPFedCMOpenUrl(configURL, accountURL, loginURL string) (string, error) {
     }`, reqID, paramsStr)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 FedCM.openUrl 请求失败: %w", err)
 	}
@@ -471,7 +469,7 @@ func CDPFedCMOpenUrl(configURL, accountURL, loginURL string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -921,8 +919,8 @@ func CDPFedCMResetCooldown() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -931,7 +929,7 @@ func CDPFedCMResetCooldown() (string, error) {
     }`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 FedCM.resetCooldown 请求失败: %w", err)
 	}
@@ -945,7 +943,7 @@ func CDPFedCMResetCooldown() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -991,8 +989,8 @@ func CDPFedCMSelectAccount(dialogID string, accountIndex int) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -1005,7 +1003,7 @@ func CDPFedCMSelectAccount(dialogID string, accountIndex int) (string, error) {
     }`, reqID, dialogID, accountIndex)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 FedCM.selectAccount 请求失败: %w", err)
 	}
@@ -1019,7 +1017,7 @@ func CDPFedCMSelectAccount(dialogID string, accountIndex int) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
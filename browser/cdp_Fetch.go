@@ -9,8 +9,6 @@ import (
 	"strings"
 	"sync"
 	"time"
-
-	"github.com/gorilla/websocket"
 )
 
 // -----------------------------------------------  Fetch.continueRequest  -----------------------------------------------
@@ -31,8 +29,8 @@ func CDPFetchContinueRequest(requestID string, modifications *ContinueRequestMod
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建修改参数
 	var modificationsJSON string
@@ -60,7 +58,7 @@ func CDPFetchContinueRequest(requestID string, modifications *ContinueRequestMod
 	message = strings.ReplaceAll(message, ",\n        }", "\n        }")
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Fetch.continueRequest 请求失败: %w", err)
 	}
@@ -74,7 +72,7 @@ func CDPFetchContinueRequest(requestID string, modifications *ContinueRequestMod
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -137,8 +135,8 @@ func CDPFetchContinueWithAuth(requestID string, authChallengeResponse *AuthChall
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建认证挑战响应
 	authResponseBytes, err := json.Marshal(authChallengeResponse)
@@ -157,7 +155,7 @@ func CDPFetchContinueWithAuth(requestID string, authChallengeResponse *AuthChall
     }`, reqID, requestID, string(authResponseBytes))
 
 	// 发送请求
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Fetch.continueWithAuth 请求失败: %w", err)
 	}
@@ -171,7 +169,7 @@ func CDPFetchContinueWithAuth(requestID string, authChallengeResponse *AuthChall
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -253,8 +251,8 @@ func CDPFetchDisable() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -263,7 +261,7 @@ func CDPFetchDisable() (string, error) {
     }`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Fetch.disable 请求失败: %w", err)
 	}
@@ -277,7 +275,7 @@ func CDPFetchDisable() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -325,8 +323,8 @@ func CDPFetchEnable(patterns []FetchPattern, handleAuthRequests bool) (string, e
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建拦截模式数组
 	var patternsJSON string
@@ -351,7 +349,7 @@ func CDPFetchEnable(patterns []FetchPattern, handleAuthRequests bool) (string, e
     }`, reqID, patternsJSON, handleAuthRequests)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Fetch.enable 请求失败: %w", err)
 	}
@@ -365,7 +363,7 @@ func CDPFetchEnable(patterns []FetchPattern, handleAuthRequests bool) (string, e
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -418,8 +416,8 @@ func CDPFetchFailRequest(requestID, errorReason string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -432,7 +430,7 @@ func CDPFetchFailRequest(requestID, errorReason string) (string, error) {
     }`, reqID, requestID, errorReason)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Fetch.failRequest 请求失败: %w", err)
 	}
@@ -446,7 +444,7 @@ func CDPFetchFailRequest(requestID, errorReason string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -492,8 +490,8 @@ func CDPFetchFulfillRequest(requestID string, response *FulfillRequestResponse)
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建响应参数
 	responseBytes, err := json.Marshal(response)
@@ -512,7 +510,7 @@ func CDPFetchFulfillRequest(requestID string, response *FulfillRequestResponse)
     }`, reqID, requestID, string(responseBytes))
 
 	// 发送请求
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Fetch.fulfillRequest 请求失败: %w", err)
 	}
@@ -526,7 +524,7 @@ func CDPFetchFulfillRequest(requestID string, response *FulfillRequestResponse)
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -582,8 +580,8 @@ func CDPFetchGetResponseBody(requestID string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -595,7 +593,7 @@ func CDPFetchGetResponseBody(requestID string) (string, error) {
     }`, reqID, requestID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Fetch.getResponseBody 请求失败: %w", err)
 	}
@@ -609,7 +607,7 @@ func CDPFetchGetResponseBody(requestID string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1024,8 +1022,8 @@ func CDPFetchTakeResponseBodyAsStream(requestID string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -1037,7 +1035,7 @@ func CDPFetchTakeResponseBodyAsStream(requestID string) (string, error) {
     }`, reqID, requestID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Fetch.takeResponseBodyAsStream 请求失败: %w", err)
 	}
@@ -1051,7 +1049,7 @@ func CDPFetchTakeResponseBodyAsStream(requestID string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -6,8 +6,6 @@ import (
 	"fmt"
 	"log"
 	"time"
-
-	"github.com/gorilla/websocket"
 )
 
 // -----------------------------------------------  FileSystem.getDirectory  -----------------------------------------------
@@ -30,8 +28,8 @@ func CDPFileSystemGetDirectory(fileSystemID, path string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -44,7 +42,7 @@ func CDPFileSystemGetDirectory(fileSystemID, path string) (string, error) {
     }`, reqID, fileSystemID, path)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 FileSystem.getDirectory 请求失败: %w", err)
 	}
@@ -58,7 +56,7 @@ func CDPFileSystemGetDirectory(fileSystemID, path string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
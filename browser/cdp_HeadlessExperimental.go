@@ -6,8 +6,6 @@ import (
 	"fmt"
 	"log"
 	"time"
-
-	"github.com/gorilla/websocket"
 )
 
 // -----------------------------------------------  HeadlessExperimental.beginFrame  -----------------------------------------------
@@ -32,8 +30,8 @@ func CDPHeadlessExperimentalBeginFrame(frameTimeTicks float64, includeDamage boo
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建带参数的CDP请求消息
 	message := fmt.Sprintf(`{
@@ -46,7 +44,7 @@ func CDPHeadlessExperimentalBeginFrame(frameTimeTicks float64, includeDamage boo
 	}`, reqID, frameTimeTicks, includeDamage)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 beginFrame 请求失败: %w", err)
 	}
@@ -60,7 +58,7 @@ func CDPHeadlessExperimentalBeginFrame(frameTimeTicks float64, includeDamage boo
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
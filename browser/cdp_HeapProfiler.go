@@ -6,8 +6,6 @@ import (
 	"fmt"
 	"log"
 	"time"
-
-	"github.com/gorilla/websocket"
 )
 
 // -----------------------------------------------  HeapProfiler.addInspectedHeapObject  -----------------------------------------------
@@ -29,8 +27,8 @@ func CDPHeapProfilerAddInspectedHeapObject(heapObjectId string) (string, error)
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -42,7 +40,7 @@ func CDPHeapProfilerAddInspectedHeapObject(heapObjectId string) (string, error)
 	}`, reqID, heapObjectId)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 addInspectedHeapObject 请求失败: %w", err)
 	}
@@ -56,7 +54,7 @@ func CDPHeapProfilerAddInspectedHeapObject(heapObjectId string) (string, error)
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -138,8 +136,8 @@ func CDPHeapProfilerCollectGarbage() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -148,7 +146,7 @@ func CDPHeapProfilerCollectGarbage() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 collectGarbage 请求失败: %w", err)
 	}
@@ -162,7 +160,7 @@ func CDPHeapProfilerCollectGarbage() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -247,8 +245,8 @@ func CDPHeapProfilerDisable() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -257,7 +255,7 @@ func CDPHeapProfilerDisable() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 disable 请求失败: %w", err)
 	}
@@ -271,7 +269,7 @@ func CDPHeapProfilerDisable() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -350,8 +348,8 @@ func CDPHeapProfilerEnable() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -360,7 +358,7 @@ func CDPHeapProfilerEnable() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 enable 请求失败: %w", err)
 	}
@@ -374,7 +372,7 @@ func CDPHeapProfilerEnable() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -454,8 +452,8 @@ func CDPHeapProfilerGetHeapObjectId(objectId string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -467,7 +465,7 @@ func CDPHeapProfilerGetHeapObjectId(objectId string) (string, error) {
 	}`, reqID, objectId)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getHeapObjectId 请求失败: %w", err)
 	}
@@ -481,7 +479,7 @@ func CDPHeapProfilerGetHeapObjectId(objectId string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -570,8 +568,8 @@ func CDPHeapProfilerGetObjectByHeapObjectId(heapObjectId string) (string, error)
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -583,7 +581,7 @@ func CDPHeapProfilerGetObjectByHeapObjectId(heapObjectId string) (string, error)
 	}`, reqID, heapObjectId)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getObjectByHeapObjectId 请求失败: %w", err)
 	}
@@ -597,7 +595,7 @@ func CDPHeapProfilerGetObjectByHeapObjectId(heapObjectId string) (string, error)
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -692,8 +690,8 @@ func CDPHeapProfilerGetSamplingProfile() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -702,7 +700,7 @@ func CDPHeapProfilerGetSamplingProfile() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getSamplingProfile 请求失败: %w", err)
 	}
@@ -716,7 +714,7 @@ func CDPHeapProfilerGetSamplingProfile() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -806,8 +804,8 @@ func CDPHeapProfilerStartSampling(samplingInterval int) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -819,7 +817,7 @@ func CDPHeapProfilerStartSampling(samplingInterval int) (string, error) {
 	}`, reqID, samplingInterval)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 startSampling 请求失败: %w", err)
 	}
@@ -833,7 +831,7 @@ func CDPHeapProfilerStartSampling(samplingInterval int) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -914,8 +912,8 @@ func CDPHeapProfilerStartTrackingHeapObjects(trackAllocations bool) (string, err
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -927,7 +925,7 @@ func CDPHeapProfilerStartTrackingHeapObjects(trackAllocations bool) (string, err
 	}`, reqID, trackAllocations)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 startTrackingHeapObjects 请求失败: %w", err)
 	}
@@ -941,7 +939,7 @@ func CDPHeapProfilerStartTrackingHeapObjects(trackAllocations bool) (string, err
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1022,8 +1020,8 @@ func CDPHeapProfilerStopSampling() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -1032,7 +1030,7 @@ func CDPHeapProfilerStopSampling() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 stopSampling 请求失败: %w", err)
 	}
@@ -1046,7 +1044,7 @@ func CDPHeapProfilerStopSampling() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1134,8 +1132,8 @@ func CDPHeapProfilerStopTrackingHeapObjects(reportProgress bool) (string, error)
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -1147,7 +1145,7 @@ func CDPHeapProfilerStopTrackingHeapObjects(reportProgress bool) (string, error)
 	}`, reqID, reportProgress)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 stopTrackingHeapObjects 请求失败: %w", err)
 	}
@@ -1161,7 +1159,7 @@ func CDPHeapProfilerStopTrackingHeapObjects(reportProgress bool) (string, error)
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1245,8 +1243,8 @@ func CDPHeapProfilerTakeHeapSnapshot(reportProgress bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -1258,7 +1256,7 @@ func CDPHeapProfilerTakeHeapSnapshot(reportProgress bool) (string, error) {
 	}`, reqID, reportProgress)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 takeHeapSnapshot 请求失败: %w", err)
 	}
@@ -1272,7 +1270,7 @@ func CDPHeapProfilerTakeHeapSnapshot(reportProgress bool) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
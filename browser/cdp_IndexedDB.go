@@ -6,8 +6,6 @@ import (
 	"fmt"
 	"log"
 	"time"
-
-	"github.com/gorilla/websocket"
 )
 
 // -----------------------------------------------  IndexedDB.clearObjectStore  -----------------------------------------------
@@ -28,8 +26,8 @@ func CDPIndexedDBClearObjectStore(databaseName, objectStoreName string) (string,
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -43,7 +41,7 @@ func CDPIndexedDBClearObjectStore(databaseName, objectStoreName string) (string,
 	}`, reqID, databaseName, objectStoreName)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 clearObjectStore 请求失败: %w", err)
 	}
@@ -57,7 +55,7 @@ func CDPIndexedDBClearObjectStore(databaseName, objectStoreName string) (string,
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -146,8 +144,8 @@ func CDPIndexedDBDeleteDatabase(databaseName string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -160,7 +158,7 @@ func CDPIndexedDBDeleteDatabase(databaseName string) (string, error) {
 	}`, reqID, databaseName)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 deleteDatabase 请求失败: %w", err)
 	}
@@ -174,7 +172,7 @@ func CDPIndexedDBDeleteDatabase(databaseName string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -271,8 +269,8 @@ func CDPIndexedDBDeleteObjectStoreEntries(databaseName, objectStoreName string,
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 将KeyRange转换为JSON
 	keyRangeJSON, err := json.Marshal(keyRange)
@@ -293,7 +291,7 @@ func CDPIndexedDBDeleteObjectStoreEntries(databaseName, objectStoreName string,
 	}`, reqID, databaseName, objectStoreName, string(keyRangeJSON))
 
 	// 发送请求
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 deleteObjectStoreEntries 请求失败: %w", err)
 	}
@@ -307,7 +305,7 @@ func CDPIndexedDBDeleteObjectStoreEntries(databaseName, objectStoreName string,
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -424,8 +422,8 @@ func CDPIndexedDBDisable() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -434,7 +432,7 @@ func CDPIndexedDBDisable() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 disable 请求失败: %w", err)
 	}
@@ -448,7 +446,7 @@ func CDPIndexedDBDisable() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -540,8 +538,8 @@ func CDPIndexedDBEnable() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -550,7 +548,7 @@ func CDPIndexedDBEnable() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 enable 请求失败: %w", err)
 	}
@@ -564,7 +562,7 @@ func CDPIndexedDBEnable() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -659,8 +657,8 @@ func CDPIndexedDBGetMetadata(databaseName string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -673,7 +671,7 @@ func CDPIndexedDBGetMetadata(databaseName string) (string, error) {
 	}`, reqID, databaseName)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getMetadata 请求失败: %w", err)
 	}
@@ -687,7 +685,7 @@ func CDPIndexedDBGetMetadata(databaseName string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -826,8 +824,8 @@ func CDPIndexedDBRequestData(databaseName, objectStoreName string, indexName str
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数
 	params := map[string]interface{}{
@@ -860,7 +858,7 @@ func CDPIndexedDBRequestData(databaseName, objectStoreName string, indexName str
 	}`, reqID, string(paramsJSON))
 
 	// 发送请求
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 requestData 请求失败: %w", err)
 	}
@@ -874,7 +872,7 @@ func CDPIndexedDBRequestData(databaseName, objectStoreName string, indexName str
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1023,8 +1021,8 @@ func CDPIndexedDBRequestDatabase(databaseName string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -1037,7 +1035,7 @@ func CDPIndexedDBRequestDatabase(databaseName string) (string, error) {
 	}`, reqID, databaseName)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 requestDatabase 请求失败: %w", err)
 	}
@@ -1051,7 +1049,7 @@ func CDPIndexedDBRequestDatabase(databaseName string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1221,8 +1219,8 @@ func CDPIndexedDBRequestDatabaseNames() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -1234,7 +1232,7 @@ func CDPIndexedDBRequestDatabaseNames() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 requestDatabaseNames 请求失败: %w", err)
 	}
@@ -1248,7 +1246,7 @@ func CDPIndexedDBRequestDatabaseNames() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
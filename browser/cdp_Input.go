@@ -6,8 +6,6 @@ import (
 	"fmt"
 	"log"
 	"time"
-
-	"github.com/gorilla/websocket"
 )
 
 // -----------------------------------------------  Input.cancelDragging  -----------------------------------------------
@@ -28,8 +26,8 @@ func CDPInputCancelDragging() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -38,7 +36,7 @@ func CDPInputCancelDragging() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 cancelDragging 请求失败: %w", err)
 	}
@@ -52,7 +50,7 @@ func CDPInputCancelDragging() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -147,8 +145,8 @@ func CDPInputDispatchKeyEvent(params map[string]interface{}) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -158,7 +156,7 @@ func CDPInputDispatchKeyEvent(params map[string]interface{}) (string, error) {
 	}`, reqID, utils.MapToJson(params))
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 dispatchKeyEvent 请求失败: %w", err)
 	}
@@ -172,7 +170,7 @@ func CDPInputDispatchKeyEvent(params map[string]interface{}) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -339,8 +337,8 @@ func CDPInputDispatchMouseEvent(params map[string]interface{}) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -350,7 +348,7 @@ func CDPInputDispatchMouseEvent(params map[string]interface{}) (string, error) {
 	}`, reqID, utils.MapToJson(params))
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 dispatchMouseEvent 请求失败: %w", err)
 	}
@@ -364,7 +362,7 @@ func CDPInputDispatchMouseEvent(params map[string]interface{}) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -588,8 +586,8 @@ func CDPInputDispatchTouchEvent(params map[string]interface{}) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -599,7 +597,7 @@ func CDPInputDispatchTouchEvent(params map[string]interface{}) (string, error) {
 	}`, reqID, utils.MapToJson(params))
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 dispatchTouchEvent 请求失败: %w", err)
 	}
@@ -613,7 +611,7 @@ func CDPInputDispatchTouchEvent(params map[string]interface{}) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -846,8 +844,8 @@ func CDPInputSetIgnoreInputEvents(ignore bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -859,7 +857,7 @@ func CDPInputSetIgnoreInputEvents(ignore bool) (string, error) {
 	}`, reqID, ignore)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setIgnoreInputEvents 请求失败: %w", err)
 	}
@@ -873,7 +871,7 @@ func CDPInputSetIgnoreInputEvents(ignore bool) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1059,8 +1057,8 @@ func CDPInputDispatchDragEvent(params map[string]interface{}) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -1070,7 +1068,7 @@ func CDPInputDispatchDragEvent(params map[string]interface{}) (string, error) {
 	}`, reqID, utils.MapToJson(params))
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 dispatchDragEvent 请求失败: %w", err)
 	}
@@ -1084,7 +1082,7 @@ func CDPInputDispatchDragEvent(params map[string]interface{}) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1361,8 +1359,8 @@ func CDPInputEmulateTouchFromMouseEvent(params map[string]interface{}) (string,
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -1372,7 +1370,7 @@ func CDPInputEmulateTouchFromMouseEvent(params map[string]interface{}) (string,
 	}`, reqID, utils.MapToJson(params))
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 emulateTouchFromMouseEvent 请求失败: %w", err)
 	}
@@ -1386,7 +1384,7 @@ func CDPInputEmulateTouchFromMouseEvent(params map[string]interface{}) (string,
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1726,8 +1724,8 @@ func CDPInputImeSetComposition(params map[string]interface{}) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -1737,7 +1735,7 @@ func CDPInputImeSetComposition(params map[string]interface{}) (string, error) {
 	}`, reqID, utils.MapToJson(params))
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 imeSetComposition 请求失败: %w", err)
 	}
@@ -1751,7 +1749,7 @@ func CDPInputImeSetComposition(params map[string]interface{}) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2101,8 +2099,8 @@ func CDPInputInsertText(params map[string]interface{}) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -2112,7 +2110,7 @@ func CDPInputInsertText(params map[string]interface{}) (string, error) {
 	}`, reqID, utils.MapToJson(params))
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 insertText 请求失败: %w", err)
 	}
@@ -2126,7 +2124,7 @@ func CDPInputInsertText(params map[string]interface{}) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2403,8 +2401,8 @@ func CDPInputSetInterceptDrags(enabled bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -2416,7 +2414,7 @@ func CDPInputSetInterceptDrags(enabled bool) (string, error) {
 	}`, reqID, enabled)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setInterceptDrags 请求失败: %w", err)
 	}
@@ -2430,7 +2428,7 @@ func CDPInputSetInterceptDrags(enabled bool) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
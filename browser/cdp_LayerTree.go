@@ -7,8 +7,6 @@ import (
 	"log"
 	"strings"
 	"time"
-
-	"github.com/gorilla/websocket"
 )
 
 // -----------------------------------------------  LayerTree.compositingReasons  -----------------------------------------------
@@ -30,8 +28,8 @@ func CDPLayerTreeCompositingReasons(layerId string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -43,7 +41,7 @@ func CDPLayerTreeCompositingReasons(layerId string) (string, error) {
 	}`, reqID, layerId)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 LayerTree.compositingReasons 请求失败: %w", err)
 	}
@@ -57,7 +55,7 @@ func CDPLayerTreeCompositingReasons(layerId string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -136,8 +134,8 @@ func CDPLayerTreeDisable() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -146,7 +144,7 @@ func CDPLayerTreeDisable() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 LayerTree.disable 请求失败: %w", err)
 	}
@@ -160,7 +158,7 @@ func CDPLayerTreeDisable() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -246,8 +244,8 @@ func CDPLayerTreeEnable() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -256,7 +254,7 @@ func CDPLayerTreeEnable() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 LayerTree.enable 请求失败: %w", err)
 	}
@@ -270,7 +268,7 @@ func CDPLayerTreeEnable() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -363,8 +361,8 @@ func CDPLayerTreeLoadSnapshot(layerTreeData string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 转义JSON数据，避免格式化冲突
 	escapedData := strings.ReplaceAll(layerTreeData, `"`, `\"`)
@@ -379,7 +377,7 @@ func CDPLayerTreeLoadSnapshot(layerTreeData string) (string, error) {
 	}`, reqID, escapedData)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 LayerTree.loadSnapshot 请求失败: %w", err)
 	}
@@ -393,7 +391,7 @@ func CDPLayerTreeLoadSnapshot(layerTreeData string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -486,8 +484,8 @@ func CDPLayerTreeMakeSnapshot(layerId string, format string, quality int) (strin
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -501,7 +499,7 @@ func CDPLayerTreeMakeSnapshot(layerId string, format string, quality int) (strin
 	}`, reqID, layerId, format, quality)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 LayerTree.makeSnapshot 请求失败: %w", err)
 	}
@@ -515,7 +513,7 @@ func CDPLayerTreeMakeSnapshot(layerId string, format string, quality int) (strin
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -600,8 +598,8 @@ func CDPLayerTreeProfileSnapshot(snapshotId string, minRepeatCount int, minDurat
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -615,7 +613,7 @@ func CDPLayerTreeProfileSnapshot(snapshotId string, minRepeatCount int, minDurat
 	}`, reqID, snapshotId, minRepeatCount, minDurationSeconds)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 LayerTree.profileSnapshot 请求失败: %w", err)
 	}
@@ -629,7 +627,7 @@ func CDPLayerTreeProfileSnapshot(snapshotId string, minRepeatCount int, minDurat
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -716,8 +714,8 @@ func CDPLayerTreeReleaseSnapshot(snapshotId string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -729,7 +727,7 @@ func CDPLayerTreeReleaseSnapshot(snapshotId string) (string, error) {
 	}`, reqID, snapshotId)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 LayerTree.releaseSnapshot 请求失败: %w", err)
 	}
@@ -743,7 +741,7 @@ func CDPLayerTreeReleaseSnapshot(snapshotId string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -840,8 +838,8 @@ func CDPLayerTreeReplaySnapshot(snapshotId string, fromStep int, toStep int, sca
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -856,7 +854,7 @@ func CDPLayerTreeReplaySnapshot(snapshotId string, fromStep int, toStep int, sca
 	}`, reqID, snapshotId, fromStep, toStep, scale)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 LayerTree.replaySnapshot 请求失败: %w", err)
 	}
@@ -870,7 +868,7 @@ func CDPLayerTreeReplaySnapshot(snapshotId string, fromStep int, toStep int, sca
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -956,8 +954,8 @@ func CDPLayerTreeSnapshotCommandLog(snapshotId string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -969,7 +967,7 @@ func CDPLayerTreeSnapshotCommandLog(snapshotId string) (string, error) {
 	}`, reqID, snapshotId)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 LayerTree.snapshotCommandLog 请求失败: %w", err)
 	}
@@ -983,7 +981,7 @@ func CDPLayerTreeSnapshotCommandLog(snapshotId string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
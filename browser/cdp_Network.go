@@ -6,8 +6,6 @@ import (
 	"fmt"
 	"log"
 	"time"
-
-	"github.com/gorilla/websocket"
 )
 
 // -----------------------------------------------  Network.clearBrowserCache  -----------------------------------------------
@@ -28,8 +26,8 @@ func CDPNetworkClearBrowserCache() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -38,7 +36,7 @@ func CDPNetworkClearBrowserCache() (string, error) {
 	}`, reqID)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 clearBrowserCache 请求失败: %w", err)
 	}
@@ -53,7 +51,7 @@ func CDPNetworkClearBrowserCache() (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -127,8 +125,8 @@ func CDPNetworkClearBrowserCookies() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -137,7 +135,7 @@ func CDPNetworkClearBrowserCookies() (string, error) {
 	}`, reqID)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 clearBrowserCookies 请求失败: %w", err)
 	}
@@ -152,7 +150,7 @@ func CDPNetworkClearBrowserCookies() (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -231,8 +229,8 @@ func CDPNetworkDeleteCookies(name, url, domain, path string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数
 	params := make(map[string]interface{})
@@ -261,7 +259,7 @@ func CDPNetworkDeleteCookies(name, url, domain, path string) (string, error) {
 	}`, reqID, string(paramsBytes))
 
 	// 发送WebSocket消息
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 deleteCookies 请求失败: %w", err)
 	}
@@ -276,7 +274,7 @@ func CDPNetworkDeleteCookies(name, url, domain, path string) (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -346,8 +344,8 @@ func CDPNetworkDisable() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -356,7 +354,7 @@ func CDPNetworkDisable() (string, error) {
 	}`, reqID)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 disable 请求失败: %w", err)
 	}
@@ -371,7 +369,7 @@ func CDPNetworkDisable() (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -446,8 +444,8 @@ func CDPNetworkEnable(maxTotalBufferSize, maxResourceBufferSize, maxPostDataSize
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数
 	params := make(map[string]interface{})
@@ -475,7 +473,7 @@ func CDPNetworkEnable(maxTotalBufferSize, maxResourceBufferSize, maxPostDataSize
 	}`, reqID, string(paramsBytes))
 
 	// 发送WebSocket消息
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 enable 请求失败: %w", err)
 	}
@@ -490,7 +488,7 @@ func CDPNetworkEnable(maxTotalBufferSize, maxResourceBufferSize, maxPostDataSize
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -562,8 +560,8 @@ func CDPNetworkGetCookies() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -572,7 +570,7 @@ func CDPNetworkGetCookies() (string, error) {
 	}`, reqID)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getCookies 请求失败: %w", err)
 	}
@@ -587,7 +585,7 @@ func CDPNetworkGetCookies() (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -666,8 +664,8 @@ func CDPNetworkGetRequestPostData(requestId string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数
 	params := map[string]string{"requestId": requestId}
@@ -684,7 +682,7 @@ func CDPNetworkGetRequestPostData(requestId string) (string, error) {
 	}`, reqID, string(paramsBytes))
 
 	// 发送WebSocket消息
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getRequestPostData 请求失败: %w", err)
 	}
@@ -699,7 +697,7 @@ func CDPNetworkGetRequestPostData(requestId string) (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -781,8 +779,8 @@ func CDPNetworkGetResponseBody(requestId string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数
 	params := map[string]string{"requestId": requestId}
@@ -799,7 +797,7 @@ func CDPNetworkGetResponseBody(requestId string) (string, error) {
 	}`, reqID, string(paramsBytes))
 
 	// 发送WebSocket消息
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getResponseBody 请求失败: %w", err)
 	}
@@ -814,7 +812,7 @@ func CDPNetworkGetResponseBody(requestId string) (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -897,8 +895,8 @@ func CDPNetworkSetBypassServiceWorker(bypass bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数
 	params := map[string]bool{"bypass": bypass}
@@ -915,7 +913,7 @@ func CDPNetworkSetBypassServiceWorker(bypass bool) (string, error) {
 	}`, reqID, string(paramsBytes))
 
 	// 发送WebSocket消息
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setBypassServiceWorker 请求失败: %w", err)
 	}
@@ -930,7 +928,7 @@ func CDPNetworkSetBypassServiceWorker(bypass bool) (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1002,8 +1000,8 @@ func CDPNetworkSetCacheDisabled(disabled bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数
 	params := map[string]bool{"disabled": disabled}
@@ -1020,7 +1018,7 @@ func CDPNetworkSetCacheDisabled(disabled bool) (string, error) {
 	}`, reqID, string(paramsBytes))
 
 	// 发送WebSocket消息
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setCacheDisabled 请求失败: %w", err)
 	}
@@ -1035,7 +1033,7 @@ func CDPNetworkSetCacheDisabled(disabled bool) (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1115,8 +1113,8 @@ func CDPNetworkSetCookie(name, value, url, domain, path string, secure, httpOnly
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数
 	params := make(map[string]interface{})
@@ -1156,7 +1154,7 @@ func CDPNetworkSetCookie(name, value, url, domain, path string, secure, httpOnly
 	}`, reqID, string(paramsBytes))
 
 	// 发送WebSocket消息
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setCookie 请求失败: %w", err)
 	}
@@ -1171,7 +1169,7 @@ func CDPNetworkSetCookie(name, value, url, domain, path string, secure, httpOnly
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1264,8 +1262,8 @@ func CDPNetworkSetCookies(cookies []map[string]interface{}) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数
 	params := map[string]interface{}{
@@ -1286,7 +1284,7 @@ func CDPNetworkSetCookies(cookies []map[string]interface{}) (string, error) {
 	}`, reqID, string(paramsBytes))
 
 	// 发送WebSocket消息
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setCookies 请求失败: %w", err)
 	}
@@ -1301,7 +1299,7 @@ func CDPNetworkSetCookies(cookies []map[string]interface{}) (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1415,8 +1413,8 @@ func CDPNetworkSetExtraHTTPHeaders(headers map[string]string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数（CDP要求 headers 字段值为字符串类型）
 	params := map[string]interface{}{
@@ -1437,7 +1435,7 @@ func CDPNetworkSetExtraHTTPHeaders(headers map[string]string) (string, error) {
 	}`, reqID, string(paramsBytes))
 
 	// 发送WebSocket消息
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setExtraHTTPHeaders 请求失败: %w", err)
 	}
@@ -1452,7 +1450,7 @@ func CDPNetworkSetExtraHTTPHeaders(headers map[string]string) (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1536,8 +1534,8 @@ func CDPNetworkSetUserAgentOverride(userAgent string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数
 	params := map[string]string{"userAgent": userAgent}
@@ -1554,7 +1552,7 @@ func CDPNetworkSetUserAgentOverride(userAgent string) (string, error) {
 	}`, reqID, string(paramsBytes))
 
 	// 发送WebSocket消息
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setUserAgentOverride 请求失败: %w", err)
 	}
@@ -1569,7 +1567,7 @@ func CDPNetworkSetUserAgentOverride(userAgent string) (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1642,8 +1640,8 @@ func CDPNetworkClearAcceptedEncodingsOverride() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -1652,7 +1650,7 @@ func CDPNetworkClearAcceptedEncodingsOverride() (string, error) {
 	}`, reqID)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 clearAcceptedEncodingsOverride 请求失败: %w", err)
 	}
@@ -1667,7 +1665,7 @@ func CDPNetworkClearAcceptedEncodingsOverride() (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1740,8 +1738,8 @@ func CDPNetworkConfigureDurableMessages(enabled bool, maxTotalSize int64) (strin
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数
 	params := make(map[string]interface{})
@@ -1764,7 +1762,7 @@ func CDPNetworkConfigureDurableMessages(enabled bool, maxTotalSize int64) (strin
 	}`, reqID, string(paramsBytes))
 
 	// 发送WebSocket消息
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 configureDurableMessages 请求失败: %w", err)
 	}
@@ -1779,7 +1777,7 @@ func CDPNetworkConfigureDurableMessages(enabled bool, maxTotalSize int64) (strin
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1856,8 +1854,8 @@ func CDPNetworkEmulateNetworkConditionsByRule(rule map[string]interface{}, downl
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数
 	params := map[string]interface{}{
@@ -1882,7 +1880,7 @@ func CDPNetworkEmulateNetworkConditionsByRule(rule map[string]interface{}, downl
 	}`, reqID, string(paramsBytes))
 
 	// 发送WebSocket消息
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 emulateNetworkConditionsByRule 请求失败: %w", err)
 	}
@@ -1897,7 +1895,7 @@ func CDPNetworkEmulateNetworkConditionsByRule(rule map[string]interface{}, downl
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1994,8 +1992,8 @@ func CDPNetworkEnableDeviceBoundSessions() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -2004,7 +2002,7 @@ func CDPNetworkEnableDeviceBoundSessions() (string, error) {
 	}`, reqID)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 enableDeviceBoundSessions 请求失败: %w", err)
 	}
@@ -2019,7 +2017,7 @@ func CDPNetworkEnableDeviceBoundSessions() (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2092,8 +2090,8 @@ func CDPNetworkEnableReportingApi() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -2102,7 +2100,7 @@ func CDPNetworkEnableReportingApi() (string, error) {
 	}`, reqID)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 enableReportingApi 请求失败: %w", err)
 	}
@@ -2117,7 +2115,7 @@ func CDPNetworkEnableReportingApi() (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2191,8 +2189,8 @@ func CDPNetworkFetchSchemefulSite(requestId string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数
 	params := map[string]string{"requestId": requestId}
@@ -2209,7 +2207,7 @@ func CDPNetworkFetchSchemefulSite(requestId string) (string, error) {
 	}`, reqID, string(paramsBytes))
 
 	// 发送WebSocket消息
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 fetchSchemefulSite 请求失败: %w", err)
 	}
@@ -2224,7 +2222,7 @@ func CDPNetworkFetchSchemefulSite(requestId string) (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2306,8 +2304,8 @@ func CDPNetworkGetCertificate(requestId string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数
 	params := map[string]string{"requestId": requestId}
@@ -2324,7 +2322,7 @@ func CDPNetworkGetCertificate(requestId string) (string, error) {
 	}`, reqID, string(paramsBytes))
 
 	// 发送WebSocket消息
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getCertificate 请求失败: %w", err)
 	}
@@ -2339,7 +2337,7 @@ func CDPNetworkGetCertificate(requestId string) (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2420,8 +2418,8 @@ func CDPNetworkGetResponseBodyForInterception(interceptionId string) (string, er
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数
 	params := map[string]string{"interceptionId": interceptionId}
@@ -2438,7 +2436,7 @@ func CDPNetworkGetResponseBodyForInterception(interceptionId string) (string, er
 	}`, reqID, string(paramsBytes))
 
 	// 发送WebSocket消息
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getResponseBodyForInterception 请求失败: %w", err)
 	}
@@ -2453,7 +2451,7 @@ func CDPNetworkGetResponseBodyForInterception(interceptionId string) (string, er
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2530,8 +2528,8 @@ func CDPNetworkGetSecurityIsolationStatus(frameId string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数（可选frameId）
 	params := make(map[string]interface{})
@@ -2551,7 +2549,7 @@ func CDPNetworkGetSecurityIsolationStatus(frameId string) (string, error) {
 	}`, reqID, string(paramsBytes))
 
 	// 发送WebSocket消息
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getSecurityIsolationStatus 请求失败: %w", err)
 	}
@@ -2566,7 +2564,7 @@ func CDPNetworkGetSecurityIsolationStatus(frameId string) (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2647,8 +2645,8 @@ func CDPNetworkLoadNetworkResource(url string, options map[string]interface{}) (
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数
 	params := map[string]interface{}{
@@ -2670,7 +2668,7 @@ func CDPNetworkLoadNetworkResource(url string, options map[string]interface{}) (
 	}`, reqID, string(paramsBytes))
 
 	// 发送WebSocket消息
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 loadNetworkResource 请求失败: %w", err)
 	}
@@ -2685,7 +2683,7 @@ func CDPNetworkLoadNetworkResource(url string, options map[string]interface{}) (
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2781,8 +2779,8 @@ func CDPNetworkOverrideNetworkState(
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数
 	params := map[string]interface{}{
@@ -2807,7 +2805,7 @@ func CDPNetworkOverrideNetworkState(
 	}`, reqID, string(paramsBytes))
 
 	// 发送WebSocket消息
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 overrideNetworkState 请求失败: %w", err)
 	}
@@ -2822,7 +2820,7 @@ func CDPNetworkOverrideNetworkState(
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2907,8 +2905,8 @@ func CDPNetworkReplayXHR(requestId string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数
 	params := map[string]string{"requestId": requestId}
@@ -2925,7 +2923,7 @@ func CDPNetworkReplayXHR(requestId string) (string, error) {
 	}`, reqID, string(paramsBytes))
 
 	// 发送WebSocket消息
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 replayXHR 请求失败: %w", err)
 	}
@@ -2940,7 +2938,7 @@ func CDPNetworkReplayXHR(requestId string) (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -3019,8 +3017,8 @@ func CDPNetworkSearchInResponseBody(requestId, searchString string) (string, err
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数
 	params := map[string]string{
@@ -3042,7 +3040,7 @@ func CDPNetworkSearchInResponseBody(requestId, searchString string) (string, err
 	}`, reqID, string(paramsBytes))
 
 	// 发送WebSocket消息
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 searchInResponseBody 请求失败: %w", err)
 	}
@@ -3057,7 +3055,7 @@ func CDPNetworkSearchInResponseBody(requestId, searchString string) (string, err
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -3141,8 +3139,8 @@ func CDPNetworkSetAcceptedEncodings(encodings []string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数
 	params := map[string]interface{}{
@@ -3163,7 +3161,7 @@ func CDPNetworkSetAcceptedEncodings(encodings []string) (string, error) {
 	}`, reqID, string(paramsBytes))
 
 	// 发送WebSocket消息
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setAcceptedEncodings 请求失败: %w", err)
 	}
@@ -3178,7 +3176,7 @@ func CDPNetworkSetAcceptedEncodings(encodings []string) (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -3252,8 +3250,8 @@ func CDPNetworkSetAttachDebugStack(enabled bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数
 	params := map[string]bool{"enabled": enabled}
@@ -3270,7 +3268,7 @@ func CDPNetworkSetAttachDebugStack(enabled bool) (string, error) {
 	}`, reqID, string(paramsBytes))
 
 	// 发送WebSocket消息
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setAttachDebugStack 请求失败: %w", err)
 	}
@@ -3285,7 +3283,7 @@ func CDPNetworkSetAttachDebugStack(enabled bool) (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -3357,8 +3355,8 @@ func CDPNetworkSetBlockedURLs(urls []string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数
 	params := map[string]interface{}{
@@ -3379,7 +3377,7 @@ func CDPNetworkSetBlockedURLs(urls []string) (string, error) {
 	}`, reqID, string(paramsBytes))
 
 	// 发送WebSocket消息
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setBlockedURLs 请求失败: %w", err)
 	}
@@ -3394,7 +3392,7 @@ func CDPNetworkSetBlockedURLs(urls []string) (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -3473,8 +3471,8 @@ func CDPNetworkSetCookieControls(enableWrite bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数：实验性字段，控制cookie写入
 	params := map[string]bool{
@@ -3493,7 +3491,7 @@ func CDPNetworkSetCookieControls(enableWrite bool) (string, error) {
 	}`, reqID, string(paramsBytes))
 
 	// 发送消息
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setCookieControls 请求失败: %w", err)
 	}
@@ -3507,7 +3505,7 @@ func CDPNetworkSetCookieControls(enableWrite bool) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -3575,8 +3573,8 @@ func CDPNetworkStreamResourceContent(requestId string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数
 	params := map[string]string{
@@ -3597,7 +3595,7 @@ func CDPNetworkStreamResourceContent(requestId string) (string, error) {
 	}`, reqID, string(paramsBytes))
 
 	// 发送WebSocket消息
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 streamResourceContent 请求失败: %w", err)
 	}
@@ -3611,7 +3609,7 @@ func CDPNetworkStreamResourceContent(requestId string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -3683,8 +3681,8 @@ func CDPNetworkTakeResponseBodyForInterceptionAsStream(interceptionId string) (s
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数
 	params := map[string]string{
@@ -3705,7 +3703,7 @@ func CDPNetworkTakeResponseBodyForInterceptionAsStream(interceptionId string) (s
 	}`, reqID, string(paramsBytes))
 
 	// 发送WebSocket消息
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 takeResponseBodyForInterceptionAsStream 请求失败: %w", err)
 	}
@@ -3719,7 +3717,7 @@ func CDPNetworkTakeResponseBodyForInterceptionAsStream(interceptionId string) (s
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -6,8 +6,6 @@ import (
 	"fmt"
 	"log"
 	"time"
-
-	"github.com/gorilla/websocket"
 )
 
 // -----------------------------------------------  Overlay.disable  -----------------------------------------------
@@ -28,8 +26,8 @@ func CDPOverlayDisable() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -38,7 +36,7 @@ func CDPOverlayDisable() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Overlay.disable 请求失败: %w", err)
 	}
@@ -52,7 +50,7 @@ func CDPOverlayDisable() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -149,8 +147,8 @@ func CDPOverlayEnable() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -159,7 +157,7 @@ func CDPOverlayEnable() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Overlay.enable 请求失败: %w", err)
 	}
@@ -173,7 +171,7 @@ func CDPOverlayEnable() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -271,8 +269,8 @@ func CDPOverlayGetGridHighlightObjectsForTest() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -281,7 +279,7 @@ func CDPOverlayGetGridHighlightObjectsForTest() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Overlay.getGridHighlightObjectsForTest 请求失败: %w", err)
 	}
@@ -295,7 +293,7 @@ func CDPOverlayGetGridHighlightObjectsForTest() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -391,8 +389,8 @@ func CDPOverlayGetHighlightObjectForTest(nodeId int) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -404,7 +402,7 @@ func CDPOverlayGetHighlightObjectForTest(nodeId int) (string, error) {
 	}`, reqID, nodeId)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Overlay.getHighlightObjectForTest 请求失败: %w", err)
 	}
@@ -418,7 +416,7 @@ func CDPOverlayGetHighlightObjectForTest(nodeId int) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -518,8 +516,8 @@ func CDPOverlayGetSourceOrderHighlightObjectForTest(nodeId int) (string, error)
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -531,7 +529,7 @@ func CDPOverlayGetSourceOrderHighlightObjectForTest(nodeId int) (string, error)
 	}`, reqID, nodeId)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Overlay.getSourceOrderHighlightObjectForTest 请求失败: %w", err)
 	}
@@ -545,7 +543,7 @@ func CDPOverlayGetSourceOrderHighlightObjectForTest(nodeId int) (string, error)
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -639,8 +637,8 @@ func CDPOverlayHideHighlight() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -649,7 +647,7 @@ func CDPOverlayHideHighlight() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Overlay.hideHighlight 请求失败: %w", err)
 	}
@@ -663,7 +661,7 @@ func CDPOverlayHideHighlight() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -763,8 +761,8 @@ func CDPOverlayHighlightNode(nodeId int, color string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -782,7 +780,7 @@ func CDPOverlayHighlightNode(nodeId int, color string) (string, error) {
 	}`, reqID, nodeId, color, color)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Overlay.highlightNode 请求失败: %w", err)
 	}
@@ -796,7 +794,7 @@ func CDPOverlayHighlightNode(nodeId int, color string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -895,8 +893,8 @@ func CDPOverlayHighlightQuad(quad string, color string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -910,7 +908,7 @@ func CDPOverlayHighlightQuad(quad string, color string) (string, error) {
 	}`, reqID, quad, color)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Overlay.highlightQuad 请求失败: %w", err)
 	}
@@ -924,7 +922,7 @@ func CDPOverlayHighlightQuad(quad string, color string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1022,8 +1020,8 @@ func CDPOverlayHighlightRect(x int, y int, width int, height int, color string)
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -1040,7 +1038,7 @@ func CDPOverlayHighlightRect(x int, y int, width int, height int, color string)
 	}`, reqID, x, y, width, height, color)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Overlay.highlightRect 请求失败: %w", err)
 	}
@@ -1054,7 +1052,7 @@ func CDPOverlayHighlightRect(x int, y int, width int, height int, color string)
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1143,8 +1141,8 @@ func CDPOverlayHighlightSourceOrder(nodeID int, color string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建SourceOrderConfig（样式配置）
 	sourceOrderConfig := fmt.Sprintf(`{
@@ -1164,7 +1162,7 @@ func CDPOverlayHighlightSourceOrder(nodeID int, color string) (string, error) {
 	}`, reqID, nodeID, sourceOrderConfig)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Overlay.highlightSourceOrder 请求失败: %w", err)
 	}
@@ -1178,7 +1176,7 @@ func CDPOverlayHighlightSourceOrder(nodeID int, color string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1283,8 +1281,8 @@ func CDPOverlaySetInspectMode(mode string, highlightConfig string) (string, erro
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -1297,7 +1295,7 @@ func CDPOverlaySetInspectMode(mode string, highlightConfig string) (string, erro
 	}`, reqID, mode, highlightConfig)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Overlay.setInspectMode 请求失败: %w", err)
 	}
@@ -1311,7 +1309,7 @@ func CDPOverlaySetInspectMode(mode string, highlightConfig string) (string, erro
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1419,8 +1417,8 @@ func CDPOverlaySetPausedInDebuggerMessage(message string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息（message为nil时隐藏提示）
 	var messageParam string
@@ -1439,7 +1437,7 @@ func CDPOverlaySetPausedInDebuggerMessage(message string) (string, error) {
 	}`, reqID, messageParam)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(messageBody))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(messageBody))
 	if err != nil {
 		return "", fmt.Errorf("发送 Overlay.setPausedInDebuggerMessage 请求失败: %w", err)
 	}
@@ -1453,7 +1451,7 @@ func CDPOverlaySetPausedInDebuggerMessage(message string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1548,8 +1546,8 @@ func CDPOverlaySetShowAdHighlights(show bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -1561,7 +1559,7 @@ func CDPOverlaySetShowAdHighlights(show bool) (string, error) {
 	}`, reqID, show)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Overlay.setShowAdHighlights 请求失败: %w", err)
 	}
@@ -1575,7 +1573,7 @@ func CDPOverlaySetShowAdHighlights(show bool) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1673,8 +1671,8 @@ func CDPOverlaySetShowContainerQueryOverlays(show bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -1686,7 +1684,7 @@ func CDPOverlaySetShowContainerQueryOverlays(show bool) (string, error) {
 	}`, reqID, show)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Overlay.setShowContainerQueryOverlays 请求失败: %w", err)
 	}
@@ -1700,7 +1698,7 @@ func CDPOverlaySetShowContainerQueryOverlays(show bool) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1796,8 +1794,8 @@ func CDPOverlaySetShowDebugBorders(show bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -1809,7 +1807,7 @@ func CDPOverlaySetShowDebugBorders(show bool) (string, error) {
 	}`, reqID, show)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Overlay.setShowDebugBorders 请求失败: %w", err)
 	}
@@ -1823,7 +1821,7 @@ func CDPOverlaySetShowDebugBorders(show bool) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1920,8 +1918,8 @@ func CDPOverlaySetShowFlexOverlays(show bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -1933,7 +1931,7 @@ func CDPOverlaySetShowFlexOverlays(show bool) (string, error) {
 	}`, reqID, show)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Overlay.setShowFlexOverlays 请求失败: %w", err)
 	}
@@ -1947,7 +1945,7 @@ func CDPOverlaySetShowFlexOverlays(show bool) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2043,8 +2041,8 @@ func CDPOverlaySetShowFPSCounter(show bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -2056,7 +2054,7 @@ func CDPOverlaySetShowFPSCounter(show bool) (string, error) {
 	}`, reqID, show)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Overlay.setShowFPSCounter 请求失败: %w", err)
 	}
@@ -2070,7 +2068,7 @@ func CDPOverlaySetShowFPSCounter(show bool) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2166,8 +2164,8 @@ func CDPOverlaySetShowGridOverlays(show bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -2179,7 +2177,7 @@ func CDPOverlaySetShowGridOverlays(show bool) (string, error) {
 	}`, reqID, show)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Overlay.setShowGridOverlays 请求失败: %w", err)
 	}
@@ -2193,7 +2191,7 @@ func CDPOverlaySetShowGridOverlays(show bool) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2289,8 +2287,8 @@ func CDPOverlaySetShowHinge(show bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -2302,7 +2300,7 @@ func CDPOverlaySetShowHinge(show bool) (string, error) {
 	}`, reqID, show)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Overlay.setShowHinge 请求失败: %w", err)
 	}
@@ -2316,7 +2314,7 @@ func CDPOverlaySetShowHinge(show bool) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2410,8 +2408,8 @@ func CDPOverlaySetShowInspectedElementAnchor(show bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -2423,7 +2421,7 @@ func CDPOverlaySetShowInspectedElementAnchor(show bool) (string, error) {
 	}`, reqID, show)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Overlay.setShowInspectedElementAnchor 请求失败: %w", err)
 	}
@@ -2437,7 +2435,7 @@ func CDPOverlaySetShowInspectedElementAnchor(show bool) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2534,8 +2532,8 @@ func CDPOverlaySetShowIsolatedElements(show bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -2547,7 +2545,7 @@ func CDPOverlaySetShowIsolatedElements(show bool) (string, error) {
 	}`, reqID, show)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Overlay.setShowIsolatedElements 请求失败: %w", err)
 	}
@@ -2561,7 +2559,7 @@ func CDPOverlaySetShowIsolatedElements(show bool) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2658,8 +2656,8 @@ func CDPOverlaySetShowLayoutShiftRegions(show bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -2671,7 +2669,7 @@ func CDPOverlaySetShowLayoutShiftRegions(show bool) (string, error) {
 	}`, reqID, show)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Overlay.setShowLayoutShiftRegions 请求失败: %w", err)
 	}
@@ -2685,7 +2683,7 @@ func CDPOverlaySetShowLayoutShiftRegions(show bool) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2779,8 +2777,8 @@ func CDPOverlaySetShowPaintRects(show bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -2792,7 +2790,7 @@ func CDPOverlaySetShowPaintRects(show bool) (string, error) {
 	}`, reqID, show)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Overlay.setShowPaintRects 请求失败: %w", err)
 	}
@@ -2806,7 +2804,7 @@ func CDPOverlaySetShowPaintRects(show bool) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2902,8 +2900,8 @@ func CDPOverlaySetShowScrollBottleneckRects(show bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -2915,7 +2913,7 @@ func CDPOverlaySetShowScrollBottleneckRects(show bool) (string, error) {
 	}`, reqID, show)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Overlay.setShowScrollBottleneckRects 请求失败: %w", err)
 	}
@@ -2929,7 +2927,7 @@ func CDPOverlaySetShowScrollBottleneckRects(show bool) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -3023,8 +3021,8 @@ func CDPOverlaySetShowScrollSnapOverlays(show bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -3036,7 +3034,7 @@ func CDPOverlaySetShowScrollSnapOverlays(show bool) (string, error) {
 	}`, reqID, show)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Overlay.setShowScrollSnapOverlays 请求失败: %w", err)
 	}
@@ -3050,7 +3048,7 @@ func CDPOverlaySetShowScrollSnapOverlays(show bool) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -3144,8 +3142,8 @@ func CDPOverlaySetShowViewportSizeOnResize(show bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -3157,7 +3155,7 @@ func CDPOverlaySetShowViewportSizeOnResize(show bool) (string, error) {
 	}`, reqID, show)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Overlay.setShowViewportSizeOnResize 请求失败: %w", err)
 	}
@@ -3171,7 +3169,7 @@ func CDPOverlaySetShowViewportSizeOnResize(show bool) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -3265,8 +3263,8 @@ func CDPOverlaySetShowWindowControlsOverlay(show bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -3278,7 +3276,7 @@ func CDPOverlaySetShowWindowControlsOverlay(show bool) (string, error) {
 	}`, reqID, show)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Overlay.setShowWindowControlsOverlay 请求失败: %w", err)
 	}
@@ -3292,7 +3290,7 @@ func CDPOverlaySetShowWindowControlsOverlay(show bool) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
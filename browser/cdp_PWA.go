@@ -6,8 +6,6 @@ import (
 	"fmt"
 	"log"
 	"time"
-
-	"github.com/gorilla/websocket"
 )
 
 // -----------------------------------------------  PWA.changeAppUserSettings  -----------------------------------------------
@@ -29,8 +27,8 @@ func CDPPWAChangeAppUserSettings(settings map[string]interface{}) (string, error
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 序列化参数
 	paramsBytes, err := json.Marshal(settings)
@@ -46,7 +44,7 @@ func CDPPWAChangeAppUserSettings(settings map[string]interface{}) (string, error
 	}`, reqID, string(paramsBytes))
 
 	// 发送 WebSocket 消息
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 changeAppUserSettings 请求失败: %w", err)
 	}
@@ -60,7 +58,7 @@ func CDPPWAChangeAppUserSettings(settings map[string]interface{}) (string, error
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -145,8 +143,8 @@ func CDPPWAGetOsAppState() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建 CDP 请求消息
 	message := fmt.Sprintf(`{
@@ -155,7 +153,7 @@ func CDPPWAGetOsAppState() (string, error) {
 	}`, reqID)
 
 	// 发送 WebSocket 消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getOsAppState 请求失败: %w", err)
 	}
@@ -169,7 +167,7 @@ func CDPPWAGetOsAppState() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -253,8 +251,8 @@ func CDPPWAInstall() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建 CDP 请求消息
 	message := fmt.Sprintf(`{
@@ -263,7 +261,7 @@ func CDPPWAInstall() (string, error) {
 	}`, reqID)
 
 	// 发送 WebSocket 消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 PWA.install 请求失败: %w", err)
 	}
@@ -277,7 +275,7 @@ func CDPPWAInstall() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -372,8 +370,8 @@ func CDPPWALaunch() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建 CDP 请求消息
 	message := fmt.Sprintf(`{
@@ -382,7 +380,7 @@ func CDPPWALaunch() (string, error) {
 	}`, reqID)
 
 	// 发送 WebSocket 消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 PWA.launch 请求失败: %w", err)
 	}
@@ -396,7 +394,7 @@ func CDPPWALaunch() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -482,8 +480,8 @@ func CDPPWALaunchFilesInApp(filePaths []string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数
 	params := map[string]interface{}{
@@ -502,7 +500,7 @@ func CDPPWALaunchFilesInApp(filePaths []string) (string, error) {
 	}`, reqID, string(paramsBytes))
 
 	// 发送 WebSocket 消息
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 PWA.launchFilesInApp 请求失败: %w", err)
 	}
@@ -516,7 +514,7 @@ func CDPPWALaunchFilesInApp(filePaths []string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -605,8 +603,8 @@ func CDPPWAOpenCurrentPageInApp() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建 CDP 请求消息
 	message := fmt.Sprintf(`{
@@ -615,7 +613,7 @@ func CDPPWAOpenCurrentPageInApp() (string, error) {
 	}`, reqID)
 
 	// 发送 WebSocket 消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 PWA.openCurrentPageInApp 请求失败: %w", err)
 	}
@@ -629,7 +627,7 @@ func CDPPWAOpenCurrentPageInApp() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -712,8 +710,8 @@ func CDPPWAUninstall() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建 CDP 请求消息
 	message := fmt.Sprintf(`{
@@ -722,7 +720,7 @@ func CDPPWAUninstall() (string, error) {
 	}`, reqID)
 
 	// 发送 WebSocket 消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 PWA.uninstall 请求失败: %w", err)
 	}
@@ -736,7 +734,7 @@ func CDPPWAUninstall() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
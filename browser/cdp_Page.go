@@ -8,8 +8,6 @@ import (
 	"log"
 	"strconv"
 	"time"
-
-	"github.com/gorilla/websocket"
 )
 
 // 获取当前页面框架ID
@@ -62,8 +60,8 @@ func CDPPageAddScriptToEvaluateOnNewDocument(source string) (string, string, err
 		return "", "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -75,7 +73,7 @@ func CDPPageAddScriptToEvaluateOnNewDocument(source string) (string, string, err
 	}`, reqID, strconv.Quote(source))
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", "", fmt.Errorf("发送 addScriptToEvaluateOnNewDocument 请求失败: %w", err)
 	}
@@ -89,7 +87,7 @@ func CDPPageAddScriptToEvaluateOnNewDocument(source string) (string, string, err
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", "", fmt.Errorf("消息队列已关闭")
 			}
@@ -200,8 +198,8 @@ func CDPPageBringToFront() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -210,7 +208,7 @@ func CDPPageBringToFront() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 bringToFront 请求失败: %w", err)
 	}
@@ -224,7 +222,7 @@ func CDPPageBringToFront() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -313,8 +311,8 @@ func CDPPageCaptureScreenshot(format string, quality int) (string, string, error
 		return "", "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -327,7 +325,7 @@ func CDPPageCaptureScreenshot(format string, quality int) (string, string, error
 	}`, reqID, format, quality)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", "", fmt.Errorf("发送 captureScreenshot 请求失败: %w", err)
 	}
@@ -341,7 +339,7 @@ func CDPPageCaptureScreenshot(format string, quality int) (string, string, error
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", "", fmt.Errorf("消息队列已关闭")
 			}
@@ -469,8 +467,8 @@ func CDPPageClose() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -479,7 +477,7 @@ func CDPPageClose() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 close 请求失败: %w", err)
 	}
@@ -493,7 +491,7 @@ func CDPPageClose() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -598,8 +596,8 @@ func CDPPageCreateIsolatedWorld(frameId string, worldName string, grantUniveralA
 		return "", "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数（frameId 可选，为空则不传递）
 	var params string
@@ -619,7 +617,7 @@ func CDPPageCreateIsolatedWorld(frameId string, worldName string, grantUniveralA
 	}`, reqID, params)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", "", fmt.Errorf("发送 createIsolatedWorld 请求失败: %w", err)
 	}
@@ -633,7 +631,7 @@ func CDPPageCreateIsolatedWorld(frameId string, worldName string, grantUniveralA
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", "", fmt.Errorf("消息队列已关闭")
 			}
@@ -731,8 +729,8 @@ func CDPPageDisable() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -741,7 +739,7 @@ func CDPPageDisable() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 disable 请求失败: %w", err)
 	}
@@ -755,7 +753,7 @@ func CDPPageDisable() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -853,8 +851,8 @@ func CDPPageEnable() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -863,7 +861,7 @@ func CDPPageEnable() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 enable 请求失败: %w", err)
 	}
@@ -877,7 +875,7 @@ func CDPPageEnable() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -983,8 +981,8 @@ func CDPPageGetAppManifest(manifestId string) (url, data string, errors []AppMan
 		return "", "", nil, fmt.Errorf("WebSocket 未连接")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建请求（带可选 manifestId）
 	var params string
@@ -998,7 +996,7 @@ func CDPPageGetAppManifest(manifestId string) (url, data string, errors []AppMan
 	}`, reqID, params)
 
 	// 发送
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", "", nil, fmt.Errorf("发送失败: %w", err)
 	}
@@ -1011,7 +1009,7 @@ func CDPPageGetAppManifest(manifestId string) (url, data string, errors []AppMan
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", "", nil, fmt.Errorf("消息队列关闭")
 			}
@@ -1153,8 +1151,8 @@ func CDPPageGetFrameTree() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -1163,7 +1161,7 @@ func CDPPageGetFrameTree() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getFrameTree 请求失败: %w", err)
 	}
@@ -1177,7 +1175,7 @@ func CDPPageGetFrameTree() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1317,8 +1315,8 @@ func CDPPageGetLayoutMetrics() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -1327,7 +1325,7 @@ func CDPPageGetLayoutMetrics() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getLayoutMetrics 请求失败: %w", err)
 	}
@@ -1341,7 +1339,7 @@ func CDPPageGetLayoutMetrics() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1467,8 +1465,8 @@ func CDPPageGetNavigationHistory() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -1477,7 +1475,7 @@ func CDPPageGetNavigationHistory() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getNavigationHistory 请求失败: %w", err)
 	}
@@ -1491,7 +1489,7 @@ func CDPPageGetNavigationHistory() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1635,8 +1633,8 @@ func CDPPageHandleJavaScriptDialog(accept bool, promptText string) (string, erro
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数
 	var params string
@@ -1658,7 +1656,7 @@ func CDPPageHandleJavaScriptDialog(accept bool, promptText string) (string, erro
 	}`, reqID, params)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 handleJavaScriptDialog 请求失败: %w", err)
 	}
@@ -1672,7 +1670,7 @@ func CDPPageHandleJavaScriptDialog(accept bool, promptText string) (string, erro
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1765,8 +1763,8 @@ func CDPPageNavigate(url string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -1778,7 +1776,7 @@ func CDPPageNavigate(url string) (string, error) {
 	}`, reqID, strconv.Quote(url))
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 navigate 请求失败: %w", err)
 	}
@@ -1792,7 +1790,7 @@ func CDPPageNavigate(url string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1860,8 +1858,7 @@ func ExampleNavigateWithReferrer() {
 
 	// 扩展用法：如需带 referrer，可扩展参数
 	// 这里使用完整参数格式演示
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID := GetNextMsgID()
 	msg := fmt.Sprintf(`{
 		"id": %d,
 		"method": "Page.navigate",
@@ -1871,7 +1868,7 @@ func ExampleNavigateWithReferrer() {
 		}
 	}`, reqID, strconv.Quote(url), strconv.Quote(referrer))
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(msg))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(msg))
 	if err != nil {
 		log.Printf("带referrer跳转失败: %v", err)
 		return
@@ -1900,8 +1897,8 @@ func CDPPageNavigateToHistoryEntry(entryId int64) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -1913,7 +1910,7 @@ func CDPPageNavigateToHistoryEntry(entryId int64) (string, error) {
 	}`, reqID, entryId)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 navigateToHistoryEntry 请求失败: %w", err)
 	}
@@ -1927,7 +1924,7 @@ func CDPPageNavigateToHistoryEntry(entryId int64) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2065,8 +2062,8 @@ func CDPPagePrintToPDF(landscape bool, printBackground bool, paperWidth float64,
 		return "", "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -2081,7 +2078,7 @@ func CDPPagePrintToPDF(landscape bool, printBackground bool, paperWidth float64,
 	}`, reqID, landscape, printBackground, paperWidth, paperHeight)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", "", fmt.Errorf("发送 printToPDF 请求失败: %w", err)
 	}
@@ -2095,7 +2092,7 @@ func CDPPagePrintToPDF(landscape bool, printBackground bool, paperWidth float64,
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2214,8 +2211,8 @@ func CDPPageReload(ignoreCache bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -2227,7 +2224,7 @@ func CDPPageReload(ignoreCache bool) (string, error) {
 	}`, reqID, ignoreCache)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 reload 请求失败: %w", err)
 	}
@@ -2241,7 +2238,7 @@ func CDPPageReload(ignoreCache bool) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2355,8 +2352,8 @@ func CDPPageRemoveScriptToEvaluateOnNewDocument(identifier string) (string, erro
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -2368,7 +2365,7 @@ func CDPPageRemoveScriptToEvaluateOnNewDocument(identifier string) (string, erro
 	}`, reqID, identifier)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 removeScriptToEvaluateOnNewDocument 请求失败: %w", err)
 	}
@@ -2382,7 +2379,7 @@ func CDPPageRemoveScriptToEvaluateOnNewDocument(identifier string) (string, erro
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2479,8 +2476,8 @@ func CDPPageResetNavigationHistory() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建命令（无参数）
 	message := fmt.Sprintf(`{
@@ -2489,7 +2486,7 @@ func CDPPageResetNavigationHistory() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 resetNavigationHistory 请求失败: %w", err)
 	}
@@ -2502,7 +2499,7 @@ func CDPPageResetNavigationHistory() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2600,8 +2597,8 @@ func CDPPageSetBypassCSP(bypass bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -2613,7 +2610,7 @@ func CDPPageSetBypassCSP(bypass bool) (string, error) {
 	}`, reqID, bypass)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setBypassCSP 请求失败: %w", err)
 	}
@@ -2627,7 +2624,7 @@ func CDPPageSetBypassCSP(bypass bool) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2724,8 +2721,8 @@ func CDPPageSetDocumentContent(frameId string, html string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数
 	var params string
@@ -2745,7 +2742,7 @@ func CDPPageSetDocumentContent(frameId string, html string) (string, error) {
 	}`, reqID, params)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setDocumentContent 请求失败: %w", err)
 	}
@@ -2759,7 +2756,7 @@ func CDPPageSetDocumentContent(frameId string, html string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2876,8 +2873,8 @@ func CDPPageSetInterceptFileChooserDialog(enabled bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -2889,7 +2886,7 @@ func CDPPageSetInterceptFileChooserDialog(enabled bool) (string, error) {
 	}`, reqID, enabled)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setInterceptFileChooserDialog 请求失败: %w", err)
 	}
@@ -2903,7 +2900,7 @@ func CDPPageSetInterceptFileChooserDialog(enabled bool) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2995,8 +2992,8 @@ func CDPPageSetLifecycleEventsEnabled(enabled bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket未连接")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	message := fmt.Sprintf(`{
 		"id": %d,
@@ -3006,7 +3003,7 @@ func CDPPageSetLifecycleEventsEnabled(enabled bool) (string, error) {
 		}
 	}`, reqID, enabled)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送生命周期事件开关失败: %w", err)
 	}
@@ -3019,7 +3016,7 @@ func CDPPageSetLifecycleEventsEnabled(enabled bool) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -3110,8 +3107,8 @@ func CDPPageStopLoading() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket未连接")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建命令（无参数）
 	message := fmt.Sprintf(`{
@@ -3120,7 +3117,7 @@ func CDPPageStopLoading() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 stopLoading 请求失败: %w", err)
 	}
@@ -3134,7 +3131,7 @@ func CDPPageStopLoading() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -3228,8 +3225,8 @@ func CDPPageAddCompilationCache(url string, cacheData []byte) (string, error) {
 		return "", fmt.Errorf("WebSocket 未连接")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// data 必须是 Base64 字符串
 	dataBase64 := base64.StdEncoding.EncodeToString(cacheData)
@@ -3243,7 +3240,7 @@ func CDPPageAddCompilationCache(url string, cacheData []byte) (string, error) {
 		}
 	}`, reqID, url, dataBase64)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 addCompilationCache 失败: %w", err)
 	}
@@ -3257,7 +3254,7 @@ func CDPPageAddCompilationCache(url string, cacheData []byte) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列关闭")
 			}
@@ -3283,8 +3280,7 @@ func CDPPageAddCompilationCache(url string, cacheData []byte) (string, error) {
 // 主动生成指定脚本的编译缓存（用于获取 data）
 func CDPPageProduceCompilationCache(scripts []CompilationCacheParams) (string, error) {
 	// 结构同官方：scripts 数组，每个含 url
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID := GetNextMsgID()
 	scriptJson, _ := json.Marshal(scripts)
 	msg := fmt.Sprintf(`{
 		"id": %d,
@@ -3338,8 +3334,8 @@ func CDPPageCaptureSnapshot(format string) (string, error) {
 		return "", fmt.Errorf("WebSocket未连接")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数（默认mhtml）
 	params := "{}"
@@ -3355,7 +3351,7 @@ func CDPPageCaptureSnapshot(format string) (string, error) {
 	}`, reqID, params)
 
 	// 发送消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送captureSnapshot失败: %w", err)
 	}
@@ -3369,7 +3365,7 @@ func CDPPageCaptureSnapshot(format string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列关闭")
 			}
@@ -3447,8 +3443,8 @@ func CDPPageClearCompilationCache() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息（无参数）
 	message := fmt.Sprintf(`{
@@ -3457,7 +3453,7 @@ func CDPPageClearCompilationCache() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 clearCompilationCache 请求失败: %w", err)
 	}
@@ -3471,7 +3467,7 @@ func CDPPageClearCompilationCache() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -3553,8 +3549,8 @@ func CDPPageCrash() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket未连接")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建命令（无参数）
 	message := fmt.Sprintf(`{
@@ -3563,7 +3559,7 @@ func CDPPageCrash() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 crash 请求失败: %w", err)
 	}
@@ -3577,7 +3573,7 @@ func CDPPageCrash() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列关闭（页面已崩溃）")
 			}
@@ -3643,8 +3639,8 @@ func CDPPageGenerateTestReport(message string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket未连接")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建命令
 	messageBody := fmt.Sprintf(`{
@@ -3656,7 +3652,7 @@ func CDPPageGenerateTestReport(message string) (string, error) {
 	}`, reqID, strconv.Quote(message))
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(messageBody))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(messageBody))
 	if err != nil {
 		return "", fmt.Errorf("发送 generateTestReport 请求失败: %w", err)
 	}
@@ -3670,7 +3666,7 @@ func CDPPageGenerateTestReport(message string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -3722,8 +3718,8 @@ func CDPPageGetAdScriptAncestry(frameId string) (*AdScriptAncestry, string, erro
 		return nil, "", fmt.Errorf("浏览器WebSocket未连接")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建命令
 	message := fmt.Sprintf(`{
@@ -3735,7 +3731,7 @@ func CDPPageGetAdScriptAncestry(frameId string) (*AdScriptAncestry, string, erro
 	}`, reqID, strconv.Quote(frameId))
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return nil, "", fmt.Errorf("发送getAdScriptAncestry请求失败: %w", err)
 	}
@@ -3749,7 +3745,7 @@ func CDPPageGetAdScriptAncestry(frameId string) (*AdScriptAncestry, string, erro
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return nil, "", fmt.Errorf("消息队列已关闭")
 			}
@@ -3829,15 +3825,15 @@ func CDPPageGetAnnotatedPageContent() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket未连接")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "Page.getAnnotatedPageContent"
 	}`, reqID)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getAnnotatedPageContent 失败: %w", err)
 	}
@@ -3850,7 +3846,7 @@ func CDPPageGetAnnotatedPageContent() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列关闭")
 			}
@@ -3907,15 +3903,15 @@ func CDPPageGetAppId() (string, error) {
 		return "", fmt.Errorf("浏览器 WebSocket 未连接")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "Page.getAppId"
 	}`, reqID)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getAppId 请求失败: %w", err)
 	}
@@ -3928,7 +3924,7 @@ func CDPPageGetAppId() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -3986,15 +3982,15 @@ func CDPPageGetInstallabilityErrors() ([]InstallabilityError, error) {
 		return nil, fmt.Errorf("浏览器WebSocket未连接")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "Page.getInstallabilityErrors"
 	}`, reqID)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return nil, fmt.Errorf("发送getInstallabilityErrors失败: %w", err)
 	}
@@ -4005,7 +4001,7 @@ func CDPPageGetInstallabilityErrors() ([]InstallabilityError, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return nil, fmt.Errorf("消息队列关闭")
 			}
@@ -4081,15 +4077,15 @@ func CDPPageGetOriginTrials() ([]OriginTrial, error) {
 		return nil, fmt.Errorf("浏览器 WebSocket 未连接")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	msg := fmt.Sprintf(`{
 		"id": %d,
 		"method": "Page.getOriginTrials"
 	}`, reqID)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(msg))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(msg))
 	if err != nil {
 		return nil, fmt.Errorf("发送 getOriginTrials 失败: %w", err)
 	}
@@ -4100,7 +4096,7 @@ func CDPPageGetOriginTrials() ([]OriginTrial, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return nil, fmt.Errorf("消息队列已关闭")
 			}
@@ -4148,8 +4144,8 @@ func CDPPageGetPermissionsPolicyState(frameID string) ([]PermissionsPolicyFeatur
 		return nil, fmt.Errorf("浏览器 WebSocket 未连接")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 可选参数 frameId：指定 frame，不填则为当前主 frame
 	var params string
@@ -4163,7 +4159,7 @@ func CDPPageGetPermissionsPolicyState(frameID string) ([]PermissionsPolicyFeatur
 		%s
 	}`, reqID, params)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(msg))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(msg))
 	if err != nil {
 		return nil, fmt.Errorf("发送 getPermissionsPolicyState 失败: %w", err)
 	}
@@ -4174,7 +4170,7 @@ func CDPPageGetPermissionsPolicyState(frameID string) ([]PermissionsPolicyFeatur
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return nil, fmt.Errorf("消息队列已关闭")
 			}
@@ -4259,8 +4255,8 @@ func CDPPageGetResourceContent(frameId, url string) (content string, base64 bool
 		return "", false, fmt.Errorf("浏览器 WebSocket 未连接")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数
 	params := map[string]interface{}{
@@ -4278,7 +4274,7 @@ func CDPPageGetResourceContent(frameId, url string) (content string, base64 bool
 	}`, reqID, paramBytes)
 
 	// 发送
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(msg))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(msg))
 	if err != nil {
 		return "", false, fmt.Errorf("发送请求失败: %w", err)
 	}
@@ -4290,7 +4286,7 @@ func CDPPageGetResourceContent(frameId, url string) (content string, base64 bool
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", false, fmt.Errorf("消息队列关闭")
 			}
@@ -4363,15 +4359,15 @@ func CDPPageGetResourceTree() (*ResourceTree, error) {
 		return nil, fmt.Errorf("浏览器 WebSocket 未连接")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	msg := fmt.Sprintf(`{
 		"id": %d,
 		"method": "Page.getResourceTree"
 	}`, reqID)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(msg))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(msg))
 	if err != nil {
 		return nil, fmt.Errorf("发送 getResourceTree 失败: %w", err)
 	}
@@ -4382,7 +4378,7 @@ func CDPPageGetResourceTree() (*ResourceTree, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return nil, fmt.Errorf("消息队列已关闭")
 			}
@@ -4474,8 +4470,8 @@ func CDPPageProduceCompilationCache(scripts []ProduceCompilationCacheScript) (st
 		return "", fmt.Errorf("浏览器WebSocket未连接")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数
 	scriptBytes, _ := json.Marshal(scripts)
@@ -4488,7 +4484,7 @@ func CDPPageProduceCompilationCache(scripts []ProduceCompilationCacheScript) (st
 	}`, reqID, scriptBytes)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 produceCompilationCache 请求失败: %w", err)
 	}
@@ -4502,7 +4498,7 @@ func CDPPageProduceCompilationCache(scripts []ProduceCompilationCacheScript) (st
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -4564,8 +4560,8 @@ func CDPPageScreencastFrameAck(frameNumber int) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket未连接")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	message := fmt.Sprintf(`{
 		"id": %d,
@@ -4575,7 +4571,7 @@ func CDPPageScreencastFrameAck(frameNumber int) (string, error) {
 		}
 	}`, reqID, frameNumber)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 screencastFrameAck 失败: %w", err)
 	}
@@ -4588,7 +4584,7 @@ func CDPPageScreencastFrameAck(frameNumber int) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -4652,8 +4648,8 @@ func CDPPageSearchInResource(frameId, url, query string, caseSensitive, isRegex
 		return nil, fmt.Errorf("浏览器WebSocket未连接")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建参数
 	params := map[string]interface{}{
@@ -4674,7 +4670,7 @@ func CDPPageSearchInResource(frameId, url, query string, caseSensitive, isRegex
 	}`, reqID, paramBytes)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return nil, fmt.Errorf("发送 searchInResource 请求失败: %w", err)
 	}
@@ -4688,7 +4684,7 @@ func CDPPageSearchInResource(frameId, url, query string, caseSensitive, isRegex
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return nil, fmt.Errorf("消息队列已关闭")
 			}
@@ -4753,8 +4749,8 @@ func CDPPageSetAdBlockingEnabled(enabled bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket未连接")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	message := fmt.Sprintf(`{
 		"id": %d,
@@ -4764,7 +4760,7 @@ func CDPPageSetAdBlockingEnabled(enabled bool) (string, error) {
 		}
 	}`, reqID, enabled)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setAdBlockingEnabled 请求失败: %w", err)
 	}
@@ -4777,7 +4773,7 @@ func CDPPageSetAdBlockingEnabled(enabled bool) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -4828,8 +4824,8 @@ func CDPPageSetFontFamilies(fonts FontFamilies) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket未连接")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	paramBytes, _ := json.Marshal(fonts)
 	message := fmt.Sprintf(`{
@@ -4838,7 +4834,7 @@ func CDPPageSetFontFamilies(fonts FontFamilies) (string, error) {
 		"params": %s
 	}`, reqID, paramBytes)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setFontFamilies 失败: %w", err)
 	}
@@ -4851,7 +4847,7 @@ func CDPPageSetFontFamilies(fonts FontFamilies) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -4918,8 +4914,8 @@ func CDPPageSetFontSizes(fontSizes FontSizes) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket未连接")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	paramBytes, _ := json.Marshal(fontSizes)
 	message := fmt.Sprintf(`{
@@ -4928,7 +4924,7 @@ func CDPPageSetFontSizes(fontSizes FontSizes) (string, error) {
 		"params": %s
 	}`, reqID, paramBytes)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setFontSizes 失败: %w", err)
 	}
@@ -4941,7 +4937,7 @@ func CDPPageSetFontSizes(fontSizes FontSizes) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -5002,8 +4998,8 @@ func CDPPageSetPrerenderingAllowed(allowed bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket未连接")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	message := fmt.Sprintf(`{
 		"id": %d,
@@ -5013,7 +5009,7 @@ func CDPPageSetPrerenderingAllowed(allowed bool) (string, error) {
 		}
 	}`, reqID, allowed)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setPrerenderingAllowed 请求失败: %w", err)
 	}
@@ -5026,7 +5022,7 @@ func CDPPageSetPrerenderingAllowed(allowed bool) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -5087,8 +5083,8 @@ func CDPPageSetRPHRegistrationMode(mode RPHRegistrationMode) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket未连接")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	message := fmt.Sprintf(`{
 		"id": %d,
@@ -5098,7 +5094,7 @@ func CDPPageSetRPHRegistrationMode(mode RPHRegistrationMode) (string, error) {
 		}
 	}`, reqID, mode)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setRPHRegistrationMode 失败: %w", err)
 	}
@@ -5111,7 +5107,7 @@ func CDPPageSetRPHRegistrationMode(mode RPHRegistrationMode) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -5175,8 +5171,8 @@ func CDPPageSetSPCTransactionMode(mode SPCTransactionMode) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket未连接")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	message := fmt.Sprintf(`{
 		"id": %d,
@@ -5186,7 +5182,7 @@ func CDPPageSetSPCTransactionMode(mode SPCTransactionMode) (string, error) {
 		}
 	}`, reqID, mode)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setSPCTransactionMode 失败: %w", err)
 	}
@@ -5199,7 +5195,7 @@ func CDPPageSetSPCTransactionMode(mode SPCTransactionMode) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -5266,8 +5262,8 @@ func CDPPageSetWebLifecycleState(state WebLifecycleState) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket未连接")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	message := fmt.Sprintf(`{
 		"id": %d,
@@ -5277,7 +5273,7 @@ func CDPPageSetWebLifecycleState(state WebLifecycleState) (string, error) {
 		}
 	}`, reqID, state)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setWebLifecycleState 失败: %w", err)
 	}
@@ -5290,7 +5286,7 @@ func CDPPageSetWebLifecycleState(state WebLifecycleState) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -5350,8 +5346,8 @@ func CDPPageStartScreencast(format string, quality int, maxWidth, maxHeight int)
 		return "", fmt.Errorf("浏览器WebSocket未连接")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	params := map[string]interface{}{
 		"format":    format,
@@ -5367,7 +5363,7 @@ func CDPPageStartScreencast(format string, quality int, maxWidth, maxHeight int)
 		"params": %s
 	}`, reqID, paramBytes)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 startScreencast 失败: %w", err)
 	}
@@ -5380,7 +5376,7 @@ func CDPPageStartScreencast(format string, quality int, maxWidth, maxHeight int)
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -5430,15 +5426,15 @@ func CDPPageStopScreencast() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket未连接")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "Page.stopScreencast"
 	}`, reqID)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 stopScreencast 失败: %w", err)
 	}
@@ -5451,7 +5447,7 @@ func CDPPageStopScreencast() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -5500,15 +5496,15 @@ func CDPPageWaitForDebugger() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket未连接")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "Page.waitForDebugger"
 	}`, reqID)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 waitForDebugger 失败: %w", err)
 	}
@@ -5521,7 +5517,7 @@ func CDPPageWaitForDebugger() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -6,8 +6,6 @@ import (
 	"fmt"
 	"log"
 	"time"
-
-	"github.com/gorilla/websocket"
 )
 
 // -----------------------------------------------  Profiler.disable  -----------------------------------------------
@@ -28,8 +26,8 @@ func CDPProfilerDisable() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -38,7 +36,7 @@ func CDPProfilerDisable() (string, error) {
 	}`, reqID)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Profiler.disable 请求失败: %w", err)
 	}
@@ -53,7 +51,7 @@ func CDPProfilerDisable() (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -132,8 +130,8 @@ func CDPProfilerEnable() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -142,7 +140,7 @@ func CDPProfilerEnable() (string, error) {
 	}`, reqID)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Profiler.enable 请求失败: %w", err)
 	}
@@ -157,7 +155,7 @@ func CDPProfilerEnable() (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -237,8 +235,8 @@ func CDPProfilerGetBestEffortCoverage() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -247,7 +245,7 @@ func CDPProfilerGetBestEffortCoverage() (string, error) {
 	}`, reqID)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Profiler.getBestEffortCoverage 请求失败: %w", err)
 	}
@@ -262,7 +260,7 @@ func CDPProfilerGetBestEffortCoverage() (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -361,8 +359,8 @@ func CDPProfilerSetSamplingInterval(interval int) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建带参数的CDP请求消息
 	message := fmt.Sprintf(`{
@@ -374,7 +372,7 @@ func CDPProfilerSetSamplingInterval(interval int) (string, error) {
 	}`, reqID, interval)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Profiler.setSamplingInterval 请求失败: %w", err)
 	}
@@ -389,7 +387,7 @@ func CDPProfilerSetSamplingInterval(interval int) (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -478,8 +476,8 @@ func CDPProfilerStart() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -488,7 +486,7 @@ func CDPProfilerStart() (string, error) {
 	}`, reqID)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Profiler.start 请求失败: %w", err)
 	}
@@ -503,7 +501,7 @@ func CDPProfilerStart() (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -599,8 +597,8 @@ func CDPProfilerStartPreciseCoverage(enableCallCount bool, enableDetailedCoverag
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建带参数的精确覆盖率启动请求
 	message := fmt.Sprintf(`{
@@ -613,7 +611,7 @@ func CDPProfilerStartPreciseCoverage(enableCallCount bool, enableDetailedCoverag
 	}`, reqID, enableCallCount, enableDetailedCoverage)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Profiler.startPreciseCoverage 请求失败: %w", err)
 	}
@@ -628,7 +626,7 @@ func CDPProfilerStartPreciseCoverage(enableCallCount bool, enableDetailedCoverag
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -713,8 +711,8 @@ func CDPProfilerStop() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -723,7 +721,7 @@ func CDPProfilerStop() (string, error) {
 	}`, reqID)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Profiler.stop 请求失败: %w", err)
 	}
@@ -738,7 +736,7 @@ func CDPProfilerStop() (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -824,8 +822,8 @@ func CDPProfilerStopPreciseCoverage() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -834,7 +832,7 @@ func CDPProfilerStopPreciseCoverage() (string, error) {
 	}`, reqID)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Profiler.stopPreciseCoverage 请求失败: %w", err)
 	}
@@ -849,7 +847,7 @@ func CDPProfilerStopPreciseCoverage() (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -938,8 +936,8 @@ func CDPProfilerTakePreciseCoverage() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -948,7 +946,7 @@ func CDPProfilerTakePreciseCoverage() (string, error) {
 	}`, reqID)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Profiler.takePreciseCoverage 请求失败: %w", err)
 	}
@@ -963,7 +961,7 @@ func CDPProfilerTakePreciseCoverage() (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
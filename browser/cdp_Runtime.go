@@ -6,8 +6,6 @@ import (
 	"fmt"
 	"log"
 	"time"
-
-	"github.com/gorilla/websocket"
 )
 
 // -----------------------------------------------  Runtime.addBinding  -----------------------------------------------
@@ -28,8 +26,8 @@ func CDPRuntimeAddBinding(bindingName string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -41,7 +39,7 @@ func CDPRuntimeAddBinding(bindingName string) (string, error) {
 	}`, reqID, bindingName)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Runtime.addBinding 请求失败: %w", err)
 	}
@@ -55,7 +53,7 @@ func CDPRuntimeAddBinding(bindingName string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -142,8 +140,8 @@ func CDPRuntimeAwaitPromise(promiseObjectID string, returnByValue bool) (string,
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -156,7 +154,7 @@ func CDPRuntimeAwaitPromise(promiseObjectID string, returnByValue bool) (string,
 	}`, reqID, promiseObjectID, returnByValue)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Runtime.awaitPromise 请求失败: %w", err)
 	}
@@ -170,7 +168,7 @@ func CDPRuntimeAwaitPromise(promiseObjectID string, returnByValue bool) (string,
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -268,8 +266,8 @@ func CDPRuntimeCallFunctionOn(objectID, functionDeclaration, args string, return
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -285,7 +283,7 @@ func CDPRuntimeCallFunctionOn(objectID, functionDeclaration, args string, return
 	}`, reqID, objectID, functionDeclaration, args, returnByValue)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Runtime.callFunctionOn 请求失败: %w", err)
 	}
@@ -299,7 +297,7 @@ func CDPRuntimeCallFunctionOn(objectID, functionDeclaration, args string, return
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -402,8 +400,8 @@ func CDPRuntimeCompileScript(expression, sourceURL string, persistScript bool, e
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -418,7 +416,7 @@ func CDPRuntimeCompileScript(expression, sourceURL string, persistScript bool, e
 	}`, reqID, jsonEscape(expression), sourceURL, persistScript, executionContextID)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Runtime.compileScript 请求失败: %w", err)
 	}
@@ -432,7 +430,7 @@ func CDPRuntimeCompileScript(expression, sourceURL string, persistScript bool, e
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -536,8 +534,8 @@ func CDPRuntimeDisable() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -546,7 +544,7 @@ func CDPRuntimeDisable() (string, error) {
 	}`, reqID)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Runtime.disable 请求失败: %w", err)
 	}
@@ -560,7 +558,7 @@ func CDPRuntimeDisable() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -649,8 +647,8 @@ func CDPRuntimeDiscardConsoleEntries() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -659,7 +657,7 @@ func CDPRuntimeDiscardConsoleEntries() (string, error) {
 	}`, reqID)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Runtime.discardConsoleEntries 请求失败: %w", err)
 	}
@@ -673,7 +671,7 @@ func CDPRuntimeDiscardConsoleEntries() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -769,8 +767,8 @@ func CDPRuntimeEnable() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -779,7 +777,7 @@ func CDPRuntimeEnable() (string, error) {
 	}`, reqID)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Runtime.enable 请求失败: %w", err)
 	}
@@ -793,7 +791,7 @@ func CDPRuntimeEnable() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -895,8 +893,8 @@ func CDPRuntimeEvaluate(expression string, returnByValue bool, awaitPromise bool
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -911,7 +909,7 @@ func CDPRuntimeEvaluate(expression string, returnByValue bool, awaitPromise bool
 	}`, reqID, jsonEscape(expression), returnByValue, awaitPromise)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Runtime.evaluate 请求失败: %w", err)
 	}
@@ -925,7 +923,7 @@ func CDPRuntimeEvaluate(expression string, returnByValue bool, awaitPromise bool
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1023,8 +1021,8 @@ func CDPRuntimeGetProperties(objectID string, ownProperties, accessorPropertiesO
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -1039,7 +1037,7 @@ func CDPRuntimeGetProperties(objectID string, ownProperties, accessorPropertiesO
 	}`, reqID, objectID, ownProperties, accessorPropertiesOnly, generatePreview)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Runtime.getProperties 请求失败: %w", err)
 	}
@@ -1053,7 +1051,7 @@ func CDPRuntimeGetProperties(objectID string, ownProperties, accessorPropertiesO
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1157,8 +1155,8 @@ func CDPRuntimeGlobalLexicalScopeNames(executionContextID int) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -1170,7 +1168,7 @@ func CDPRuntimeGlobalLexicalScopeNames(executionContextID int) (string, error) {
 	}`, reqID, executionContextID)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Runtime.globalLexicalScopeNames 请求失败: %w", err)
 	}
@@ -1184,7 +1182,7 @@ func CDPRuntimeGlobalLexicalScopeNames(executionContextID int) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1272,8 +1270,8 @@ func CDPRuntimeQueryObjects(prototypeObjectID string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -1285,7 +1283,7 @@ func CDPRuntimeQueryObjects(prototypeObjectID string) (string, error) {
 	}`, reqID, prototypeObjectID)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Runtime.queryObjects 请求失败: %w", err)
 	}
@@ -1299,7 +1297,7 @@ func CDPRuntimeQueryObjects(prototypeObjectID string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1406,8 +1404,8 @@ func CDPRuntimeReleaseObject(objectID string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -1419,7 +1417,7 @@ func CDPRuntimeReleaseObject(objectID string) (string, error) {
 	}`, reqID, objectID)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Runtime.releaseObject 请求失败: %w", err)
 	}
@@ -1433,7 +1431,7 @@ func CDPRuntimeReleaseObject(objectID string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1547,8 +1545,8 @@ func CDPRuntimeReleaseObjectGroup(objectGroup string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -1560,7 +1558,7 @@ func CDPRuntimeReleaseObjectGroup(objectGroup string) (string, error) {
 	}`, reqID, objectGroup)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Runtime.releaseObjectGroup 请求失败: %w", err)
 	}
@@ -1574,7 +1572,7 @@ func CDPRuntimeReleaseObjectGroup(objectGroup string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1687,8 +1685,8 @@ func CDPRuntimeRemoveBinding(bindingName string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -1700,7 +1698,7 @@ func CDPRuntimeRemoveBinding(bindingName string) (string, error) {
 	}`, reqID, bindingName)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Runtime.removeBinding 请求失败: %w", err)
 	}
@@ -1714,7 +1712,7 @@ func CDPRuntimeRemoveBinding(bindingName string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1817,8 +1815,8 @@ func CDPRuntimeRunIfWaitingForDebugger() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -1827,7 +1825,7 @@ func CDPRuntimeRunIfWaitingForDebugger() (string, error) {
 	}`, reqID)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Runtime.runIfWaitingForDebugger 请求失败: %w", err)
 	}
@@ -1841,7 +1839,7 @@ func CDPRuntimeRunIfWaitingForDebugger() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1937,8 +1935,8 @@ func CDPRuntimeRunScript(scriptID string, executionContextID int, returnByValue
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -1953,7 +1951,7 @@ func CDPRuntimeRunScript(scriptID string, executionContextID int, returnByValue
 	}`, reqID, scriptID, executionContextID, returnByValue, awaitPromise)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Runtime.runScript 请求失败: %w", err)
 	}
@@ -1967,7 +1965,7 @@ func CDPRuntimeRunScript(scriptID string, executionContextID int, returnByValue
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2075,8 +2073,8 @@ func CDPRuntimeSetAsyncCallStackDepth(depth int) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -2088,7 +2086,7 @@ func CDPRuntimeSetAsyncCallStackDepth(depth int) (string, error) {
 	}`, reqID, depth)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Runtime.setAsyncCallStackDepth 请求失败: %w", err)
 	}
@@ -2102,7 +2100,7 @@ func CDPRuntimeSetAsyncCallStackDepth(depth int) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2199,8 +2197,8 @@ func CDPRuntimeGetExceptionDetails(exceptionID int) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -2212,7 +2210,7 @@ func CDPRuntimeGetExceptionDetails(exceptionID int) (string, error) {
 	}`, reqID, exceptionID)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Runtime.getExceptionDetails 请求失败: %w", err)
 	}
@@ -2226,7 +2224,7 @@ func CDPRuntimeGetExceptionDetails(exceptionID int) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2334,8 +2332,8 @@ func CDPRuntimeGetHeapUsage() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -2344,7 +2342,7 @@ func CDPRuntimeGetHeapUsage() (string, error) {
 	}`, reqID)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Runtime.getHeapUsage 请求失败: %w", err)
 	}
@@ -2358,7 +2356,7 @@ func CDPRuntimeGetHeapUsage() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2464,8 +2462,8 @@ func CDPRuntimeGetIsolateId() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -2474,7 +2472,7 @@ func CDPRuntimeGetIsolateId() (string, error) {
 	}`, reqID)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Runtime.getIsolateId 请求失败: %w", err)
 	}
@@ -2488,7 +2486,7 @@ func CDPRuntimeGetIsolateId() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2584,8 +2582,8 @@ func CDPRuntimeSetCustomObjectFormatterEnabled(enabled bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -2597,7 +2595,7 @@ func CDPRuntimeSetCustomObjectFormatterEnabled(enabled bool) (string, error) {
 	}`, reqID, enabled)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Runtime.setCustomObjectFormatterEnabled 请求失败: %w", err)
 	}
@@ -2611,7 +2609,7 @@ func CDPRuntimeSetCustomObjectFormatterEnabled(enabled bool) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2705,8 +2703,8 @@ func CDPRuntimeSetMaxCallStackSizeToCapture(size int) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -2718,7 +2716,7 @@ func CDPRuntimeSetMaxCallStackSizeToCapture(size int) (string, error) {
 	}`, reqID, size)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Runtime.setMaxCallStackSizeToCapture 请求失败: %w", err)
 	}
@@ -2732,7 +2730,7 @@ func CDPRuntimeSetMaxCallStackSizeToCapture(size int) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2823,8 +2821,8 @@ func CDPRuntimeTerminateExecution() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -2833,7 +2831,7 @@ func CDPRuntimeTerminateExecution() (string, error) {
 	}`, reqID)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Runtime.terminateExecution 请求失败: %w", err)
 	}
@@ -2847,7 +2845,7 @@ func CDPRuntimeTerminateExecution() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
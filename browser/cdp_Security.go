@@ -6,8 +6,6 @@ import (
 	"fmt"
 	"log"
 	"time"
-
-	"github.com/gorilla/websocket"
 )
 
 // -----------------------------------------------  Security.disable  -----------------------------------------------
@@ -27,8 +25,8 @@ func CDPSecurityDisable() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -37,7 +35,7 @@ func CDPSecurityDisable() (string, error) {
 	}`, reqID)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Security.disable 请求失败: %w", err)
 	}
@@ -51,7 +49,7 @@ func CDPSecurityDisable() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -127,8 +125,8 @@ func CDPSecurityEnable() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -137,7 +135,7 @@ func CDPSecurityEnable() (string, error) {
 	}`, reqID)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Security.enable 请求失败: %w", err)
 	}
@@ -151,7 +149,7 @@ func CDPSecurityEnable() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -225,8 +223,8 @@ func CDPSecuritySetIgnoreCertificateErrors(ignore bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -238,7 +236,7 @@ func CDPSecuritySetIgnoreCertificateErrors(ignore bool) (string, error) {
 	}`, reqID, ignore)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 Security.setIgnoreCertificateErrors 请求失败: %w", err)
 	}
@@ -252,7 +250,7 @@ func CDPSecuritySetIgnoreCertificateErrors(ignore bool) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
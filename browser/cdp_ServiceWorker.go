@@ -6,8 +6,6 @@ import (
 	"fmt"
 	"log"
 	"time"
-
-	"github.com/gorilla/websocket"
 )
 
 // -----------------------------------------------  ServiceWorker.deliverPushMessage  -----------------------------------------------
@@ -31,8 +29,8 @@ func CDPServiceWorkerDeliverPushMessage(versionId string, data string, timeToLiv
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求参数
 	params := fmt.Sprintf(`"versionId": "%s", "data": "%s", "timeToLive": %d`, versionId, data, timeToLive)
@@ -44,7 +42,7 @@ func CDPServiceWorkerDeliverPushMessage(versionId string, data string, timeToLiv
 	}`, reqID, params)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 deliverPushMessage 请求失败: %w", err)
 	}
@@ -58,7 +56,7 @@ func CDPServiceWorkerDeliverPushMessage(versionId string, data string, timeToLiv
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -143,8 +141,8 @@ func CDPServiceWorkerDisable() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -153,7 +151,7 @@ func CDPServiceWorkerDisable() (string, error) {
 	}`, reqID)
 
 	// 发送WebSocket请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 disable 请求失败: %w", err)
 	}
@@ -167,7 +165,7 @@ func CDPServiceWorkerDisable() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -243,8 +241,8 @@ func CDPServiceWorkerDispatchPeriodicSyncEvent(origin string, registrationId str
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求参数
 	params := fmt.Sprintf(`"origin": "%s", "registrationId": "%s", "tag": "%s"`, origin, registrationId, tag)
@@ -256,7 +254,7 @@ func CDPServiceWorkerDispatchPeriodicSyncEvent(origin string, registrationId str
 	}`, reqID, params)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 dispatchPeriodicSyncEvent 请求失败: %w", err)
 	}
@@ -270,7 +268,7 @@ func CDPServiceWorkerDispatchPeriodicSyncEvent(origin string, registrationId str
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -358,8 +356,8 @@ func CDPServiceWorkerDispatchSyncEvent(origin string, registrationId string, tag
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求参数
 	params := fmt.Sprintf(`"origin": "%s", "registrationId": "%s", "tag": "%s"`, origin, registrationId, tag)
@@ -371,7 +369,7 @@ func CDPServiceWorkerDispatchSyncEvent(origin string, registrationId string, tag
 	}`, reqID, params)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 dispatchSyncEvent 请求失败: %w", err)
 	}
@@ -385,7 +383,7 @@ func CDPServiceWorkerDispatchSyncEvent(origin string, registrationId string, tag
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -466,8 +464,8 @@ func CDPServiceWorkerEnable() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -476,7 +474,7 @@ func CDPServiceWorkerEnable() (string, error) {
 	}`, reqID)
 
 	// 发送WebSocket请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 enable 请求失败: %w", err)
 	}
@@ -490,7 +488,7 @@ func CDPServiceWorkerEnable() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -564,8 +562,8 @@ func CDPServiceWorkerSetForceUpdateOnPageLoad(forceUpdate bool) (string, error)
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求参数
 	params := fmt.Sprintf(`"forceUpdateOnPageLoad": %t`, forceUpdate)
@@ -577,7 +575,7 @@ func CDPServiceWorkerSetForceUpdateOnPageLoad(forceUpdate bool) (string, error)
 	}`, reqID, params)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setForceUpdateOnPageLoad 请求失败: %w", err)
 	}
@@ -591,7 +589,7 @@ func CDPServiceWorkerSetForceUpdateOnPageLoad(forceUpdate bool) (string, error)
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -667,8 +665,8 @@ func CDPServiceWorkerSkipWaiting(versionId string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求参数
 	params := fmt.Sprintf(`"versionId": "%s"`, versionId)
@@ -680,7 +678,7 @@ func CDPServiceWorkerSkipWaiting(versionId string) (string, error) {
 	}`, reqID, params)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 skipWaiting 请求失败: %w", err)
 	}
@@ -694,7 +692,7 @@ func CDPServiceWorkerSkipWaiting(versionId string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -773,8 +771,8 @@ func CDPServiceWorkerStartWorker(versionId string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求参数
 	params := fmt.Sprintf(`"versionId": "%s"`, versionId)
@@ -786,7 +784,7 @@ func CDPServiceWorkerStartWorker(versionId string) (string, error) {
 	}`, reqID, params)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 startWorker 请求失败: %w", err)
 	}
@@ -800,7 +798,7 @@ func CDPServiceWorkerStartWorker(versionId string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -878,8 +876,8 @@ func CDPServiceWorkerStopAllWorkers() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -888,7 +886,7 @@ func CDPServiceWorkerStopAllWorkers() (string, error) {
 	}`, reqID)
 
 	// 发送WebSocket请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 stopAllWorkers 请求失败: %w", err)
 	}
@@ -902,7 +900,7 @@ func CDPServiceWorkerStopAllWorkers() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -976,8 +974,8 @@ func CDPServiceWorkerStopWorker(versionId string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求参数
 	params := fmt.Sprintf(`"versionId": "%s"`, versionId)
@@ -989,7 +987,7 @@ func CDPServiceWorkerStopWorker(versionId string) (string, error) {
 	}`, reqID, params)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 stopWorker 请求失败: %w", err)
 	}
@@ -1003,7 +1001,7 @@ func CDPServiceWorkerStopWorker(versionId string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1082,8 +1080,8 @@ func CDPServiceWorkerUnregister(scopeURL string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求参数
 	params := fmt.Sprintf(`"scopeURL": "%s"`, scopeURL)
@@ -1095,7 +1093,7 @@ func CDPServiceWorkerUnregister(scopeURL string) (string, error) {
 	}`, reqID, params)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 unregister 请求失败: %w", err)
 	}
@@ -1109,7 +1107,7 @@ func CDPServiceWorkerUnregister(scopeURL string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1188,8 +1186,8 @@ func CDPServiceWorkerUpdateRegistration(scopeURL string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求参数
 	params := fmt.Sprintf(`"scopeURL": "%s"`, scopeURL)
@@ -1201,7 +1199,7 @@ func CDPServiceWorkerUpdateRegistration(scopeURL string) (string, error) {
 	}`, reqID, params)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 updateRegistration 请求失败: %w", err)
 	}
@@ -1215,7 +1213,7 @@ func CDPServiceWorkerUpdateRegistration(scopeURL string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -6,8 +6,6 @@ import (
 	"fmt"
 	"log"
 	"time"
-
-	"github.com/gorilla/websocket"
 )
 
 // -----------------------------------------------  Storage.clearCookies  -----------------------------------------------
@@ -28,8 +26,8 @@ func CDPStorageClearCookies() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -38,7 +36,7 @@ func CDPStorageClearCookies() (string, error) {
 	}`, reqID)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 clearCookies 请求失败: %w", err)
 	}
@@ -53,7 +51,7 @@ func CDPStorageClearCookies() (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -127,8 +125,8 @@ func CDPStorageClearDataForOrigin(origin string, storageTypes string) (string, e
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息，带参数
 	message := fmt.Sprintf(`{
@@ -141,7 +139,7 @@ func CDPStorageClearDataForOrigin(origin string, storageTypes string) (string, e
 	}`, reqID, origin, storageTypes)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 clearDataForOrigin 请求失败: %w", err)
 	}
@@ -156,7 +154,7 @@ func CDPStorageClearDataForOrigin(origin string, storageTypes string) (string, e
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -245,8 +243,8 @@ func CDPStorageClearDataForStorageKey(storageKey string, storageTypes string) (s
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -259,7 +257,7 @@ func CDPStorageClearDataForStorageKey(storageKey string, storageTypes string) (s
 	}`, reqID, storageKey, storageTypes)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 clearDataForStorageKey 请求失败: %w", err)
 	}
@@ -274,7 +272,7 @@ func CDPStorageClearDataForStorageKey(storageKey string, storageTypes string) (s
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -361,8 +359,8 @@ func CDPStorageGetCookies() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -371,7 +369,7 @@ func CDPStorageGetCookies() (string, error) {
 	}`, reqID)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getCookies 请求失败: %w", err)
 	}
@@ -386,7 +384,7 @@ func CDPStorageGetCookies() (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -476,8 +474,8 @@ func CDPStorageGetUsageAndQuota(origin string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -489,7 +487,7 @@ func CDPStorageGetUsageAndQuota(origin string) (string, error) {
 	}`, reqID, origin)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getUsageAndQuota 请求失败: %w", err)
 	}
@@ -504,7 +502,7 @@ func CDPStorageGetUsageAndQuota(origin string) (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -599,8 +597,8 @@ func CDPStorageSetCookies(cookiesJson string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息，直接传入拼接好的cookies数组
 	message := fmt.Sprintf(`{
@@ -612,7 +610,7 @@ func CDPStorageSetCookies(cookiesJson string) (string, error) {
 	}`, reqID, cookiesJson)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setCookies 请求失败: %w", err)
 	}
@@ -627,7 +625,7 @@ func CDPStorageSetCookies(cookiesJson string) (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -742,8 +740,8 @@ func CDPStorageSetProtectedAudienceKAnonymity(name string, value bool) (string,
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建 CDP 请求消息
 	message := fmt.Sprintf(`{
@@ -756,7 +754,7 @@ func CDPStorageSetProtectedAudienceKAnonymity(name string, value bool) (string,
 	}`, reqID, name, value)
 
 	// 发送 WebSocket 消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setProtectedAudienceKAnonymity 请求失败: %w", err)
 	}
@@ -771,7 +769,7 @@ func CDPStorageSetProtectedAudienceKAnonymity(name string, value bool) (string,
 	// 循环等待对应 ID 的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -860,8 +858,8 @@ func CDPStorageTrackCacheStorageForOrigin(origin string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -873,7 +871,7 @@ func CDPStorageTrackCacheStorageForOrigin(origin string) (string, error) {
 	}`, reqID, origin)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 trackCacheStorageForOrigin 请求失败: %w", err)
 	}
@@ -888,7 +886,7 @@ func CDPStorageTrackCacheStorageForOrigin(origin string) (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -974,8 +972,8 @@ func CDPStorageTrackCacheStorageForStorageKey(storageKey string) (string, error)
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -987,7 +985,7 @@ func CDPStorageTrackCacheStorageForStorageKey(storageKey string) (string, error)
 	}`, reqID, storageKey)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 trackCacheStorageForStorageKey 请求失败: %w", err)
 	}
@@ -1002,7 +1000,7 @@ func CDPStorageTrackCacheStorageForStorageKey(storageKey string) (string, error)
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1088,8 +1086,8 @@ func CDPStorageTrackIndexedDBForOrigin(origin string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -1101,7 +1099,7 @@ func CDPStorageTrackIndexedDBForOrigin(origin string) (string, error) {
 	}`, reqID, origin)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 trackIndexedDBForOrigin 请求失败: %w", err)
 	}
@@ -1116,7 +1114,7 @@ func CDPStorageTrackIndexedDBForOrigin(origin string) (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1202,8 +1200,8 @@ func CDPStorageTrackIndexedDBForStorageKey(storageKey string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -1215,7 +1213,7 @@ func CDPStorageTrackIndexedDBForStorageKey(storageKey string) (string, error) {
 	}`, reqID, storageKey)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 trackIndexedDBForStorageKey 请求失败: %w", err)
 	}
@@ -1230,7 +1228,7 @@ func CDPStorageTrackIndexedDBForStorageKey(storageKey string) (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1316,8 +1314,8 @@ func CDPStorageUntrackCacheStorageForOrigin(origin string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -1329,7 +1327,7 @@ func CDPStorageUntrackCacheStorageForOrigin(origin string) (string, error) {
 	}`, reqID, origin)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 untrackCacheStorageForOrigin 请求失败: %w", err)
 	}
@@ -1344,7 +1342,7 @@ func CDPStorageUntrackCacheStorageForOrigin(origin string) (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1431,8 +1429,8 @@ func CDPStorageUntrackCacheStorageForStorageKey(storageKey string) (string, erro
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -1444,7 +1442,7 @@ func CDPStorageUntrackCacheStorageForStorageKey(storageKey string) (string, erro
 	}`, reqID, storageKey)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 untrackCacheStorageForStorageKey 请求失败: %w", err)
 	}
@@ -1459,7 +1457,7 @@ func CDPStorageUntrackCacheStorageForStorageKey(storageKey string) (string, erro
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1546,8 +1544,8 @@ func CDPStorageUntrackIndexedDBForOrigin(origin string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -1559,7 +1557,7 @@ func CDPStorageUntrackIndexedDBForOrigin(origin string) (string, error) {
 	}`, reqID, origin)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 untrackIndexedDBForOrigin 请求失败: %w", err)
 	}
@@ -1574,7 +1572,7 @@ func CDPStorageUntrackIndexedDBForOrigin(origin string) (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1661,8 +1659,8 @@ func CDPStorageUntrackIndexedDBForStorageKey(storageKey string) (string, error)
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -1674,7 +1672,7 @@ func CDPStorageUntrackIndexedDBForStorageKey(storageKey string) (string, error)
 	}`, reqID, storageKey)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 untrackIndexedDBForStorageKey 请求失败: %w", err)
 	}
@@ -1689,7 +1687,7 @@ func CDPStorageUntrackIndexedDBForStorageKey(storageKey string) (string, error)
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1776,8 +1774,8 @@ func CDPStorageClearSharedStorageEntries(origin string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -1789,7 +1787,7 @@ func CDPStorageClearSharedStorageEntries(origin string) (string, error) {
 	}`, reqID, origin)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 clearSharedStorageEntries 请求失败: %w", err)
 	}
@@ -1804,7 +1802,7 @@ func CDPStorageClearSharedStorageEntries(origin string) (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1889,8 +1887,8 @@ func CDPStorageClearTrustTokens() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -1899,7 +1897,7 @@ func CDPStorageClearTrustTokens() (string, error) {
 	}`, reqID)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 clearTrustTokens 请求失败: %w", err)
 	}
@@ -1914,7 +1912,7 @@ func CDPStorageClearTrustTokens() (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1998,8 +1996,8 @@ func CDPStorageDeleteSharedStorageEntry(origin string, key string) (string, erro
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -2012,7 +2010,7 @@ func CDPStorageDeleteSharedStorageEntry(origin string, key string) (string, erro
 	}`, reqID, origin, key)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 deleteSharedStorageEntry 请求失败: %w", err)
 	}
@@ -2027,7 +2025,7 @@ func CDPStorageDeleteSharedStorageEntry(origin string, key string) (string, erro
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2117,8 +2115,8 @@ func CDPStorageDeleteStorageBucket(storageBucketId string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -2130,7 +2128,7 @@ func CDPStorageDeleteStorageBucket(storageBucketId string) (string, error) {
 	}`, reqID, storageBucketId)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 deleteStorageBucket 请求失败: %w", err)
 	}
@@ -2145,7 +2143,7 @@ func CDPStorageDeleteStorageBucket(storageBucketId string) (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2231,8 +2229,8 @@ func CDPStorageGetAffectedUrlsForThirdPartyCookieMetadata(cookieMetadataJson str
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -2244,7 +2242,7 @@ func CDPStorageGetAffectedUrlsForThirdPartyCookieMetadata(cookieMetadataJson str
 	}`, reqID, cookieMetadataJson)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getAffectedUrlsForThirdPartyCookieMetadata 请求失败: %w", err)
 	}
@@ -2259,7 +2257,7 @@ func CDPStorageGetAffectedUrlsForThirdPartyCookieMetadata(cookieMetadataJson str
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2361,8 +2359,8 @@ func CDPStorageGetInterestGroupDetails(ownerOrigin string, name string) (string,
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -2375,7 +2373,7 @@ func CDPStorageGetInterestGroupDetails(ownerOrigin string, name string) (string,
 	}`, reqID, ownerOrigin, name)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getInterestGroupDetails 请求失败: %w", err)
 	}
@@ -2390,7 +2388,7 @@ func CDPStorageGetInterestGroupDetails(ownerOrigin string, name string) (string,
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2486,8 +2484,8 @@ func CDPStorageGetRelatedWebsiteSets() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -2496,7 +2494,7 @@ func CDPStorageGetRelatedWebsiteSets() (string, error) {
 	}`, reqID)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getRelatedWebsiteSets 请求失败: %w", err)
 	}
@@ -2511,7 +2509,7 @@ func CDPStorageGetRelatedWebsiteSets() (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2600,8 +2598,8 @@ func CDPStorageGetSharedStorageEntries(ownerOrigin string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -2613,7 +2611,7 @@ func CDPStorageGetSharedStorageEntries(ownerOrigin string) (string, error) {
 	}`, reqID, ownerOrigin)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getSharedStorageEntries 请求失败: %w", err)
 	}
@@ -2628,7 +2626,7 @@ func CDPStorageGetSharedStorageEntries(ownerOrigin string) (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2720,8 +2718,8 @@ func CDPStorageGetSharedStorageMetadata(ownerOrigin string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -2733,7 +2731,7 @@ func CDPStorageGetSharedStorageMetadata(ownerOrigin string) (string, error) {
 	}`, reqID, ownerOrigin)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getSharedStorageMetadata 请求失败: %w", err)
 	}
@@ -2748,7 +2746,7 @@ func CDPStorageGetSharedStorageMetadata(ownerOrigin string) (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2840,8 +2838,8 @@ func CDPStorageGetStorageKey(origin string, topFrameOrigin string) (string, erro
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	var message string
@@ -2865,7 +2863,7 @@ func CDPStorageGetStorageKey(origin string, topFrameOrigin string) (string, erro
 	}
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getStorageKey 请求失败: %w", err)
 	}
@@ -2880,7 +2878,7 @@ func CDPStorageGetStorageKey(origin string, topFrameOrigin string) (string, erro
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2965,8 +2963,8 @@ func CDPStorageGetTrustTokens() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -2975,7 +2973,7 @@ func CDPStorageGetTrustTokens() (string, error) {
 	}`, reqID)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getTrustTokens 请求失败: %w", err)
 	}
@@ -2990,7 +2988,7 @@ func CDPStorageGetTrustTokens() (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -3080,8 +3078,8 @@ func CDPStorageOverrideQuotaForOrigin(origin string, quotaSize int64) (string, e
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -3094,7 +3092,7 @@ func CDPStorageOverrideQuotaForOrigin(origin string, quotaSize int64) (string, e
 	}`, reqID, origin, quotaSize)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 overrideQuotaForOrigin 请求失败: %w", err)
 	}
@@ -3109,7 +3107,7 @@ func CDPStorageOverrideQuotaForOrigin(origin string, quotaSize int64) (string, e
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -3198,8 +3196,8 @@ func CDPStorageResetSharedStorageBudget(ownerOrigin string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -3211,7 +3209,7 @@ func CDPStorageResetSharedStorageBudget(ownerOrigin string) (string, error) {
 	}`, reqID, ownerOrigin)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 resetSharedStorageBudget 请求失败: %w", err)
 	}
@@ -3226,7 +3224,7 @@ func CDPStorageResetSharedStorageBudget(ownerOrigin string) (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -3311,8 +3309,8 @@ func CDPStorageRunBounceTrackingMitigations() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -3321,7 +3319,7 @@ func CDPStorageRunBounceTrackingMitigations() (string, error) {
 	}`, reqID)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 runBounceTrackingMitigations 请求失败: %w", err)
 	}
@@ -3336,7 +3334,7 @@ func CDPStorageRunBounceTrackingMitigations() (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -3418,8 +3416,8 @@ func CDPStorageSendPendingAttributionReports() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -3428,7 +3426,7 @@ func CDPStorageSendPendingAttributionReports() (string, error) {
 	}`, reqID)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 sendPendingAttributionReports 请求失败: %w", err)
 	}
@@ -3443,7 +3441,7 @@ func CDPStorageSendPendingAttributionReports() (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -3526,8 +3524,8 @@ func CDPStorageSetAttributionReportingLocalTestingMode(enabled bool) (string, er
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -3539,7 +3537,7 @@ func CDPStorageSetAttributionReportingLocalTestingMode(enabled bool) (string, er
 	}`, reqID, enabled)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setAttributionReportingLocalTestingMode 请求失败: %w", err)
 	}
@@ -3553,7 +3551,7 @@ func CDPStorageSetAttributionReportingLocalTestingMode(enabled bool) (string, er
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -3636,8 +3634,8 @@ func CDPStorageSetAttributionReportingTracking(enable bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -3649,7 +3647,7 @@ func CDPStorageSetAttributionReportingTracking(enable bool) (string, error) {
 	}`, reqID, enable)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setAttributionReportingTracking 请求失败: %w", err)
 	}
@@ -3664,7 +3662,7 @@ func CDPStorageSetAttributionReportingTracking(enable bool) (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -3749,8 +3747,8 @@ func CDPStorageSetInterestGroupAuctionTracking(enable bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -3762,7 +3760,7 @@ func CDPStorageSetInterestGroupAuctionTracking(enable bool) (string, error) {
 	}`, reqID, enable)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setInterestGroupAuctionTracking 请求失败: %w", err)
 	}
@@ -3777,7 +3775,7 @@ func CDPStorageSetInterestGroupAuctionTracking(enable bool) (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -3863,8 +3861,8 @@ func CDPStorageSetSharedStorageEntry(ownerOrigin string, key string, value strin
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -3878,7 +3876,7 @@ func CDPStorageSetSharedStorageEntry(ownerOrigin string, key string, value strin
 	}`, reqID, ownerOrigin, key, value)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setSharedStorageEntry 请求失败: %w", err)
 	}
@@ -3893,7 +3891,7 @@ func CDPStorageSetSharedStorageEntry(ownerOrigin string, key string, value strin
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -3981,8 +3979,8 @@ func CDPStorageSetSharedStorageTracking(enable bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -3994,7 +3992,7 @@ func CDPStorageSetSharedStorageTracking(enable bool) (string, error) {
 	}`, reqID, enable)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setSharedStorageTracking 请求失败: %w", err)
 	}
@@ -4009,7 +4007,7 @@ func CDPStorageSetSharedStorageTracking(enable bool) (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -4093,8 +4091,8 @@ func CDPStorageSetStorageBucketTracking(enable bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -4106,7 +4104,7 @@ func CDPStorageSetStorageBucketTracking(enable bool) (string, error) {
 	}`, reqID, enable)
 
 	// 发送WebSocket消息
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setStorageBucketTracking 请求失败: %w", err)
 	}
@@ -4121,7 +4119,7 @@ func CDPStorageSetStorageBucketTracking(enable bool) (string, error) {
 	// 循环等待对应ID的响应
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -6,8 +6,6 @@ import (
 	"fmt"
 	"log"
 	"time"
-
-	"github.com/gorilla/websocket"
 )
 
 // -----------------------------------------------  WebAuthn.addCredential  -----------------------------------------------
@@ -29,8 +27,8 @@ func CDPWebAuthnAddCredential(credential interface{}) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 序列化凭证参数
 	credentialBytes, err := json.Marshal(credential)
@@ -48,7 +46,7 @@ func CDPWebAuthnAddCredential(credential interface{}) (string, error) {
 	}`, reqID, string(credentialBytes))
 
 	// 发送WebSocket请求
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 WebAuthn.addCredential 请求失败: %w", err)
 	}
@@ -63,7 +61,7 @@ func CDPWebAuthnAddCredential(credential interface{}) (string, error) {
 	// 监听响应消息队列
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -158,8 +156,8 @@ func CDPWebAuthnAddVirtualAuthenticator(options interface{}) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 序列化配置参数
 	optBytes, err := json.Marshal(options)
@@ -177,7 +175,7 @@ func CDPWebAuthnAddVirtualAuthenticator(options interface{}) (string, error) {
 	}`, reqID, string(optBytes))
 
 	// 发送WebSocket请求
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 WebAuthn.addVirtualAuthenticator 请求失败: %w", err)
 	}
@@ -192,7 +190,7 @@ func CDPWebAuthnAddVirtualAuthenticator(options interface{}) (string, error) {
 	// 监听响应消息队列
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -285,8 +283,8 @@ func CDPWebAuthnClearCredentials() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -295,7 +293,7 @@ func CDPWebAuthnClearCredentials() (string, error) {
 	}`, reqID)
 
 	// 发送WebSocket请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 WebAuthn.clearCredentials 请求失败: %w", err)
 	}
@@ -310,7 +308,7 @@ func CDPWebAuthnClearCredentials() (string, error) {
 	// 监听响应消息队列
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -388,8 +386,8 @@ func CDPWebAuthnDisable() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -398,7 +396,7 @@ func CDPWebAuthnDisable() (string, error) {
 	}`, reqID)
 
 	// 发送WebSocket请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 WebAuthn.disable 请求失败: %w", err)
 	}
@@ -413,7 +411,7 @@ func CDPWebAuthnDisable() (string, error) {
 	// 监听响应消息队列
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -491,8 +489,8 @@ func CDPWebAuthnEnable() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -501,7 +499,7 @@ func CDPWebAuthnEnable() (string, error) {
 	}`, reqID)
 
 	// 发送WebSocket请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 WebAuthn.enable 请求失败: %w", err)
 	}
@@ -516,7 +514,7 @@ func CDPWebAuthnEnable() (string, error) {
 	// 监听响应消息队列
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -596,8 +594,8 @@ func CDPWebAuthnGetCredential(credentialId string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -609,7 +607,7 @@ func CDPWebAuthnGetCredential(credentialId string) (string, error) {
 	}`, reqID, credentialId)
 
 	// 发送WebSocket请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 WebAuthn.getCredential 请求失败: %w", err)
 	}
@@ -624,7 +622,7 @@ func CDPWebAuthnGetCredential(credentialId string) (string, error) {
 	// 监听响应消息队列
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -703,8 +701,8 @@ func CDPWebAuthnGetCredentials() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -713,7 +711,7 @@ func CDPWebAuthnGetCredentials() (string, error) {
 	}`, reqID)
 
 	// 发送WebSocket请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 WebAuthn.getCredentials 请求失败: %w", err)
 	}
@@ -728,7 +726,7 @@ func CDPWebAuthnGetCredentials() (string, error) {
 	// 监听响应消息队列
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -808,8 +806,8 @@ func CDPWebAuthnRemoveCredential(credentialId string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -821,7 +819,7 @@ func CDPWebAuthnRemoveCredential(credentialId string) (string, error) {
 	}`, reqID, credentialId)
 
 	// 发送WebSocket请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 WebAuthn.removeCredential 请求失败: %w", err)
 	}
@@ -836,7 +834,7 @@ func CDPWebAuthnRemoveCredential(credentialId string) (string, error) {
 	// 监听响应消息队列
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -918,8 +916,8 @@ func CDPWebAuthnRemoveVirtualAuthenticator(authenticatorId string) (string, erro
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -931,7 +929,7 @@ func CDPWebAuthnRemoveVirtualAuthenticator(authenticatorId string) (string, erro
 	}`, reqID, authenticatorId)
 
 	// 发送WebSocket请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 WebAuthn.removeVirtualAuthenticator 请求失败: %w", err)
 	}
@@ -946,7 +944,7 @@ func CDPWebAuthnRemoveVirtualAuthenticator(authenticatorId string) (string, erro
 	// 监听响应消息队列
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1031,8 +1029,8 @@ func CDPWebAuthnSetAutomaticPresenceSimulation(authenticatorId string, enabled b
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -1045,7 +1043,7 @@ func CDPWebAuthnSetAutomaticPresenceSimulation(authenticatorId string, enabled b
 	}`, reqID, authenticatorId, enabled)
 
 	// 发送WebSocket请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 WebAuthn.setAutomaticPresenceSimulation 请求失败: %w", err)
 	}
@@ -1060,7 +1058,7 @@ func CDPWebAuthnSetAutomaticPresenceSimulation(authenticatorId string, enabled b
 	// 监听响应消息队列
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1142,8 +1140,8 @@ func CDPWebAuthnSetCredentialProperties(credentialId string, properties interfac
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 序列化属性参数
 	propBytes, err := json.Marshal(properties)
@@ -1162,7 +1160,7 @@ func CDPWebAuthnSetCredentialProperties(credentialId string, properties interfac
 	}`, reqID, credentialId, string(propBytes))
 
 	// 发送WebSocket请求
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 WebAuthn.setCredentialProperties 请求失败: %w", err)
 	}
@@ -1177,7 +1175,7 @@ func CDPWebAuthnSetCredentialProperties(credentialId string, properties interfac
 	// 监听响应消息队列
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1268,8 +1266,8 @@ func CDPWebAuthnSetResponseOverrideBits(authenticatorId string, bits interface{}
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 序列化覆盖参数
 	bitsBytes, err := json.Marshal(bits)
@@ -1288,7 +1286,7 @@ func CDPWebAuthnSetResponseOverrideBits(authenticatorId string, bits interface{}
 	}`, reqID, authenticatorId, string(bitsBytes))
 
 	// 发送WebSocket请求
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 WebAuthn.setResponseOverrideBits 请求失败: %w", err)
 	}
@@ -1303,7 +1301,7 @@ func CDPWebAuthnSetResponseOverrideBits(authenticatorId string, bits interface{}
 	// 监听响应消息队列
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1393,8 +1391,8 @@ func CDPWebAuthnSetUserVerified(authenticatorId string, isUserVerified bool) (st
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建CDP请求消息
 	message := fmt.Sprintf(`{
@@ -1407,7 +1405,7 @@ func CDPWebAuthnSetUserVerified(authenticatorId string, isUserVerified bool) (st
 	}`, reqID, authenticatorId, isUserVerified)
 
 	// 发送WebSocket请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 WebAuthn.setUserVerified 请求失败: %w", err)
 	}
@@ -1422,7 +1420,7 @@ func CDPWebAuthnSetUserVerified(authenticatorId string, isUserVerified bool) (st
 	// 监听响应消息队列
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
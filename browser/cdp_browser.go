@@ -7,8 +7,6 @@ import (
 	"log"
 	"strings"
 	"time"
-
-	"github.com/gorilla/websocket"
 )
 
 // CDPBrowserClose 关闭浏览器 Browser.close
@@ -20,8 +18,8 @@ func CDPBrowserClose() (string, error) {
 		return "", fmt.Errorf("BrowserWSConn 未连接，无法调用 Browser.close")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// Browser.close 方法不需要参数
 	message := fmt.Sprintf(`{
@@ -29,7 +27,7 @@ func CDPBrowserClose() (string, error) {
 		"method": "Browser.close"
 	}`, reqID)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		log.Println("发送 Browser.close 失败:", err)
 		return "", err
@@ -43,7 +41,7 @@ func CDPBrowserClose() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -91,8 +89,8 @@ func CDPBrowserResetPermissions(origin string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "Browser.resetPermissions",
@@ -100,7 +98,7 @@ func CDPBrowserResetPermissions(origin string) (string, error) {
 			"origin": "%s"
 		}
 	}`, reqID, origin)
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		log.Printf("[ERROR] 发送 Browser.resetPermissions 请求失败 (origin: %s): %v", origin, err)
 		return "", fmt.Errorf("发送重置权限请求失败: %w", err)
@@ -113,7 +111,7 @@ func CDPBrowserResetPermissions(origin string) (string, error) {
 	defer timer.Stop()
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("CDP消息队列异常关闭")
 			}
@@ -155,8 +153,8 @@ func CDPBrowserGetWindowForTarget(targetId string) (int, error) {
 		return 0, fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	message := fmt.Sprintf(`{
 		"id": %d,
@@ -166,7 +164,7 @@ func CDPBrowserGetWindowForTarget(targetId string) (int, error) {
 		}
 	}`, reqID, targetId)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		log.Printf("[ERROR] 发送 Browser.getWindowForTarget 失败: %v", err)
 		return 0, err
@@ -178,7 +176,7 @@ func CDPBrowserGetWindowForTarget(targetId string) (int, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return 0, fmt.Errorf("消息队列已关闭")
 			}
@@ -230,8 +228,8 @@ func CDPBrowserGetWindowBounds(windowId int) (map[string]interface{}, error) {
 		return nil, fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	message := fmt.Sprintf(`{
 		"id": %d,
@@ -241,7 +239,7 @@ func CDPBrowserGetWindowBounds(windowId int) (map[string]interface{}, error) {
 		}
 	}`, reqID, windowId)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		log.Printf("[ERROR] 发送 Browser.getWindowBounds 请求失败 (windowId: %d): %v", windowId, err)
 		return nil, fmt.Errorf("发送获取窗口边界请求失败: %w", err)
@@ -254,7 +252,7 @@ func CDPBrowserGetWindowBounds(windowId int) (map[string]interface{}, error) {
 	defer timer.Stop()
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return nil, fmt.Errorf("CDP消息队列异常关闭")
 			}
@@ -433,8 +431,8 @@ func getAllTargets() ([]TargetInfo, error) {
 		return nil, fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建 Target.getTargets 请求
 	// 该方法可以获取所有可用的目标列表
@@ -443,7 +441,7 @@ func getAllTargets() ([]TargetInfo, error) {
 		"method": "Target.getTargets"
 	}`, reqID)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		log.Printf("[ERROR] 发送 Target.getTargets 失败: %v", err)
 		return nil, fmt.Errorf("发送获取目标列表请求失败: %w", err)
@@ -457,7 +455,7 @@ func getAllTargets() ([]TargetInfo, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return nil, fmt.Errorf("消息队列已关闭")
 			}
@@ -767,8 +765,8 @@ func evaluateJavaScriptSimple(sessionId, script string) (interface{}, error) {
 		return nil, fmt.Errorf("CDP连接不可用")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	escapedScript := strings.ReplaceAll(script, `"`, `\"`)
 	escapedScript = strings.ReplaceAll(escapedScript, "\n", "\\n")
@@ -784,7 +782,7 @@ func evaluateJavaScriptSimple(sessionId, script string) (interface{}, error) {
 		"sessionId": "%s"
 	}`, reqID, escapedScript, sessionId)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return nil, fmt.Errorf("发送JS执行请求失败: %w", err)
 	}
@@ -795,7 +793,7 @@ func evaluateJavaScriptSimple(sessionId, script string) (interface{}, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return nil, fmt.Errorf("消息队列关闭")
 			}
@@ -944,8 +942,8 @@ func detachFromTarget(sessionId string) error {
 		return fmt.Errorf("CDP连接不可用")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	message := fmt.Sprintf(`{
 		"id": %d,
@@ -955,7 +953,7 @@ func detachFromTarget(sessionId string) error {
 		}
 	}`, reqID, sessionId)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return fmt.Errorf("发送分离请求失败: %w", err)
 	}
@@ -968,7 +966,7 @@ func detachFromTarget(sessionId string) error {
 
 		for {
 			select {
-			case respMsg, ok := <-messageQueue:
+			case respMsg, ok := <-respCh:
 				if !ok {
 					return
 				}
@@ -1058,13 +1056,13 @@ func CDPBrowserSetContentsSizeFn(windowId int, width, height int, options ...Set
 	windowWidth, windowHeight := calculateWindowSize(width, height, config.IncludeChrome)
 
 	// 6. 构建新的边界设置
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	message := buildSetWindowBoundsMessage(reqID, windowId, windowWidth, windowHeight, config, currentBounds)
 
 	// 7. 发送请求
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		log.Printf("[ERROR] 发送 Browser.setWindowBounds 失败 (windowId: %d): %v", windowId, err)
 		return "", fmt.Errorf("发送设置窗口边界请求失败: %w", err)
@@ -1073,7 +1071,7 @@ func CDPBrowserSetContentsSizeFn(windowId int, width, height int, options ...Set
 	utils.Debugf("[CDP] 发送设置窗口边界请求: %s", message)
 
 	// 8. 等待响应
-	return waitForSetWindowBoundsResponse(reqID, windowId, width, height)
+	return waitForSetWindowBoundsResponse(reqID, respCh, windowId, width, height)
 }
 
 // SizeConfig 尺寸配置
@@ -1192,14 +1190,14 @@ func calculatePosition(config *SizeConfig, currentBounds map[string]interface{})
 }
 
 // waitForSetWindowBoundsResponse 等待设置窗口边界的响应
-func waitForSetWindowBoundsResponse(reqID, windowId, width, height int) (string, error) {
+func waitForSetWindowBoundsResponse(reqID int, respCh chan mess, windowId, width, height int) (string, error) {
 	timeout := 5 * time.Second
 	timer := time.NewTimer(timeout)
 	defer timer.Stop()
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1287,8 +1285,8 @@ func CDPBrowserSetWindowBounds(windowId, left, top, width, height int, windowSta
 		return "", fmt.Errorf("无效的窗口状态: %s", windowState)
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -1307,7 +1305,7 @@ func CDPBrowserSetWindowBounds(windowId, left, top, width, height int, windowSta
 	}`, reqID, windowId, left, top, width, height, windowState)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送设置窗口边界请求失败: %w", err)
 	}
@@ -1321,7 +1319,7 @@ func CDPBrowserSetWindowBounds(windowId, left, top, width, height int, windowSta
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1372,8 +1370,8 @@ func CDPBrowserSetContentsSize(windowId, width, height int) (string, error) {
 		return "", fmt.Errorf("width 和 height 不能同时省略")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	message := fmt.Sprintf(`{
 		"id": %d,
@@ -1385,7 +1383,7 @@ func CDPBrowserSetContentsSize(windowId, width, height int) (string, error) {
 		}
 	}`, reqID, windowId, width, height)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setContentsSize 请求失败: %w", err)
 	}
@@ -1399,7 +1397,7 @@ func CDPBrowserSetContentsSize(windowId, width, height int) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -7,8 +7,6 @@ import (
 	"log"
 	"strings"
 	"time"
-
-	"github.com/gorilla/websocket"
 )
 
 // -----------------------------------------------  CSS.addRule  -----------------------------------------------
@@ -51,8 +49,8 @@ func CDPCSSAddRule(params string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "CSS.addRule",
@@ -60,7 +58,7 @@ func CDPCSSAddRule(params string) (string, error) {
 	}`, reqID, params)
 
 	// 发送请求
-	err := chromeInstance.NowTabWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.NowTabWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 addRule 请求失败: %w", err)
 	}
@@ -74,7 +72,7 @@ func CDPCSSAddRule(params string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -139,8 +137,8 @@ func CDPCSSColectClassNames(styleSheetId string) (string, error) {
 		return "", fmt.Errorf("样式表ID不能为空")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -152,7 +150,7 @@ func CDPCSSColectClassNames(styleSheetId string) (string, error) {
 	}`, reqID, styleSheetId)
 
 	// 发送请求
-	err := chromeInstance.NowTabWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.NowTabWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 collectClassNames 请求失败: %w", err)
 	}
@@ -166,7 +164,7 @@ func CDPCSSColectClassNames(styleSheetId string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -203,14 +201,14 @@ func CDPCSSEnable() (string, error) {
 		return "", fmt.Errorf("BrowserWSConn 未连接")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "CSS.enable"
 	}`, reqID)
 
-	err := chromeInstance.NowTabWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.NowTabWSConn, []byte(message))
 	if err != nil {
 		log.Println("发送 CSS.enable 失败:", err)
 		return "", err
@@ -223,7 +221,7 @@ func CDPCSSEnable() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -247,14 +245,14 @@ func CDPCSSDisable() (string, error) {
 		return "", fmt.Errorf("BrowserWSConn 未连接")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "CSS.disable"
 	}`, reqID)
 
-	err := chromeInstance.NowTabWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.NowTabWSConn, []byte(message))
 	if err != nil {
 		log.Println("发送 CSS.disable 失败:", err)
 		return "", err
@@ -267,7 +265,7 @@ func CDPCSSDisable() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -304,8 +302,8 @@ func CDPCSSCreateStyleSheet(params string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -315,7 +313,7 @@ func CDPCSSCreateStyleSheet(params string) (string, error) {
 	}`, reqID, params)
 
 	// 发送请求
-	err := chromeInstance.NowTabWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.NowTabWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 createStyleSheet 请求失败: %w", err)
 	}
@@ -329,7 +327,7 @@ func CDPCSSCreateStyleSheet(params string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -426,8 +424,8 @@ func CDPCSSForcePseudoState(nodeId int, forcedPseudoClasses []string) (string, e
 		}
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建伪类数组JSON
 	pseudoClassesJSON, err := json.Marshal(forcedPseudoClasses)
@@ -446,7 +444,7 @@ func CDPCSSForcePseudoState(nodeId int, forcedPseudoClasses []string) (string, e
 	}`, reqID, nodeId, pseudoClassesJSON)
 
 	// 发送请求
-	err = chromeInstance.NowTabWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.NowTabWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 forcePseudoState 请求失败: %w", err)
 	}
@@ -460,7 +458,7 @@ func CDPCSSForcePseudoState(nodeId int, forcedPseudoClasses []string) (string, e
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -572,8 +570,8 @@ func CDPCSSForceStartingStyle(nodeId int, forced bool) (string, error) {
 		return "", fmt.Errorf("节点ID必须是正整数")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -586,7 +584,7 @@ func CDPCSSForceStartingStyle(nodeId int, forced bool) (string, error) {
 	}`, reqID, nodeId, forced)
 
 	// 发送请求
-	err := chromeInstance.NowTabWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.NowTabWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 forceStartingStyle 请求失败: %w", err)
 	}
@@ -600,7 +598,7 @@ func CDPCSSForceStartingStyle(nodeId int, forced bool) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -699,8 +697,8 @@ func CDPCSSGetBackgroundColors(nodeId int) (string, error) {
 		return "", fmt.Errorf("节点ID必须是正整数")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -712,7 +710,7 @@ func CDPCSSGetBackgroundColors(nodeId int) (string, error) {
 	}`, reqID, nodeId)
 
 	// 发送请求
-	err := chromeInstance.NowTabWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.NowTabWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getBackgroundColors 请求失败: %w", err)
 	}
@@ -726,7 +724,7 @@ func CDPCSSGetBackgroundColors(nodeId int) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -779,8 +777,8 @@ func CDPCSSGetComputedStyleForNode(nodeId int) (string, error) {
 		return "", fmt.Errorf("节点ID必须是正整数")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -792,7 +790,7 @@ func CDPCSSGetComputedStyleForNode(nodeId int) (string, error) {
 	}`, reqID, nodeId)
 
 	// 发送请求
-	err := chromeInstance.NowTabWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.NowTabWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getComputedStyleForNode 请求失败: %w", err)
 	}
@@ -806,7 +804,7 @@ func CDPCSSGetComputedStyleForNode(nodeId int) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1067,8 +1065,8 @@ func CDPCSSGetInlineStylesForNode(nodeId int) (string, error) {
 		return "", fmt.Errorf("节点ID必须是正整数")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -1080,7 +1078,7 @@ func CDPCSSGetInlineStylesForNode(nodeId int) (string, error) {
 	}`, reqID, nodeId)
 
 	// 发送请求
-	err := chromeInstance.NowTabWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.NowTabWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getInlineStylesForNode 请求失败: %w", err)
 	}
@@ -1094,7 +1092,7 @@ func CDPCSSGetInlineStylesForNode(nodeId int) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1181,8 +1179,8 @@ func CDPCSSGetMatchedStylesForNode(nodeId int) (string, error) {
 		return "", fmt.Errorf("节点ID必须是正整数")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -1194,7 +1192,7 @@ func CDPCSSGetMatchedStylesForNode(nodeId int) (string, error) {
 	}`, reqID, nodeId)
 
 	// 发送请求
-	err := chromeInstance.NowTabWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.NowTabWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getMatchedStylesForNode 请求失败: %w", err)
 	}
@@ -1208,7 +1206,7 @@ func CDPCSSGetMatchedStylesForNode(nodeId int) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1254,8 +1252,8 @@ func CDPCSSGetMediaQueries() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息（无参数）
 	message := fmt.Sprintf(`{
@@ -1264,7 +1262,7 @@ func CDPCSSGetMediaQueries() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.NowTabWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.NowTabWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getMediaQueries 请求失败: %w", err)
 	}
@@ -1278,7 +1276,7 @@ func CDPCSSGetMediaQueries() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1473,8 +1471,8 @@ func CDPCSSGetPlatformFontsForNode(nodeId int) (string, error) {
 		return "", fmt.Errorf("节点ID必须是正整数")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -1486,7 +1484,7 @@ func CDPCSSGetPlatformFontsForNode(nodeId int) (string, error) {
 	}`, reqID, nodeId)
 
 	// 发送请求
-	err := chromeInstance.NowTabWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.NowTabWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getPlatformFontsForNode 请求失败: %w", err)
 	}
@@ -1500,7 +1498,7 @@ func CDPCSSGetPlatformFontsForNode(nodeId int) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1553,8 +1551,8 @@ func CDPCSSGetStyleSheetText(styleSheetId string) (string, error) {
 		return "", fmt.Errorf("样式表ID不能为空")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -1566,7 +1564,7 @@ func CDPCSSGetStyleSheetText(styleSheetId string) (string, error) {
 	}`, reqID, styleSheetId)
 
 	// 发送请求
-	err := chromeInstance.NowTabWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.NowTabWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getStyleSheetText 请求失败: %w", err)
 	}
@@ -1580,7 +1578,7 @@ func CDPCSSGetStyleSheetText(styleSheetId string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1630,8 +1628,8 @@ func CDPCSSSetEffectivePropertyValueForNode(params string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -1641,7 +1639,7 @@ func CDPCSSSetEffectivePropertyValueForNode(params string) (string, error) {
 	}`, reqID, params)
 
 	// 发送请求
-	err := chromeInstance.NowTabWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.NowTabWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setEffectivePropertyValueForNode 请求失败: %w", err)
 	}
@@ -1655,7 +1653,7 @@ func CDPCSSSetEffectivePropertyValueForNode(params string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1704,14 +1702,14 @@ func CDPCSSSetKeyframeKey(params string) (string, error) {
 	if chromeInstance.NowTabWSConn == nil {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "CSS.setKeyframeKey",
 		"params": %s
 	}`, reqID, params)
-	err := chromeInstance.NowTabWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.NowTabWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setKeyframeKey 请求失败: %w", err)
 	}
@@ -1725,7 +1723,7 @@ func CDPCSSSetKeyframeKey(params string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1775,14 +1773,14 @@ func CDPCSSSetMediaText(params string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "CSS.setMediaText",
 		"params": %s
 	}`, reqID, params)
-	err := chromeInstance.NowTabWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.NowTabWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setMediaText 请求失败: %w", err)
 	}
@@ -1794,7 +1792,7 @@ func CDPCSSSetMediaText(params string) (string, error) {
 	defer timer.Stop()
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1844,14 +1842,14 @@ func CDPCSSSetPropertyRulePropertyName(params string) (string, error) {
 	if chromeInstance.NowTabWSConn == nil {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "CSS.setPropertyRulePropertyName",
 		"params": %s
 	}`, reqID, params)
-	err := chromeInstance.NowTabWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.NowTabWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setPropertyRulePropertyName 请求失败: %w", err)
 	}
@@ -1865,7 +1863,7 @@ func CDPCSSSetPropertyRulePropertyName(params string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1919,14 +1917,14 @@ func CDPCSSSetRuleSelector(params string) (string, error) {
 	if chromeInstance.NowTabWSConn == nil {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "CSS.setRuleSelector",
 		"params": %s
 	}`, reqID, params)
-	err := chromeInstance.NowTabWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.NowTabWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setRuleSelector 请求失败: %w", err)
 	}
@@ -1939,7 +1937,7 @@ func CDPCSSSetRuleSelector(params string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1996,8 +1994,8 @@ func CDPCSSSetStyleSheetText(styleSheetId, text string) (string, error) {
 		return "", fmt.Errorf("样式表文本不能为空")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 转义特殊字符
 	escapedText := strings.ReplaceAll(text, `"`, `\"`)
@@ -2015,7 +2013,7 @@ func CDPCSSSetStyleSheetText(styleSheetId, text string) (string, error) {
 	}`, reqID, styleSheetId, escapedText)
 
 	// 发送请求
-	err := chromeInstance.NowTabWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.NowTabWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setStyleSheetText 请求失败: %w", err)
 	}
@@ -2029,7 +2027,7 @@ func CDPCSSSetStyleSheetText(styleSheetId, text string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2077,14 +2075,14 @@ func CDPCSSSetStyleTexts(params string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "CSS.setStyleTexts",
 		"params": %s
 	}`, reqID, params)
-	err := chromeInstance.NowTabWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.NowTabWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setStyleTexts 请求失败: %w", err)
 	}
@@ -2098,7 +2096,7 @@ func CDPCSSSetStyleTexts(params string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2144,8 +2142,8 @@ func CDPCSSStartRuleUsageTracking() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息（无参数）
 	message := fmt.Sprintf(`{
@@ -2154,7 +2152,7 @@ func CDPCSSStartRuleUsageTracking() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.NowTabWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.NowTabWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 startRuleUsageTracking 请求失败: %w", err)
 	}
@@ -2168,7 +2166,7 @@ func CDPCSSStartRuleUsageTracking() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2214,8 +2212,8 @@ func CDPCSSStopRuleUsageTracking() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息（无参数）
 	message := fmt.Sprintf(`{
@@ -2224,7 +2222,7 @@ func CDPCSSStopRuleUsageTracking() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.NowTabWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.NowTabWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 stopRuleUsageTracking 请求失败: %w", err)
 	}
@@ -2238,7 +2236,7 @@ func CDPCSSStopRuleUsageTracking() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2284,8 +2282,8 @@ func CDPCSSTakeCoverageDelta() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息（无参数）
 	message := fmt.Sprintf(`{
@@ -2294,7 +2292,7 @@ func CDPCSSTakeCoverageDelta() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.NowTabWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.NowTabWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 takeCoverageDelta 请求失败: %w", err)
 	}
@@ -2308,7 +2306,7 @@ func CDPCSSTakeCoverageDelta() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2354,8 +2352,8 @@ func CDPCSSGetEnvironmentVariables() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息（无参数）
 	message := fmt.Sprintf(`{
@@ -2364,7 +2362,7 @@ func CDPCSSGetEnvironmentVariables() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.NowTabWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.NowTabWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getEnvironmentVariables 请求失败: %w", err)
 	}
@@ -2378,7 +2376,7 @@ func CDPCSSGetEnvironmentVariables() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2428,14 +2426,14 @@ func CDPCSSSetContainerQueryText(params string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "CSS.setContainerQueryText",
 		"params": %s
 	}`, reqID, params)
-	err := chromeInstance.NowTabWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.NowTabWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setContainerQueryText 请求失败: %w", err)
 	}
@@ -2449,7 +2447,7 @@ func CDPCSSSetContainerQueryText(params string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
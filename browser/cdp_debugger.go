@@ -7,8 +7,6 @@ import (
 	"log"
 	"strings"
 	"time"
-
-	"github.com/gorilla/websocket"
 )
 
 // -----------------------------------------------  Debugger.continueToLocation  -----------------------------------------------
@@ -44,8 +42,8 @@ func CDPDebuggerContinueToLocation(scriptId string, lineNumber, columnNumber int
 		return "", fmt.Errorf("列号必须是非负整数")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建位置对象
 	location := map[string]interface{}{
@@ -69,7 +67,7 @@ func CDPDebuggerContinueToLocation(scriptId string, lineNumber, columnNumber int
 	}`, reqID, locationJSON)
 
 	// 发送请求
-	err = chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err = wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 continueToLocation 请求失败: %w", err)
 	}
@@ -83,7 +81,7 @@ func CDPDebuggerContinueToLocation(scriptId string, lineNumber, columnNumber int
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -122,8 +120,8 @@ func CDPDebuggerDisable() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息（无参数）
 	message := fmt.Sprintf(`{
@@ -132,7 +130,7 @@ func CDPDebuggerDisable() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 disable 请求失败: %w", err)
 	}
@@ -146,7 +144,7 @@ func CDPDebuggerDisable() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -187,8 +185,8 @@ func CDPDebuggerEnable(maxScriptsCacheSize int) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	params := ""
@@ -207,7 +205,7 @@ func CDPDebuggerEnable(maxScriptsCacheSize int) (string, error) {
 	}())
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 enable 请求失败: %w", err)
 	}
@@ -221,7 +219,7 @@ func CDPDebuggerEnable(maxScriptsCacheSize int) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -272,8 +270,8 @@ func CDPDebuggerEvaluateOnCallFrame(params string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -283,7 +281,7 @@ func CDPDebuggerEvaluateOnCallFrame(params string) (string, error) {
 	}`, reqID, params)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 evaluateOnCallFrame 请求失败: %w", err)
 	}
@@ -297,7 +295,7 @@ func CDPDebuggerEvaluateOnCallFrame(params string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -350,14 +348,14 @@ func CDPDebuggerGetPossibleBreakpoints(params string) (string, error) {
 	if chromeInstance.BrowserWSConn == nil {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "Debugger.getPossibleBreakpoints",
 		"params": %s
 	}`, reqID, params)
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getPossibleBreakpoints 请求失败: %w", err)
 	}
@@ -368,7 +366,7 @@ func CDPDebuggerGetPossibleBreakpoints(params string) (string, error) {
 	defer timer.Stop()
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -421,8 +419,8 @@ func CDPDebuggerGetScriptSource(scriptId string) (string, error) {
 		return "", fmt.Errorf("脚本ID不能为空")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -434,7 +432,7 @@ func CDPDebuggerGetScriptSource(scriptId string) (string, error) {
 	}`, reqID, scriptId)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getScriptSource 请求失败: %w", err)
 	}
@@ -448,7 +446,7 @@ func CDPDebuggerGetScriptSource(scriptId string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -517,8 +515,8 @@ func CDPDebuggerPause() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息（无参数）
 	message := fmt.Sprintf(`{
@@ -527,7 +525,7 @@ func CDPDebuggerPause() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 pause 请求失败: %w", err)
 	}
@@ -541,7 +539,7 @@ func CDPDebuggerPause() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -594,8 +592,8 @@ func CDPDebuggerRestartFrame(callFrameId string) (string, error) {
 		return "", fmt.Errorf("调用帧ID不能为空")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -608,7 +606,7 @@ func CDPDebuggerRestartFrame(callFrameId string) (string, error) {
 	}`, reqID, callFrameId)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 restartFrame 请求失败: %w", err)
 	}
@@ -622,7 +620,7 @@ func CDPDebuggerRestartFrame(callFrameId string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -670,8 +668,8 @@ func CDPDebuggerResume(terminateOnResume bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -683,7 +681,7 @@ func CDPDebuggerResume(terminateOnResume bool) (string, error) {
 	}`, reqID, terminateOnResume)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 resume 请求失败: %w", err)
 	}
@@ -697,7 +695,7 @@ func CDPDebuggerResume(terminateOnResume bool) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -756,8 +754,8 @@ func CDPDebuggerSearchInContent(scriptId, query string, caseSensitive, isRegex b
 		return "", fmt.Errorf("搜索查询不能为空")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 转义查询字符串中的特殊字符
 	escapedQuery := strings.ReplaceAll(query, `"`, `\"`)
@@ -777,7 +775,7 @@ func CDPDebuggerSearchInContent(scriptId, query string, caseSensitive, isRegex b
 	}`, reqID, scriptId, escapedQuery, caseSensitive, isRegex)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 searchInContent 请求失败: %w", err)
 	}
@@ -791,7 +789,7 @@ func CDPDebuggerSearchInContent(scriptId, query string, caseSensitive, isRegex b
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1141,8 +1139,8 @@ func CDPDebuggerSetAsyncCallStackDepth(maxDepth int) (string, error) {
 		return "", fmt.Errorf("异步调用栈深度不能为负数")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -1154,7 +1152,7 @@ func CDPDebuggerSetAsyncCallStackDepth(maxDepth int) (string, error) {
 	}`, reqID, maxDepth)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setAsyncCallStackDepth 请求失败: %w", err)
 	}
@@ -1168,7 +1166,7 @@ func CDPDebuggerSetAsyncCallStackDepth(maxDepth int) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1218,8 +1216,8 @@ func CDPDebuggerSetBreakpoint(params string) (string, error) {
 	if chromeInstance.BrowserWSConn == nil {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	// 构建消息
 	message := fmt.Sprintf(`{
 		"id": %d,
@@ -1228,7 +1226,7 @@ func CDPDebuggerSetBreakpoint(params string) (string, error) {
 	}`, reqID, params)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setBreakpoint 请求失败: %w", err)
 	}
@@ -1242,7 +1240,7 @@ func CDPDebuggerSetBreakpoint(params string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1295,14 +1293,14 @@ func CDPDebuggerSetBreakpointByUrl(params string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "Debugger.setBreakpointByUrl",
 		"params": %s
 	}`, reqID, params)
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setBreakpointByUrl 请求失败: %w", err)
 	}
@@ -1314,7 +1312,7 @@ func CDPDebuggerSetBreakpointByUrl(params string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1362,8 +1360,8 @@ func CDPDebuggerSetBreakpointsActive(active bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -1375,7 +1373,7 @@ func CDPDebuggerSetBreakpointsActive(active bool) (string, error) {
 	}`, reqID, active)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setBreakpointsActive 请求失败: %w", err)
 	}
@@ -1389,7 +1387,7 @@ func CDPDebuggerSetBreakpointsActive(active bool) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1438,14 +1436,14 @@ func CDPDebuggerSetInstrumentationBreakpoint(params string) (string, error) {
 	if chromeInstance.BrowserWSConn == nil {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "Debugger.setInstrumentationBreakpoint",
 		"params": %s
 	}`, reqID, params)
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setInstrumentationBreakpoint 请求失败: %w", err)
 	}
@@ -1456,7 +1454,7 @@ func CDPDebuggerSetInstrumentationBreakpoint(params string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1520,8 +1518,8 @@ func CDPDebuggerSetPauseOnExceptions(state string) error {
 		return fmt.Errorf("无效的异常暂停状态: %s，有效值为: none, caught, uncaught, all", state)
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -1533,7 +1531,7 @@ func CDPDebuggerSetPauseOnExceptions(state string) error {
 	}`, reqID, state)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return fmt.Errorf("发送 setPauseOnExceptions 请求失败: %w", err)
 	}
@@ -1547,7 +1545,7 @@ func CDPDebuggerSetPauseOnExceptions(state string) error {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return fmt.Errorf("消息队列已关闭")
 			}
@@ -1596,14 +1594,14 @@ func CDPDebuggerSetScriptSource(params string) (string, error) {
 	if chromeInstance.BrowserWSConn == nil {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "Debugger.setScriptSource",
 		"params": %s
 	}`, reqID, params)
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setScriptSource 请求失败: %w", err)
 	}
@@ -1617,7 +1615,7 @@ func CDPDebuggerSetScriptSource(params string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1665,8 +1663,8 @@ func CDPDebuggerSetSkipAllPauses(skip bool) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -1678,7 +1676,7 @@ func CDPDebuggerSetSkipAllPauses(skip bool) (string, error) {
 	}`, reqID, skip)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setSkipAllPauses 请求失败: %w", err)
 	}
@@ -1692,7 +1690,7 @@ func CDPDebuggerSetSkipAllPauses(skip bool) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1742,8 +1740,8 @@ func CDPDebuggerSetVariableValue(params string) (string, error) {
 	if chromeInstance.BrowserWSConn == nil {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "Debugger.setVariableValue",
@@ -1751,7 +1749,7 @@ func CDPDebuggerSetVariableValue(params string) (string, error) {
 	}`, reqID, params)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 setVariableValue 请求失败: %w", err)
 	}
@@ -1765,7 +1763,7 @@ func CDPDebuggerSetVariableValue(params string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1813,8 +1811,8 @@ func CDPDebuggerStepInto(params string) (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -1824,7 +1822,7 @@ func CDPDebuggerStepInto(params string) (string, error) {
 		}`, reqID, params)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 stepInto 请求失败: %w", err)
 	}
@@ -1838,7 +1836,7 @@ func CDPDebuggerStepInto(params string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1884,8 +1882,8 @@ func CDPDebuggerStepOut() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -1894,7 +1892,7 @@ func CDPDebuggerStepOut() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 stepOut 请求失败: %w", err)
 	}
@@ -1908,7 +1906,7 @@ func CDPDebuggerStepOut() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -1954,8 +1952,8 @@ func CDPDebuggerStepOver() (string, error) {
 		return "", fmt.Errorf("浏览器WebSocket连接未建立")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -1964,7 +1962,7 @@ func CDPDebuggerStepOver() (string, error) {
 	}`, reqID)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 stepOver 请求失败: %w", err)
 	}
@@ -1978,7 +1976,7 @@ func CDPDebuggerStepOver() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2031,8 +2029,8 @@ func CDPDebuggerDisassembleWasmModule(scriptId string) (string, error) {
 		return "", fmt.Errorf("脚本ID不能为空")
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -2044,7 +2042,7 @@ func CDPDebuggerDisassembleWasmModule(scriptId string) (string, error) {
 	}`, reqID, scriptId)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 disassembleWasmModule 请求失败: %w", err)
 	}
@@ -2058,7 +2056,7 @@ func CDPDebuggerDisassembleWasmModule(scriptId string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -2108,8 +2106,8 @@ func CDPDebuggerGetStackTrace(stackTraceId string) (string, error) {
 	if stackTraceId == "" {
 		return "", fmt.Errorf("调用栈ID不能为空")
 	}
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	// 构建消息
 	message := fmt.Sprintf(`{
 		"id": %d,
@@ -2120,7 +2118,7 @@ func CDPDebuggerGetStackTrace(stackTraceId string) (string, error) {
 	}`, reqID, stackTraceId)
 
 	// 发送请求
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		return "", fmt.Errorf("发送 getStackTrace 请求失败: %w", err)
 	}
@@ -2134,7 +2132,7 @@ func CDPDebuggerGetStackTrace(stackTraceId string) (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -6,7 +6,6 @@ import (
 	"log"
 	"time"
 
-	"github.com/gorilla/websocket"
 	gt "github.com/mangenotwork/gathertool"
 )
 
@@ -27,8 +26,8 @@ func CDPSystemInfoGetFeatureState(featureName string) (bool, error) {
 	if !DefaultBrowserWS() {
 		return false, nil
 	}
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	message := fmt.Sprintf(`{
 		   "id": %d,
@@ -38,7 +37,7 @@ func CDPSystemInfoGetFeatureState(featureName string) (bool, error) {
 		   }
 		}`, reqID, featureName)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		log.Println("发送 SystemInfo.getFeatureState 失败:", err)
 		chromeInstance.BrowserWSConn = nil
@@ -51,7 +50,7 @@ func CDPSystemInfoGetFeatureState(featureName string) (bool, error) {
 	defer timer.Stop()
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return false, fmt.Errorf("消息队列已关闭")
 			}
@@ -80,14 +79,14 @@ func CDPSystemInfoGetInfo() (string, error) {
 	if !DefaultBrowserWS() {
 		return "", nil
 	}
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 	   "id": %d,
 	   "method": "SystemInfo.getInfo"
 	}`, reqID)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		log.Println("发送 SystemInfo.getInfo 失败:", err)
 		chromeInstance.BrowserWSConn = nil
@@ -101,7 +100,7 @@ func CDPSystemInfoGetInfo() (string, error) {
 	defer timer.Stop()
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -125,14 +124,14 @@ func CDPSystemInfoGetProcessInfo() (string, error) {
 	if chromeInstance.BrowserWSConn == nil {
 		return "", fmt.Errorf("BrowserWSConn 未连接，无法调用 SystemInfo.getProcessInfo")
 	}
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "SystemInfo.getProcessInfo"
 	}`, reqID)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		log.Println("发送 SystemInfo.getProcessInfo 失败:", err)
 		return "", err
@@ -144,7 +143,7 @@ func CDPSystemInfoGetProcessInfo() (string, error) {
 	defer timer.Stop()
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -6,8 +6,6 @@ import (
 	"fmt"
 	"log"
 	"time"
-
-	"github.com/gorilla/websocket"
 )
 
 // CDPTargetActivateTarget 激活指定的目标
@@ -18,8 +16,8 @@ func CDPTargetActivateTarget(targetId string) (string, error) {
 	if chromeInstance.BrowserWSConn == nil {
 		return "", fmt.Errorf("BrowserWSConn 未连接，无法调用 Target.activateTarget")
 	}
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "Target.activateTarget",
@@ -28,7 +26,7 @@ func CDPTargetActivateTarget(targetId string) (string, error) {
 		}
 	}`, reqID, targetId)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		log.Println("发送 Target.activateTarget 失败:", err)
 		return "", err
@@ -40,7 +38,7 @@ func CDPTargetActivateTarget(targetId string) (string, error) {
 	defer timer.Stop()
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -63,8 +61,8 @@ func CDPTargetAttachToTarget(targetId string) (string, error) {
 	if chromeInstance.BrowserWSConn == nil {
 		return "", fmt.Errorf("BrowserWSConn 未连接，无法调用 Target.attachToTarget")
 	}
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "Target.attachToTarget",
@@ -73,7 +71,7 @@ func CDPTargetAttachToTarget(targetId string) (string, error) {
 		}
 	}`, reqID, targetId)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		log.Println("发送 Target.attachToTarget 失败:", err)
 		return "", err
@@ -85,7 +83,7 @@ func CDPTargetAttachToTarget(targetId string) (string, error) {
 	defer timer.Stop()
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -128,8 +126,8 @@ func CDPTargetCloseTarget(targetId string) (string, error) {
 	if chromeInstance.BrowserWSConn == nil {
 		return "", fmt.Errorf("BrowserWSConn 未连接，无法调用 Target.closeTarget")
 	}
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "Target.closeTarget",
@@ -138,7 +136,7 @@ func CDPTargetCloseTarget(targetId string) (string, error) {
 		}
 	}`, reqID, targetId)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		log.Println("发送 Target.closeTarget 失败:", err)
 		return "", err
@@ -150,7 +148,7 @@ func CDPTargetCloseTarget(targetId string) (string, error) {
 	defer timer.Stop()
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -179,14 +177,14 @@ func CDPTargetCreateBrowserContext() (string, error) {
 	if chromeInstance.BrowserWSConn == nil {
 		return "", fmt.Errorf("BrowserWSConn 未连接，无法调用 Target.createBrowserContext")
 	}
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "Target.createBrowserContext"
 	}`, reqID)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		log.Println("发送 Target.createBrowserContext 失败:", err)
 		return "", err
@@ -198,7 +196,7 @@ func CDPTargetCreateBrowserContext() (string, error) {
 	defer timer.Stop()
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -268,8 +266,8 @@ func CDPTargetCreateTarget(url string, options ...CreateTargetOption) (string, e
 		option(config)
 	}
 
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 
 	// 构建消息
 	message := fmt.Sprintf(`{
@@ -289,7 +287,7 @@ func CDPTargetCreateTarget(url string, options ...CreateTargetOption) (string, e
 
 	message += `}}`
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		log.Println("发送 Target.createTarget 失败:", err)
 		return "", err
@@ -301,7 +299,7 @@ func CDPTargetCreateTarget(url string, options ...CreateTargetOption) (string, e
 	defer timer.Stop()
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -400,8 +398,8 @@ func CDPTargetDetachFromTarget(sessionId string) (string, error) {
 	if chromeInstance.BrowserWSConn == nil {
 		return "", fmt.Errorf("BrowserWSConn 未连接，无法调用 Target.detachFromTarget")
 	}
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "Target.detachFromTarget",
@@ -410,7 +408,7 @@ func CDPTargetDetachFromTarget(sessionId string) (string, error) {
 		}
 	}`, reqID, sessionId)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		log.Println("发送 Target.detachFromTarget 失败:", err)
 		return "", err
@@ -422,7 +420,7 @@ func CDPTargetDetachFromTarget(sessionId string) (string, error) {
 	defer timer.Stop()
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -487,8 +485,8 @@ func CDPTargetDisposeBrowserContext(browserContextId string) (string, error) {
 	if chromeInstance.BrowserWSConn == nil {
 		return "", fmt.Errorf("BrowserWSConn 未连接，无法调用 Target.disposeBrowserContext")
 	}
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "Target.disposeBrowserContext",
@@ -497,7 +495,7 @@ func CDPTargetDisposeBrowserContext(browserContextId string) (string, error) {
 		}
 	}`, reqID, browserContextId)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		log.Println("发送 Target.disposeBrowserContext 失败:", err)
 		return "", err
@@ -509,7 +507,7 @@ func CDPTargetDisposeBrowserContext(browserContextId string) (string, error) {
 	defer timer.Stop()
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -532,14 +530,14 @@ func CDPTargetGetBrowserContexts() (string, error) {
 	if chromeInstance.BrowserWSConn == nil {
 		return "", fmt.Errorf("BrowserWSConn 未连接，无法调用 Target.getBrowserContexts")
 	}
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "Target.getBrowserContexts"
 	}`, reqID)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		log.Println("发送 Target.getBrowserContexts 失败:", err)
 		return "", err
@@ -551,7 +549,7 @@ func CDPTargetGetBrowserContexts() (string, error) {
 	defer timer.Stop()
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -574,14 +572,14 @@ func CDPTargetGetTargets() (string, error) {
 	if chromeInstance.BrowserWSConn == nil {
 		return "", fmt.Errorf("BrowserWSConn 未连接，无法调用 Target.getTargets")
 	}
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "Target.getTargets"
 	}`, reqID)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		log.Println("发送 Target.getTargets 失败:", err)
 		return "", err
@@ -593,7 +591,7 @@ func CDPTargetGetTargets() (string, error) {
 	defer timer.Stop()
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -616,8 +614,8 @@ func CDPTargetGetTargetInfo(targetId string) (string, error) {
 	if chromeInstance.BrowserWSConn == nil {
 		return "", fmt.Errorf("BrowserWSConn 未连接，无法调用 Target.getTargetInfo")
 	}
-	chromeInstance.NextID++
-	reqID := chromeInstance.NextID
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 		"id": %d,
 		"method": "Target.getTargetInfo",
@@ -626,7 +624,7 @@ func CDPTargetGetTargetInfo(targetId string) (string, error) {
 		}
 	}`, reqID, targetId)
 
-	err := chromeInstance.BrowserWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	err := wsWrite(chromeInstance.BrowserWSConn, []byte(message))
 	if err != nil {
 		log.Println("发送 Target.getTargetInfo 失败:", err)
 		return "", err
@@ -638,7 +636,7 @@ func CDPTargetGetTargetInfo(targetId string) (string, error) {
 	defer timer.Stop()
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return "", fmt.Errorf("消息队列已关闭")
 			}
@@ -0,0 +1,223 @@
+package browser
+
+import (
+	"ChromeBot/utils"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+捕获接口响应
+
+很多页面的数据来自json接口, 直接取接口的响应比解析渲染后的html更稳定;
+开始捕获后记录当前tab中地址匹配的XHR、fetch请求(通配 *、正则 /.../、包含, 见 utils.MatchURLPattern),
+请求完成(Network.loadingFinished)后按顺序放入待取列表, 取出时通过 Network.getResponseBody 获取响应体并解析json
+*/
+
+// DefaultCaptureTimeout 等待匹配的响应的默认超时时间
+var DefaultCaptureTimeout = 10 * time.Second
+
+// captureTypes 捕获的请求类型
+var captureTypes = map[string]bool{
+	"XHR":   true,
+	"Fetch": true,
+}
+
+// Captured 捕获到的接口响应
+type Captured struct {
+	URL      string
+	Method   string
+	Status   int
+	Headers  map[string]string
+	MimeType string
+	Body     string // 响应体文本
+	JSON     any    // 解析后的json, 响应体不是json时为nil
+	IsJSON   bool
+}
+
+// Capture 一次捕获
+type Capture struct {
+	Pattern  string
+	mu       sync.Mutex
+	listenId int
+	session  string
+	pending  map[string]*NetEntry // requestId -> 进行中的请求
+	done     []*NetEntry          // 已完成未取出的请求
+	notify   chan struct{}
+}
+
+// StartCapture 开始捕获当前tab中地址匹配pattern的XHR、fetch响应
+func StartCapture(pattern string) (*Capture, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("捕获的地址匹配不能为空")
+	}
+	if err := ensureNetwork(); err != nil {
+		return nil, err
+	}
+	c := &Capture{
+		Pattern: pattern,
+		session: chromeInstance.NowTabSession,
+		pending: make(map[string]*NetEntry),
+		notify:  make(chan struct{}, 1),
+	}
+	c.listenId = OnEvent("Network.*", c.onEvent)
+	log.Printf("[Chrome]开始捕获接口响应: %s", pattern)
+	return c, nil
+}
+
+func (c *Capture) onEvent(method, sessionId string, params map[string]any) {
+	if sessionId != c.session {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	requestId := mapStr(params, "requestId")
+	timestamp, _ := params["timestamp"].(float64)
+
+	if method == "Network.requestWillBeSent" {
+		request, _ := params["request"].(map[string]any)
+		url := mapStr(request, "url")
+		if e, ok := c.pending[requestId]; ok { // 重定向后的地址
+			e.URL = url
+			return
+		}
+		if !captureTypes[mapStr(params, "type")] || !utils.MatchURLPattern(c.Pattern, url) {
+			return
+		}
+		c.pending[requestId] = &NetEntry{
+			RequestId: requestId,
+			SessionId: sessionId,
+			URL:       url,
+			Method:    mapStr(request, "method"),
+			Type:      mapStr(params, "type"),
+			StartTime: timestamp,
+		}
+		return
+	}
+
+	e, ok := c.pending[requestId]
+	if !ok {
+		return
+	}
+	switch method {
+	case "Network.responseReceived":
+		response, _ := params["response"].(map[string]any)
+		e.applyResponse(response, timestamp)
+	case "Network.loadingFinished":
+		e.EndTime = timestamp
+		e.Finished = true
+		delete(c.pending, requestId)
+		c.done = append(c.done, e)
+		select {
+		case c.notify <- struct{}{}:
+		default:
+		}
+	case "Network.loadingFailed":
+		delete(c.pending, requestId)
+		log.Printf("[Chrome]捕获的请求失败: %s %s", e.URL, mapStr(params, "errorText"))
+	}
+}
+
+// Next 等待下一个匹配的响应, 已有完成的响应时直接返回
+func (c *Capture) Next(timeout time.Duration) (*Captured, error) {
+	if timeout <= 0 {
+		timeout = DefaultCaptureTimeout
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		c.mu.Lock()
+		if len(c.done) > 0 {
+			e := c.done[0]
+			c.done = c.done[1:]
+			c.mu.Unlock()
+			return loadCaptured(e), nil
+		}
+		c.mu.Unlock()
+		select {
+		case <-c.notify:
+		case <-timer.C:
+			return nil, fmt.Errorf("等待接口响应超时(%v): %s", timeout, c.Pattern)
+		}
+	}
+}
+
+// Take 等待进行中的匹配请求完成(最多timeout), 取出所有已完成的响应; timeout 小于等于0时不等待, 只取出已完成的
+func (c *Capture) Take(timeout time.Duration) []*Captured {
+	if timeout > 0 {
+		if _, err := pollUntil(timeout, "捕获的请求完成", func() (bool, string, error) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			return len(c.pending) == 0, fmt.Sprintf("%d个请求进行中", len(c.pending)), nil
+		}); err != nil {
+			log.Println("[Chrome]", err.Error())
+		}
+	}
+	c.mu.Lock()
+	done := c.done
+	c.done = nil
+	c.mu.Unlock()
+	res := make([]*Captured, 0, len(done))
+	for _, e := range done {
+		res = append(res, loadCaptured(e))
+	}
+	return res
+}
+
+// Stop 停止捕获
+func (c *Capture) Stop() {
+	OffEvent(c.listenId)
+	log.Printf("[Chrome]停止捕获接口响应: %s", c.Pattern)
+}
+
+// loadCaptured 获取响应体并解析json
+func loadCaptured(e *NetEntry) *Captured {
+	res := &Captured{
+		URL:      e.URL,
+		Method:   e.Method,
+		Status:   e.Status,
+		Headers:  e.ResponseHeaders,
+		MimeType: e.MimeType,
+	}
+	if e.Status == 204 || !DefaultNowTab(false) {
+		return res
+	}
+	body, err := cdpCall(chromeInstance.NowTabWSConn, e.SessionId, "Network.getResponseBody", map[string]any{"requestId": e.RequestId}, 6*time.Second)
+	if err != nil {
+		log.Printf("[Chrome]获取响应体失败: %s %s", e.URL, err.Error())
+		return res
+	}
+	res.Body = mapStr(body, "body")
+	if encoded, _ := body["base64Encoded"].(bool); encoded {
+		if b, err := base64.StdEncoding.DecodeString(res.Body); err == nil {
+			res.Body = string(b)
+		}
+	}
+	res.JSON, res.IsJSON = parseCapturedJSON(res.Body)
+	return res
+}
+
+// xssiPrefixes 部分接口在json前加的防劫持前缀
+var xssiPrefixes = []string{")]}'", "while(1);", "for(;;);"}
+
+// parseCapturedJSON 解析响应体中的json, 去掉防劫持前缀
+func parseCapturedJSON(body string) (any, bool) {
+	text := strings.TrimSpace(body)
+	for _, prefix := range xssiPrefixes {
+		if strings.HasPrefix(text, prefix) {
+			text = strings.TrimSpace(strings.TrimPrefix(text, prefix))
+			text = strings.TrimPrefix(text, ",")
+			break
+		}
+	}
+	var v any
+	if err := json.Unmarshal([]byte(text), &v); err != nil {
+		return nil, false
+	}
+	return v, true
+}
@@ -0,0 +1,82 @@
+package browser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCaptureEvents(t *testing.T) {
+	c := &Capture{Pattern: "*/api/list*", session: "s1", pending: make(map[string]*NetEntry), notify: make(chan struct{}, 1)}
+	send := func(method, id string, params map[string]any) {
+		params["requestId"] = id
+		c.onEvent(method, "s1", params)
+	}
+	request := func(url string) map[string]any {
+		return map[string]any{"url": url, "method": "GET"}
+	}
+
+	send("Network.requestWillBeSent", "1", map[string]any{"type": "XHR", "request": request("http://a.com/api/list?page=1")})
+	send("Network.requestWillBeSent", "2", map[string]any{"type": "Image", "request": request("http://a.com/api/list.png")})
+	send("Network.requestWillBeSent", "3", map[string]any{"type": "Fetch", "request": request("http://a.com/api/user")})
+	send("Network.requestWillBeSent", "4", map[string]any{"type": "Fetch", "request": request("http://a.com/api/list?page=2")})
+	c.onEvent("Network.requestWillBeSent", "s2", map[string]any{"requestId": "5", "type": "XHR", "request": request("http://a.com/api/list")})
+	if len(c.pending) != 2 {
+		t.Fatalf("pending: %d", len(c.pending))
+	}
+
+	send("Network.responseReceived", "4", map[string]any{"response": map[string]any{"status": float64(500)}})
+	send("Network.loadingFinished", "4", map[string]any{})
+	send("Network.responseReceived", "1", map[string]any{"response": map[string]any{"status": float64(200), "headers": map[string]any{"Content-Type": "application/json"}}})
+	send("Network.loadingFailed", "1", map[string]any{"errorText": "net::ERR_ABORTED"})
+	if len(c.pending) != 0 || len(c.done) != 1 {
+		t.Fatalf("pending: %d done: %d", len(c.pending), len(c.done))
+	}
+
+	res, err := c.Next(time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.URL != "http://a.com/api/list?page=2" || res.Status != 500 {
+		t.Errorf("next: %+v", res)
+	}
+	if _, err := c.Next(50 * time.Millisecond); err == nil {
+		t.Error("没有响应时应超时")
+	}
+}
+
+func TestCaptureTakeNoWait(t *testing.T) {
+	c := &Capture{Pattern: "*/api/list*", session: "s1", pending: make(map[string]*NetEntry), notify: make(chan struct{}, 1)}
+	c.onEvent("Network.requestWillBeSent", "s1", map[string]any{"requestId": "1", "type": "XHR", "request": map[string]any{"url": "http://a.com/api/list", "method": "GET"}})
+
+	// 有进行中的请求时, timeout 为0只取出已完成的, 不等待
+	start := time.Now()
+	if res := c.Take(0); len(res) != 0 {
+		t.Errorf("take: %d", len(res))
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("不应等待进行中的请求: %v", elapsed)
+	}
+	if len(c.pending) != 1 {
+		t.Errorf("进行中的请求不应被取走: %d", len(c.pending))
+	}
+}
+
+func TestParseCapturedJSON(t *testing.T) {
+	tests := []struct {
+		body string
+		ok   bool
+	}{
+		{`{"list": [1, 2]}`, true},
+		{" [1, 2]\n", true},
+		{")]}',\n{\"a\": 1}", true},
+		{`while(1);{"a": 1}`, true},
+		{"<html></html>", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		v, ok := parseCapturedJSON(tt.body)
+		if ok != tt.ok || (ok && v == nil) {
+			t.Errorf("%q: %v %v", tt.body, v, ok)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package browser
+
+import (
+	"ChromeBot/utils"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	gt "github.com/mangenotwork/gathertool"
+)
+
+// cdpCall 发送cdp指令并等待回复，返回回复中的result
+// conn 为nil时使用当前tab的连接; sessionId 为空时是浏览器级的指令
+func cdpCall(conn *websocket.Conn, sessionId, method string, params map[string]any, timeout time.Duration) (map[string]any, error) {
+	if conn == nil {
+		if chromeInstance == nil || chromeInstance.NowTabWSConn == nil {
+			return nil, notReadyErr()
+		}
+		conn = chromeInstance.NowTabWSConn
+	}
+
+	id, respCh := nextReply()
+	defer dropReply(id)
+	msg := cdpMessage(id, sessionId, method, params)
+	if err := wsWriteJSON(conn, msg); err != nil {
+		log.Println("发送消息失败:", err)
+		return nil, fmt.Errorf("发送消息失败")
+	}
+	msgStr, _ := json.Marshal(msg)
+	utils.Debugf("发送消息: %s", string(msgStr))
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop() // 重要：确保计时器被清理
+
+	select {
+	case respMsg := <-respCh:
+		result, err := gt.Json2Map(respMsg.Content)
+		if err != nil {
+			return nil, fmt.Errorf("回复内容解析错误")
+		}
+		if errData, has := result["error"].(map[string]any); has {
+			return nil, fmt.Errorf("%s 执行错误: %v", method, errData["message"])
+		}
+		resultData, _ := result["result"].(map[string]any)
+		if resultData == nil {
+			resultData = map[string]any{}
+		}
+		return resultData, nil
+
+	case <-timer.C:
+		return nil, fmt.Errorf("%s 接收消息超时; %v未收到消息", method, timeout)
+	}
+}
+
+// cdpMessage 组装cdp指令
+func cdpMessage(id int, sessionId, method string, params map[string]any) map[string]any {
+	if params == nil {
+		params = map[string]any{}
+	}
+	msg := map[string]any{
+		"id":     id,
+		"method": method,
+		"params": params,
+	}
+	if sessionId != "" {
+		msg["sessionId"] = sessionId
+	}
+	return msg
+}
+
+// tabCall 在当前tab的session上执行cdp指令
+func tabCall(method string, params map[string]any) (map[string]any, error) {
+	if !DefaultNowTab(false) {
+		return nil, notReadyErr()
+	}
+	return cdpCall(chromeInstance.NowTabWSConn, chromeInstance.NowTabSession, method, params, 6*time.Second)
+}
+
+// browserCall 在浏览器级连接上执行cdp指令
+func browserCall(method string, params map[string]any) (map[string]any, error) {
+	conn, err := ensureBrowserWS()
+	if err != nil {
+		return nil, err
+	}
+	return cdpCall(conn, "", method, params, 6*time.Second)
+}
+
+// ensureBrowserWS 获取浏览器级的ws连接, 已连接则复用
+func ensureBrowserWS() (*websocket.Conn, error) {
+	if chromeInstance == nil {
+		return nil, notReadyErr()
+	}
+	if chromeInstance.BrowserWSConn != nil {
+		return chromeInstance.BrowserWSConn, nil
+	}
+	if !DefaultBrowserWS() || chromeInstance.BrowserWSConn == nil {
+		return nil, fmt.Errorf("连接浏览器debug url失败")
+	}
+	return chromeInstance.BrowserWSConn, nil
+}
+
+// cdpSend 发送cdp指令不等待回复, 用于事件回调中(回调在读ws的协程中执行, 不能等待回复); 回复没有登记, 收到后直接丢弃
+func cdpSend(conn *websocket.Conn, sessionId, method string, params map[string]any) error {
+	if conn == nil {
+		return notReadyErr()
+	}
+	utils.Debugf("发送消息: %s %s", method, sessionId)
+	return wsWriteJSON(conn, cdpMessage(GetNextMsgID(), sessionId, method, params))
+}
+
+// mapStr 取cdp回复中的字符串字段, 不存在时为空
+func mapStr(m map[string]any, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
+
+// mapInt 取cdp回复中的数值字段, 不存在时为0
+func mapInt(m map[string]any, key string) int {
+	f, _ := m[key].(float64)
+	return int(f)
+}
+
+// frameEval 在当前操作的frame中执行js表达式, 返回值按值返回; js抛出的异常作为错误返回
+func frameEval(expression string, awaitPromise bool, timeout time.Duration) (any, error) {
+	if !DefaultNowTab(false) {
+		return nil, notReadyErr()
+	}
+	sessionId, contextId, err := nowFrameContext()
+	if err != nil {
+		return nil, err
+	}
+	res, err := cdpCall(chromeInstance.NowTabWSConn, sessionId, "Runtime.evaluate", withContextId(map[string]any{
+		"expression":    expression,
+		"returnByValue": true,
+		"awaitPromise":  awaitPromise,
+	}, contextId), timeout)
+	if err != nil {
+		return nil, err
+	}
+	if exception, has := res["exceptionDetails"].(map[string]any); has {
+		return nil, fmt.Errorf("js执行异常: %s", exceptionMessage(exception))
+	}
+	obj, _ := res["result"].(map[string]any)
+	return obj["value"], nil
+}
+
+// exceptionMessage 取js异常的描述, 描述中没有调用栈时(如抛出的不是Error、语法错误)补上调用栈或出错位置
+func exceptionMessage(exception map[string]any) string {
+	msg := mapStr(exception, "text")
+	if obj, ok := exception["exception"].(map[string]any); ok {
+		if desc := mapStr(obj, "description"); desc != "" {
+			msg = desc
+		} else if v, ok := obj["value"]; ok {
+			msg = fmt.Sprint(v)
+		}
+	}
+	if strings.Contains(msg, "\n    at ") {
+		return msg
+	}
+	stack, _ := exception["stackTrace"].(map[string]any)
+	frames, _ := stack["callFrames"].([]any)
+	if len(frames) == 0 {
+		if mapStr(exception, "url") == "" {
+			return msg
+		}
+		return fmt.Sprintf("%s\n    at %s:%d:%d", msg, mapStr(exception, "url"), mapInt(exception, "lineNumber")+1, mapInt(exception, "columnNumber")+1)
+	}
+	for _, f := range frames {
+		frame, _ := f.(map[string]any)
+		fn := mapStr(frame, "functionName")
+		if fn == "" {
+			fn = "<anonymous>"
+		}
+		msg += fmt.Sprintf("\n    at %s (%s:%d:%d)", fn, mapStr(frame, "url"), mapInt(frame, "lineNumber")+1, mapInt(frame, "columnNumber")+1)
+	}
+	return msg
+}
@@ -7,7 +7,6 @@ import (
 	"fmt"
 	gt "github.com/mangenotwork/gathertool"
 	"log"
-	"strings"
 	"time"
 )
 
@@ -17,24 +16,29 @@ var chromeCheckJS string
 func Check(xPath string) (bool, error) {
 
 	if !DefaultNowTab(true) {
-		return false, nil
+		return false, notReadyErr()
 	}
 
-	xPath = "\"" + strings.ReplaceAll(xPath, "\"", "\\\"") + "\""
-	js := strings.ReplaceAll(chromeCheckJS, "__XPATH__", xPath)
+	js := jsCall(chromeCheckJS, map[string]any{"xpath": xPath})
 
-	chromeInstance.NextID++
+	sessionId, contextId, err := nowFrameContext()
+	if err != nil {
+		return false, err
+	}
+
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	msg := map[string]interface{}{
-		"id":     chromeInstance.NextID,
+		"id":     reqID,
 		"method": "Runtime.evaluate",
-		"params": map[string]interface{}{
+		"params": withContextId(map[string]interface{}{
 			"expression":    js,
 			"returnByValue": true,
 			"awaitPromise":  true,
-		},
-		"sessionId": chromeInstance.NowTabSession,
+		}, contextId),
+		"sessionId": sessionId,
 	}
-	err := chromeInstance.NowTabWSConn.WriteJSON(msg)
+	err = wsWriteJSON(chromeInstance.NowTabWSConn, msg)
 	if err != nil {
 		gt.Error("发送消息失败:", err)
 		return false, fmt.Errorf("发送消息失败")
@@ -48,13 +52,13 @@ func Check(xPath string) (bool, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				log.Println("消息队列已关闭")
 				return false, fmt.Errorf("消息队列已关闭")
 			}
 			utils.Debug("收到的消息 -> ", respMsg.Content)
-			if chromeInstance.NextID == respMsg.ID {
+			if reqID == respMsg.ID {
 				resultValue, err := gt.JsonFind(respMsg.Content, "/result/result/value")
 				if err != nil {
 					log.Println(err)
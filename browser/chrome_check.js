@@ -1,15 +1,9 @@
-(() => {
+(args) => {
+    const __cbLocator = __LOCATOR__;
     try {
-        const xpath = __XPATH__;
-        const result = document.evaluate(
-            xpath,
-            document,
-            null,
-            XPathResult.FIRST_ORDERED_NODE_TYPE,
-            null
-        );
-        return result.singleNodeValue !== null;
+        const xpath = args.xpath;
+        return __cbLocator.first(xpath) !== null;
     } catch (error) {
         return false;
     }
-})()
\ No newline at end of file
+}
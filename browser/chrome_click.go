@@ -7,33 +7,47 @@ import (
 	"fmt"
 	gt "github.com/mangenotwork/gathertool"
 	"log"
-	"strings"
 	"time"
 )
 
 //go:embed chrome_click.js
 var chromeClickJS string
 
+// Click 点击, 等待元素可操作的时间为 DefaultActionTimeout
 func Click(xPath string) error {
+	return ClickWithTimeout(xPath, DefaultActionTimeout)
+}
+
+// ClickWithTimeout 点击, 先在timeout内等待元素可操作(见 chrome_wait.go)
+func ClickWithTimeout(xPath string, timeout time.Duration) error {
 	if !DefaultNowTab(true) {
-		return fmt.Errorf("浏览器未初始化")
+		return notReadyErr()
+	}
+
+	if err := waitActionable(xPath, actionClick, timeout); err != nil {
+		return err
 	}
 
-	xPath = "'" + strings.ReplaceAll(xPath, "\"", "\\\"") + "'"
-	js := strings.ReplaceAll(chromeClickJS, "__XPATH__", xPath)
+	js := jsCall(chromeClickJS, map[string]any{"xpath": xPath})
 
-	chromeInstance.NextID++
+	sessionId, contextId, err := nowFrameContext()
+	if err != nil {
+		return err
+	}
+
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	msg := map[string]interface{}{
-		"id":     chromeInstance.NextID,
+		"id":     reqID,
 		"method": "Runtime.evaluate",
-		"params": map[string]interface{}{
+		"params": withContextId(map[string]interface{}{
 			"expression":    js,
 			"returnByValue": true,
 			"awaitPromise":  true,
-		},
-		"sessionId": chromeInstance.NowTabSession,
+		}, contextId),
+		"sessionId": sessionId,
 	}
-	err := chromeInstance.NowTabWSConn.WriteJSON(msg)
+	err = wsWriteJSON(chromeInstance.NowTabWSConn, msg)
 	if err != nil {
 		log.Println("发送消息失败:", err)
 		return fmt.Errorf("发送消息失败")
@@ -41,19 +55,18 @@ func Click(xPath string) error {
 	msgStr, _ := json.Marshal(msg)
 	utils.Debugf("发送消息: %s", string(msgStr))
 
-	timeout := 6 * time.Second
-	timer := time.NewTimer(timeout)
+	timer := time.NewTimer(6 * time.Second)
 	defer timer.Stop() // 重要：确保计时器被清理
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				log.Println("消息队列已关闭")
 				return fmt.Errorf("消息队列已关闭")
 			}
 			utils.Debug("收到的消息 -> ", utils.UnescapeUnicode(respMsg.Content))
-			if chromeInstance.NextID == respMsg.ID {
+			if reqID == respMsg.ID {
 
 				resultValue, err := gt.JsonFind(respMsg.Content, "result/result/subtype")
 				if err != nil {
@@ -89,24 +102,4 @@ func Click(xPath string) error {
 	}
 }
 
-// todo  如果是知道坐标可以使用 cdp来替代js点击
-/*
-// CDP触发鼠标点击
-func (c *ChromeProcess) CDPClick(x, y int) error {
-    c.NextID++
-    msg := map[string]interface{}{
-        "id":     c.NextID,
-        "method": "Input.dispatchMouseEvent",
-        "params": map[string]interface{}{
-            "type": "mousePressed",
-            "x":    x,
-            "y":    y,
-            "button": "left",
-            "clickCount": 1,
-        },
-        "sessionId": c.NowTabSession,
-    }
-    // 发送请求...
-}
-....
-*/
+// 基于坐标的原生鼠标点击(Input.dispatchMouseEvent)见 chrome_mouse.go
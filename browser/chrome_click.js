@@ -1,4 +1,5 @@
-(() => {
+(args) => {
+    const __cbLocator = __LOCATOR__;
     const result = {
         success: false,
         message: "",
@@ -7,17 +8,11 @@
     };
 
     try {
-        // 1. 替换XPath参数（确保XPath正确）
-        const buttonXPath = __XPATH__;
+        // 1. 参数由调用方以JSON传入
+        const buttonXPath = args.xpath;
 
         // 2. 查找目标元素
-        const button = document.evaluate(
-            buttonXPath,
-            document,
-            null,
-            XPathResult.FIRST_ORDERED_NODE_TYPE,
-            null
-        ).singleNodeValue;
+        const button = __cbLocator.first(buttonXPath);
 
         if (!button) {
             result.message = `未找到XPath对应的元素: ${buttonXPath}`;
@@ -140,4 +135,4 @@
         result.message = `点击失败：${error.message}`;
         return result;
     }
-})();
\ No newline at end of file
+}
@@ -24,6 +24,9 @@ type DOMNode struct {
 	Children []*DOMNode
 }
 
+// shadowRootTag shadow root 在DOM树中的标签名, 其子节点的XPath以 宿主XPath + " >>> " 开头
+const shadowRootTag = "#shadow-root"
+
 // ParseHTMLToDOM 将HTML内容解析为带XPath的DOM树
 func ParseHTMLToDOM(htmlContent string) (*DOMNode, error) {
 	// 将HTML字符串转为io.Reader
@@ -39,6 +42,73 @@ func ParseHTMLToDOM(htmlContent string) (*DOMNode, error) {
 	return domRoot, nil
 }
 
+// ParseCDPNodeToDOM 将 DOM.getDocument(pierce:true) 返回的节点树解析为带XPath的DOM树, 会进入open的shadow root
+func ParseCDPNodeToDOM(root *Node) *DOMNode {
+	if root == nil {
+		return nil
+	}
+	return convertToDOMNode(cdpNodeToHTML(root), "")
+}
+
+// cdpNodeToHTML cdp节点转为html.Node, shadow root 转为标签为 shadowRootTag 的子节点
+func cdpNodeToHTML(n *Node) *html.Node {
+	node := &html.Node{}
+	switch n.NodeType {
+	case 1:
+		node.Type = html.ElementNode
+		node.Data = n.LocalName
+		for i := 0; i+1 < len(n.Attributes); i += 2 {
+			node.Attr = append(node.Attr, html.Attribute{Key: n.Attributes[i], Val: n.Attributes[i+1]})
+		}
+		for i := range n.ShadowRoots {
+			if n.ShadowRoots[i].ShadowRootType != "open" {
+				continue // closed 与 user-agent 的 shadow root 页面脚本访问不到
+			}
+			shadow := &html.Node{Type: html.ElementNode, Data: shadowRootTag}
+			for j := range n.ShadowRoots[i].Children {
+				shadow.AppendChild(cdpNodeToHTML(&n.ShadowRoots[i].Children[j]))
+			}
+			node.AppendChild(shadow)
+		}
+	case 3:
+		node.Type = html.TextNode
+		node.Data = n.NodeValue
+	case 8:
+		node.Type = html.CommentNode
+		node.Data = n.NodeValue
+	case 9:
+		node.Type = html.DocumentNode
+	case 10:
+		node.Type = html.DoctypeNode
+		node.Data = n.NodeName
+	default:
+		node.Type = html.RawNode
+		node.Data = n.NodeName
+	}
+	for i := range n.Children {
+		node.AppendChild(cdpNodeToHTML(&n.Children[i]))
+	}
+	return node
+}
+
+// isShadowRoot 是否是shadow root: cdp树中的 shadowRootTag 节点, 或html中声明式的 <template shadowrootmode="open">
+func isShadowRoot(node *html.Node) bool {
+	if node.Type != html.ElementNode {
+		return false
+	}
+	if node.Data == shadowRootTag {
+		return true
+	}
+	if node.Data == "template" {
+		for _, attr := range node.Attr {
+			if (attr.Key == "shadowrootmode" || attr.Key == "shadowroot") && attr.Val == "open" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // isWhitespaceTextNode 判断是否是空白文本节点（过滤换行/空格等无意义文本）
 func isWhitespaceTextNode(node *html.Node) bool {
 	if node.Type != html.TextNode {
@@ -131,25 +201,32 @@ func convertToDOMNode(node *html.Node, parentXPath string) *DOMNode {
 
 	// 构建当前节点的XPath
 	xpath := buildXPath(parentXPath, node)
+	tagName := node.Data
+	if isShadowRoot(node) {
+		// shadow root 内的节点相对宿主定位: 宿主XPath >>> /div[1]
+		xpath = parentXPath + " >>> "
+		tagName = shadowRootTag
+	}
 
 	// 初始化自定义节点
 	domNode := &DOMNode{
 		Type:       node.Type,
-		TagName:    node.Data,
+		TagName:    tagName,
 		Attributes: make(map[string]string),
 		XPath:      xpath,
 	}
 
 	// 处理不同类型的节点内容
-	switch node.Type {
-	case html.TextNode:
+	switch {
+	case tagName == shadowRootTag:
+	case node.Type == html.TextNode:
 		domNode.Content = strings.TrimSpace(node.Data) // 清理文本空白
-	case html.ElementNode:
+	case node.Type == html.ElementNode:
 		// 完整保留所有属性键值对
 		for _, attr := range node.Attr {
 			domNode.Attributes[attr.Key] = attr.Val
 		}
-	case html.CommentNode:
+	case node.Type == html.CommentNode:
 		domNode.Content = node.Data
 	}
 
@@ -37,3 +37,56 @@ func TestDemo(t *testing.T) {
 	fmt.Println("解析后的DOM树（含XPath和所有属性）：")
 	PrintDOM(domRoot, 0)
 }
+
+func TestParseHTMLToDOMShadowRoot(t *testing.T) {
+	// 声明式 shadow root
+	testHTML := `<html><body><my-app><template shadowrootmode="open"><div><input name="q"></div></template></my-app></body></html>`
+
+	domRoot, err := ParseHTMLToDOM(testHTML)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	PrintDOM(domRoot, 0)
+
+	xpathList := make([]string, 0)
+	MatchInput(domRoot, &xpathList)
+	want := "/html[1]/body[1]/my-app[1] >>> /div[1]/input[1]"
+	if len(xpathList) != 1 || xpathList[0] != want {
+		t.Errorf("MatchInput = %v, want [%s]", xpathList, want)
+	}
+}
+
+func TestParseCDPNodeToDOM(t *testing.T) {
+	// DOM.getDocument(pierce:true) 返回的结构
+	root := &Node{NodeType: 9, NodeName: "#document", Children: []Node{
+		{NodeType: 1, LocalName: "html", Children: []Node{
+			{NodeType: 1, LocalName: "body", Children: []Node{
+				{NodeType: 1, LocalName: "my-app", ShadowRoots: []Node{
+					{NodeType: 11, ShadowRootType: "open", Children: []Node{
+						{NodeType: 1, LocalName: "button", Attributes: []string{"id", "buy"}, Children: []Node{
+							{NodeType: 3, NodeValue: "立即购买"},
+						}},
+					}},
+				}},
+				{NodeType: 1, LocalName: "my-closed", ShadowRoots: []Node{
+					{NodeType: 11, ShadowRootType: "closed", Children: []Node{
+						{NodeType: 1, LocalName: "input"},
+					}},
+				}},
+			}},
+		}},
+	}}
+
+	domRoot := ParseCDPNodeToDOM(root)
+	PrintDOM(domRoot, 0)
+
+	xpath := MatchDemoContentOPByDOM(domRoot, "立即购买")
+	want := "//my-app >>> //button[@id='buy']"
+	if xpath != want {
+		t.Errorf("MatchDemoContentOPByDOM = %s, want %s", xpath, want)
+	}
+
+	if input := GetInputFirstXpathByDOM(domRoot); input != "" {
+		t.Errorf("closed shadow root 不应被匹配, got %s", input)
+	}
+}
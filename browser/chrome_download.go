@@ -0,0 +1,247 @@
+package browser
+
+import (
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+文件下载
+
+download dir 通过 Browser.setDownloadBehavior(allowAndName) 把下载保存到指定目录, 浏览器先以guid作为文件名保存,
+Browser.downloadWillBegin / downloadProgress 事件跟踪每个下载, 完成后按建议的文件名或 rename 规则重命名
+download wait 按开始的顺序等待下一个还没有取走的下载完成, 所以先点击再 wait 也不会错过
+*/
+
+// DownloadInfo 一个下载
+type DownloadInfo struct {
+	GUID              string
+	URL               string
+	SuggestedFilename string // 浏览器建议的文件名(服务器的 Content-Disposition 或地址中的文件名)
+	Path              string // 完成后的保存路径
+	MimeType          string
+	Size              int64
+	State             string // inProgress completed canceled
+	Begin             time.Time
+}
+
+type downloadTracker struct {
+	mu       sync.Mutex
+	listenId int
+	dir      string
+	items    map[string]*DownloadInfo
+	order    []string // 按开始顺序的guid
+	taken    int      // 已经被 wait 取走的数量
+}
+
+var downloads = &downloadTracker{}
+
+// DownloadDir 设置下载目录并开始跟踪下载, 返回目录的绝对路径
+func DownloadDir(dir string) (string, error) {
+	if dir == "" {
+		return "", fmt.Errorf("需要下载目录")
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("解析路径失败: %w", err)
+	}
+	if err = os.MkdirAll(absDir, 0755); err != nil {
+		return "", fmt.Errorf("创建下载目录失败: %w", err)
+	}
+	if _, err = browserCall("Browser.setDownloadBehavior", map[string]any{
+		"behavior":      "allowAndName",
+		"downloadPath":  absDir,
+		"eventsEnabled": true,
+	}); err != nil {
+		return "", fmt.Errorf("设置下载目录失败: %w", err)
+	}
+
+	downloads.mu.Lock()
+	defer downloads.mu.Unlock()
+	downloads.dir = absDir
+	if downloads.listenId == 0 {
+		downloads.items = make(map[string]*DownloadInfo)
+		downloads.order = nil
+		downloads.taken = 0
+		downloads.listenId = OnEvent("Browser.download*", downloads.onEvent)
+	}
+	log.Println("[Chrome]下载目录:", absDir)
+	return absDir, nil
+}
+
+func (d *downloadTracker) onEvent(method, sessionId string, params map[string]any) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	guid := mapStr(params, "guid")
+	if d.items == nil || guid == "" {
+		return
+	}
+	switch method {
+	case "Browser.downloadWillBegin":
+		if _, ok := d.items[guid]; ok {
+			return
+		}
+		d.items[guid] = &DownloadInfo{
+			GUID:              guid,
+			URL:               mapStr(params, "url"),
+			SuggestedFilename: mapStr(params, "suggestedFilename"),
+			State:             "inProgress",
+			Begin:             time.Now(),
+		}
+		d.order = append(d.order, guid)
+	case "Browser.downloadProgress":
+		info, ok := d.items[guid]
+		if !ok {
+			return
+		}
+		received, _ := params["receivedBytes"].(float64)
+		info.Size = int64(received)
+		if state := mapStr(params, "state"); state != "" {
+			info.State = state
+		}
+	}
+}
+
+// next 下一个还没有取走的下载的副本, 没有时为nil
+func (d *downloadTracker) next() *DownloadInfo {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.taken >= len(d.order) {
+		return nil
+	}
+	info := *d.items[d.order[d.taken]]
+	return &info
+}
+
+// take 取走下一个下载, 返回它是第几个下载(从1开始)
+func (d *downloadTracker) take() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.taken++
+	return d.taken
+}
+
+// WaitDownload 等待下一个下载完成, rename 为文件名规则, 为空时使用建议的文件名
+func WaitDownload(timeout time.Duration, rename string) (*DownloadInfo, error) {
+	downloads.mu.Lock()
+	dir := downloads.dir
+	downloads.mu.Unlock()
+	if dir == "" {
+		return nil, fmt.Errorf("请先设置下载目录 chrome download dir=<目录>")
+	}
+
+	var info *DownloadInfo
+	_, err := pollUntil(timeout, "下载完成", func() (bool, string, error) {
+		info = downloads.next()
+		if info == nil {
+			return false, "没有开始的下载", nil
+		}
+		if info.State == "inProgress" {
+			return false, fmt.Sprintf("%s 已下载%d字节", info.SuggestedFilename, info.Size), nil
+		}
+		return true, "", nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	n := downloads.take()
+	if info.State != "completed" {
+		return nil, fmt.Errorf("下载%s: %s", info.State, info.URL)
+	}
+	if err = finishDownload(info, dir, rename, n, time.Now()); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// finishDownload 把以guid命名的文件按规则重命名, 填充保存路径、大小与MIME类型
+func finishDownload(info *DownloadInfo, dir, rename string, n int, now time.Time) error {
+	src := filepath.Join(dir, info.GUID)
+	name := info.SuggestedFilename
+	if name == "" {
+		name = "download"
+	}
+	if rename != "" {
+		name = DownloadName(rename, name, n, now)
+	}
+	dst := uniquePath(filepath.Join(dir, sanitizeFileName(name)))
+	if err := os.Rename(src, dst); err != nil {
+		return fmt.Errorf("重命名下载文件失败: %w", err)
+	}
+	info.Path = dst
+	if stat, err := os.Stat(dst); err == nil {
+		info.Size = stat.Size()
+	}
+	info.MimeType = detectMime(dst)
+	return nil
+}
+
+// DownloadName 按规则生成文件名, 支持 {name}(不含扩展名的建议文件名) {ext}(含点的扩展名) {date} {time} {n}(第几个下载)
+// 规则中没有扩展名时加上原来的扩展名
+func DownloadName(pattern, suggested string, n int, now time.Time) string {
+	ext := filepath.Ext(suggested)
+	name := strings.NewReplacer(
+		"{name}", strings.TrimSuffix(suggested, ext),
+		"{ext}", ext,
+		"{date}", now.Format("20060102"),
+		"{time}", now.Format("150405"),
+		"{n}", strconv.Itoa(n),
+	).Replace(pattern)
+	if filepath.Ext(name) == "" {
+		name += ext
+	}
+	return name
+}
+
+// sanitizeFileName 去掉文件名中的路径与不能用于文件名的字符
+func sanitizeFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || r < 32 {
+			return '_'
+		}
+		return r
+	}, name)
+	name = strings.Trim(name, " .")
+	if name == "" {
+		return "download"
+	}
+	return name
+}
+
+// uniquePath 文件已存在时加上序号: a.csv a (1).csv a (2).csv
+func uniquePath(path string) string {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return path
+	}
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	for i := 1; ; i++ {
+		p := fmt.Sprintf("%s (%d)%s", base, i, ext)
+		if _, err := os.Stat(p); os.IsNotExist(err) {
+			return p
+		}
+	}
+}
+
+// detectMime 按扩展名判断MIME类型, 不能判断时按文件内容
+func detectMime(path string) string {
+	if t := mime.TypeByExtension(filepath.Ext(path)); t != "" {
+		return t
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	buf := make([]byte, 512)
+	n, _ := f.Read(buf)
+	return http.DetectContentType(buf[:n])
+}
@@ -0,0 +1,79 @@
+package browser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDownloadName(t *testing.T) {
+	now := time.Date(2026, 3, 5, 9, 8, 7, 0, time.Local)
+	cases := []struct {
+		pattern, suggested, want string
+	}{
+		{"report_{date}{ext}", "export.csv", "report_20260305.csv"},
+		{"{name}_{n}", "订单.xlsx", "订单_3.xlsx"},
+		{"{date}_{time}_{name}.txt", "a.csv", "20260305_090807_a.txt"},
+		{"data", "", "data"},
+	}
+	for _, c := range cases {
+		if got := DownloadName(c.pattern, c.suggested, 3, now); got != c.want {
+			t.Errorf("DownloadName(%q, %q) = %q, want %q", c.pattern, c.suggested, got, c.want)
+		}
+	}
+	if got := sanitizeFileName(`../a/b:c?.csv`); got != "_a_b_c_.csv" {
+		t.Errorf("sanitizeFileName = %q", got)
+	}
+	if got := sanitizeFileName(" .. "); got != "download" {
+		t.Errorf("sanitizeFileName = %q", got)
+	}
+}
+
+func TestDownloadTracker(t *testing.T) {
+	dir := t.TempDir()
+	d := &downloadTracker{dir: dir, items: make(map[string]*DownloadInfo)}
+	d.onEvent("Browser.downloadWillBegin", "", map[string]any{"guid": "g1", "url": "https://a.com/export", "suggestedFilename": "export.csv"})
+	d.onEvent("Browser.downloadWillBegin", "", map[string]any{"guid": "g2", "url": "https://a.com/b", "suggestedFilename": "report"})
+	d.onEvent("Browser.downloadProgress", "", map[string]any{"guid": "g1", "receivedBytes": 3.0, "state": "inProgress"})
+	// 未知的下载忽略
+	d.onEvent("Browser.downloadProgress", "", map[string]any{"guid": "x", "state": "completed"})
+
+	info := d.next()
+	if info == nil || info.GUID != "g1" || info.State != "inProgress" || info.Size != 3 {
+		t.Fatalf("第一个下载: %+v", info)
+	}
+	d.onEvent("Browser.downloadProgress", "", map[string]any{"guid": "g1", "receivedBytes": 9.0, "state": "completed"})
+	if info = d.next(); info.State != "completed" || d.take() != 1 {
+		t.Fatalf("完成: %+v", info)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "g1"), []byte("a,b\n1,2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// 已存在同名文件时加序号
+	if err := os.WriteFile(filepath.Join(dir, "export.csv"), []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := finishDownload(info, dir, "", 1, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(info.Path) != "export (1).csv" || info.Size != 8 || !strings.HasPrefix(info.MimeType, "text/csv") {
+		t.Errorf("保存: %+v", info)
+	}
+
+	if info = d.next(); info == nil || info.GUID != "g2" {
+		t.Fatalf("第二个下载: %+v", info)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "g2"), []byte("%PDF-1.4\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := finishDownload(info, dir, "{name}_{n}", 2, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	// 没有扩展名时按内容判断
+	if filepath.Base(info.Path) != "report_2" || info.MimeType != "application/pdf" {
+		t.Errorf("重命名: %+v", info)
+	}
+}
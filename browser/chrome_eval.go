@@ -0,0 +1,117 @@
+package browser
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+/*
+执行js代码
+
+代码作为异步函数的函数体在当前frame中执行, 用 return 返回结果, 只有一个表达式时可以省略 return;
+参数以JSON传入, 每个参数在代码中是同名的变量, 也可以通过 args.名称 取到:
+
+	(async (args) => { const {a, b} = args; 代码
+	})({"a":1,"b":"x"}).then(结果转换)
+
+返回 Promise 时等待完成; DOM元素返回 outerHTML, 元素列表、Set 返回数组, Map 返回对象, 循环引用返回 null
+执行出错时返回错误描述与调用栈
+*/
+
+//go:embed chrome_eval.js
+var chromeEvalJS string
+
+// DefaultEvalTimeout 执行js代码的默认超时时间
+var DefaultEvalTimeout = 30 * time.Second
+
+var jsIdentReg = regexp.MustCompile(`^[A-Za-z_$][\w$]*$`)
+
+// jsStatementReg 以语句开头的代码是函数体
+var jsStatementReg = regexp.MustCompile(`^(return|const|let|var|if|for|while|do|switch|try|throw|function|class|async\s+function)\b`)
+
+// jsBody 代码只是一个表达式时补上 return
+func jsBody(code string) string {
+	code = strings.TrimSpace(code)
+	if strings.Contains(code, "\n") || jsStatementReg.MatchString(code) {
+		return code
+	}
+	expr := strings.TrimSpace(strings.TrimSuffix(code, ";"))
+	if expr == "" || strings.Contains(expr, ";") {
+		return code
+	}
+	return "return (" + expr + ");"
+}
+
+// evalScript 生成执行代码的表达式, name 用于异常调用栈中显示的脚本名
+func evalScript(code string, args map[string]any, name string) (string, error) {
+	if args == nil {
+		args = map[string]any{}
+	}
+	names := make([]string, 0, len(args))
+	for k := range args {
+		if !jsIdentReg.MatchString(k) {
+			return "", fmt.Errorf("js参数名不合法: %s", k)
+		}
+		// args 本身就是参数对象
+		if k != "args" {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+	argsJson, err := json.Marshal(args)
+	if err != nil {
+		return "", fmt.Errorf("js参数不能转为json: %w", err)
+	}
+
+	// 用户代码从第一行开始, 调用栈中的行号与代码一致
+	head := "(async (args) => { "
+	if len(names) > 0 {
+		head += "const {" + strings.Join(names, ", ") + "} = args; "
+	}
+	script := head + jsBody(code) + "\n})(" + string(argsJson) + ").then(" + strings.TrimSpace(chromeEvalJS) + ")"
+	if name != "" {
+		script += "\n//# sourceURL=" + name
+	}
+	return script, nil
+}
+
+// EvalJS 在当前frame中执行js代码并返回结果, args 是传入的参数
+// 结果是 JSON 对应的类型: map[string]any、[]any、float64、string、bool 或 nil
+func EvalJS(code string, args map[string]any, timeout time.Duration) (any, error) {
+	return evalJS(code, args, "chromebot.js", timeout)
+}
+
+// EvalJSFile 在当前frame中执行js文件, 同 EvalJS
+func EvalJSFile(path string, args map[string]any, timeout time.Duration) (any, error) {
+	code, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取js文件失败: %w", err)
+	}
+	return evalJS(string(code), args, filepath.Base(path), timeout)
+}
+
+func evalJS(code string, args map[string]any, name string, timeout time.Duration) (any, error) {
+	if strings.TrimSpace(code) == "" {
+		return nil, fmt.Errorf("js代码不能为空")
+	}
+	if !DefaultNowTab(true) {
+		return nil, notReadyErr()
+	}
+	if timeout <= 0 {
+		timeout = DefaultEvalTimeout
+	}
+	script, err := evalScript(code, args, name)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[Chrome]执行js: %s", name)
+	return frameEval(script, true, timeout)
+}
@@ -0,0 +1,41 @@
+(value) => {
+    // 把执行结果转换成可以按值返回的数据
+    const ancestors = new Set();
+    const norm = (v, depth) => {
+        if (v === undefined || v === null) return null;
+        switch (typeof v) {
+            case 'bigint':
+                return v.toString();
+            case 'number':
+                return Number.isFinite(v) ? v : String(v);
+            case 'function':
+            case 'symbol':
+                return String(v);
+            case 'object':
+                break;
+            default:
+                return v;
+        }
+        if (typeof Node !== 'undefined' && v instanceof Node) {
+            return v.nodeType === Node.ELEMENT_NODE ? v.outerHTML : v.textContent;
+        }
+        if (v instanceof Date) return v.toISOString();
+        if (v instanceof RegExp || v instanceof Error) return String(v);
+        // 循环引用与过深的嵌套返回null
+        if (ancestors.has(v) || depth > 32) return null;
+        ancestors.add(v);
+        let res;
+        if (Array.isArray(v) || v instanceof Set || (typeof v.length === 'number' && typeof v.item === 'function')) {
+            res = Array.from(v, (item) => norm(item, depth + 1));
+        } else {
+            const obj = v instanceof Map ? Object.fromEntries(v) : v;
+            res = {};
+            for (const key of Object.keys(obj)) {
+                res[key] = norm(obj[key], depth + 1);
+            }
+        }
+        ancestors.delete(v);
+        return res;
+    };
+    return norm(value, 0);
+}
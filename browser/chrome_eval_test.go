@@ -0,0 +1,43 @@
+package browser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJsBody(t *testing.T) {
+	cases := map[string]string{
+		`document.title`:                      `return (document.title);`,
+		` document.title; `:                   `return (document.title);`,
+		`return document.title`:               `return document.title`,
+		`const a = 1; return a`:               `const a = 1; return a`,
+		`let s = 0; s += 1`:                   `let s = 0; s += 1`,
+		`await fetch('/api').then(r => r.ok)`: `return (await fetch('/api').then(r => r.ok));`,
+		"const a = 1\nreturn a":               "const a = 1\nreturn a",
+	}
+	for code, want := range cases {
+		if got := jsBody(code); got != want {
+			t.Errorf("%q: got %q, want %q", code, got, want)
+		}
+	}
+}
+
+func TestEvalScript(t *testing.T) {
+	script, err := evalScript("return a + b", map[string]any{"b": 2, "a": "x\"})//", "args": 1}, "lib.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(script, "(async (args) => { const {a, b} = args; return a + b\n})(") {
+		t.Errorf("参数变量声明错误: %s", script)
+	}
+	if !strings.Contains(script, `"a":"x\"})//"`) {
+		t.Errorf("参数没有按JSON传入: %s", script)
+	}
+	if !strings.HasSuffix(script, "\n//# sourceURL=lib.js") {
+		t.Errorf("缺少sourceURL: %s", script)
+	}
+
+	if _, err = evalScript("return 1", map[string]any{"a-b": 1}, ""); err == nil {
+		t.Error("参数名不合法时期望返回错误")
+	}
+}
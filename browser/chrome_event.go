@@ -0,0 +1,73 @@
+package browser
+
+import (
+	"strings"
+	"sync"
+)
+
+// EventHandler cdp事件回调, 在ws读协程中执行, 不能阻塞也不能在回调里直接等待cdp回复(需要另起协程)
+type EventHandler func(method, sessionId string, params map[string]any)
+
+type eventListener struct {
+	id      int
+	pattern string // 事件名, 支持 Domain.* 匹配整个域, * 匹配所有
+	handler EventHandler
+}
+
+var (
+	eventMu        sync.RWMutex
+	eventListeners = make([]*eventListener, 0)
+	eventNextID    = 0
+)
+
+// OnEvent 注册cdp事件监听, 返回监听id用于取消
+func OnEvent(pattern string, handler EventHandler) int {
+	eventMu.Lock()
+	defer eventMu.Unlock()
+	eventNextID++
+	eventListeners = append(eventListeners, &eventListener{
+		id:      eventNextID,
+		pattern: pattern,
+		handler: handler,
+	})
+	return eventNextID
+}
+
+// OffEvent 取消cdp事件监听
+func OffEvent(id int) {
+	eventMu.Lock()
+	defer eventMu.Unlock()
+	for i, l := range eventListeners {
+		if l.id == id {
+			eventListeners = append(eventListeners[:i], eventListeners[i+1:]...)
+			return
+		}
+	}
+}
+
+// matchEventName 事件名匹配 Network.* 或 *
+func matchEventName(pattern, method string) bool {
+	if pattern == "*" || pattern == method {
+		return true
+	}
+	if strings.HasSuffix(pattern, "*") {
+		return strings.HasPrefix(method, strings.TrimSuffix(pattern, "*"))
+	}
+	return false
+}
+
+// dispatchEvent 将事件分发给监听者
+func dispatchEvent(method, sessionId string, params map[string]any) {
+	eventMu.RLock()
+	handlers := make([]EventHandler, 0)
+	for _, l := range eventListeners {
+		if matchEventName(l.pattern, method) {
+			handlers = append(handlers, l.handler)
+		}
+	}
+	eventMu.RUnlock()
+
+	for _, h := range handlers {
+		h(method, sessionId, params)
+	}
+}
@@ -0,0 +1,297 @@
+package browser
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+声明式提取
+
+按 row 找到每一行的元素, 在行内按 fields 的选择器取出每个字段, 得到一行一个的记录;
+字段的值默认取元素的文本, 可以取属性、用正则截取并转换类型, 链接地址会按页面地址转为绝对地址
+*/
+
+// ExtractField 一个字段的提取规则
+type ExtractField struct {
+	Name    string
+	Sel     string // xpath或css选择器, 相对于行
+	Attr    string // 取元素的属性, 为空时取文本
+	Re      string // 正则截取, 有分组时取第一个分组
+	Type    string // string int float bool url html
+	All     bool   // 取所有匹配的值, 结果是列表
+	Default any    // 没有匹配到时的值
+	re      *regexp.Regexp
+}
+
+// ExtractSchema 提取规则, Row 为空时整个页面作为一行
+type ExtractSchema struct {
+	Row    string
+	Fields []*ExtractField
+	Base   string // 页面地址, 用于把相对地址转为绝对地址
+}
+
+// ExtractMiss 一个字段在一行中没有取到值
+type ExtractMiss struct {
+	Row    int // 从1开始
+	Field  string
+	Reason string
+}
+
+// ExtractReport 提取结果统计
+type ExtractReport struct {
+	Rows   int
+	Misses []ExtractMiss
+}
+
+// FieldMisses 每个字段没有取到值的行号
+func (r *ExtractReport) FieldMisses() map[string][]int {
+	res := make(map[string][]int)
+	for _, m := range r.Misses {
+		res[m.Field] = append(res[m.Field], m.Row)
+	}
+	return res
+}
+
+var extractTypes = map[string]bool{"": true, "string": true, "int": true, "float": true, "bool": true, "url": true, "html": true}
+
+// urlAttrs 值是地址的属性, 取这些属性时会转为绝对地址
+var urlAttrs = map[string]bool{"href": true, "src": true, "action": true, "data-src": true, "poster": true, "data-href": true}
+
+var urlAttrXPathRe = regexp.MustCompile(`@([\w-]+)\s*\)?\s*$`)
+
+// Compile 检查规则并编译正则, 字段按名称排序
+func (s *ExtractSchema) Compile() error {
+	for _, f := range s.Fields {
+		if f.Sel == "" {
+			return fmt.Errorf("字段 %s 没有选择器", f.Name)
+		}
+		if !extractTypes[f.Type] {
+			return fmt.Errorf("字段 %s 的类型 %s 不支持, 支持 string int float bool url html", f.Name, f.Type)
+		}
+		if f.Re != "" {
+			re, err := regexp.Compile(f.Re)
+			if err != nil {
+				return fmt.Errorf("字段 %s 的正则错误: %w", f.Name, err)
+			}
+			f.re = re
+		}
+	}
+	sort.Slice(s.Fields, func(i, j int) bool {
+		return s.Fields[i].Name < s.Fields[j].Name
+	})
+	return nil
+}
+
+// isURL 字段的值是否是地址
+func (f *ExtractField) isURL() bool {
+	if f.Type == "url" {
+		return true
+	}
+	if f.Type != "" && f.Type != "string" {
+		return false
+	}
+	if f.Attr != "" {
+		return urlAttrs[f.Attr]
+	}
+	m := urlAttrXPathRe.FindStringSubmatch(f.Sel)
+	return m != nil && urlAttrs[strings.ToLower(m[1])]
+}
+
+// Extract 按规则从DOM树提取记录
+func Extract(root *DOMNode, schema *ExtractSchema) ([]map[string]any, *ExtractReport, error) {
+	if err := schema.Compile(); err != nil {
+		return nil, nil, err
+	}
+	base, err := extractBase(root, schema.Base)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows := []*DOMNode{root}
+	if schema.Row != "" {
+		res, err := QuerySelector(root, schema.Row)
+		if err != nil {
+			return nil, nil, fmt.Errorf("row: %w", err)
+		}
+		rows = make([]*DOMNode, 0)
+		for _, item := range queryNodes(res) {
+			n, ok := item.(*DOMNode)
+			if !ok {
+				return nil, nil, fmt.Errorf("row 需要匹配元素: %s", schema.Row)
+			}
+			rows = append(rows, n)
+		}
+	}
+
+	report := &ExtractReport{Rows: len(rows), Misses: make([]ExtractMiss, 0)}
+	records := make([]map[string]any, 0, len(rows))
+	for i, row := range rows {
+		record := make(map[string]any, len(schema.Fields))
+		for _, f := range schema.Fields {
+			val, reason, err := f.extract(row, base)
+			if err != nil {
+				return nil, nil, fmt.Errorf("字段 %s: %w", f.Name, err)
+			}
+			if reason != "" {
+				report.Misses = append(report.Misses, ExtractMiss{Row: i + 1, Field: f.Name, Reason: reason})
+				val = f.Default
+				if val == nil {
+					val = ""
+				}
+			}
+			record[f.Name] = val
+		}
+		records = append(records, record)
+	}
+	return records, report, nil
+}
+
+// extractBase 页面中有 <base href> 时以它为准
+func extractBase(root *DOMNode, pageURL string) (*url.URL, error) {
+	var base *url.URL
+	if pageURL != "" {
+		u, err := url.Parse(pageURL)
+		if err != nil {
+			return nil, fmt.Errorf("页面地址错误: %w", err)
+		}
+		base = u
+	}
+	if nodes, err := QueryCSS(root, "base[href]"); err == nil && len(nodes) > 0 {
+		if u, err := url.Parse(strings.TrimSpace(nodes[0].Attributes["href"])); err == nil {
+			if base != nil {
+				u = base.ResolveReference(u)
+			}
+			if u.IsAbs() {
+				base = u
+			}
+		}
+	}
+	return base, nil
+}
+
+// queryNodes 查询结果统一为列表, 标量结果作为一个元素
+func queryNodes(res any) []any {
+	switch v := res.(type) {
+	case []*DOMNode:
+		list := make([]any, 0, len(v))
+		for _, n := range v {
+			list = append(list, n)
+		}
+		return list
+	case []any:
+		return v
+	case string:
+		return []any{v}
+	case float64:
+		return []any{strconv.FormatFloat(v, 'f', -1, 64)}
+	case bool:
+		return []any{strconv.FormatBool(v)}
+	}
+	return nil
+}
+
+// extract 在一行中取字段的值, 没有取到时返回原因
+func (f *ExtractField) extract(row *DOMNode, base *url.URL) (any, string, error) {
+	res, err := QuerySelector(row, f.Sel)
+	if err != nil {
+		return nil, "", err
+	}
+	items := queryNodes(res)
+	if len(items) == 0 {
+		return nil, "没有匹配到", nil
+	}
+	if !f.All {
+		items = items[:1]
+	}
+	values := make([]any, 0, len(items))
+	reason := ""
+	for _, item := range items {
+		val, why := f.value(item, base)
+		if why != "" {
+			reason = why
+			continue
+		}
+		values = append(values, val)
+	}
+	if f.All {
+		if len(values) == 0 {
+			return nil, reason, nil
+		}
+		return values, "", nil
+	}
+	if len(values) == 0 {
+		return nil, reason, nil
+	}
+	return values[0], "", nil
+}
+
+// value 一个匹配结果的值
+func (f *ExtractField) value(item any, base *url.URL) (any, string) {
+	var s string
+	switch v := item.(type) {
+	case *DOMNode:
+		switch {
+		case f.Attr != "":
+			attr, ok := v.Attributes[f.Attr]
+			if !ok {
+				return nil, "没有属性" + f.Attr
+			}
+			s = attr
+		case f.Type == "html":
+			s = NodeHTML(v)
+		default:
+			s = NodeText(v)
+		}
+	case string:
+		s = v
+	}
+	s = strings.TrimSpace(s)
+
+	if f.re != nil {
+		m := f.re.FindStringSubmatch(s)
+		if m == nil {
+			return nil, "正则没有匹配到"
+		}
+		s = m[0]
+		if len(m) > 1 {
+			s = m[1]
+		}
+	}
+
+	switch f.Type {
+	case "int":
+		n, err := strconv.ParseInt(cleanNumber(s), 10, 64)
+		if err != nil {
+			return nil, "不是整数: " + s
+		}
+		return n, ""
+	case "float":
+		n, err := strconv.ParseFloat(cleanNumber(s), 64)
+		if err != nil {
+			return nil, "不是数字: " + s
+		}
+		return n, ""
+	case "bool":
+		switch strings.ToLower(s) {
+		case "", "0", "false", "no", "off":
+			return false, ""
+		}
+		return true, ""
+	}
+	if f.isURL() && base != nil && s != "" {
+		if u, err := url.Parse(s); err == nil {
+			s = base.ResolveReference(u).String()
+		}
+	}
+	return s, ""
+}
+
+// cleanNumber 去掉数字中的千分位逗号与空白
+func cleanNumber(s string) string {
+	return strings.NewReplacer(",", "", " ", "", " ", "").Replace(s)
+}
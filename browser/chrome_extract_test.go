@@ -0,0 +1,73 @@
+package browser
+
+import (
+	"reflect"
+	"testing"
+)
+
+const extractTestHTML = `<html><head><base href="/shop/"></head><body>
+<ul id="list">
+	<li><h3> 苹果 </h3><a href="item/1">详情</a><span class="price">¥1,299.50</span><i>新品</i><i>热卖</i></li>
+	<li><h3>香蕉</h3><a href="https://other.com/2">详情</a><span class="price">暂无</span></li>
+	<li><h3>橙子</h3><span class="price">¥8</span><img src="/img/3.png"></li>
+</ul>
+</body></html>`
+
+func TestExtract(t *testing.T) {
+	root, err := ParseHTMLToDOM(extractTestHTML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := &ExtractSchema{
+		Row:  "//ul[@id='list']/li",
+		Base: "https://example.com/list?page=1",
+		Fields: []*ExtractField{
+			{Name: "title", Sel: ".//h3/text()"},
+			{Name: "url", Sel: ".//a/@href"},
+			{Name: "img", Sel: "img", Attr: "src"},
+			{Name: "price", Sel: "span.price", Re: `[0-9.,]+`, Type: "float", Default: float64(-1)},
+			{Name: "tags", Sel: "i", All: true},
+		},
+	}
+	records, report, err := Extract(root, schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []map[string]any{
+		{"title": "苹果", "url": "https://example.com/shop/item/1", "img": "", "price": 1299.5, "tags": []any{"新品", "热卖"}},
+		{"title": "香蕉", "url": "https://other.com/2", "img": "", "price": float64(-1), "tags": ""},
+		{"title": "橙子", "url": "", "img": "https://example.com/img/3.png", "price": 8.0, "tags": ""},
+	}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("records:\n%v\n期望:\n%v", records, expected)
+	}
+	if report.Rows != 3 {
+		t.Errorf("rows: %d", report.Rows)
+	}
+	misses := report.FieldMisses()
+	expectedMisses := map[string][]int{"img": {1, 2}, "price": {2}, "tags": {2, 3}, "url": {3}}
+	if !reflect.DeepEqual(misses, expectedMisses) {
+		t.Errorf("misses: %v", misses)
+	}
+}
+
+func TestExtractSchemaError(t *testing.T) {
+	root, _ := ParseHTMLToDOM(extractTestHTML)
+	for _, schema := range []*ExtractSchema{
+		{Fields: []*ExtractField{{Name: "a"}}},
+		{Fields: []*ExtractField{{Name: "a", Sel: "h3", Re: "("}}},
+		{Fields: []*ExtractField{{Name: "a", Sel: "h3", Type: "date"}}},
+		{Row: "//li/h3/text()", Fields: []*ExtractField{{Name: "a", Sel: "h3"}}},
+		{Row: "li", Fields: []*ExtractField{{Name: "a", Sel: "h3["}}},
+	} {
+		if _, _, err := Extract(root, schema); err == nil {
+			t.Errorf("应该报错: %+v", schema)
+		}
+	}
+
+	// 没有 row 时整个页面作为一行
+	records, _, err := Extract(root, &ExtractSchema{Fields: []*ExtractField{{Name: "n", Sel: "count(//li)", Type: "int"}}})
+	if err != nil || len(records) != 1 || records[0]["n"] != int64(3) {
+		t.Errorf("records: %v %v", records, err)
+	}
+}
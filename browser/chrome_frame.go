@@ -0,0 +1,323 @@
+package browser
+
+import (
+	"ChromeBot/utils"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+/*
+frame(iframe)支持
+
+1. 通过 Runtime.enable 的 Runtime.executionContextCreated 事件记录每个frame的默认执行上下文
+2. 通过 Target.setAutoAttach(flatten) 自动附加跨进程的iframe(OOPIF), 它们有自己的session
+3. chrome frame=<定位器|name|url-pattern> 切换后, 点击、输入、检查、获取html、滚动等操作都在该frame的上下文中执行
+4. chrome frame=main 返回主页面
+*/
+
+// FrameInfo frame信息
+type FrameInfo struct {
+	FrameId   string
+	ParentId  string
+	Name      string
+	URL       string
+	SessionId string // frame所在的session, 跨进程的iframe是自己的session
+}
+
+var (
+	frameMu       sync.RWMutex
+	frameContexts = make(map[string]map[string]int) // sessionId -> frameId -> 默认执行上下文id
+	frameTargets  = make(map[string]string)         // 跨进程iframe的 targetId(即frameId) -> sessionId
+	nowFrameId    = ""                              // 当前操作的frame, 空为主页面
+)
+
+func init() {
+	OnEvent("Runtime.executionContext*", onExecutionContextEvent)
+	OnEvent("Target.attachedToTarget", onFrameTargetAttached)
+	OnEvent("Target.detachedFromTarget", onFrameTargetDetached)
+}
+
+func onExecutionContextEvent(method, sessionId string, params map[string]any) {
+	frameMu.Lock()
+	defer frameMu.Unlock()
+	switch method {
+	case "Runtime.executionContextCreated":
+		ctx, _ := params["context"].(map[string]any)
+		auxData, _ := ctx["auxData"].(map[string]any)
+		if isDefault, _ := auxData["isDefault"].(bool); !isDefault {
+			return
+		}
+		if frameContexts[sessionId] == nil {
+			frameContexts[sessionId] = make(map[string]int)
+		}
+		frameContexts[sessionId][mapStr(auxData, "frameId")] = mapInt(ctx, "id")
+
+	case "Runtime.executionContextDestroyed":
+		id := mapInt(params, "executionContextId")
+		for frameId, ctxId := range frameContexts[sessionId] {
+			if ctxId == id {
+				delete(frameContexts[sessionId], frameId)
+			}
+		}
+
+	case "Runtime.executionContextsCleared":
+		delete(frameContexts, sessionId)
+	}
+}
+
+func onFrameTargetAttached(method, sessionId string, params map[string]any) {
+	info, _ := params["targetInfo"].(map[string]any)
+	if mapStr(info, "type") != "iframe" {
+		return
+	}
+	childSession := mapStr(params, "sessionId")
+	frameMu.Lock()
+	frameTargets[mapStr(info, "targetId")] = childSession
+	frameMu.Unlock()
+	utils.Debug("附加跨进程iframe: ", info["url"], " session = ", childSession)
+
+	// 回调在ws读协程中执行不能等待回复, 在新的协程中开启跟踪
+	if chromeInstance != nil && chromeInstance.NowTabWSConn != nil {
+		go trackSession(chromeInstance.NowTabWSConn, childSession)
+	}
+}
+
+func onFrameTargetDetached(method, sessionId string, params map[string]any) {
+	childSession := mapStr(params, "sessionId")
+	frameMu.Lock()
+	defer frameMu.Unlock()
+	for targetId, s := range frameTargets {
+		if s == childSession {
+			delete(frameTargets, targetId)
+		}
+	}
+	delete(frameContexts, childSession)
+}
+
+// enableFrameTracking 当前tab开启执行上下文与跨进程iframe的跟踪
+func enableFrameTracking() {
+	frameMu.Lock()
+	nowFrameId = ""
+	frameMu.Unlock()
+
+	trackSession(chromeInstance.NowTabWSConn, chromeInstance.NowTabSession)
+}
+
+// trackSession 在session上开启执行上下文跟踪与跨进程iframe的自动附加
+func trackSession(conn *websocket.Conn, sessionId string) {
+	if _, err := cdpCall(conn, sessionId, "Runtime.enable", nil, 6*time.Second); err != nil {
+		log.Println("[Chrome]Runtime.enable 失败: ", err)
+	}
+	_, err := cdpCall(conn, sessionId, "Target.setAutoAttach", map[string]any{
+		"autoAttach":             true,
+		"waitForDebuggerOnStart": false,
+		"flatten":                true,
+	}, 6*time.Second)
+	if err != nil {
+		log.Println("[Chrome]Target.setAutoAttach 失败: ", err)
+	}
+}
+
+// frameSessionOf frame所在的session, 需持有锁
+func frameSessionOf(frameId string) string {
+	if s, ok := frameTargets[frameId]; ok {
+		return s
+	}
+	for s, frames := range frameContexts {
+		if _, ok := frames[frameId]; ok {
+			return s
+		}
+	}
+	return chromeInstance.NowTabSession
+}
+
+// nowFrameContext 当前操作的frame所在的session与执行上下文id, 主页面时contextId为0
+func nowFrameContext() (string, int, error) {
+	frameMu.RLock()
+	frameId := nowFrameId
+	frameMu.RUnlock()
+	if frameId == "" {
+		return chromeInstance.NowTabSession, 0, nil
+	}
+
+	// frame刚切换或在跳转时上下文可能还没创建, 稍等
+	for i := 0; i < 20; i++ {
+		frameMu.RLock()
+		sessionId := frameSessionOf(frameId)
+		contextId, ok := frameContexts[sessionId][frameId]
+		frameMu.RUnlock()
+		if ok {
+			return sessionId, contextId, nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return "", 0, fmt.Errorf("frame %s 已不存在或未加载完成, 可使用 chrome frame=main 返回主页面", frameId)
+}
+
+// withContextId 在当前frame执行js时补充执行上下文
+func withContextId(params map[string]interface{}, contextId int) map[string]interface{} {
+	if contextId > 0 {
+		params["contextId"] = contextId
+	}
+	return params
+}
+
+// GetFrames 获取当前tab的所有frame, 包含跨进程的iframe
+func GetFrames() ([]FrameInfo, error) {
+	if !DefaultNowTab(false) {
+		return nil, notReadyErr()
+	}
+	sessions := []string{chromeInstance.NowTabSession}
+	frameMu.RLock()
+	for _, s := range frameTargets {
+		sessions = append(sessions, s)
+	}
+	frameMu.RUnlock()
+
+	res := make([]FrameInfo, 0)
+	for _, s := range sessions {
+		tree, err := cdpCall(chromeInstance.NowTabWSConn, s, "Page.getFrameTree", nil, 6*time.Second)
+		if err != nil {
+			utils.Debug("获取frame树失败: ", err)
+			continue
+		}
+		frameTree, _ := tree["frameTree"].(map[string]any)
+		res = appendFrameTree(res, frameTree, s)
+	}
+	return res, nil
+}
+
+func appendFrameTree(res []FrameInfo, tree map[string]any, sessionId string) []FrameInfo {
+	if tree == nil {
+		return res
+	}
+	frame, _ := tree["frame"].(map[string]any)
+	info := FrameInfo{
+		FrameId:   mapStr(frame, "id"),
+		ParentId:  mapStr(frame, "parentId"),
+		Name:      mapStr(frame, "name"),
+		URL:       mapStr(frame, "url"),
+		SessionId: sessionId,
+	}
+	has := false
+	for _, f := range res {
+		if f.FrameId == info.FrameId {
+			has = true // 跨进程的iframe在父页面的树中也会出现
+		}
+	}
+	if !has {
+		res = append(res, info)
+	}
+	children, _ := tree["childFrames"].([]any)
+	for _, c := range children {
+		child, _ := c.(map[string]any)
+		res = appendFrameTree(res, child, sessionId)
+	}
+	return res
+}
+
+// SwitchFrame 切换当前操作的frame
+// key: main 返回主页面; 以 // 、/html 或 ( 开头的xpath以及带 css= 等前缀的定位器视为iframe元素的定位器; 其他依次匹配 frame的name、frameId、地址(见 utils.MatchURLPattern)
+func SwitchFrame(key string) (FrameInfo, error) {
+	if !DefaultNowTab(false) {
+		return FrameInfo{}, notReadyErr()
+	}
+	if key == "" || key == "main" {
+		frameMu.Lock()
+		nowFrameId = ""
+		frameMu.Unlock()
+		return FrameInfo{FrameId: "", Name: "main", SessionId: chromeInstance.NowTabSession}, nil
+	}
+
+	frames, err := GetFrames()
+	if err != nil {
+		return FrameInfo{}, err
+	}
+
+	frameId := ""
+	if isFrameLocator(key) {
+		frameId, err = frameIdByLocator(key)
+		if err != nil {
+			return FrameInfo{}, err
+		}
+	}
+
+	match := func(fn func(f FrameInfo) bool) (FrameInfo, bool) {
+		for _, f := range frames {
+			if f.ParentId != "" && fn(f) {
+				return f, true
+			}
+		}
+		return FrameInfo{}, false
+	}
+
+	var matchFns []func(f FrameInfo) bool
+	if frameId != "" {
+		matchFns = append(matchFns, func(f FrameInfo) bool { return f.FrameId == frameId })
+	} else {
+		matchFns = append(matchFns,
+			func(f FrameInfo) bool { return f.Name == key },
+			func(f FrameInfo) bool { return f.FrameId == key },
+			func(f FrameInfo) bool { return utils.MatchURLPattern(key, f.URL) },
+		)
+	}
+	for _, fn := range matchFns {
+		if f, ok := match(fn); ok {
+			frameMu.Lock()
+			nowFrameId = f.FrameId
+			frameMu.Unlock()
+			log.Printf("[Chrome]切换到frame: id=%s name=%s url=%s", f.FrameId, f.Name, f.URL)
+			return f, nil
+		}
+	}
+	if frameId != "" {
+		// 刚插入的iframe可能还不在frame树中
+		frameMu.Lock()
+		nowFrameId = frameId
+		frameMu.Unlock()
+		return FrameInfo{FrameId: frameId}, nil
+	}
+	return FrameInfo{}, fmt.Errorf("未匹配到frame: %s", key)
+}
+
+// frameIdByLocator 通过iframe元素的定位器获取frameId
+func frameIdByLocator(selector string) (string, error) {
+	if err := checkLocator(selector); err != nil {
+		return "", err
+	}
+	sessionId, objectId, err := locatorObjectId(selector)
+	if err != nil {
+		return "", err
+	}
+	node, err := cdpCall(chromeInstance.NowTabWSConn, sessionId, "DOM.describeNode", map[string]any{"objectId": objectId}, 6*time.Second)
+	if err != nil {
+		return "", err
+	}
+	nodeMap, _ := node["node"].(map[string]any)
+	frameId := mapStr(nodeMap, "frameId")
+	if frameId == "" {
+		return "", fmt.Errorf("定位到的元素不是iframe: %s", selector)
+	}
+	return frameId, nil
+}
+
+// isFrameLocator frame= 的值是否为iframe元素的定位器
+func isFrameLocator(key string) bool {
+	if strings.HasPrefix(key, "//") || strings.HasPrefix(key, "/html") || strings.HasPrefix(key, "(") {
+		return true
+	}
+	m := locatorPrefixReg.FindStringSubmatch(key)
+	return m != nil && isLocatorEngine(m[1])
+}
+
+// NowFrame 当前操作的frame, 空为主页面
+func NowFrame() string {
+	frameMu.RLock()
+	defer frameMu.RUnlock()
+	return nowFrameId
+}
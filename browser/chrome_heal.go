@@ -0,0 +1,497 @@
+package browser
+
+import (
+	"ChromeBot/utils"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+自愈定位器
+
+1. 定位器可以带备用: click=["//button[@id='buy']", "text=立即购买", "css=.buy-btn"], 按顺序使用第一个匹配到元素的
+2. 开启 LocatorFingerprint(chrome init heal)后, 操作成功后把元素的特征(标签、文本、属性、父元素、相邻元素)按页面(域名+路径)与第一个定位器记录到脚本旁的文件 <脚本名>.locators.json
+3. 开启 LocatorFingerprint 且所有定位器都匹配不到时, 在页面中找与当前页面记录的特征最相似的元素, 相似度达到 HealThreshold 时使用该元素;
+   记录的元素有文本时, 文本相似度低于 HealMinTextSimilarity 的元素不会被使用, 避免把"立即购买"换成"删除账户"这类按钮
+4. 使用了备用定位器或相似元素时输出定位器漂移警告, 并给出建议的新定位器
+5. 查找定位器的时间从操作的 timeout 中扣除, 操作只用剩下的时间等待元素
+*/
+
+// HealThreshold 相似元素的最低相似度(0~1)
+var HealThreshold = 0.6
+
+// HealMinTextSimilarity 记录的元素有文本时, 相似元素的文本的最低相似度(0~1)
+var HealMinTextSimilarity = 0.3
+
+// LocatorFingerprint 是否记录元素特征, 默认关闭, chrome init heal 开启; 关闭时不写特征文件也不查找相似元素
+var LocatorFingerprint = false
+
+// ElementFingerprint 元素的特征
+type ElementFingerprint struct {
+	URL    string            `json:"url"`
+	Tag    string            `json:"tag"`
+	Text   string            `json:"text"`
+	Attrs  map[string]string `json:"attrs"`
+	Parent string            `json:"parent"` // 父元素的描述 tag#id.class
+	Prev   string            `json:"prev"`   // 前一个兄弟元素的描述与文本
+	Next   string            `json:"next"`   // 后一个兄弟元素的描述与文本
+	Used   string            `json:"used"`   // 匹配到该元素的定位器
+	Drift  string            `json:"drift,omitempty"`
+	Time   string            `json:"time"`
+}
+
+// fingerprintTextMax 特征中文本的最大长度
+const fingerprintTextMax = 100
+
+// fingerprintJS 定位器第一个元素的特征, 没有匹配到元素时返回null; 与 nodeFingerprint 的计算方式一致
+const fingerprintJS = `(args) => {
+    const el = (__LOCATOR__).first(args.selector);
+    if (!el) return null;
+    const norm = (s, n) => (s || '').replace(/\s+/g, ' ').trim().slice(0, n);
+    const desc = (e) => {
+        if (!e || e.nodeType !== 1) return '';
+        let d = e.localName;
+        if (e.id) d += '#' + e.id;
+        const cls = (e.getAttribute('class') || '').trim().split(/\s+/).filter(Boolean);
+        if (cls.length) d += '.' + cls.join('.');
+        return d;
+    };
+    const near = (e) => e ? desc(e) + ' ' + norm(e.textContent, 40) : '';
+    const attrs = {};
+    for (const a of el.attributes) {
+        if (a.name !== 'style') attrs[a.name] = a.value.slice(0, args.max);
+    }
+    return {
+        url: location.href,
+        tag: el.localName,
+        text: norm(el.textContent, args.max),
+        attrs: attrs,
+        parent: desc(el.parentElement),
+        prev: near(el.previousElementSibling),
+        next: near(el.nextElementSibling)
+    };
+}`
+
+// CaptureFingerprint 获取定位器匹配到的第一个元素的特征, 没有匹配到元素时返回nil
+func CaptureFingerprint(selector string) (*ElementFingerprint, error) {
+	value, err := frameEval(jsCall(fingerprintJS, map[string]any{"selector": selector, "max": fingerprintTextMax}), false, 6*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, nil
+	}
+	fp := &ElementFingerprint{}
+	b, _ := json.Marshal(value)
+	if err = json.Unmarshal(b, fp); err != nil {
+		return nil, fmt.Errorf("解析元素特征失败: %w", err)
+	}
+	fp.Used = selector
+	return fp, nil
+}
+
+// normText 合并空白并截断
+func normText(s string, max int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > max {
+		s = string(r[:max])
+	}
+	return s
+}
+
+// nodeDesc 元素的描述 tag#id.class
+func nodeDesc(n *DOMNode) string {
+	if n == nil || !isElement(n) {
+		return ""
+	}
+	d := n.TagName
+	if id := n.Attributes["id"]; id != "" {
+		d += "#" + id
+	}
+	if cls := strings.Fields(n.Attributes["class"]); len(cls) > 0 {
+		d += "." + strings.Join(cls, ".")
+	}
+	return d
+}
+
+func nodeNear(n *DOMNode) string {
+	if n == nil {
+		return ""
+	}
+	return nodeDesc(n) + " " + normText(NodeText(n), 40)
+}
+
+// nodeFingerprint DOM树中元素的特征, parent 是父节点
+func nodeFingerprint(n, parent *DOMNode) *ElementFingerprint {
+	fp := &ElementFingerprint{
+		Tag:   n.TagName,
+		Text:  normText(NodeText(n), fingerprintTextMax),
+		Attrs: make(map[string]string, len(n.Attributes)),
+	}
+	for k, v := range n.Attributes {
+		if k != "style" {
+			if r := []rune(v); len(r) > fingerprintTextMax {
+				v = string(r[:fingerprintTextMax])
+			}
+			fp.Attrs[k] = v
+		}
+	}
+	if parent != nil {
+		fp.Parent = nodeDesc(parent)
+		siblings := make([]*DOMNode, 0)
+		for _, c := range parent.Children {
+			if isElement(c) {
+				siblings = append(siblings, c)
+			}
+		}
+		for i, c := range siblings {
+			if c != n {
+				continue
+			}
+			if i > 0 {
+				fp.Prev = nodeNear(siblings[i-1])
+			}
+			if i < len(siblings)-1 {
+				fp.Next = nodeNear(siblings[i+1])
+			}
+		}
+	}
+	return fp
+}
+
+// textSimilarity 两个字符串的相似度, 按相邻两个字符的集合计算(Dice系数)
+func textSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	ra, rb := []rune(strings.ToLower(a)), []rune(strings.ToLower(b))
+	if len(ra) < 2 || len(rb) < 2 {
+		return 0
+	}
+	pairs := make(map[string]int)
+	for i := 0; i < len(ra)-1; i++ {
+		pairs[string(ra[i:i+2])]++
+	}
+	match := 0
+	for i := 0; i < len(rb)-1; i++ {
+		p := string(rb[i : i+2])
+		if pairs[p] > 0 {
+			pairs[p]--
+			match++
+		}
+	}
+	return float64(2*match) / float64(len(ra)+len(rb)-2)
+}
+
+// attrSimilarity 属性的相似度, class 按单个class比较, 其他属性比较值
+func attrSimilarity(a, b map[string]string) float64 {
+	total, score := 0.0, 0.0
+	for k, va := range a {
+		if k == "class" {
+			continue
+		}
+		total++
+		if vb, ok := b[k]; ok {
+			score += textSimilarity(va, vb)
+		}
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok && k != "class" {
+			total++
+		}
+	}
+	ca, cb := strings.Fields(a["class"]), strings.Fields(b["class"])
+	if len(ca) > 0 || len(cb) > 0 {
+		same := 0
+		for _, c := range ca {
+			if containsString(cb, c) {
+				same++
+			}
+		}
+		total++
+		score += float64(same) / float64(len(ca)+len(cb)-same)
+	}
+	// 都没有属性时不能说明是同一个元素, 不加分
+	if total == 0 {
+		return 0
+	}
+	return score / total
+}
+
+// FingerprintScore 元素特征与记录的特征的相似度(0~1): 文本0.35、属性0.3、标签0.15、父元素0.1、相邻元素0.1
+// 记录的元素有文本而文本相似度低于 HealMinTextSimilarity 时为0
+func FingerprintScore(want, got *ElementFingerprint) float64 {
+	score := 0.0
+	if want.Tag == got.Tag {
+		score += 0.15
+	}
+	if want.Text == "" && got.Text == "" {
+		score += 0.35
+	} else {
+		text := textSimilarity(want.Text, got.Text)
+		if want.Text != "" && text < HealMinTextSimilarity {
+			return 0
+		}
+		score += 0.35 * text
+	}
+	score += 0.3 * attrSimilarity(want.Attrs, got.Attrs)
+	score += 0.1 * textSimilarity(want.Parent, got.Parent)
+	near := 0.0
+	for _, pair := range [][2]string{{want.Prev, got.Prev}, {want.Next, got.Next}} {
+		if pair[0] == "" && pair[1] == "" {
+			near += 0.5
+		} else {
+			near += 0.5 * textSimilarity(pair[0], pair[1])
+		}
+	}
+	score += 0.1 * near
+	// 标签不同时多半不是同一个元素
+	if want.Tag != got.Tag {
+		score *= 0.6
+	}
+	return score
+}
+
+// FindSimilar 在DOM树中查找与特征最相似的元素, 相同相似度时取文档顺序中的第一个
+func FindSimilar(root *DOMNode, want *ElementFingerprint) (*DOMNode, float64) {
+	var (
+		best      *DOMNode
+		bestScore float64
+	)
+	var walk func(n, parent *DOMNode)
+	walk = func(n, parent *DOMNode) {
+		if isElement(n) && n.TagName != "html" && n.TagName != "head" && n.TagName != "body" {
+			if score := FingerprintScore(want, nodeFingerprint(n, parent)); score > bestScore {
+				best, bestScore = n, score
+			}
+		}
+		for _, c := range n.Children {
+			walk(c, n)
+		}
+	}
+	walk(root, nil)
+	return best, bestScore
+}
+
+// locatorStore 元素特征文件, 按页面与第一个定位器记录(见 fingerprintKey)
+type locatorStore struct {
+	mu    sync.Mutex
+	path  string
+	items map[string]*ElementFingerprint
+}
+
+var (
+	locatorStoreOnce sync.Once
+	locators         *locatorStore
+)
+
+// LocatorStorePath 元素特征文件的路径: 脚本模式下是脚本旁的 <脚本名>.locators.json, 交互模式下是当前目录的 chromebot.locators.json
+func LocatorStorePath() string {
+	if utils.ScriptFile != "" {
+		return strings.TrimSuffix(utils.ScriptFile, filepath.Ext(utils.ScriptFile)) + ".locators.json"
+	}
+	return filepath.Join(utils.ScriptDir, "chromebot.locators.json")
+}
+
+func getLocatorStore() *locatorStore {
+	locatorStoreOnce.Do(func() {
+		locators = &locatorStore{path: LocatorStorePath(), items: make(map[string]*ElementFingerprint)}
+		b, err := os.ReadFile(locators.path)
+		if err != nil {
+			return
+		}
+		if err = json.Unmarshal(b, &locators.items); err != nil {
+			log.Println("[Chrome]读取元素特征文件失败:", locators.path, err.Error())
+			locators.items = make(map[string]*ElementFingerprint)
+		}
+	})
+	return locators
+}
+
+func (s *locatorStore) get(key string) *ElementFingerprint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.items[key]
+}
+
+// find 页面上定位器记录的特征; 兼容只按定位器记录的旧文件, 记录时的页面不同时不使用
+func (s *locatorStore) find(pageURL, selector string) *ElementFingerprint {
+	if fp := s.get(fingerprintKey(pageURL, selector)); fp != nil {
+		return fp
+	}
+	if fp := s.get(selector); fp != nil && pagePath(fp.URL) == pagePath(pageURL) {
+		return fp
+	}
+	return nil
+}
+
+// fingerprintKey 特征文件中的键: 页面的域名+路径与定位器, 不同页面上的相同定位器分开记录
+func fingerprintKey(pageURL, selector string) string {
+	return pagePath(pageURL) + " " + selector
+}
+
+// pagePath 地址的域名+路径, 不含查询参数与锚点
+func pagePath(pageURL string) string {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return pageURL
+	}
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+	return u.Host + path
+}
+
+// put 记录特征并写入文件, 特征没有变化时不写
+func (s *locatorStore) put(key string, fp *ElementFingerprint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if old, ok := s.items[key]; ok {
+		fp.Time = old.Time
+		if reflect.DeepEqual(old, fp) {
+			return nil
+		}
+	}
+	fp.Time = time.Now().Format("2006-01-02 15:04:05")
+	s.items[key] = fp
+	b, err := json.MarshalIndent(s.items, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, b, 0644)
+}
+
+// HealedLocator 实际使用的定位器
+type HealedLocator struct {
+	Selector string        // 用于操作的定位器
+	Key      string        // 第一个定位器, 特征按页面与它记录
+	Drift    string        // 漂移说明, 没有漂移时为空
+	Score    float64       // 使用相似元素时的相似度
+	Timeout  time.Duration // 操作剩余的等待时间
+	fp       *ElementFingerprint
+}
+
+// Remember 操作成功后记录元素特征
+func (h *HealedLocator) Remember() {
+	if h == nil || h.fp == nil || !LocatorFingerprint {
+		return
+	}
+	h.fp.Drift = h.Drift
+	if err := getLocatorStore().put(fingerprintKey(h.fp.URL, h.Key), h.fp); err != nil {
+		log.Println("[Chrome]写入元素特征文件失败:", err.Error())
+	}
+}
+
+// HealLocator 在候选定位器中选出用于操作的定位器, 查找用掉的时间从 timeout 中扣除, 剩下的记在 Timeout 中给操作使用
+// 有备用定位器时在 timeout 的一半内等待任意一个定位器匹配到元素; 只有一个定位器时只检查一次, 由操作本身等待;
+// 开启了 LocatorFingerprint 且都匹配不到时按记录的特征查找相似元素; 还是没有时返回第一个定位器, 由操作本身等待并报错
+func HealLocator(candidates []string, timeout time.Duration) (*HealedLocator, error) {
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("定位器不能为空")
+	}
+	for _, c := range candidates {
+		if err := ValidateLocator(c); err != nil {
+			return nil, err
+		}
+	}
+	if timeout <= 0 {
+		timeout = DefaultActionTimeout
+	}
+	deadline := time.Now().Add(timeout)
+	res := &HealedLocator{Selector: candidates[0], Key: candidates[0]}
+	res.heal(candidates, timeout)
+	res.Timeout = time.Until(deadline)
+	if res.Timeout < time.Millisecond {
+		res.Timeout = time.Millisecond // 时间已经用完, 操作只检查一次
+	}
+	return res, nil
+}
+
+// heal 选出定位器, 结果写入 h
+func (h *HealedLocator) heal(candidates []string, timeout time.Duration) {
+	if len(candidates) == 1 && !LocatorFingerprint {
+		return
+	}
+
+	match := func() (bool, string, error) {
+		for _, c := range candidates {
+			fp, err := CaptureFingerprint(c)
+			if err != nil {
+				if isContextLost(err) {
+					return false, err.Error(), nil
+				}
+				return false, "", err
+			}
+			if fp != nil {
+				h.Selector, h.fp = c, fp
+				return true, "", nil
+			}
+		}
+		return false, "都没有匹配到元素", nil
+	}
+	var found bool
+	if len(candidates) == 1 {
+		found, _, _ = match()
+	} else {
+		found, _ = pollUntil(timeout/2, "定位器匹配到元素", match)
+	}
+	if found {
+		if h.Selector != h.Key {
+			h.Drift = fmt.Sprintf("%s 未匹配到元素, 使用备用定位器 %s", h.Key, h.Selector)
+			fmt.Println("[Chrome]定位器漂移警告:", h.Drift)
+		}
+		return
+	}
+
+	if !LocatorFingerprint {
+		return
+	}
+	pageURL, _ := frameEval("location.href", false, 6*time.Second)
+	href, _ := pageURL.(string)
+	want := getLocatorStore().find(href, h.Key)
+	if want == nil {
+		return
+	}
+	htmlText, err := GetHtml()
+	if err != nil {
+		return
+	}
+	root, err := ParseHTMLToDOM(htmlText)
+	if err != nil {
+		return
+	}
+	node, score := FindSimilar(root, want)
+	if node == nil || score < HealThreshold {
+		fmt.Printf("[Chrome]定位器 %s 未匹配到元素, 也没有找到相似的元素(最高相似度%.2f)\n", h.Key, score)
+		return
+	}
+	suggest := VerifySelectors(SelectorsByXPath(root, node.XPath))
+	h.Selector, h.Score = suggest[0], score
+	h.fp, _ = CaptureFingerprint(h.Selector)
+	h.Drift = fmt.Sprintf("%s 未匹配到元素, 使用相似度%.2f的元素 %s, 建议更新定位器为 %s", h.Key, score, nodeNear(node), h.Selector)
+	fmt.Println("[Chrome]定位器漂移警告:", h.Drift)
+	if len(suggest) > 1 {
+		fmt.Println("[Chrome]其他可用的定位器:", strings.Join(suggest[1:], " | "))
+	}
+}
+
+// ParseLocatorList 解析带备用的定位器, 值是json字符串数组时按顺序作为候选, 否则是单个定位器
+func ParseLocatorList(arg string) []string {
+	arg = strings.TrimSpace(arg)
+	if strings.HasPrefix(arg, "[") {
+		list := make([]string, 0)
+		if err := json.Unmarshal([]byte(arg), &list); err == nil && len(list) > 0 {
+			return list
+		}
+	}
+	return []string{arg}
+}
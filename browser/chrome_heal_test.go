@@ -0,0 +1,172 @@
+package browser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindSimilar(t *testing.T) {
+	before := `<html><body><div id="app">
+<nav><a href="/">首页</a><a href="/cart">购物车</a></nav>
+<div class="product">
+  <h1>商品</h1>
+  <button id="buy" class="btn buy-btn" data-sku="42">立即购买</button>
+  <button class="btn">加入收藏</button>
+</div>
+</div></body></html>`
+	// 改版后: id 变了, 多了一层包裹和一个横幅
+	after := `<html><body><div class="banner">活动</div><div id="app">
+<nav><a href="/">首页</a><a href="/cart">购物车</a></nav>
+<div class="product">
+  <h1>商品</h1>
+  <div class="actions">
+    <button id="buy-now" class="btn buy-btn primary" data-sku="42">立即购买</button>
+    <button class="btn">加入收藏</button>
+  </div>
+</div>
+</div></body></html>`
+
+	root, err := ParseHTMLToDOM(before)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, _ := QueryXPath(root, "//button[@id='buy']")
+	buy := queryNodes(res)[0].(*DOMNode)
+	product, _ := QueryXPath(root, "//div[@class='product']")
+	fp := nodeFingerprint(buy, queryNodes(product)[0].(*DOMNode))
+	if fp.Tag != "button" || fp.Text != "立即购买" || fp.Parent != "div.product" || fp.Prev != "h1 商品" || fp.Next != "button.btn 加入收藏" {
+		t.Fatalf("特征: %+v", fp)
+	}
+
+	// 同一个元素相似度为1
+	if node, score := FindSimilar(root, fp); node != buy || score < 0.999 {
+		t.Errorf("原页面: %v %.2f", node, score)
+	}
+
+	changed, err := ParseHTMLToDOM(after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	node, score := FindSimilar(changed, fp)
+	if node == nil || node.Attributes["id"] != "buy-now" || score < HealThreshold {
+		t.Fatalf("改版后: %v %.2f", node, score)
+	}
+	if got := SelectorsByXPath(changed, node.XPath)[0]; got != "//button[@id='buy-now']" {
+		t.Errorf("建议的定位器: %s", got)
+	}
+
+	// 元素被删掉后不能误用其他元素
+	removed, _ := ParseHTMLToDOM(`<html><body><div id="app"><nav><a href="/">首页</a></nav><p>已售罄</p></div></body></html>`)
+	if node, score := FindSimilar(removed, fp); score >= HealThreshold {
+		t.Errorf("删除后不应匹配: %v %.2f", node, score)
+	}
+}
+
+func TestFindSimilarDifferentText(t *testing.T) {
+	// 结构相同、都没有属性, 只有文本不同的按钮不能当作同一个元素
+	root, _ := ParseHTMLToDOM(`<html><body><div><button>立即购买</button></div></body></html>`)
+	res, _ := QueryXPath(root, "//button")
+	buy := queryNodes(res)[0].(*DOMNode)
+	div, _ := QueryXPath(root, "//div")
+	fp := nodeFingerprint(buy, queryNodes(div)[0].(*DOMNode))
+
+	other, _ := ParseHTMLToDOM(`<html><body><div><button>删除账户</button></div></body></html>`)
+	if node, score := FindSimilar(other, fp); score >= HealThreshold {
+		t.Errorf("文本不同不应匹配: %v %.2f", node, score)
+	}
+	// 文本相同时仍能找到
+	same, _ := ParseHTMLToDOM(`<html><body><section><div><button>立即购买</button></div></section></body></html>`)
+	if node, score := FindSimilar(same, fp); node == nil || node.TagName != "button" || score < HealThreshold {
+		t.Errorf("文本相同: %v %.2f", node, score)
+	}
+}
+
+func TestTextSimilarity(t *testing.T) {
+	cases := []struct {
+		a, b string
+		min  float64
+		max  float64
+	}{
+		{"立即购买", "立即购买", 1, 1},
+		{"立即购买", "马上购买", 0.3, 0.4},
+		{"login", "LOGIN", 1, 1},
+		{"a", "b", 0, 0},
+		{"加入购物车", "删除", 0, 0},
+	}
+	for _, c := range cases {
+		if got := textSimilarity(c.a, c.b); got < c.min || got > c.max {
+			t.Errorf("textSimilarity(%q, %q) = %.2f", c.a, c.b, got)
+		}
+	}
+}
+
+func TestParseLocatorList(t *testing.T) {
+	cases := map[string][]string{
+		`["//button[@id='buy']","text=立即购买","css=.buy-btn"]`: {"//button[@id='buy']", "text=立即购买", "css=.buy-btn"},
+		`//button[@id='buy']`: {"//button[@id='buy']"},
+		`[data-testid=buy]`:   {"[data-testid=buy]"},
+		` ["css=#kw"] `:       {"css=#kw"},
+		`[]`:                  {"[]"},
+	}
+	for arg, want := range cases {
+		if got := ParseLocatorList(arg); !reflect.DeepEqual(got, want) {
+			t.Errorf("ParseLocatorList(%s) = %q, want %q", arg, got, want)
+		}
+	}
+}
+
+func TestLocatorStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.locators.json")
+	s := &locatorStore{path: path, items: make(map[string]*ElementFingerprint)}
+	fp := &ElementFingerprint{Tag: "button", Text: "立即购买", Attrs: map[string]string{"id": "buy"}}
+	if err := s.put("//button[@id='buy']", fp); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 特征没有变化时不重写文件
+	same := &ElementFingerprint{Tag: "button", Text: "立即购买", Attrs: map[string]string{"id": "buy"}}
+	if err := s.put("//button[@id='buy']", same); err != nil {
+		t.Fatal(err)
+	}
+	if info2, _ := os.Stat(path); !info2.ModTime().Equal(info.ModTime()) || s.get("//button[@id='buy']") != fp {
+		t.Errorf("特征没有变化时不应重写")
+	}
+	b, _ := os.ReadFile(path)
+	if len(b) == 0 || s.get("//button[@id='buy']").Time == "" {
+		t.Errorf("文件内容: %s", b)
+	}
+}
+
+func TestLocatorStoreFind(t *testing.T) {
+	s := &locatorStore{path: filepath.Join(t.TempDir(), "a.locators.json"), items: make(map[string]*ElementFingerprint)}
+	cart := &ElementFingerprint{URL: "https://shop.com/cart?id=1", Tag: "button", Text: "结算"}
+	account := &ElementFingerprint{URL: "https://shop.com/account", Tag: "button", Text: "删除账户"}
+	_ = s.put(fingerprintKey(cart.URL, "css=.primary"), cart)
+	_ = s.put(fingerprintKey(account.URL, "css=.primary"), account)
+
+	// 同一个定位器在不同页面分开记录, 查询参数与锚点不影响
+	if fp := s.find("https://shop.com/cart?id=2#top", "css=.primary"); fp != cart {
+		t.Errorf("购物车页面: %+v", fp)
+	}
+	if fp := s.find("https://shop.com/account", "css=.primary"); fp != account {
+		t.Errorf("账户页面: %+v", fp)
+	}
+	if fp := s.find("https://shop.com/orders", "css=.primary"); fp != nil {
+		t.Errorf("没有记录的页面: %+v", fp)
+	}
+
+	// 旧文件只按定位器记录, 页面相同时才使用
+	old := &ElementFingerprint{URL: "https://shop.com/list", Tag: "a", Text: "下一页"}
+	s.items["css=.next"] = old
+	if fp := s.find("https://shop.com/list?page=3", "css=.next"); fp != old {
+		t.Errorf("旧记录: %+v", fp)
+	}
+	if fp := s.find("https://shop.com/other", "css=.next"); fp != nil {
+		t.Errorf("旧记录在其他页面: %+v", fp)
+	}
+}
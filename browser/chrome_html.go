@@ -19,21 +19,27 @@ var chromeHtmlJS string
 // GetHtml 获取页面的html
 func GetHtml() (string, error) {
 	if !DefaultNowTab(true) {
-		return "", nil
+		return "", notReadyErr()
 	}
 
-	chromeInstance.NextID++
+	sessionId, contextId, err := nowFrameContext()
+	if err != nil {
+		return "", err
+	}
+
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	msg := map[string]interface{}{
-		"id":     chromeInstance.NextID,
+		"id":     reqID,
 		"method": "Runtime.evaluate",
-		"params": map[string]interface{}{
-			"expression":    chromeHtmlJS,
+		"params": withContextId(map[string]interface{}{
+			"expression":    jsCall(chromeHtmlJS, nil),
 			"returnByValue": true,
 			"awaitPromise":  true,
-		},
-		"sessionId": chromeInstance.NowTabSession,
+		}, contextId),
+		"sessionId": sessionId,
 	}
-	err := chromeInstance.NowTabWSConn.WriteJSON(msg)
+	err = wsWriteJSON(chromeInstance.NowTabWSConn, msg)
 	if err != nil {
 		gt.Error("发送消息失败:", err)
 		return "", fmt.Errorf("发送消息失败")
@@ -47,12 +53,12 @@ func GetHtml() (string, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				gt.Info("消息队列已关闭")
 				return "", fmt.Errorf("消息队列已关闭")
 			}
-			if chromeInstance.NextID == respMsg.ID {
+			if reqID == respMsg.ID {
 				result, err := gt.Json2Map(respMsg.Content)
 				if err != nil {
 					log.Println("回复内容解析错误")
@@ -1,4 +1,4 @@
-(function() {
+function(args) {
     try {
         // 获取完整的HTML
         const html = document.documentElement.outerHTML;
@@ -16,4 +16,4 @@
             error: error.message
         };
     }
-})()
\ No newline at end of file
+}
@@ -6,37 +6,49 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"strings"
 	"time"
 )
 
 //go:embed chrome_input.js
 var chromeInputJS string
 
+// Input 输入, 等待元素可操作的时间为 DefaultActionTimeout
 func Input(xPath, text string) error {
+	return InputWithTimeout(xPath, text, DefaultActionTimeout)
+}
+
+// InputWithTimeout 输入, 先在timeout内等待元素可编辑(见 chrome_wait.go)
+func InputWithTimeout(xPath, text string, timeout time.Duration) error {
 	if !DefaultNowTab(true) {
-		return nil
+		return notReadyErr()
 	}
 
 	utils.Debug("输入内容 : ", text)
 
-	xPath = "'" + strings.ReplaceAll(xPath, "\"", "\\\"") + "'"
-	text = "'" + strings.ReplaceAll(text, "\"", "\\\"") + "'"
-	js := strings.ReplaceAll(chromeInputJS, "__XPATH__", xPath)
-	js = strings.ReplaceAll(js, "__INPUTTEXT__", text)
+	if err := waitActionable(xPath, actionInput, timeout); err != nil {
+		return err
+	}
+
+	js := jsCall(chromeInputJS, map[string]any{"xpath": xPath, "text": text})
+
+	sessionId, contextId, err := nowFrameContext()
+	if err != nil {
+		return err
+	}
 
-	id := GetNextMsgID()
+	id, respCh := nextReply()
+	defer dropReply(id)
 	msg := map[string]interface{}{
 		"id":     id,
 		"method": "Runtime.evaluate",
-		"params": map[string]interface{}{
+		"params": withContextId(map[string]interface{}{
 			"expression":    js,
 			"returnByValue": true,
 			"awaitPromise":  true,
-		},
-		"sessionId": chromeInstance.NowTabSession,
+		}, contextId),
+		"sessionId": sessionId,
 	}
-	err := chromeInstance.NowTabWSConn.WriteJSON(msg)
+	err = wsWriteJSON(chromeInstance.NowTabWSConn, msg)
 	if err != nil {
 		log.Println("发送消息失败:", err)
 		return fmt.Errorf("发送消息失败")
@@ -44,13 +56,12 @@ func Input(xPath, text string) error {
 	msgStr, _ := json.Marshal(msg)
 	utils.Debugf("发送消息: %s", string(msgStr))
 
-	timeout := 6 * time.Second
-	timer := time.NewTimer(timeout)
+	timer := time.NewTimer(6 * time.Second)
 	defer timer.Stop() // 重要：确保计时器被清理
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				log.Println("消息队列已关闭")
 				return fmt.Errorf("消息队列已关闭")
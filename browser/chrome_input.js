@@ -1,7 +1,8 @@
-(function() {
-    // 统一占位符
-    const xpath = __XPATH__;
-    const newValue = __INPUTTEXT__;
+function(args) {
+    const __cbLocator = __LOCATOR__;
+    // 参数由调用方以JSON传入
+    const xpath = args.xpath;
+    const newValue = args.text;
     // 用于存储最终执行结果（CDP需要显式返回）
     let finalResult = {
         success: false,
@@ -21,13 +22,7 @@
         const startTime = Date.now();
         while (Date.now() - startTime < timeout) {
             try {
-                const el = document.evaluate(
-                    xpath,
-                    document,
-                    null,
-                    XPathResult.FIRST_ORDERED_NODE_TYPE,
-                    null
-                ).singleNodeValue;
+                const el = __cbLocator.first(xpath);
                 if (el && (el.tagName === 'TEXTAREA' || el.tagName === 'INPUT')) {
                     return el;
                 }
@@ -294,4 +289,4 @@
     // ====================== CDP关键：显式返回结果（避免undefined） ======================
     console.log('CDP输入执行结果:', finalResult);
     return finalResult; // 必须显式返回，CDP才能拿到结果
-})()
\ No newline at end of file
+}
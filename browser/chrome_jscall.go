@@ -0,0 +1,29 @@
+package browser
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+/*
+执行内嵌js的参数传递
+
+内嵌的 .js 文件都是接收一个参数对象的函数, 如 (args) => {...}, 调用时参数以JSON编码传入:
+
+	((args) => {...})({"xpath":"//a[@title='x']"})
+
+JSON是js表达式的子集, 字符串里的引号、反斜杠、换行、</script>、U+2028 等都只会是数据, 不会被当作代码执行;
+不要再用字符串替换把参数拼进js里
+*/
+
+// jsCall 生成以参数对象调用js函数模板的表达式, 模板中的 __LOCATOR__ 会替换为定位器代码
+func jsCall(fn string, args map[string]any) string {
+	if args == nil {
+		args = map[string]any{}
+	}
+	argsJson, err := json.Marshal(args)
+	if err != nil {
+		argsJson = []byte("{}")
+	}
+	return "(" + strings.TrimSpace(injectLocator(fn)) + ")(" + string(argsJson) + ")"
+}
@@ -0,0 +1,82 @@
+package browser
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// hostileStrings 会破坏字符串拼接的js的输入
+var hostileStrings = []string{
+	`//a[@title='it's']`,
+	`//div[text()="say \"hi\""]`,
+	`\`,
+	`\\'; alert(1); //`,
+	`'); alert(document.cookie); ('`,
+	`"); alert(1); ("`,
+	"第一行\n第二行\r\n第三行",
+	"tab\there",
+	"`${alert(1)}`",
+	`</script><script>alert(1)</script>`,
+	"  ",
+	"\x00\x1f",
+	`__XPATH__ __INPUTTEXT__ __LOCATOR__`,
+	`*/ alert(1) /*`,
+	"😀 中文 العربية",
+	"line\u2028separator\u2029paragraph",
+}
+
+func TestJsCallHostileStrings(t *testing.T) {
+	fn := "(args) => args"
+	for _, s := range hostileStrings {
+		js := jsCall(fn, map[string]any{"xpath": s, "text": s})
+
+		prefix := "(" + fn + ")("
+		if !strings.HasPrefix(js, prefix) || !strings.HasSuffix(js, ")") {
+			t.Fatalf("%q: 调用表达式结构被破坏: %s", s, js)
+		}
+		argsJson := strings.TrimSuffix(strings.TrimPrefix(js, prefix), ")")
+		if strings.ContainsAny(argsJson, "\n\r\u2028\u2029") {
+			t.Errorf("%q: 参数中不应出现原始换行: %s", s, argsJson)
+		}
+		if strings.Contains(argsJson, "</script") {
+			t.Errorf("%q: 参数中不应出现原始的 </script", s)
+		}
+
+		args := map[string]string{}
+		if err := json.Unmarshal([]byte(argsJson), &args); err != nil {
+			t.Fatalf("%q: 参数不是合法的JSON: %v", s, err)
+		}
+		if args["xpath"] != s || args["text"] != s {
+			t.Errorf("%q: 参数传递后被改变: %q", s, args["xpath"])
+		}
+	}
+}
+
+func TestJsCallTemplates(t *testing.T) {
+	// 内嵌的js都应是接收参数对象的函数, 不再有参数占位符
+	templates := map[string]string{
+		"chrome_check.js":          chromeCheckJS,
+		"chrome_click.js":          chromeClickJS,
+		"chrome_input.js":          chromeInputJS,
+		"chrome_html.js":           chromeHtmlJS,
+		"chrome_scroll_element.js": chromeScrollElementJS,
+		"chrome_scroll_pixel.js":   chromeScrollPixelJS,
+		"pagerStateJS":             pagerStateJS,
+		"locatorStateJS":           locatorStateJS,
+		"fingerprintJS":            fingerprintJS,
+		"screenshotMaskJS":         screenshotMaskJS,
+		"screenshotUnmaskJS":       screenshotUnmaskJS,
+	}
+	for name, tpl := range templates {
+		js := jsCall(tpl, map[string]any{"xpath": hostileStrings[3]})
+		for _, placeholder := range []string{"__XPATH__", "__INPUTTEXT__", "__SCROLL_", "__LOCATOR__", "__SELECTOR", "__ATTR__", "__MAX__"} {
+			if strings.Contains(strings.SplitN(js, ")({", 2)[0], placeholder) {
+				t.Errorf("%s: 仍有占位符 %s", name, placeholder)
+			}
+		}
+		if !strings.HasPrefix(strings.TrimSpace(tpl), "(args) =>") && !strings.HasPrefix(strings.TrimSpace(tpl), "function(args)") {
+			t.Errorf("%s: 应为接收参数对象的函数", name)
+		}
+	}
+}
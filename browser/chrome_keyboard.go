@@ -0,0 +1,414 @@
+package browser
+
+import (
+	"ChromeBot/utils"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+/*
+真实键盘输入
+
+chrome_input.js 是直接给元素赋值, 部分框架的受控组件和依赖按键的控件(联想输入、验证码格子、富文本编辑器)收不到按键;
+这里通过 Input.dispatchKeyEvent 逐个字符发送按键, 键盘上没有的字符(中文等)通过 Input.imeSetComposition + Input.insertText 模拟输入法上屏
+*/
+
+// keyDef 按键定义, 美式键盘布局
+type keyDef struct {
+	Key       string // KeyboardEvent.key
+	Code      string // KeyboardEvent.code
+	KeyCode   int    // windowsVirtualKeyCode
+	Text      string // 按下时产生的字符, 功能键为空
+	ShiftKey  string // 按住Shift时的 key
+	ShiftText string
+	Location  int // 1:左 2:右 3:小键盘
+}
+
+// 修饰键对应 Input.dispatchKeyEvent 的 modifiers 位
+const (
+	modAlt   = 1
+	modCtrl  = 2
+	modMeta  = 4
+	modShift = 8
+)
+
+var modifierBits = map[string]int{
+	"Alt":     modAlt,
+	"Control": modCtrl,
+	"Meta":    modMeta,
+	"Shift":   modShift,
+}
+
+// keyTable 按键名 -> 按键定义
+var keyTable = map[string]keyDef{
+	"Enter":       {Key: "Enter", Code: "Enter", KeyCode: 13, Text: "\r"},
+	"Tab":         {Key: "Tab", Code: "Tab", KeyCode: 9},
+	"Backspace":   {Key: "Backspace", Code: "Backspace", KeyCode: 8},
+	"Delete":      {Key: "Delete", Code: "Delete", KeyCode: 46},
+	"Escape":      {Key: "Escape", Code: "Escape", KeyCode: 27},
+	"Space":       {Key: " ", Code: "Space", KeyCode: 32, Text: " "},
+	"ArrowUp":     {Key: "ArrowUp", Code: "ArrowUp", KeyCode: 38},
+	"ArrowDown":   {Key: "ArrowDown", Code: "ArrowDown", KeyCode: 40},
+	"ArrowLeft":   {Key: "ArrowLeft", Code: "ArrowLeft", KeyCode: 37},
+	"ArrowRight":  {Key: "ArrowRight", Code: "ArrowRight", KeyCode: 39},
+	"Home":        {Key: "Home", Code: "Home", KeyCode: 36},
+	"End":         {Key: "End", Code: "End", KeyCode: 35},
+	"PageUp":      {Key: "PageUp", Code: "PageUp", KeyCode: 33},
+	"PageDown":    {Key: "PageDown", Code: "PageDown", KeyCode: 34},
+	"Insert":      {Key: "Insert", Code: "Insert", KeyCode: 45},
+	"CapsLock":    {Key: "CapsLock", Code: "CapsLock", KeyCode: 20},
+	"ContextMenu": {Key: "ContextMenu", Code: "ContextMenu", KeyCode: 93},
+	"PrintScreen": {Key: "PrintScreen", Code: "PrintScreen", KeyCode: 44},
+	"Pause":       {Key: "Pause", Code: "Pause", KeyCode: 19},
+	"Shift":       {Key: "Shift", Code: "ShiftLeft", KeyCode: 16, Location: 1},
+	"Control":     {Key: "Control", Code: "ControlLeft", KeyCode: 17, Location: 1},
+	"Alt":         {Key: "Alt", Code: "AltLeft", KeyCode: 18, Location: 1},
+	"Meta":        {Key: "Meta", Code: "MetaLeft", KeyCode: 91, Location: 1},
+}
+
+// keyAlias 按键别名
+var keyAlias = map[string]string{
+	"ctrl":    "Control",
+	"control": "Control",
+	"cmd":     "Meta",
+	"command": "Meta",
+	"win":     "Meta",
+	"meta":    "Meta",
+	"option":  "Alt",
+	"alt":     "Alt",
+	"shift":   "Shift",
+	"esc":     "Escape",
+	"escape":  "Escape",
+	"return":  "Enter",
+	"enter":   "Enter",
+	"tab":     "Tab",
+	"del":     "Delete",
+	"delete":  "Delete",
+	"back":    "Backspace",
+	"up":      "ArrowUp",
+	"down":    "ArrowDown",
+	"left":    "ArrowLeft",
+	"right":   "ArrowRight",
+	"space":   "Space",
+	"pgup":    "PageUp",
+	"pgdn":    "PageDown",
+}
+
+// editCommands 组合键对应的编辑命令, 部分平台上组合键不会自动触发编辑行为
+var editCommands = map[string]string{
+	"Control+a": "selectAll",
+	"Control+c": "copy",
+	"Control+x": "cut",
+	"Control+v": "paste",
+	"Control+z": "undo",
+	"Control+y": "redo",
+	"Meta+a":    "selectAll",
+	"Meta+c":    "copy",
+	"Meta+x":    "cut",
+	"Meta+v":    "paste",
+	"Meta+z":    "undo",
+}
+
+func init() {
+	for c := 'a'; c <= 'z'; c++ {
+		upper := strings.ToUpper(string(c))
+		keyTable[string(c)] = keyDef{Key: string(c), Code: "Key" + upper, KeyCode: int(c - 'a' + 65), Text: string(c), ShiftKey: upper, ShiftText: upper}
+	}
+	digitShift := ")!@#$%^&*("
+	for i := 0; i <= 9; i++ {
+		d := fmt.Sprint(i)
+		s := string(digitShift[i])
+		keyTable[d] = keyDef{Key: d, Code: "Digit" + d, KeyCode: 48 + i, Text: d, ShiftKey: s, ShiftText: s}
+	}
+	for i := 1; i <= 12; i++ {
+		name := fmt.Sprintf("F%d", i)
+		keyTable[name] = keyDef{Key: name, Code: name, KeyCode: 111 + i}
+	}
+	punct := []struct {
+		key, shift, code string
+		keyCode          int
+	}{
+		{"`", "~", "Backquote", 192},
+		{"-", "_", "Minus", 189},
+		{"=", "+", "Equal", 187},
+		{"[", "{", "BracketLeft", 219},
+		{"]", "}", "BracketRight", 221},
+		{"\\", "|", "Backslash", 220},
+		{";", ":", "Semicolon", 186},
+		{"'", "\"", "Quote", 222},
+		{",", "<", "Comma", 188},
+		{".", ">", "Period", 190},
+		{"/", "?", "Slash", 191},
+	}
+	for _, p := range punct {
+		keyTable[p.key] = keyDef{Key: p.key, Code: p.code, KeyCode: p.keyCode, Text: p.key, ShiftKey: p.shift, ShiftText: p.shift}
+	}
+}
+
+// lookupKey 按键名查找, 大小写不敏感; 单个字符的按键(如 A、!)返回对应的键与是否需要Shift
+func lookupKey(name string) (keyDef, bool, error) {
+	if k, ok := keyTable[name]; ok {
+		return k, false, nil
+	}
+	if alias, ok := keyAlias[strings.ToLower(name)]; ok {
+		return keyTable[alias], false, nil
+	}
+	if utf8.RuneCountInString(name) == 1 {
+		if k, shift, ok := keyForChar(name); ok {
+			return k, shift, nil
+		}
+	}
+	for n, k := range keyTable {
+		if strings.EqualFold(n, name) {
+			return k, false, nil
+		}
+	}
+	return keyDef{}, false, fmt.Errorf("未知的按键: %s", name)
+}
+
+// keyForChar 键盘上能直接输入的字符对应的按键, shift表示需要按住Shift
+func keyForChar(ch string) (keyDef, bool, bool) {
+	if k, ok := keyTable[ch]; ok && k.Text == ch {
+		return k, false, true
+	}
+	for _, k := range keyTable {
+		if k.Text != "" && k.Text == ch {
+			return k, false, true // 如空格
+		}
+	}
+	for _, k := range keyTable {
+		if k.ShiftText != "" && k.ShiftText == ch {
+			return k, true, true
+		}
+	}
+	return keyDef{}, false, false
+}
+
+// keyCombo 解析后的组合键
+type keyCombo struct {
+	Modifiers []keyDef
+	Key       keyDef
+	Shift     bool   // 主键是需要Shift的字符
+	Command   string // 编辑命令
+}
+
+// parseKeyCombo 解析 "Enter"、"Control+A"、"Control+Shift+ArrowLeft"、"Control++"
+func parseKeyCombo(keys string) (keyCombo, error) {
+	combo := keyCombo{}
+	keys = strings.TrimSpace(keys)
+	if keys == "" {
+		return combo, fmt.Errorf("按键不能为空")
+	}
+	var parts []string
+	if keys == "+" {
+		parts = []string{"+"}
+	} else if strings.HasSuffix(keys, "++") {
+		parts = append(strings.Split(strings.TrimSuffix(keys, "++"), "+"), "+")
+	} else {
+		parts = strings.Split(keys, "+")
+	}
+
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if i > 0 && len(part) == 1 && part[0] >= 'A' && part[0] <= 'Z' {
+			part = strings.ToLower(part) // 组合键中的字母不区分大小写, Control+A 即 Control+a
+		}
+		k, shift, err := lookupKey(part)
+		if err != nil {
+			return combo, err
+		}
+		if i < len(parts)-1 {
+			if _, ok := modifierBits[k.Key]; !ok {
+				return combo, fmt.Errorf("%s 不是修饰键, 组合键格式如 Control+A", part)
+			}
+			combo.Modifiers = append(combo.Modifiers, k)
+			continue
+		}
+		combo.Key = k
+		combo.Shift = shift
+	}
+	if len(combo.Modifiers) > 0 {
+		combo.Command = editCommands[combo.Modifiers[len(combo.Modifiers)-1].Key+"+"+strings.ToLower(combo.Key.Key)]
+	}
+	return combo, nil
+}
+
+// dispatchKey 发送一次按键事件
+func dispatchKey(eventType string, k keyDef, modifiers int, text string, commands []string) error {
+	params := map[string]any{
+		"type":                  eventType,
+		"key":                   k.Key,
+		"code":                  k.Code,
+		"windowsVirtualKeyCode": k.KeyCode,
+		"nativeVirtualKeyCode":  k.KeyCode,
+		"modifiers":             modifiers,
+	}
+	if k.Location > 0 {
+		params["location"] = k.Location
+	}
+	if text != "" {
+		params["text"] = text
+		params["unmodifiedText"] = text
+	}
+	if len(commands) > 0 {
+		params["commands"] = commands
+	}
+	_, err := tabCall("Input.dispatchKeyEvent", params)
+	return err
+}
+
+// pressCombo 按下并松开组合键
+func pressCombo(combo keyCombo) error {
+	modifiers := 0
+	for _, m := range combo.Modifiers {
+		modifiers |= modifierBits[m.Key]
+		if err := dispatchKey("rawKeyDown", m, modifiers, "", nil); err != nil {
+			return err
+		}
+	}
+
+	k := combo.Key
+	text := k.Text
+	if combo.Shift || modifiers&modShift != 0 {
+		if k.ShiftKey != "" {
+			k.Key = k.ShiftKey
+			text = k.ShiftText
+		}
+		if combo.Shift {
+			modifiers |= modShift
+		}
+	}
+	// 按住 Control/Alt/Meta 时不产生字符
+	if modifiers&(modCtrl|modAlt|modMeta) != 0 {
+		text = ""
+	}
+	var commands []string
+	if combo.Command != "" {
+		commands = []string{combo.Command}
+	}
+	downType := "rawKeyDown"
+	if text != "" {
+		downType = "keyDown"
+	}
+	if err := dispatchKey(downType, k, modifiers, text, commands); err != nil {
+		return err
+	}
+	if err := dispatchKey("keyUp", k, modifiers, "", nil); err != nil {
+		return err
+	}
+
+	for i := len(combo.Modifiers) - 1; i >= 0; i-- {
+		m := combo.Modifiers[i]
+		modifiers &^= modifierBits[m.Key]
+		if err := dispatchKey("keyUp", m, modifiers, "", nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// imeInsert 模拟输入法输入键盘上没有的字符: 先显示组合文字再上屏
+func imeInsert(text string) error {
+	n := utf8.RuneCountInString(text)
+	_, err := tabCall("Input.imeSetComposition", map[string]any{
+		"text":           text,
+		"selectionStart": n,
+		"selectionEnd":   n,
+	})
+	if err != nil {
+		utils.Debug("imeSetComposition 失败, 直接插入文字: ", err)
+	}
+	_, err = tabCall("Input.insertText", map[string]any{"text": text})
+	return err
+}
+
+// focusLocator 聚焦定位器对应的元素, 先等待元素可编辑或可点击
+func focusLocator(selector string, need Actionability, timeout time.Duration) error {
+	if err := waitActionable(selector, need, timeout); err != nil {
+		return err
+	}
+	selectorJson, _ := json.Marshal(selector)
+	js := fmt.Sprintf(`(() => {
+    const el = (%s).first(%s);
+    if (!el) return false;
+    el.focus();
+    const active = el.getRootNode().activeElement;
+    return active === el || el.contains(active);
+})()`, strings.TrimSpace(chromeLocatorJS), string(selectorJson))
+	value, err := frameEval(js, false, 6*time.Second)
+	if err != nil {
+		return err
+	}
+	if ok, _ := value.(bool); !ok {
+		return fmt.Errorf("元素无法获得焦点: %s", selector)
+	}
+	return nil
+}
+
+// TypeText 逐字符键盘输入, selector为空时输入到当前获得焦点的元素; delay为每个字符之间的间隔
+func TypeText(selector, text string, delay, timeout time.Duration) error {
+	if !DefaultNowTab(true) {
+		return notReadyErr()
+	}
+	if selector != "" {
+		if err := focusLocator(selector, actionInput, timeout); err != nil {
+			return err
+		}
+	}
+	log.Printf("[Chrome]键盘输入: %s", text)
+
+	runes := []rune(text)
+	for i, r := range runes {
+		ch := string(r)
+		switch ch {
+		case "\n":
+			ch = "Enter"
+		case "\t":
+			ch = "Tab"
+		case "\r":
+			continue
+		}
+		var combo keyCombo
+		if k, ok := keyTable[ch]; ok && (k.Text == ch || ch == "Enter" || ch == "Tab") {
+			combo = keyCombo{Key: k}
+		} else if k, shift, ok := keyForChar(ch); ok {
+			combo = keyCombo{Key: k, Shift: shift}
+		}
+
+		var err error
+		if combo.Key.Key == "" {
+			err = imeInsert(ch) // 键盘上没有的字符(中文等)走输入法
+		} else {
+			err = pressCombo(combo)
+		}
+		if err != nil {
+			return err
+		}
+		if delay > 0 && i < len(runes)-1 {
+			time.Sleep(delay)
+		}
+	}
+	return nil
+}
+
+// PressKey 按键, 如 Enter、Control+A、Shift+Tab; selector不为空时先聚焦该元素
+func PressKey(selector, keys string, timeout time.Duration) error {
+	combo, err := parseKeyCombo(keys)
+	if err != nil {
+		return err
+	}
+	if !DefaultNowTab(true) {
+		return notReadyErr()
+	}
+	if selector != "" {
+		if err := focusLocator(selector, actionClick, timeout); err != nil {
+			return err
+		}
+	}
+	log.Printf("[Chrome]按键: %s", keys)
+	return pressCombo(combo)
+}
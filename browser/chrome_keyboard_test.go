@@ -0,0 +1,52 @@
+package browser
+
+import "testing"
+
+func TestParseKeyCombo(t *testing.T) {
+	cases := []struct {
+		keys      string
+		modifiers int
+		key       string
+		shift     bool
+		command   string
+	}{
+		{"Enter", 0, "Enter", false, ""},
+		{"esc", 0, "Escape", false, ""},
+		{"Control+A", modCtrl, "a", false, "selectAll"},
+		{"Ctrl+Shift+ArrowLeft", modCtrl | modShift, "ArrowLeft", false, ""},
+		{"Control++", modCtrl, "=", true, ""},
+		{"A", 0, "a", true, ""},
+		{"F5", 0, "F5", false, ""},
+	}
+	for _, c := range cases {
+		combo, err := parseKeyCombo(c.keys)
+		if err != nil {
+			t.Fatalf("%s: %v", c.keys, err)
+		}
+		modifiers := 0
+		for _, m := range combo.Modifiers {
+			modifiers |= modifierBits[m.Key]
+		}
+		if modifiers != c.modifiers || combo.Key.Key != c.key || combo.Shift != c.shift || combo.Command != c.command {
+			t.Errorf("%s: got modifiers=%d key=%s shift=%v command=%s", c.keys, modifiers, combo.Key.Key, combo.Shift, combo.Command)
+		}
+	}
+
+	for _, bad := range []string{"", "A+B", "Control+NoSuchKey"} {
+		if _, err := parseKeyCombo(bad); err == nil {
+			t.Errorf("%q: 期望返回错误", bad)
+		}
+	}
+}
+
+func TestKeyForChar(t *testing.T) {
+	for ch, want := range map[string]string{"a": "KeyA", "Z": "KeyZ", " ": "Space", "?": "Slash", "7": "Digit7"} {
+		k, _, ok := keyForChar(ch)
+		if !ok || k.Code != want {
+			t.Errorf("%q: got %s %v, want %s", ch, k.Code, ok, want)
+		}
+	}
+	if _, _, ok := keyForChar("中"); ok {
+		t.Errorf("中文字符应走输入法")
+	}
+}
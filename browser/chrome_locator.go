@@ -0,0 +1,215 @@
+package browser
+
+import (
+	"ChromeBot/utils"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+//go:embed chrome_locator.js
+var chromeLocatorJS string
+
+// injectLocator 将定位器代码注入到操作js中, 操作js通过 __cbLocator.first(selector) 查找元素
+// 所有操作(点击、输入、检查、滚动、frame等)都使用同一个定位器, 定位器语法见 chrome_locator.js
+func injectLocator(js string) string {
+	return strings.ReplaceAll(js, "__LOCATOR__", strings.TrimSpace(chromeLocatorJS))
+}
+
+// splitPierce 按 >>> 切分选择器, 忽略引号与括号内的内容, 与 chrome_locator.js 一致
+func splitPierce(selector string) []string {
+	parts := make([]string, 0)
+	var buf strings.Builder
+	quote := rune(0)
+	depth := 0
+	runes := []rune(selector)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			buf.WriteRune(c)
+			continue
+		}
+		switch {
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '(':
+			depth++
+		case c == ']' || c == ')':
+			depth--
+		case depth == 0 && strings.HasPrefix(string(runes[i:]), ">>>"):
+			parts = append(parts, strings.TrimSpace(buf.String()))
+			buf.Reset()
+			i += 2
+			continue
+		}
+		buf.WriteRune(c)
+	}
+	parts = append(parts, strings.TrimSpace(buf.String()))
+
+	res := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p != "" {
+			res = append(res, p)
+		}
+	}
+	return res
+}
+
+// LocatorPart 定位器的一段, 多段之间用 >>> 穿透 shadow root
+type LocatorPart struct {
+	Engine string // css xpath text role label placeholder testid
+	Body   string
+	Bare   bool // 不带前缀的xpath
+}
+
+// locatorEngines 支持的定位器类型, 与 chrome_locator.js 一致
+var locatorEngines = []string{"css", "xpath", "text", "role", "label", "placeholder", "testid"}
+
+func isLocatorEngine(engine string) bool {
+	for _, e := range locatorEngines {
+		if e == engine {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	locatorPrefixReg = regexp.MustCompile(`^([a-z]+)=`)
+	locatorRoleReg   = regexp.MustCompile(`^[a-zA-Z]+\s*(\[\s*name\s*\*?=\s*[\s\S]*?\s*\])?$`)
+)
+
+// ParseLocator 解析定位器
+// 支持 css= xpath= text= role= label= placeholder= testid= 前缀; 不带前缀时以 / ( ./ 开头为xpath, 其他为css
+func ParseLocator(selector string) ([]LocatorPart, error) {
+	segs := splitPierce(selector)
+	if len(segs) == 0 {
+		return nil, fmt.Errorf("定位器不能为空")
+	}
+	parts := make([]LocatorPart, 0, len(segs))
+	for _, seg := range segs {
+		part := LocatorPart{}
+		if m := locatorPrefixReg.FindStringSubmatch(seg); m != nil {
+			if !isLocatorEngine(m[1]) {
+				return nil, fmt.Errorf("不支持的定位器类型: %s=, 支持 %s=", m[1], strings.Join(locatorEngines, "=, "))
+			}
+			part.Engine = m[1]
+			part.Body = strings.TrimSpace(seg[len(m[0]):])
+		} else if strings.HasPrefix(seg, "/") || strings.HasPrefix(seg, "(") || strings.HasPrefix(seg, "./") {
+			part = LocatorPart{Engine: "xpath", Body: seg, Bare: true}
+		} else {
+			part = LocatorPart{Engine: "css", Body: seg}
+		}
+		if part.Body == "" {
+			return nil, fmt.Errorf("%s定位器的值不能为空: %s", part.Engine, selector)
+		}
+		parts = append(parts, part)
+	}
+	return parts, nil
+}
+
+// ValidateLocator 校验定位器的格式, 元素是否存在在浏览器中确认
+func ValidateLocator(selector string) error {
+	parts, err := ParseLocator(selector)
+	if err != nil {
+		return err
+	}
+	for _, part := range parts {
+		switch part.Engine {
+		case "xpath":
+			if _, err := utils.ValidateXPathPureNative(part.Body); err != nil {
+				return err
+			}
+		case "role":
+			if !locatorRoleReg.MatchString(part.Body) {
+				return fmt.Errorf("role定位器格式错误: %s, 例: role=button[name=\"Submit\"]", part.Body)
+			}
+		}
+	}
+	return nil
+}
+
+// LocatorMatch 定位器在页面中的匹配情况
+type LocatorMatch struct {
+	Count      int
+	Strict     bool     // 是否要求唯一, 不带前缀的xpath取第一个
+	Candidates []string // 匹配到的元素描述, 最多5个
+}
+
+// MatchLocator 在当前操作的frame中查询定位器匹配到的元素
+func MatchLocator(selector string) (LocatorMatch, error) {
+	if err := ValidateLocator(selector); err != nil {
+		return LocatorMatch{}, err
+	}
+	selectorJson, _ := json.Marshal(selector)
+	js := fmt.Sprintf("(%s).describe(%s, 5)", strings.TrimSpace(chromeLocatorJS), string(selectorJson))
+	value, err := frameEval(js, false, 6*time.Second)
+	if err != nil {
+		return LocatorMatch{}, err
+	}
+	res, _ := value.(map[string]any)
+	match := LocatorMatch{Count: mapInt(res, "count")}
+	match.Strict, _ = res["strict"].(bool)
+	candidates, _ := res["candidates"].([]any)
+	for _, c := range candidates {
+		match.Candidates = append(match.Candidates, fmt.Sprint(c))
+	}
+	return match, nil
+}
+
+// ambiguousErr 匹配到多个元素的错误, 列出候选
+func (m LocatorMatch) ambiguousErr(selector string) error {
+	var buf strings.Builder
+	buf.WriteString(fmt.Sprintf("定位器 %s 匹配到%d个元素, 请使用更精确的定位器, 候选:", selector, m.Count))
+	for i, c := range m.Candidates {
+		buf.WriteString(fmt.Sprintf("\n  %d) %s", i+1, c))
+	}
+	if m.Count > len(m.Candidates) {
+		buf.WriteString(fmt.Sprintf("\n  ...等%d个", m.Count))
+	}
+	return fmt.Errorf("%s", buf.String())
+}
+
+// checkLocator 操作前检查定位器: 格式错误或有歧义时返回错误, 未匹配到元素交给操作本身处理
+func checkLocator(selector string) error {
+	match, err := MatchLocator(selector)
+	if err != nil {
+		return err
+	}
+	if match.Strict && match.Count > 1 {
+		return match.ambiguousErr(selector)
+	}
+	return nil
+}
+
+// locatorObjectId 在当前操作的frame中查找定位器的第一个元素, 返回所在的session与元素的objectId
+func locatorObjectId(selector string) (string, string, error) {
+	sessionId, contextId, err := nowFrameContext()
+	if err != nil {
+		return "", "", err
+	}
+	selectorJson, _ := json.Marshal(selector)
+	js := fmt.Sprintf(`(%s).first(%s)`, strings.TrimSpace(chromeLocatorJS), string(selectorJson))
+	res, err := cdpCall(chromeInstance.NowTabWSConn, sessionId, "Runtime.evaluate", withContextId(map[string]interface{}{
+		"expression":    js,
+		"returnByValue": false,
+	}, contextId), 6*time.Second)
+	if err != nil {
+		return "", "", err
+	}
+	if exception, has := res["exceptionDetails"].(map[string]any); has {
+		return "", "", fmt.Errorf("js执行异常: %s", exceptionMessage(exception))
+	}
+	obj, _ := res["result"].(map[string]any)
+	objectId := mapStr(obj, "objectId")
+	if objectId == "" {
+		return "", "", fmt.Errorf("未找到元素: %s", selector)
+	}
+	return sessionId, objectId, nil
+}
@@ -0,0 +1,436 @@
+(() => {
+    // ChromeBot 定位器, 所有操作的元素查找都通过这里
+    // 1. xpath: 以 / ( ./ 开头, 或使用 xpath= 前缀
+    // 2. css: 使用 css= 前缀, 或不带前缀且不以 / ( 开头的选择器
+    // 3. text=文本 : 文本包含(忽略大小写), text="文本" 完全匹配, text=/正则/i 正则匹配; 返回包含该文本的最内层元素
+    // 4. role=button[name="Submit"] : 按角色(显式role属性或标签隐含的角色)与可访问名称查找, name*= 为包含匹配
+    // 5. label=文本 : 按关联的label(for、包裹、aria-label、aria-labelledby)查找表单控件, 文本匹配规则同 text=
+    // 6. placeholder=文本 : 按placeholder查找, 文本匹配规则同 text=
+    // 7. testid=值 : 按 data-testid 属性查找
+    // 8. >>> : 穿透 shadow root, 后一段在前一段匹配到的元素的 shadowRoot 内查找, 如 css=my-app >>> input[name=q]
+    //    shadow root 内以 / 开头的 xpath 相对 shadowRoot 查找
+    // 除不带前缀的xpath(保持原来取第一个的行为)外, 匹配到多个元素视为有歧义
+
+    const ENGINES = ['css', 'xpath', 'text', 'role', 'label', 'placeholder', 'testid'];
+
+    // 按 >>> 切分, 忽略引号与括号内的内容
+    function splitPierce(selector) {
+        const parts = [];
+        let buf = '';
+        let quote = '';
+        let depth = 0;
+        for (let i = 0; i < selector.length; i++) {
+            const c = selector[i];
+            if (quote) {
+                if (c === quote) quote = '';
+                buf += c;
+                continue;
+            }
+            if (c === '"' || c === "'") {
+                quote = c;
+            } else if (c === '[' || c === '(') {
+                depth++;
+            } else if (c === ']' || c === ')') {
+                depth--;
+            } else if (depth === 0 && selector.startsWith('>>>', i)) {
+                parts.push(buf.trim());
+                buf = '';
+                i += 2;
+                continue;
+            }
+            buf += c;
+        }
+        parts.push(buf.trim());
+        return parts.filter(p => p !== '');
+    }
+
+    // 解析一段选择器为 {engine, body}
+    function parseSegment(part) {
+        const m = /^([a-z]+)=/.exec(part);
+        if (m && ENGINES.includes(m[1])) {
+            return { engine: m[1], body: part.slice(m[0].length).trim() };
+        }
+        if (m) {
+            throw new Error(`不支持的定位器类型: ${m[1]}=, 支持 ${ENGINES.join('=, ')}=`);
+        }
+        if (part.startsWith('/') || part.startsWith('(') || part.startsWith('./')) {
+            return { engine: 'xpath', body: part, bare: true };
+        }
+        return { engine: 'css', body: part };
+    }
+
+    function toElement(node) {
+        if (!node) return null;
+        if (node.nodeType === Node.ELEMENT_NODE) return node;
+        return node.parentElement;
+    }
+
+    function normalize(s) {
+        return (s || '').replace(/\s+/g, ' ').trim();
+    }
+
+    // 文本匹配: "xx" 完全匹配, /xx/flags 正则, 其他为忽略大小写的包含匹配
+    function textMatcher(pattern) {
+        const quoted = /^(["'])([\s\S]*)\1$/.exec(pattern);
+        if (quoted) {
+            const want = normalize(quoted[2]);
+            return s => normalize(s) === want;
+        }
+        const re = /^\/([\s\S]+)\/([a-z]*)$/.exec(pattern);
+        if (re) {
+            const reg = new RegExp(re[1], re[2]);
+            return s => reg.test(normalize(s));
+        }
+        const want = normalize(pattern).toLowerCase();
+        return s => normalize(s).toLowerCase().includes(want);
+    }
+
+    function allElements(root) {
+        return Array.from(root.querySelectorAll('*'));
+    }
+
+    function elementText(el) {
+        if (el.tagName === 'INPUT' && ['button', 'submit', 'reset'].includes((el.type || '').toLowerCase())) {
+            return el.value;
+        }
+        return el.innerText !== undefined ? el.innerText : el.textContent;
+    }
+
+    function queryXPath(expr, root) {
+        if (root.nodeType !== Node.DOCUMENT_NODE && expr.startsWith('/')) {
+            expr = '.' + expr;
+        }
+        const doc = root.ownerDocument || root;
+        const snapshot = doc.evaluate(expr, root, null, XPathResult.ORDERED_NODE_SNAPSHOT_TYPE, null);
+        const res = [];
+        for (let i = 0; i < snapshot.snapshotLength; i++) {
+            const el = toElement(snapshot.snapshotItem(i));
+            if (el) res.push(el);
+        }
+        return res;
+    }
+
+    function queryCss(sel, root) {
+        return Array.from(root.querySelectorAll(sel));
+    }
+
+    const SKIP_TAGS = ['SCRIPT', 'STYLE', 'NOSCRIPT', 'TEMPLATE', 'HEAD', 'TITLE', 'META', 'LINK'];
+
+    // 包含文本的最内层元素
+    function queryText(pattern, root) {
+        const match = textMatcher(pattern);
+        const hits = allElements(root).filter(el => !SKIP_TAGS.includes(el.tagName) && match(elementText(el)));
+        return hits.filter(el => !hits.some(other => other !== el && el.contains(other)));
+    }
+
+    // 标签隐含的角色
+    function implicitRole(el) {
+        const tag = el.tagName.toLowerCase();
+        const type = (el.getAttribute('type') || '').toLowerCase();
+        switch (tag) {
+            case 'button':
+            case 'summary':
+                return 'button';
+            case 'a':
+            case 'area':
+                return el.hasAttribute('href') ? 'link' : '';
+            case 'input':
+                if (['button', 'submit', 'reset', 'image'].includes(type)) return 'button';
+                if (type === 'checkbox') return 'checkbox';
+                if (type === 'radio') return 'radio';
+                if (type === 'range') return 'slider';
+                if (type === 'number') return 'spinbutton';
+                if (type === 'search') return 'searchbox';
+                if (['', 'text', 'email', 'tel', 'url', 'password'].includes(type)) return 'textbox';
+                return '';
+            case 'textarea':
+                return 'textbox';
+            case 'select':
+                return el.multiple || el.size > 1 ? 'listbox' : 'combobox';
+            case 'option':
+                return 'option';
+            case 'img':
+                return el.getAttribute('alt') === '' ? 'presentation' : 'img';
+            case 'h1': case 'h2': case 'h3': case 'h4': case 'h5': case 'h6':
+                return 'heading';
+            case 'ul':
+            case 'ol':
+                return 'list';
+            case 'li':
+                return 'listitem';
+            case 'nav':
+                return 'navigation';
+            case 'form':
+                return 'form';
+            case 'table':
+                return 'table';
+            case 'tr':
+                return 'row';
+            case 'td':
+                return 'cell';
+            case 'th':
+                return 'columnheader';
+            case 'dialog':
+                return 'dialog';
+        }
+        return '';
+    }
+
+    function roleOf(el) {
+        const explicit = (el.getAttribute('role') || '').trim().split(/\s+/)[0];
+        return explicit || implicitRole(el);
+    }
+
+    function labelsText(el) {
+        const texts = [];
+        if (el.labels) {
+            Array.from(el.labels).forEach(l => texts.push(l.innerText || l.textContent));
+        }
+        return texts;
+    }
+
+    function labelledByText(el) {
+        const ids = (el.getAttribute('aria-labelledby') || '').split(/\s+/).filter(Boolean);
+        const root = el.getRootNode();
+        return ids.map(id => {
+            const ref = root.getElementById ? root.getElementById(id) : document.getElementById(id);
+            return ref ? (ref.innerText || ref.textContent) : '';
+        }).join(' ');
+    }
+
+    // 可访问名称(简化版)
+    function accessibleName(el) {
+        const byIds = labelledByText(el);
+        if (normalize(byIds)) return byIds;
+        const aria = el.getAttribute('aria-label');
+        if (normalize(aria)) return aria;
+        const labels = labelsText(el).join(' ');
+        if (normalize(labels)) return labels;
+        const tag = el.tagName;
+        if (tag === 'IMG' || (tag === 'INPUT' && el.type === 'image')) return el.getAttribute('alt') || el.getAttribute('title') || '';
+        if (tag === 'INPUT' && ['button', 'submit', 'reset'].includes((el.type || '').toLowerCase())) return el.value || '';
+        if (tag === 'INPUT' || tag === 'TEXTAREA' || tag === 'SELECT') return el.getAttribute('title') || el.getAttribute('placeholder') || '';
+        return elementText(el) || el.getAttribute('title') || '';
+    }
+
+    // role=button[name="Submit"] 或 role=button[name*="Sub"]
+    function queryRole(body, root) {
+        const m = /^([a-zA-Z]+)\s*(?:\[\s*name\s*(\*?=)\s*([\s\S]*?)\s*\])?$/.exec(body);
+        if (!m) {
+            throw new Error(`role定位器格式错误: ${body}, 例: role=button[name="Submit"]`);
+        }
+        const role = m[1].toLowerCase();
+        let nameMatch = null;
+        if (m[2]) {
+            let value = m[3];
+            const quoted = /^(["'])([\s\S]*)\1$/.exec(value);
+            if (quoted) value = quoted[2];
+            if (m[2] === '=') {
+                const want = normalize(value);
+                nameMatch = s => normalize(s) === want;
+            } else {
+                const want = normalize(value).toLowerCase();
+                nameMatch = s => normalize(s).toLowerCase().includes(want);
+            }
+        }
+        return allElements(root).filter(el => roleOf(el) === role && (!nameMatch || nameMatch(accessibleName(el))));
+    }
+
+    function queryLabel(pattern, root) {
+        const match = textMatcher(pattern);
+        return allElements(root).filter(el => {
+            if (el.hasAttribute('aria-label') && match(el.getAttribute('aria-label'))) return true;
+            if (el.hasAttribute('aria-labelledby') && match(labelledByText(el))) return true;
+            return labelsText(el).some(t => match(t));
+        });
+    }
+
+    function queryPlaceholder(pattern, root) {
+        const match = textMatcher(pattern);
+        return allElements(root).filter(el => el.hasAttribute('placeholder') && match(el.getAttribute('placeholder')));
+    }
+
+    function queryTestId(value, root) {
+        const quoted = /^(["'])([\s\S]*)\1$/.exec(value);
+        if (quoted) value = quoted[2];
+        return allElements(root).filter(el => el.getAttribute('data-testid') === value);
+    }
+
+    function querySegment(seg, root) {
+        switch (seg.engine) {
+            case 'xpath': return queryXPath(seg.body, root);
+            case 'css': return queryCss(seg.body, root);
+            case 'text': return queryText(seg.body, root);
+            case 'role': return queryRole(seg.body, root);
+            case 'label': return queryLabel(seg.body, root);
+            case 'placeholder': return queryPlaceholder(seg.body, root);
+            case 'testid': return queryTestId(seg.body, root);
+        }
+        return [];
+    }
+
+    function all(selector) {
+        const segs = splitPierce(selector).map(parseSegment);
+        let roots = [document];
+        let found = [];
+        segs.forEach((seg, i) => {
+            if (i > 0) {
+                roots = found.map(el => el.shadowRoot).filter(Boolean);
+            }
+            found = [];
+            roots.forEach(root => {
+                querySegment(seg, root).forEach(el => {
+                    if (!found.includes(el)) found.push(el);
+                });
+            });
+        });
+        return found;
+    }
+
+    // 不带前缀的xpath保持取第一个的行为, 其他定位器要求唯一
+    function strict(selector) {
+        const segs = splitPierce(selector);
+        if (segs.length === 0) return false;
+        return !parseSegment(segs[segs.length - 1]).bare;
+    }
+
+    // 元素的简短描述, 用于歧义时列出候选
+    function describeElement(el) {
+        let s = '<' + el.tagName.toLowerCase();
+        if (el.id) s += ' id="' + el.id + '"';
+        ['name', 'type', 'role', 'data-testid', 'aria-label', 'placeholder'].forEach(attr => {
+            if (el.hasAttribute(attr)) s += ' ' + attr + '="' + el.getAttribute(attr) + '"';
+        });
+        const cls = (el.getAttribute('class') || '').trim();
+        if (cls) s += ' class="' + cls.slice(0, 40) + '"';
+        s += '>';
+        const text = normalize(elementText(el)).slice(0, 30);
+        if (text) s += text;
+        return s;
+    }
+
+    function describe(selector, max) {
+        const found = all(selector);
+        return {
+            count: found.length,
+            strict: strict(selector),
+            candidates: found.slice(0, max || 5).map(describeElement)
+        };
+    }
+
+    // 元素是否被其他元素遮挡: 取元素中心点命中的元素, 穿透 shadow root
+    function hitTarget(el, rect) {
+        const x = rect.left + rect.width / 2;
+        const y = rect.top + rect.height / 2;
+        let hit = document.elementFromPoint(x, y);
+        while (hit && hit.shadowRoot) {
+            const inner = hit.shadowRoot.elementFromPoint(x, y);
+            if (!inner || inner === hit) break;
+            hit = inner;
+        }
+        if (!hit) return null;
+        // 命中元素自身、子孙元素或 label 关联的控件都不算遮挡
+        let node = hit;
+        while (node) {
+            if (node === el) return null;
+            node = node.parentNode || node.host;
+        }
+        if (hit.tagName === 'LABEL' && hit.control === el) return null;
+        return hit;
+    }
+
+    // 元素不可见的原因, 可见时为空
+    function hiddenReason(el) {
+        if (!el.isConnected) return '不在DOM中';
+        const style = window.getComputedStyle(el);
+        const rect = el.getBoundingClientRect();
+        if (style.visibility === 'hidden' || style.visibility === 'collapse') return 'visibility:' + style.visibility;
+        if (style.display === 'none') return 'display:none';
+        if (rect.width === 0 || rect.height === 0) return '尺寸为0';
+        if (parseFloat(style.opacity) === 0) return 'opacity:0';
+        return '';
+    }
+
+    // 元素状态, 用于 waitfor: 匹配数量与第一个元素是否可见
+    function stateOf(selector) {
+        const el = all(selector)[0] || null;
+        return {
+            attached: el !== null,
+            visible: el !== null && hiddenReason(el) === ''
+        };
+    }
+
+    function nextFrame() {
+        // 后台tab中 requestAnimationFrame 可能不触发, 用定时器兜底
+        return new Promise(resolve => {
+            let done = false;
+            const finish = () => { if (!done) { done = true; resolve(); } };
+            requestAnimationFrame(() => requestAnimationFrame(finish));
+            setTimeout(finish, 100);
+        });
+    }
+
+    // 可操作性检查(见 chrome_wait.go): 唯一、在DOM中、可见、稳定(不在动画中)、可用、未被遮挡
+    // opts.editable 要求可编辑(输入), opts.hit 要求未被遮挡(点击)
+    async function actionability(selector, opts) {
+        opts = opts || {};
+        const found = all(selector);
+        const res = {
+            count: found.length,
+            strict: strict(selector),
+            candidates: found.slice(0, 5).map(describeElement),
+            attached: false,
+            visible: false,
+            stable: false,
+            enabled: false,
+            editable: false,
+            covered: '',
+            reason: ''
+        };
+        if (found.length === 0 || (res.strict && found.length > 1)) {
+            return res;
+        }
+        const el = found[0];
+        res.element = describeElement(el);
+        res.attached = el.isConnected;
+        if (!res.attached) return res;
+
+        res.reason = hiddenReason(el);
+        res.visible = res.reason === '';
+        let rect = el.getBoundingClientRect();
+
+        res.enabled = !(el.disabled || el.getAttribute('aria-disabled') === 'true' || el.closest('fieldset[disabled]'));
+        res.editable = res.enabled && !el.readOnly && (
+            el.isContentEditable || ['INPUT', 'TEXTAREA', 'SELECT'].includes(el.tagName)
+        );
+        if (!res.visible) return res;
+
+        // 不在视口内先滚动到视口中间
+        if (rect.bottom < 0 || rect.right < 0 || rect.top > window.innerHeight || rect.left > window.innerWidth) {
+            el.scrollIntoView({ behavior: 'auto', block: 'center', inline: 'center' });
+        }
+        rect = el.getBoundingClientRect();
+        await nextFrame();
+        const after = el.getBoundingClientRect();
+        res.stable = rect.top === after.top && rect.left === after.left && rect.width === after.width && rect.height === after.height;
+        if (!res.stable) {
+            res.reason = '位置或尺寸在变化(动画中)';
+            return res;
+        }
+        if (opts.hit) {
+            const cover = hitTarget(el, after);
+            if (cover) {
+                res.covered = describeElement(cover);
+            }
+        }
+        return res;
+    }
+
+    return {
+        all: all,
+        first: (selector) => all(selector)[0] || null,
+        describe: describe,
+        actionability: actionability,
+        stateOf: stateOf
+    };
+})()
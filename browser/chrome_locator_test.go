@@ -0,0 +1,52 @@
+package browser
+
+import (
+	"testing"
+)
+
+func TestParseLocator(t *testing.T) {
+	cases := []struct {
+		selector string
+		want     []LocatorPart
+	}{
+		{`//*[@id="kw"]`, []LocatorPart{{Engine: "xpath", Body: `//*[@id="kw"]`, Bare: true}}},
+		{`xpath=//div`, []LocatorPart{{Engine: "xpath", Body: `//div`}}},
+		{`css=#kw`, []LocatorPart{{Engine: "css", Body: `#kw`}}},
+		{`input[name=q]`, []LocatorPart{{Engine: "css", Body: `input[name=q]`}}},
+		{`text="百度一下"`, []LocatorPart{{Engine: "text", Body: `"百度一下"`}}},
+		{`role=button[name="Submit >>> now"]`, []LocatorPart{{Engine: "role", Body: `button[name="Submit >>> now"]`}}},
+		{`label=邮箱`, []LocatorPart{{Engine: "label", Body: `邮箱`}}},
+		{`placeholder=请输入`, []LocatorPart{{Engine: "placeholder", Body: `请输入`}}},
+		{`testid=login-btn`, []LocatorPart{{Engine: "testid", Body: `login-btn`}}},
+		{`css=my-app >>> role=textbox`, []LocatorPart{{Engine: "css", Body: `my-app`}, {Engine: "role", Body: `textbox`}}},
+	}
+	for _, c := range cases {
+		got, err := ParseLocator(c.selector)
+		if err != nil {
+			t.Fatalf("%s: %v", c.selector, err)
+		}
+		if len(got) != len(c.want) {
+			t.Fatalf("%s: got %v, want %v", c.selector, got, c.want)
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Errorf("%s: got %v, want %v", c.selector, got[i], c.want[i])
+			}
+		}
+	}
+}
+
+func TestValidateLocator(t *testing.T) {
+	ok := []string{`//a[@href]`, `css=a.link`, `role=button[name="Submit"]`, `role=link[name*=更多]`, `text=/登录|注册/i`}
+	for _, s := range ok {
+		if err := ValidateLocator(s); err != nil {
+			t.Errorf("%s: %v", s, err)
+		}
+	}
+	bad := []string{``, `foo=bar`, `css=`, `role=button[title="x"]`, `//a[`}
+	for _, s := range bad {
+		if err := ValidateLocator(s); err == nil {
+			t.Errorf("%s: 期望返回错误", s)
+		}
+	}
+}
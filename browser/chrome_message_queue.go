@@ -1,20 +1,168 @@
 package browser
 
-// 定义全局消息通道
+import (
+	"ChromeBot/utils"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	gt "github.com/mangenotwork/gathertool"
+)
+
+// mess 带id的回复
 type mess struct {
 	ID      int
 	Content string
 }
 
-var messageQueue = make(chan mess, 100) // 缓冲队列
+// 等待回复的请求: 发送前按id登记接收回复的通道, 读ws的协程收到回复后投递到对应的通道;
+// 没有登记的回复(不等待回复的指令、已经超时的请求)直接丢弃, 读ws的协程不会因此阻塞
+var (
+	pendingMu sync.Mutex
+	pending   = make(map[int]chan mess)
+	msgSeq    int // 自增消息id, tab与浏览器的连接共用, 保证id不重复
+)
+
+var wsWriteMu sync.Mutex // gorilla websocket 不支持并发写
 
 var ConnTabDone = make(chan struct{})
 
-// GetNextMsgID 获取自增的消息ID（线程安全）
+// GetNextMsgID 获取自增的消息ID（线程安全）, 用于不等待回复的指令
 func GetNextMsgID() int {
-	mu.Lock()
-	defer mu.Unlock()
-	id := chromeInstance.NextID
-	chromeInstance.NextID++
-	return id
+	pendingMu.Lock()
+	defer pendingMu.Unlock()
+	msgSeq++
+	return msgSeq
+}
+
+// nextReply 获取自增的消息ID并登记接收回复的通道, 不再等待时用 dropReply 注销
+func nextReply() (int, chan mess) {
+	ch := make(chan mess, 1)
+	pendingMu.Lock()
+	defer pendingMu.Unlock()
+	msgSeq++
+	pending[msgSeq] = ch
+	return msgSeq, ch
+}
+
+// dropReply 注销接收回复的通道
+func dropReply(id int) {
+	pendingMu.Lock()
+	delete(pending, id)
+	pendingMu.Unlock()
+}
+
+// deliverReply 把回复投递到登记的通道, 每个id只投递一次, 通道有1个缓冲所以不会阻塞
+func deliverReply(msg mess) {
+	pendingMu.Lock()
+	ch, ok := pending[msg.ID]
+	delete(pending, msg.ID)
+	pendingMu.Unlock()
+	if !ok {
+		utils.Debugf("没有等待的回复, 丢弃: id=%d", msg.ID)
+		return
+	}
+	ch <- msg
+}
+
+// wsWrite 加锁写入一条消息, 所有向tab与浏览器ws发送的指令都经过这里
+func wsWrite(conn *websocket.Conn, message []byte) error {
+	if conn == nil {
+		return fmt.Errorf("ws连接未建立")
+	}
+	wsWriteMu.Lock()
+	defer wsWriteMu.Unlock()
+	return conn.WriteMessage(websocket.TextMessage, message)
+}
+
+// wsWriteJSON 同 wsWrite, 消息为json对象
+func wsWriteJSON(conn *websocket.Conn, msg any) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return wsWrite(conn, data)
+}
+
+// handleWSMessage 处理tab与浏览器ws收到的消息: 事件分发与带id的回复投递给等待的请求
+func handleWSMessage(message []byte) {
+	msgDebug := string(message)
+	if len(msgDebug) > 4000 {
+		msgDebug = msgDebug[0:4000] + " --> 太多了省略 ..."
+	}
+
+	utils.Debugf("=====> 收到服务器回复: %s", msgDebug)
+
+	// 网络请求的记录通过事件监听实现, 见 chrome_network.go 与 chrome_netlog.go
+
+	result, err := gt.Json2Map(string(message))
+	if err != nil {
+		gt.Error("回复内容解析错误")
+		return
+	}
+
+	// 提取sessionId
+	sessionId, sessionIdOK := result["sessionId"].(string)
+	if !sessionIdOK {
+		sessionId = ""
+	}
+
+	// 监听页面加载事件
+	method, methodOK := result["method"].(string)
+	if methodOK {
+		switch method {
+		case "Page.loadEventFired":
+			if sessionId != "" {
+				// 使用select+default，避免NowPageLoadEventFired无缓冲时阻塞
+				select {
+				case NowPageLoadEventFired <- sessionId:
+					utils.Debugf("发送页面加载事件，sessionId: %s", sessionId)
+				default:
+					utils.Debugf("NowPageLoadEventFired通道阻塞，跳过发送: %s", sessionId)
+				}
+			}
+
+		case "Page.frameNavigated":
+			// 记录当前tab主frame的地址，断线恢复时用于还原页面
+			params, _ := result["params"].(map[string]any)
+			frame, _ := params["frame"].(map[string]any)
+			if _, hasParent := frame["parentId"]; !hasParent {
+				if c := chromeInstance; c != nil && sessionId != "" && sessionId == c.NowTabSession {
+					c.LastURL = mapStr(frame, "url")
+				}
+			}
+		}
+
+		params, _ := result["params"].(map[string]any)
+		dispatchEvent(method, sessionId, params)
+	}
+
+	id, ok := result["id"].(float64)
+	if ok {
+		deliverReply(mess{
+			ID:      int(id),
+			Content: string(message),
+		})
+	}
+}
+
+// isWSClosedErr 判断ws读错误是否是连接已断开
+func isWSClosedErr(err error) bool {
+	if err == nil {
+		return false
+	}
+	if err == io.EOF {
+		return true
+	}
+	if _, ok := err.(*websocket.CloseError); ok {
+		return true
+	}
+	errStr := err.Error()
+	return strings.Contains(errStr, "unexpected EOF") ||
+		strings.Contains(errStr, "use of closed network connection") ||
+		strings.Contains(errStr, "connection reset") ||
+		strings.Contains(errStr, "forcibly closed")
 }
@@ -0,0 +1,83 @@
+package browser
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDeliverReply(t *testing.T) {
+	id1, ch1 := nextReply()
+	id2, ch2 := nextReply()
+	defer dropReply(id1)
+	defer dropReply(id2)
+	if id1 == id2 {
+		t.Fatalf("消息id重复: %d", id1)
+	}
+
+	// 没有登记的回复直接丢弃, 不阻塞
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 200; i++ {
+			deliverReply(mess{ID: -1 - i})
+		}
+		deliverReply(mess{ID: id2, Content: "b"})
+		deliverReply(mess{ID: id1, Content: "a"})
+		// 同一个id只投递一次
+		deliverReply(mess{ID: id1, Content: "again"})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("投递回复阻塞")
+	}
+	if m := <-ch1; m.Content != "a" {
+		t.Errorf("id1 收到 %q", m.Content)
+	}
+	if m := <-ch2; m.Content != "b" {
+		t.Errorf("id2 收到 %q", m.Content)
+	}
+	select {
+	case m := <-ch1:
+		t.Errorf("重复投递: %q", m.Content)
+	default:
+	}
+
+	// 注销后的回复丢弃
+	id3, ch3 := nextReply()
+	dropReply(id3)
+	deliverReply(mess{ID: id3})
+	select {
+	case <-ch3:
+		t.Error("注销后仍收到回复")
+	default:
+	}
+}
+
+func TestNextReplyConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	ids := make(chan int, 400)
+	for i := 0; i < 200; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			id, _ := nextReply()
+			dropReply(id)
+			ids <- id
+		}()
+		go func() {
+			defer wg.Done()
+			ids <- GetNextMsgID()
+		}()
+	}
+	wg.Wait()
+	close(ids)
+	seen := map[int]bool{}
+	for id := range ids {
+		if seen[id] {
+			t.Fatalf("消息id重复: %d", id)
+		}
+		seen[id] = true
+	}
+}
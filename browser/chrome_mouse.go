@@ -0,0 +1,326 @@
+package browser
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+/*
+原生鼠标操作
+
+通过 DOM.getBoxModel 计算元素中心坐标, 再用 Input.dispatchMouseEvent 发送真实的鼠标事件(isTrusted 为 true),
+支持悬停、双击、右键、拖放和移动到坐标; human 为 true 时鼠标沿贝塞尔曲线分步移动, 模拟真人轨迹
+拖放时开启 Input.setInterceptDrags, 页面发起的 HTML5 拖拽通过 Input.dispatchDragEvent 完成放下
+*/
+
+// 鼠标当前位置, 用于计算移动轨迹
+var (
+	mouseMu sync.Mutex
+	mouseX  float64
+	mouseY  float64
+)
+
+// Point 坐标, 相对于页面视口
+type Point struct {
+	X float64
+	Y float64
+}
+
+var actionHover = Actionability{Visible: true}
+
+// ElementCenter 元素中心点坐标, 会先等待元素可见并滚动到视口中
+func ElementCenter(selector string, timeout time.Duration) (Point, error) {
+	return elementCenter(selector, actionHover, timeout)
+}
+
+func elementCenter(selector string, need Actionability, timeout time.Duration) (Point, error) {
+	if err := waitActionable(selector, need, timeout); err != nil {
+		return Point{}, err
+	}
+	sessionId, objectId, err := locatorObjectId(selector)
+	if err != nil {
+		return Point{}, err
+	}
+	box, err := cdpCall(chromeInstance.NowTabWSConn, sessionId, "DOM.getBoxModel", map[string]any{"objectId": objectId}, 6*time.Second)
+	if err != nil {
+		return Point{}, err
+	}
+	model, _ := box["model"].(map[string]any)
+	p, err := quadCenter(model["content"])
+	if err != nil {
+		return Point{}, fmt.Errorf("%s: %w", selector, err)
+	}
+
+	// 跨进程的iframe坐标相对于iframe, 需要加上iframe在页面中的位置
+	if sessionId != chromeInstance.NowTabSession {
+		offset, err := frameOffset()
+		if err != nil {
+			return Point{}, err
+		}
+		p.X += offset.X
+		p.Y += offset.Y
+	}
+	return p, nil
+}
+
+// quadCenter 四边形 [x1,y1,x2,y2,x3,y3,x4,y4] 的中心
+func quadCenter(v any) (Point, error) {
+	quad, _ := v.([]any)
+	if len(quad) != 8 {
+		return Point{}, fmt.Errorf("获取元素位置失败")
+	}
+	p := Point{}
+	for i := 0; i < 8; i += 2 {
+		x, _ := quad[i].(float64)
+		y, _ := quad[i+1].(float64)
+		p.X += x / 4
+		p.Y += y / 4
+	}
+	return p, nil
+}
+
+// frameOffset 当前跨进程iframe的内容区域左上角在页面中的位置
+func frameOffset() (Point, error) {
+	frameId := NowFrame()
+	owner, err := tabCall("DOM.getFrameOwner", map[string]any{"frameId": frameId})
+	if err != nil {
+		return Point{}, err
+	}
+	box, err := tabCall("DOM.getBoxModel", map[string]any{"backendNodeId": mapInt(owner, "backendNodeId")})
+	if err != nil {
+		return Point{}, err
+	}
+	model, _ := box["model"].(map[string]any)
+	quad, _ := model["content"].([]any)
+	if len(quad) != 8 {
+		return Point{}, fmt.Errorf("获取iframe位置失败")
+	}
+	x, _ := quad[0].(float64)
+	y, _ := quad[1].(float64)
+	return Point{X: x, Y: y}, nil
+}
+
+// dispatchMouse 发送一次鼠标事件
+func dispatchMouse(eventType string, p Point, button string, buttons, clickCount int) error {
+	params := map[string]any{
+		"type":    eventType,
+		"x":       p.X,
+		"y":       p.Y,
+		"button":  button,
+		"buttons": buttons,
+	}
+	if clickCount > 0 {
+		params["clickCount"] = clickCount
+	}
+	_, err := tabCall("Input.dispatchMouseEvent", params)
+	return err
+}
+
+// humanPath 从from到to的真人鼠标轨迹: 三次贝塞尔曲线, 控制点在连线两侧随机偏移
+func humanPath(from, to Point) []Point {
+	dist := math.Hypot(to.X-from.X, to.Y-from.Y)
+	if dist < 1 {
+		return []Point{to}
+	}
+	steps := int(dist / 15)
+	if steps < 5 {
+		steps = 5
+	}
+	if steps > 40 {
+		steps = 40
+	}
+	// 垂直于连线的方向
+	nx, ny := -(to.Y-from.Y)/dist, (to.X-from.X)/dist
+	spread := math.Min(dist*0.25, 80)
+	c1 := Point{
+		X: from.X + (to.X-from.X)*0.3 + nx*spread*(rand.Float64()*2-1),
+		Y: from.Y + (to.Y-from.Y)*0.3 + ny*spread*(rand.Float64()*2-1),
+	}
+	c2 := Point{
+		X: from.X + (to.X-from.X)*0.7 + nx*spread*(rand.Float64()*2-1),
+		Y: from.Y + (to.Y-from.Y)*0.7 + ny*spread*(rand.Float64()*2-1),
+	}
+
+	path := make([]Point, 0, steps)
+	for i := 1; i <= steps; i++ {
+		// 先快后慢
+		t := float64(i) / float64(steps)
+		t = 1 - (1-t)*(1-t)
+		mt := 1 - t
+		path = append(path, Point{
+			X: mt*mt*mt*from.X + 3*mt*mt*t*c1.X + 3*mt*t*t*c2.X + t*t*t*to.X,
+			Y: mt*mt*mt*from.Y + 3*mt*mt*t*c1.Y + 3*mt*t*t*c2.Y + t*t*t*to.Y,
+		})
+	}
+	path[len(path)-1] = to
+	return path
+}
+
+// moveMouse 移动鼠标到to, buttons为移动时按住的键(拖动时为1)
+func moveMouse(to Point, human bool, buttons int) error {
+	mouseMu.Lock()
+	from := Point{X: mouseX, Y: mouseY}
+	mouseMu.Unlock()
+
+	path := []Point{to}
+	if human {
+		path = humanPath(from, to)
+	}
+	button := "none"
+	if buttons == 1 {
+		button = "left"
+	}
+	for _, p := range path {
+		if err := dispatchMouse("mouseMoved", p, button, buttons, 0); err != nil {
+			return err
+		}
+		mouseMu.Lock()
+		mouseX, mouseY = p.X, p.Y
+		mouseMu.Unlock()
+		if human {
+			time.Sleep(time.Duration(8+rand.Intn(10)) * time.Millisecond)
+		}
+	}
+	return nil
+}
+
+// clickAt 在坐标处点击, button: left right middle; count: 1单击 2双击
+func clickAt(p Point, button string, count int, human bool) error {
+	if err := moveMouse(p, human, 0); err != nil {
+		return err
+	}
+	buttons := map[string]int{"left": 1, "right": 2, "middle": 4}[button]
+	for i := 1; i <= count; i++ {
+		if err := dispatchMouse("mousePressed", p, button, buttons, i); err != nil {
+			return err
+		}
+		if human {
+			time.Sleep(time.Duration(40+rand.Intn(60)) * time.Millisecond)
+		}
+		if err := dispatchMouse("mouseReleased", p, button, 0, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Hover 鼠标悬停到元素上
+func Hover(selector string, human bool, timeout time.Duration) error {
+	if !DefaultNowTab(true) {
+		return notReadyErr()
+	}
+	p, err := ElementCenter(selector, timeout)
+	if err != nil {
+		return err
+	}
+	log.Printf("[Chrome]悬停: %s (%.0f, %.0f)", selector, p.X, p.Y)
+	return moveMouse(p, human, 0)
+}
+
+// mouseClickElement 在元素中心点击
+func mouseClickElement(selector, button string, count int, human bool, timeout time.Duration) error {
+	if !DefaultNowTab(true) {
+		return notReadyErr()
+	}
+	p, err := elementCenter(selector, actionClick, timeout)
+	if err != nil {
+		return err
+	}
+	log.Printf("[Chrome]鼠标%s x%d: %s (%.0f, %.0f)", button, count, selector, p.X, p.Y)
+	return clickAt(p, button, count, human)
+}
+
+// DblClick 双击元素
+func DblClick(selector string, human bool, timeout time.Duration) error {
+	return mouseClickElement(selector, "left", 2, human, timeout)
+}
+
+// RightClick 右键点击元素
+func RightClick(selector string, human bool, timeout time.Duration) error {
+	return mouseClickElement(selector, "right", 1, human, timeout)
+}
+
+// MouseAction 在页面坐标处执行鼠标操作, action: move(默认) click dblclick rightclick
+func MouseAction(action string, x, y float64, human bool) error {
+	if !DefaultNowTab(true) {
+		return notReadyErr()
+	}
+	p := Point{X: x, Y: y}
+	log.Printf("[Chrome]鼠标%s: (%.0f, %.0f)", action, x, y)
+	switch action {
+	case "", "move":
+		return moveMouse(p, human, 0)
+	case "click":
+		return clickAt(p, "left", 1, human)
+	case "dblclick":
+		return clickAt(p, "left", 2, human)
+	case "rightclick":
+		return clickAt(p, "right", 1, human)
+	}
+	return fmt.Errorf("不支持的鼠标操作: %s, 支持 move click dblclick rightclick", action)
+}
+
+// DragAndDrop 把from元素拖放到to元素上
+// 页面使用HTML5拖拽时拦截拖拽数据并通过 Input.dispatchDragEvent 放下, 否则按鼠标按下-移动-松开完成
+func DragAndDrop(from, to string, human bool, timeout time.Duration) error {
+	if !DefaultNowTab(true) {
+		return notReadyErr()
+	}
+	start, err := ElementCenter(from, timeout)
+	if err != nil {
+		return err
+	}
+	end, err := ElementCenter(to, timeout)
+	if err != nil {
+		return err
+	}
+	log.Printf("[Chrome]拖放: %s (%.0f, %.0f) -> %s (%.0f, %.0f)", from, start.X, start.Y, to, end.X, end.Y)
+
+	// 拦截HTML5拖拽
+	dragData := make(chan map[string]any, 1)
+	listenId := OnEvent("Input.dragIntercepted", func(method, sessionId string, params map[string]any) {
+		if data, ok := params["data"].(map[string]any); ok {
+			select {
+			case dragData <- data:
+			default:
+			}
+		}
+	})
+	defer OffEvent(listenId)
+	if _, err = tabCall("Input.setInterceptDrags", map[string]any{"enabled": true}); err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = tabCall("Input.setInterceptDrags", map[string]any{"enabled": false})
+	}()
+
+	if err = moveMouse(start, human, 0); err != nil {
+		return err
+	}
+	if err = dispatchMouse("mousePressed", start, "left", 1, 1); err != nil {
+		return err
+	}
+	// 先小幅移动触发拖拽开始, 再移动到目标
+	if err = moveMouse(Point{X: start.X + 5, Y: start.Y + 5}, false, 1); err != nil {
+		return err
+	}
+	if err = moveMouse(end, human, 1); err != nil {
+		return err
+	}
+
+	select {
+	case data := <-dragData:
+		for _, t := range []string{"dragEnter", "dragOver", "drop"} {
+			_, err = tabCall("Input.dispatchDragEvent", map[string]any{"type": t, "x": end.X, "y": end.Y, "data": data})
+			if err != nil {
+				return err
+			}
+		}
+	case <-time.After(100 * time.Millisecond):
+	}
+	return dispatchMouse("mouseReleased", end, "left", 0, 1)
+}
@@ -0,0 +1,29 @@
+package browser
+
+import "testing"
+
+func TestHumanPath(t *testing.T) {
+	from, to := Point{X: 10, Y: 10}, Point{X: 610, Y: 410}
+	path := humanPath(from, to)
+	if len(path) < 5 || len(path) > 40 {
+		t.Fatalf("轨迹点数量不合理: %d", len(path))
+	}
+	if path[len(path)-1] != to {
+		t.Errorf("轨迹终点应为目标点, got %v", path[len(path)-1])
+	}
+
+	// 原地不动只有目标点
+	if path = humanPath(to, to); len(path) != 1 || path[0] != to {
+		t.Errorf("原地移动的轨迹应只有目标点, got %v", path)
+	}
+}
+
+func TestQuadCenter(t *testing.T) {
+	p, err := quadCenter([]any{10.0, 20.0, 110.0, 20.0, 110.0, 60.0, 10.0, 60.0})
+	if err != nil || p != (Point{X: 60, Y: 40}) {
+		t.Errorf("got %v %v", p, err)
+	}
+	if _, err := quadCenter(nil); err == nil {
+		t.Errorf("期望返回错误")
+	}
+}
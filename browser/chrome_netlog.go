@@ -0,0 +1,550 @@
+package browser
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+网络请求记录与HAR导出
+
+开始记录后通过 Network.requestWillBeSent / responseReceived / dataReceived / loadingFinished / loadingFailed 事件
+记录当前tab的每个请求(包括XHR、fetch), 重定向的每一跳是单独的一条记录;
+响应体按需通过 Network.getResponseBody 获取, 浏览器只在页面未跳转前保留响应体, 跳转后之前页面的响应体可能取不到
+导出的HAR为1.2版本: http://www.softwareishard.com/blog/har-12-spec/
+*/
+
+// NetEntry 一条网络请求记录
+type NetEntry struct {
+	RequestId       string
+	SessionId       string
+	URL             string
+	Method          string
+	Type            string // Document XHR Fetch Script Image ...
+	RequestHeaders  map[string]string
+	PostData        string
+	WallTime        float64 // 请求开始的时间, 秒
+	StartTime       float64 // 请求开始的单调时钟, 秒
+	Status          int
+	StatusText      string
+	Protocol        string
+	MimeType        string
+	ResponseHeaders map[string]string
+	RemoteIP        string
+	Timing          map[string]any
+	FromCache       bool
+	ResponseTime    float64 // 收到响应头的单调时钟, 秒
+	EndTime         float64 // 请求结束的单调时钟, 秒
+	DataLength      int     // 解码后的响应体大小
+	EncodedLength   int     // 传输的大小
+	Finished        bool
+	Error           string
+	RedirectURL     string
+	Body            string
+	Base64          bool // Body 是否是base64编码(二进制内容)
+	BodyLoaded      bool
+}
+
+// Duration 请求耗时, 毫秒
+func (e *NetEntry) Duration() float64 {
+	if e.EndTime == 0 || e.StartTime == 0 {
+		return -1
+	}
+	return (e.EndTime - e.StartTime) * 1000
+}
+
+type netRecorder struct {
+	mu       sync.Mutex
+	listenId int
+	entries  []*NetEntry
+	pending  map[string]*NetEntry // requestId -> 请求记录, 重定向后指向新的一跳
+}
+
+var netlog = &netRecorder{}
+
+// NetlogStart 开始记录当前tab的网络请求, 已在记录时清空之前的记录
+func NetlogStart() error {
+	if err := ensureNetwork(); err != nil {
+		return err
+	}
+	netlog.mu.Lock()
+	defer netlog.mu.Unlock()
+	netlog.entries = make([]*NetEntry, 0)
+	netlog.pending = make(map[string]*NetEntry)
+	if netlog.listenId == 0 {
+		netlog.listenId = OnEvent("Network.*", netlog.onEvent)
+	}
+	log.Println("[Chrome]开始记录网络请求")
+	return nil
+}
+
+// NetlogStop 停止记录并返回记录到的请求
+func NetlogStop() []NetEntry {
+	netlog.mu.Lock()
+	if netlog.listenId != 0 {
+		OffEvent(netlog.listenId)
+		netlog.listenId = 0
+	}
+	netlog.mu.Unlock()
+	entries := NetlogEntries()
+	log.Printf("[Chrome]停止记录网络请求, 共%d条", len(entries))
+	return entries
+}
+
+// NetlogEntries 当前记录到的请求的副本
+func NetlogEntries() []NetEntry {
+	netlog.mu.Lock()
+	defer netlog.mu.Unlock()
+	res := make([]NetEntry, 0, len(netlog.entries))
+	for _, e := range netlog.entries {
+		res = append(res, *e)
+	}
+	return res
+}
+
+func (n *netRecorder) onEvent(method, sessionId string, params map[string]any) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.pending == nil || sessionId == "" {
+		return
+	}
+	requestId := mapStr(params, "requestId")
+	timestamp, _ := params["timestamp"].(float64)
+
+	if method == "Network.requestWillBeSent" {
+		request, _ := params["request"].(map[string]any)
+		// 重定向: 同一个requestId的上一跳以重定向响应结束
+		if prev, ok := n.pending[requestId]; ok {
+			if redirect, has := params["redirectResponse"].(map[string]any); has {
+				prev.applyResponse(redirect, timestamp)
+			}
+			prev.RedirectURL = mapStr(request, "url")
+			prev.EndTime = timestamp
+			prev.Finished = true
+		}
+		wallTime, _ := params["wallTime"].(float64)
+		e := &NetEntry{
+			RequestId:      requestId,
+			SessionId:      sessionId,
+			URL:            mapStr(request, "url"),
+			Method:         mapStr(request, "method"),
+			Type:           mapStr(params, "type"),
+			RequestHeaders: headerMap(request["headers"]),
+			PostData:       mapStr(request, "postData"),
+			WallTime:       wallTime,
+			StartTime:      timestamp,
+		}
+		n.entries = append(n.entries, e)
+		n.pending[requestId] = e
+		return
+	}
+
+	e, ok := n.pending[requestId]
+	if !ok || e.SessionId != sessionId {
+		return
+	}
+	switch method {
+	case "Network.responseReceived":
+		response, _ := params["response"].(map[string]any)
+		e.applyResponse(response, timestamp)
+		if t := mapStr(params, "type"); t != "" {
+			e.Type = t
+		}
+	case "Network.requestServedFromCache":
+		e.FromCache = true
+	case "Network.dataReceived":
+		e.DataLength += mapInt(params, "dataLength")
+	case "Network.loadingFinished":
+		e.EncodedLength = mapInt(params, "encodedDataLength")
+		e.EndTime = timestamp
+		e.Finished = true
+	case "Network.loadingFailed":
+		e.Error = mapStr(params, "errorText")
+		if canceled, _ := params["canceled"].(bool); canceled && e.Error == "" {
+			e.Error = "canceled"
+		}
+		e.EndTime = timestamp
+		e.Finished = true
+	}
+}
+
+func (e *NetEntry) applyResponse(response map[string]any, timestamp float64) {
+	e.Status = mapInt(response, "status")
+	e.StatusText = mapStr(response, "statusText")
+	e.Protocol = mapStr(response, "protocol")
+	e.MimeType = mapStr(response, "mimeType")
+	e.ResponseHeaders = headerMap(response["headers"])
+	e.RemoteIP = mapStr(response, "remoteIPAddress")
+	e.Timing, _ = response["timing"].(map[string]any)
+	e.ResponseTime = timestamp
+	if fromDisk, _ := response["fromDiskCache"].(bool); fromDisk {
+		e.FromCache = true
+	}
+	// 响应头中带有实际发送的请求头时以它为准
+	if headers, ok := response["requestHeaders"]; ok {
+		e.RequestHeaders = headerMap(headers)
+	}
+}
+
+// headerMap cdp的头信息对象转为 map[string]string
+func headerMap(v any) map[string]string {
+	res := make(map[string]string)
+	obj, _ := v.(map[string]any)
+	for k, val := range obj {
+		res[k] = fmt.Sprint(val)
+	}
+	return res
+}
+
+// NetlogLoadBodies 获取已完成请求的响应体, 取不到的忽略
+func NetlogLoadBodies(entries []NetEntry) {
+	if !DefaultNowTab(false) {
+		return
+	}
+	for i := range entries {
+		e := &entries[i]
+		if e.BodyLoaded || !e.Finished || e.Error != "" || e.RedirectURL != "" || e.Status == 204 {
+			continue
+		}
+		res, err := cdpCall(chromeInstance.NowTabWSConn, e.SessionId, "Network.getResponseBody", map[string]any{"requestId": e.RequestId}, 6*time.Second)
+		if err != nil {
+			log.Printf("[Chrome]获取响应体失败: %s %s", e.URL, err.Error())
+			continue
+		}
+		e.Body = mapStr(res, "body")
+		e.Base64, _ = res["base64Encoded"].(bool)
+		e.BodyLoaded = true
+	}
+}
+
+// HAR 1.2 的结构
+
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+type HARLog struct {
+	Version string     `json:"version"`
+	Creator HARCreator `json:"creator"`
+	Entries []HAREntry `json:"entries"`
+}
+
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type HAREntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HARTimings  `json:"timings"`
+	ServerIPAddress string      `json:"serverIPAddress,omitempty"`
+	ResourceType    string      `json:"_resourceType,omitempty"`
+	Error           string      `json:"_error,omitempty"`
+}
+
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARCookie    `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	PostData    *HARPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type HARResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARCookie    `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	Content     HARContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type HARCookie struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Path     string `json:"path,omitempty"`
+	Domain   string `json:"domain,omitempty"`
+	Expires  string `json:"expires,omitempty"`
+	HTTPOnly bool   `json:"httpOnly,omitempty"`
+	Secure   bool   `json:"secure,omitempty"`
+}
+
+type HARPostData struct {
+	MimeType string         `json:"mimeType"`
+	Params   []HARNameValue `json:"params"`
+	Text     string         `json:"text"`
+}
+
+type HARContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type HARTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// BuildHAR 把请求记录转为HAR
+func BuildHAR(entries []NetEntry) HAR {
+	har := HAR{Log: HARLog{
+		Version: "1.2",
+		Creator: HARCreator{Name: "ChromeBot", Version: "0.1"},
+		Entries: make([]HAREntry, 0, len(entries)),
+	}}
+	for i := range entries {
+		har.Log.Entries = append(har.Log.Entries, harEntry(&entries[i]))
+	}
+	return har
+}
+
+// SaveHAR 把请求记录保存为HAR文件
+func SaveHAR(path string, entries []NetEntry) error {
+	data, err := json.MarshalIndent(BuildHAR(entries), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("保存HAR文件失败: %w", err)
+	}
+	log.Printf("[Chrome]保存HAR文件: %s, 共%d条请求", path, len(entries))
+	return nil
+}
+
+func harEntry(e *NetEntry) HAREntry {
+	httpVersion := harHTTPVersion(e.Protocol)
+	reqHeaders := harHeaders(e.RequestHeaders)
+	respHeaders := harHeaders(e.ResponseHeaders)
+	timings := harTimings(e)
+
+	entry := HAREntry{
+		StartedDateTime: time.UnixMilli(int64(e.WallTime * 1000)).UTC().Format("2006-01-02T15:04:05.000Z"),
+		Request: HARRequest{
+			Method:      e.Method,
+			URL:         e.URL,
+			HTTPVersion: httpVersion,
+			Cookies:     harCookies(reqHeaders, "Cookie"),
+			Headers:     reqHeaders,
+			QueryString: harQuery(e.URL),
+			HeadersSize: -1,
+			BodySize:    len(e.PostData),
+		},
+		Response: HARResponse{
+			Status:      e.Status,
+			StatusText:  e.StatusText,
+			HTTPVersion: httpVersion,
+			Cookies:     harCookies(respHeaders, "Set-Cookie"),
+			Headers:     respHeaders,
+			Content: HARContent{
+				Size:     e.DataLength,
+				MimeType: e.MimeType,
+			},
+			RedirectURL: e.RedirectURL,
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Timings:         timings,
+		ServerIPAddress: strings.Trim(e.RemoteIP, "[]"),
+		ResourceType:    strings.ToLower(e.Type),
+		Error:           e.Error,
+	}
+	// HAR 中不适用的时间段为 -1, 总时间只累加非负的时间段
+	for _, t := range []float64{timings.Blocked, timings.DNS, timings.Connect, timings.Send, timings.Wait, timings.Receive} {
+		if t > 0 {
+			entry.Time += t
+		}
+	}
+	if e.PostData != "" {
+		entry.Request.PostData = &HARPostData{
+			MimeType: headerValue(e.RequestHeaders, "Content-Type"),
+			Params:   []HARNameValue{},
+			Text:     e.PostData,
+		}
+	}
+	if e.Finished && e.Error == "" {
+		entry.Response.BodySize = e.EncodedLength
+	}
+	if e.Status == 0 {
+		entry.Response.HTTPVersion = ""
+	}
+	if e.BodyLoaded {
+		entry.Response.Content.Text = e.Body
+		if e.Base64 {
+			entry.Response.Content.Encoding = "base64"
+		} else if entry.Response.Content.Size == 0 {
+			entry.Response.Content.Size = len(e.Body)
+		}
+	}
+	return entry
+}
+
+// harTimings 由 ResourceTiming 计算各阶段耗时(毫秒), 计算方式与 Chrome DevTools 导出的HAR一致
+func harTimings(e *NetEntry) HARTimings {
+	t := HARTimings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1}
+	total := e.Duration()
+	if e.Timing == nil {
+		// 缓存、data: 等没有时序信息的请求
+		if e.ResponseTime > 0 && e.StartTime > 0 {
+			t.Wait = (e.ResponseTime - e.StartTime) * 1000
+		}
+		if total > t.Wait {
+			t.Receive = total - t.Wait
+		}
+		return t
+	}
+	get := func(key string) float64 {
+		v, ok := e.Timing[key].(float64)
+		if !ok {
+			return -1
+		}
+		return v
+	}
+	requestTime := get("requestTime")
+	// requestTime 之前的排队时间
+	queued := 0.0
+	if requestTime > 0 && e.StartTime > 0 && requestTime > e.StartTime {
+		queued = (requestTime - e.StartTime) * 1000
+	}
+
+	blocked := queued
+	for _, key := range []string{"dnsStart", "connectStart", "sendStart"} {
+		if v := get(key); v >= 0 {
+			blocked += v
+			break
+		}
+	}
+	t.Blocked = blocked
+	if start, end := get("dnsStart"), get("dnsEnd"); start >= 0 && end >= start {
+		t.DNS = end - start
+	}
+	if start, end := get("connectStart"), get("connectEnd"); start >= 0 && end >= start {
+		t.Connect = end - start
+	}
+	if start, end := get("sslStart"), get("sslEnd"); start >= 0 && end >= start {
+		t.SSL = end - start
+	}
+	sendStart, sendEnd := get("sendStart"), get("sendEnd")
+	if sendStart >= 0 && sendEnd >= sendStart {
+		t.Send = sendEnd - sendStart
+	}
+	if headersEnd := get("receiveHeadersEnd"); headersEnd >= 0 && sendEnd >= 0 {
+		t.Wait = headersEnd - sendEnd
+		if e.EndTime > 0 && requestTime > 0 {
+			if receive := (e.EndTime-requestTime)*1000 - headersEnd; receive > 0 {
+				t.Receive = receive
+			}
+		}
+	}
+	return t
+}
+
+func harHTTPVersion(protocol string) string {
+	switch strings.ToLower(protocol) {
+	case "h2":
+		return "HTTP/2"
+	case "h3", "h3-29", "quic":
+		return "HTTP/3"
+	case "http/1.0":
+		return "HTTP/1.0"
+	case "":
+		return "HTTP/1.1"
+	}
+	return strings.ToUpper(protocol)
+}
+
+// harHeaders 头信息按名称排序, 一个头有多个值(换行分隔)时拆为多条
+func harHeaders(headers map[string]string) []HARNameValue {
+	res := make([]HARNameValue, 0, len(headers))
+	for name, value := range headers {
+		for _, v := range strings.Split(value, "\n") {
+			res = append(res, HARNameValue{Name: name, Value: v})
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return strings.ToLower(res[i].Name) < strings.ToLower(res[j].Name)
+	})
+	return res
+}
+
+func headerValue(headers map[string]string, name string) string {
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return ""
+}
+
+func harQuery(rawURL string) []HARNameValue {
+	res := make([]HARNameValue, 0)
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return res
+	}
+	for _, pair := range strings.Split(u.RawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(pair, "=")
+		name, _ = url.QueryUnescape(name)
+		value, _ = url.QueryUnescape(value)
+		res = append(res, HARNameValue{Name: name, Value: value})
+	}
+	return res
+}
+
+// harCookies 从 Cookie 请求头或 Set-Cookie 响应头解析cookie
+func harCookies(headers []HARNameValue, name string) []HARCookie {
+	header := http.Header{}
+	for _, h := range headers {
+		if strings.EqualFold(h.Name, name) {
+			header.Add(name, h.Value)
+		}
+	}
+	res := make([]HARCookie, 0)
+	if name == "Cookie" {
+		for _, c := range (&http.Request{Header: header}).Cookies() {
+			res = append(res, HARCookie{Name: c.Name, Value: c.Value})
+		}
+		return res
+	}
+	for _, c := range (&http.Response{Header: header}).Cookies() {
+		cookie := HARCookie{Name: c.Name, Value: c.Value, Path: c.Path, Domain: c.Domain, HTTPOnly: c.HttpOnly, Secure: c.Secure}
+		if !c.Expires.IsZero() {
+			cookie.Expires = c.Expires.UTC().Format("2006-01-02T15:04:05.000Z")
+		}
+		res = append(res, cookie)
+	}
+	return res
+}
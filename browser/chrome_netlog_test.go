@@ -0,0 +1,88 @@
+package browser
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNetlogHAR(t *testing.T) {
+	n := &netRecorder{pending: make(map[string]*NetEntry)}
+	events := []struct {
+		method string
+		params string
+	}{
+		{"Network.requestWillBeSent", `{"requestId":"1","timestamp":100,"wallTime":1700000000,"type":"Document",
+			"request":{"url":"http://a.com/old","method":"GET","headers":{"Accept":"*/*"}}}`},
+		{"Network.requestWillBeSent", `{"requestId":"1","timestamp":100.05,"wallTime":1700000000.05,"type":"Document",
+			"request":{"url":"http://a.com/list?q=a%20b&page=2","method":"GET","headers":{"Cookie":"sid=1; lang=zh"}},
+			"redirectResponse":{"status":302,"statusText":"Found","headers":{"Location":"/list"},"protocol":"http/1.1"}}`},
+		{"Network.responseReceived", `{"requestId":"1","timestamp":100.2,"type":"Document",
+			"response":{"status":200,"statusText":"OK","mimeType":"text/html","protocol":"h2","remoteIPAddress":"[::1]",
+			"headers":{"Content-Type":"text/html","Set-Cookie":"a=1; Path=/; HttpOnly\nb=2"},
+			"timing":{"requestTime":100.06,"dnsStart":1,"dnsEnd":3,"connectStart":3,"connectEnd":10,"sslStart":5,"sslEnd":10,
+			"sendStart":10,"sendEnd":11,"receiveHeadersEnd":111}}}`},
+		{"Network.dataReceived", `{"requestId":"1","dataLength":1024}`},
+		{"Network.loadingFinished", `{"requestId":"1","timestamp":100.3,"encodedDataLength":600}`},
+		{"Network.requestWillBeSent", `{"requestId":"2","timestamp":100.4,"wallTime":1700000000.4,"type":"XHR",
+			"request":{"url":"http://a.com/api","method":"POST","postData":"{\"a\":1}","headers":{"Content-Type":"application/json"}}}`},
+		{"Network.loadingFailed", `{"requestId":"2","timestamp":100.5,"errorText":"net::ERR_FAILED"}`},
+		{"Network.loadingFinished", `{"requestId":"3","timestamp":100.5}`},
+	}
+	for _, e := range events {
+		params := map[string]any{}
+		if err := json.Unmarshal([]byte(e.params), &params); err != nil {
+			t.Fatal(err)
+		}
+		n.onEvent(e.method, "S1", params)
+	}
+	defer func(old *netRecorder) { netlog = old }(netlog)
+	netlog = n
+	entries := NetlogEntries()
+	if len(entries) != 3 {
+		t.Fatalf("期望3条记录, 得到%d", len(entries))
+	}
+	har := BuildHAR(entries)
+	if har.Log.Version != "1.2" {
+		t.Errorf("version: %s", har.Log.Version)
+	}
+
+	redirect := har.Log.Entries[0]
+	if redirect.Response.Status != 302 || redirect.Response.RedirectURL != "http://a.com/list?q=a%20b&page=2" {
+		t.Errorf("重定向记录错误: %+v", redirect.Response)
+	}
+
+	page := har.Log.Entries[1]
+	if page.StartedDateTime != "2023-11-14T22:13:20.050Z" {
+		t.Errorf("startedDateTime: %s", page.StartedDateTime)
+	}
+	if page.Request.HTTPVersion != "HTTP/2" || page.ServerIPAddress != "::1" {
+		t.Errorf("httpVersion/serverIPAddress: %s %s", page.Request.HTTPVersion, page.ServerIPAddress)
+	}
+	if len(page.Request.QueryString) != 2 || page.Request.QueryString[0].Value != "a b" {
+		t.Errorf("queryString: %+v", page.Request.QueryString)
+	}
+	if len(page.Request.Cookies) != 2 || len(page.Response.Cookies) != 2 || !page.Response.Cookies[0].HTTPOnly {
+		t.Errorf("cookies: %+v %+v", page.Request.Cookies, page.Response.Cookies)
+	}
+	if len(page.Response.Headers) != 3 {
+		t.Errorf("多值响应头应拆为多条: %+v", page.Response.Headers)
+	}
+	want := HARTimings{Blocked: 11, DNS: 2, Connect: 7, SSL: 5, Send: 1, Wait: 100, Receive: 129}
+	got := page.Timings
+	round := func(f float64) float64 { return float64(int(f + 0.5)) }
+	got.Blocked, got.Receive = round(got.Blocked), round(got.Receive)
+	if got != want {
+		t.Errorf("timings: got %+v, want %+v", got, want)
+	}
+	if page.Response.Content.Size != 1024 || page.Response.BodySize != 600 {
+		t.Errorf("size: %d %d", page.Response.Content.Size, page.Response.BodySize)
+	}
+
+	failed := har.Log.Entries[2]
+	if failed.Error != "net::ERR_FAILED" || failed.Request.PostData == nil || failed.Request.PostData.MimeType != "application/json" {
+		t.Errorf("失败的请求: %+v", failed)
+	}
+	if _, err := json.Marshal(har); err != nil {
+		t.Fatal(err)
+	}
+}
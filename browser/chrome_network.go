@@ -0,0 +1,90 @@
+package browser
+
+import (
+	"sync"
+	"time"
+)
+
+/*
+网络请求跟踪
+
+开启当前tab的 Network 域后, 通过 Network.requestWillBeSent / loadingFinished / loadingFailed 事件
+记录进行中的请求与最后一次网络活动的时间, 用于等待网络空闲
+*/
+
+type networkTracker struct {
+	mu           sync.Mutex
+	session      string               // 已开启Network的tab session
+	inflight     map[string]time.Time // requestId -> 开始时间
+	lastActivity time.Time
+	listenId     int
+}
+
+var network = &networkTracker{inflight: make(map[string]time.Time)}
+
+// ignoreIdleTypes 不计入网络空闲判断的长连接请求
+var ignoreIdleTypes = map[string]bool{
+	"EventSource": true,
+	"WebSocket":   true,
+}
+
+// ensureNetwork 当前tab开启Network域, 切换tab后重新开启并清空记录
+func ensureNetwork() error {
+	if !DefaultNowTab(false) {
+		return notReadyErr()
+	}
+	network.mu.Lock()
+	if network.listenId == 0 {
+		network.listenId = OnEvent("Network.*", network.onEvent)
+	}
+	if network.session == chromeInstance.NowTabSession {
+		network.mu.Unlock()
+		return nil
+	}
+	network.session = chromeInstance.NowTabSession
+	network.inflight = make(map[string]time.Time)
+	network.lastActivity = time.Now()
+	network.mu.Unlock()
+
+	_, err := tabCall("Network.enable", nil)
+	if err != nil {
+		network.mu.Lock()
+		network.session = ""
+		network.mu.Unlock()
+	}
+	return err
+}
+
+func (n *networkTracker) onEvent(method, sessionId string, params map[string]any) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if sessionId != n.session {
+		return
+	}
+	requestId := mapStr(params, "requestId")
+	switch method {
+	case "Network.requestWillBeSent":
+		if !ignoreIdleTypes[mapStr(params, "type")] {
+			n.inflight[requestId] = time.Now()
+		}
+	case "Network.loadingFinished", "Network.loadingFailed":
+		delete(n.inflight, requestId)
+	case "Network.responseReceived", "Network.dataReceived":
+		if ignoreIdleTypes[mapStr(params, "type")] {
+			delete(n.inflight, requestId)
+		}
+	default:
+		return
+	}
+	n.lastActivity = time.Now()
+}
+
+// idleFor 没有进行中的请求且持续了多久
+func (n *networkTracker) idleFor() (time.Duration, int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if len(n.inflight) > 0 {
+		return 0, len(n.inflight)
+	}
+	return time.Since(n.lastActivity), 0
+}
@@ -10,18 +10,19 @@ import (
 
 func PageEnable() error {
 	if !DefaultNowTab(false) {
-		return fmt.Errorf("浏览器未初始化")
+		return notReadyErr()
 	}
 
 	// 1. 启用Page事件监听（必须）
-	chromeInstance.NextID++
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	msg := map[string]interface{}{
-		"id":        chromeInstance.NextID,
+		"id":        reqID,
 		"method":    "Page.enable",
 		"params":    map[string]interface{}{},
 		"sessionId": chromeInstance.NowTabSession,
 	}
-	err := chromeInstance.NowTabWSConn.WriteJSON(msg)
+	err := wsWriteJSON(chromeInstance.NowTabWSConn, msg)
 	if err != nil {
 		log.Println("发送消息失败:", err)
 		return fmt.Errorf("发送消息失败")
@@ -35,22 +36,16 @@ func PageEnable() error {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				log.Println("消息队列已关闭")
 				return fmt.Errorf("消息队列已关闭")
 			}
-			if chromeInstance.NextID == respMsg.ID {
+			if reqID == respMsg.ID {
 				utils.Debug("收到的消息 -> ", respMsg.Content)
 
-				chromeInstance.NextID++
-				disableFrameEvents := map[string]interface{}{
-					"id":        chromeInstance.NextID,
-					"method":    "Page.setLifecycleEventsEnabled", // 禁用生命周期事件
-					"params":    map[string]interface{}{"enabled": false},
-					"sessionId": chromeInstance.NowTabSession,
-				}
-				if err := chromeInstance.NowTabWSConn.WriteJSON(disableFrameEvents); err != nil {
+				// 禁用生命周期事件, 不需要等待回复
+				if err := cdpSend(chromeInstance.NowTabWSConn, chromeInstance.NowTabSession, "Page.setLifecycleEventsEnabled", map[string]any{"enabled": false}); err != nil {
 					utils.Debug("禁用frame事件失败: ", err)
 				}
 
@@ -0,0 +1,228 @@
+package browser
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+/*
+翻页与无限滚动
+
+paginate: 点击下一页, 下一页按钮不存在、不可用(disabled、aria-disabled、disabled样式), 或点击后页面内容没有变化时结束
+infinite_scroll: 滚动到底部, 页面高度与内容不再增长, 或达到条目数量、出现指定元素时结束
+每页的条目按 key 去重, 只把新的条目交给代码块
+*/
+
+// DefaultScrollSettle 滚动后等待页面增长的默认时间
+var DefaultScrollSettle = 5 * time.Second
+
+// PagerState 下一页按钮的状态
+type PagerState struct {
+	Found    bool   `json:"found"`
+	Visible  bool   `json:"visible"`
+	Disabled bool   `json:"disabled"`
+	Text     string `json:"text"`
+}
+
+// pagerStateJS 下一页按钮是否存在、可见、可用; 自身或祖先有 disabled 样式也视为不可用(常见的 li.disabled > a)
+const pagerStateJS = `(args) => {
+    const el = (__LOCATOR__).first(args.selector);
+    if (!el) return { found: false };
+    const style = window.getComputedStyle(el);
+    const rect = el.getBoundingClientRect();
+    const disabled = !!(el.disabled || el.getAttribute('aria-disabled') === 'true' ||
+        style.pointerEvents === 'none' ||
+        (el.closest && el.closest('.disabled, .is-disabled, [aria-disabled="true"], fieldset[disabled]')));
+    return {
+        found: true,
+        visible: rect.width > 0 && rect.height > 0 && style.visibility !== 'hidden' && style.display !== 'none',
+        disabled: disabled,
+        text: (el.innerText || el.value || '').trim().slice(0, 50)
+    };
+}`
+
+// GetPagerState 获取下一页按钮的状态
+func GetPagerState(selector string) (PagerState, error) {
+	if err := ValidateLocator(selector); err != nil {
+		return PagerState{}, err
+	}
+	value, err := frameEval(jsCall(pagerStateJS, map[string]any{"selector": selector}), false, 6*time.Second)
+	if err != nil {
+		return PagerState{}, err
+	}
+	state := PagerState{}
+	b, _ := json.Marshal(value)
+	if err = json.Unmarshal(b, &state); err != nil {
+		return state, fmt.Errorf("解析下一页按钮状态失败: %w", err)
+	}
+	return state, nil
+}
+
+// PageSignature 页面内容的特征, 用于判断翻页、滚动后内容是否变化
+type PageSignature struct {
+	URL    string `json:"url"`
+	Height int    `json:"height"`
+	Length int    `json:"length"`
+	Hash   int    `json:"hash"`
+}
+
+const pageSignatureJS = `(() => {
+    const s = document.scrollingElement || document.documentElement;
+    const t = document.body ? document.body.innerText : '';
+    let h = 0;
+    for (let i = 0; i < t.length; i++) h = (h * 31 + t.charCodeAt(i)) | 0;
+    return { url: location.href, height: s ? s.scrollHeight : 0, length: t.length, hash: h };
+})()`
+
+// GetPageSignature 获取当前frame页面内容的特征
+func GetPageSignature() (PageSignature, error) {
+	value, err := frameEval(pageSignatureJS, false, 6*time.Second)
+	if err != nil {
+		return PageSignature{}, err
+	}
+	sig := PageSignature{}
+	b, _ := json.Marshal(value)
+	if err = json.Unmarshal(b, &sig); err != nil {
+		return sig, fmt.Errorf("解析页面特征失败: %w", err)
+	}
+	return sig, nil
+}
+
+// WaitPageChange 等待页面内容与before不同, 然后等待网络空闲; 超时未变化返回false
+// grow 为 true 时只看页面高度与文本长度的增长(滚动加载)
+func WaitPageChange(before PageSignature, grow bool, timeout time.Duration) (bool, error) {
+	var evalErr error
+	changed, _ := pollUntil(timeout, "页面内容变化", func() (bool, string, error) {
+		now, err := GetPageSignature()
+		if err != nil {
+			// 翻页跳转中执行上下文会被销毁, 继续等待
+			if isContextLost(err) {
+				return false, err.Error(), nil
+			}
+			evalErr = err
+			return false, "", err
+		}
+		if grow {
+			return now.Height > before.Height || now.Length > before.Length, "", nil
+		}
+		return now != before, "", nil
+	})
+	if evalErr != nil {
+		return false, evalErr
+	}
+	if !changed {
+		return false, nil
+	}
+	if _, err := WaitIdle(500*time.Millisecond, timeout); err != nil {
+		fmt.Println("[Chrome]等待网络空闲:", err.Error())
+	}
+	return true, nil
+}
+
+const scrollBottomJS = `(() => {
+    const s = document.scrollingElement || document.documentElement;
+    const h = s.scrollHeight;
+    s.scrollTo(0, h);
+    window.scrollTo(0, h);
+    window.dispatchEvent(new Event('scroll'));
+    return h;
+})()`
+
+// ScrollToBottom 滚动到页面底部, 触发滚动加载
+func ScrollToBottom() error {
+	_, err := frameEval(scrollBottomJS, false, 6*time.Second)
+	return err
+}
+
+// locatorStateJS 定位器匹配到的第一个元素的状态(见 chrome_locator.js 的 stateOf)
+const locatorStateJS = `(args) => (__LOCATOR__).stateOf(args.selector)`
+
+// LocatorVisible 定位器匹配到的第一个元素是否可见
+func LocatorVisible(selector string) (bool, error) {
+	if err := ValidateLocator(selector); err != nil {
+		return false, err
+	}
+	value, err := frameEval(jsCall(locatorStateJS, map[string]any{"selector": selector}), false, 6*time.Second)
+	if err != nil {
+		return false, err
+	}
+	res, _ := value.(map[string]any)
+	visible, _ := res["visible"].(bool)
+	return visible, nil
+}
+
+// ItemSet 翻页、滚动中已经见过的条目, 按 key 去重
+// key: 为空时用条目的html, text 用文本, @属性 用属性值, 其他作为条目内的选择器取第一个结果的文本
+type ItemSet struct {
+	Selector string
+	Key      string
+	seen     map[string]bool
+	Total    int
+}
+
+// NewItemSet selector 是条目的选择器(xpath或css)
+func NewItemSet(selector, key string) (*ItemSet, error) {
+	if _, err := ParseLocator(selector); err != nil {
+		return nil, err
+	}
+	return &ItemSet{Selector: selector, Key: key, seen: make(map[string]bool)}, nil
+}
+
+// ItemKey 条目的去重key
+func ItemKey(n *DOMNode, key string) (string, error) {
+	switch {
+	case key == "":
+		return NodeHTML(n), nil
+	case key == "text":
+		return strings.TrimSpace(NodeText(n)), nil
+	case strings.HasPrefix(key, "@"):
+		return n.Attributes[key[1:]], nil
+	}
+	res, err := QuerySelector(n, key)
+	if err != nil {
+		return "", err
+	}
+	items := queryNodes(res)
+	if len(items) == 0 {
+		return "", nil
+	}
+	if node, ok := items[0].(*DOMNode); ok {
+		return strings.TrimSpace(NodeText(node)), nil
+	}
+	return strings.TrimSpace(fmt.Sprint(items[0])), nil
+}
+
+// New 在html中查询条目, 返回没有见过的条目; 取不到key的条目按html去重
+func (s *ItemSet) New(htmlText string) ([]*DOMNode, error) {
+	root, err := ParseHTMLToDOM(htmlText)
+	if err != nil {
+		return nil, err
+	}
+	res, err := QuerySelector(root, s.Selector)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*DOMNode, 0)
+	for _, item := range queryNodes(res) {
+		n, ok := item.(*DOMNode)
+		if !ok {
+			return nil, fmt.Errorf("item 需要匹配元素: %s", s.Selector)
+		}
+		key, err := ItemKey(n, s.Key)
+		if err != nil {
+			return nil, err
+		}
+		if key == "" {
+			key = NodeHTML(n)
+		}
+		if s.seen[key] {
+			continue
+		}
+		s.seen[key] = true
+		items = append(items, n)
+	}
+	s.Total += len(items)
+	return items, nil
+}
@@ -0,0 +1,40 @@
+package browser
+
+import (
+	"testing"
+)
+
+func TestItemSet(t *testing.T) {
+	page1 := `<ul><li data-id="1"><a href="/1">一</a></li><li data-id="2"><a href="/2">二</a></li></ul>`
+	page2 := `<ul><li data-id="2"><a href="/2">二</a></li><li data-id="3"><a href="/3">三</a></li><li><a href="/4">四</a></li></ul>`
+
+	for _, key := range []string{"", "text", "@data-id", "./a/@href", "css=a"} {
+		set, err := NewItemSet("li", key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		items, err := set.New(page1)
+		if err != nil || len(items) != 2 {
+			t.Fatalf("key=%q 第一页: %d %v", key, len(items), err)
+		}
+		items, err = set.New(page2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 没有 data-id 的条目按html去重
+		if len(items) != 2 || items[0].Attributes["data-id"] != "3" || NodeText(items[1]) != "四" {
+			t.Errorf("key=%q 第二页: %d", key, len(items))
+		}
+		if set.Total != 4 {
+			t.Errorf("key=%q total: %d", key, set.Total)
+		}
+		if items, _ = set.New(page2); len(items) != 0 {
+			t.Errorf("key=%q 重复页: %d", key, len(items))
+		}
+	}
+
+	set, _ := NewItemSet("//li/@data-id", "")
+	if _, err := set.New(page1); err == nil {
+		t.Error("条目不是元素时应该报错")
+	}
+}
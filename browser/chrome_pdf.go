@@ -0,0 +1,266 @@
+package browser
+
+import (
+	"ChromeBot/utils"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+导出PDF
+
+使用 Page.printToPDF 的 ReturnAsStream 模式, 浏览器返回流句柄, 再用 IO.read 分块读取写入文件,
+大文档不会一次性放入内存(也不会超过ws消息大小)
+*/
+
+// PDFOptions 导出PDF的选项
+type PDFOptions struct {
+	Landscape      bool    // 横向
+	Paper          string  // 纸张 A4(默认) Letter Legal Tabloid Ledger A0~A6, 或自定义宽x高如 "8.5inx11in"、"210mmx297mm"
+	Margin         string  // 页边距, 同css: "1cm" "10mm 20mm" "0.4in 0.4in 0.6in"; 不带单位为英寸
+	Scale          float64 // 缩放 0.1~2, 默认1
+	HeaderTemplate string  // 页眉html, 可用 class 为 date title url pageNumber totalPages 的元素填充内容
+	FooterTemplate string  // 页脚html
+	PageRanges     string  // 页码范围如 "1-5, 8, 11-13", 默认全部
+	Background     bool    // 打印背景图形
+	Timeout        time.Duration
+}
+
+// DefaultPDFTimeout 生成PDF的默认超时时间, 大文档生成比较慢
+const DefaultPDFTimeout = 120 * time.Second
+
+// pdfReadSize IO.read 每次读取的字节数
+var pdfReadSize = 1 << 20
+
+// paperSizes 纸张尺寸, 单位英寸
+var paperSizes = map[string][2]float64{
+	"letter":  {8.5, 11},
+	"legal":   {8.5, 14},
+	"tabloid": {11, 17},
+	"ledger":  {17, 11},
+	"a0":      {33.1, 46.8},
+	"a1":      {23.4, 33.1},
+	"a2":      {16.54, 23.4},
+	"a3":      {11.7, 16.54},
+	"a4":      {8.27, 11.7},
+	"a5":      {5.83, 8.27},
+	"a6":      {4.13, 5.83},
+}
+
+// unitInches 长度单位换算为英寸
+var unitInches = map[string]float64{
+	"":   1,
+	"in": 1,
+	"cm": 1 / 2.54,
+	"mm": 1 / 25.4,
+	"px": 1.0 / 96,
+	"pt": 1.0 / 72,
+}
+
+// parseLength 解析带单位的长度, 返回英寸
+func parseLength(s string) (float64, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	i := len(s)
+	for i > 0 && (s[i-1] < '0' || s[i-1] > '9') && s[i-1] != '.' {
+		i--
+	}
+	unit, ok := unitInches[s[i:]]
+	if !ok {
+		return 0, fmt.Errorf("不支持的长度单位: %s", s)
+	}
+	n, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("长度格式错误: %s", s)
+	}
+	return n * unit, nil
+}
+
+// paperSizeRe 自定义纸张 宽x高
+var paperSizeRe = regexp.MustCompile(`^([\d.]+[a-z]*)\s*x\s*([\d.]+[a-z]*)$`)
+
+// ParsePaper 解析纸张, 返回宽高(英寸)
+func ParsePaper(paper string) (float64, float64, error) {
+	paper = strings.TrimSpace(paper)
+	if paper == "" {
+		paper = "A4"
+	}
+	if size, ok := paperSizes[strings.ToLower(paper)]; ok {
+		return size[0], size[1], nil
+	}
+	wh := paperSizeRe.FindStringSubmatch(strings.ToLower(paper))
+	if wh == nil {
+		return 0, 0, fmt.Errorf("不支持的纸张: %s", paper)
+	}
+	w, err := parseLength(wh[1])
+	if err != nil {
+		return 0, 0, err
+	}
+	h, err := parseLength(wh[2])
+	if err != nil {
+		return 0, 0, err
+	}
+	if w == 0 || h == 0 {
+		return 0, 0, fmt.Errorf("纸张尺寸不能为0: %s", paper)
+	}
+	return w, h, nil
+}
+
+// ParseMargin 解析页边距, 与css的margin相同: 1个值四边相同, 2个值为上下 左右, 3个值为上 左右 下, 4个值为上 右 下 左
+func ParseMargin(margin string) (top, right, bottom, left float64, err error) {
+	fields := strings.Fields(strings.ReplaceAll(margin, ",", " "))
+	if len(fields) == 0 || len(fields) > 4 {
+		return 0, 0, 0, 0, fmt.Errorf("页边距格式错误: %s", margin)
+	}
+	vals := make([]float64, len(fields))
+	for i, f := range fields {
+		if vals[i], err = parseLength(f); err != nil {
+			return 0, 0, 0, 0, err
+		}
+	}
+	switch len(vals) {
+	case 1:
+		return vals[0], vals[0], vals[0], vals[0], nil
+	case 2:
+		return vals[0], vals[1], vals[0], vals[1], nil
+	case 3:
+		return vals[0], vals[1], vals[2], vals[1], nil
+	}
+	return vals[0], vals[1], vals[2], vals[3], nil
+}
+
+// pdfParams 选项转为 Page.printToPDF 的参数
+func pdfParams(opts PDFOptions) (map[string]any, error) {
+	w, h, err := ParsePaper(opts.Paper)
+	if err != nil {
+		return nil, err
+	}
+	params := map[string]any{
+		"landscape":       opts.Landscape,
+		"printBackground": opts.Background,
+		"paperWidth":      w,
+		"paperHeight":     h,
+		"transferMode":    "ReturnAsStream",
+	}
+	if opts.Margin != "" {
+		top, right, bottom, left, err := ParseMargin(opts.Margin)
+		if err != nil {
+			return nil, err
+		}
+		params["marginTop"], params["marginRight"], params["marginBottom"], params["marginLeft"] = top, right, bottom, left
+	}
+	if opts.Scale != 0 {
+		if opts.Scale < 0.1 || opts.Scale > 2 {
+			return nil, fmt.Errorf("scale 范围是 0.1~2: %v", opts.Scale)
+		}
+		params["scale"] = opts.Scale
+	}
+	if opts.HeaderTemplate != "" || opts.FooterTemplate != "" {
+		params["displayHeaderFooter"] = true
+		// 只给了一个时另一个留空, 否则浏览器会显示默认的标题与日期
+		params["headerTemplate"] = opts.HeaderTemplate
+		if opts.HeaderTemplate == "" {
+			params["headerTemplate"] = "<span></span>"
+		}
+		params["footerTemplate"] = opts.FooterTemplate
+		if opts.FooterTemplate == "" {
+			params["footerTemplate"] = "<span></span>"
+		}
+	}
+	if ranges := strings.TrimSpace(opts.PageRanges); ranges != "" {
+		params["pageRanges"] = ranges
+	}
+	return params, nil
+}
+
+// readStream 循环 IO.read 直到eof, 把内容写入w, 返回写入的字节数
+func readStream(read func() (map[string]any, error), w io.Writer) (int64, error) {
+	var total int64
+	for {
+		res, err := read()
+		if err != nil {
+			return total, err
+		}
+		data := mapStr(res, "data")
+		var chunk []byte
+		if encoded, _ := res["base64Encoded"].(bool); encoded {
+			if chunk, err = base64.StdEncoding.DecodeString(data); err != nil {
+				return total, fmt.Errorf("解析流数据base64失败: %w", err)
+			}
+		} else {
+			chunk = []byte(data)
+		}
+		n, err := w.Write(chunk)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+		if eof, _ := res["eof"].(bool); eof {
+			return total, nil
+		}
+	}
+}
+
+// PrintPDF 把当前页面导出为PDF写入w, 返回写入的字节数
+func PrintPDF(opts PDFOptions, w io.Writer) (int64, error) {
+	if !DefaultNowTab(true) {
+		return 0, notReadyErr()
+	}
+	params, err := pdfParams(opts)
+	if err != nil {
+		return 0, err
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultPDFTimeout
+	}
+
+	conn, session := chromeInstance.NowTabWSConn, chromeInstance.NowTabSession
+	res, err := cdpCall(conn, session, "Page.printToPDF", params, opts.Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("生成PDF失败: %w", err)
+	}
+	handle := mapStr(res, "stream")
+	if handle == "" {
+		return 0, fmt.Errorf("生成PDF失败: 没有返回流句柄")
+	}
+	defer func() {
+		if _, err := cdpCall(conn, session, "IO.close", map[string]any{"handle": handle}, 6*time.Second); err != nil {
+			utils.Debugf("关闭PDF流失败: %s", err.Error())
+		}
+	}()
+
+	return readStream(func() (map[string]any, error) {
+		return cdpCall(conn, session, "IO.read", map[string]any{"handle": handle, "size": pdfReadSize}, opts.Timeout)
+	}, w)
+}
+
+// SavePDF 把当前页面导出为PDF文件, 会创建父目录; 失败时删除不完整的文件
+func SavePDF(outputPath string, opts PDFOptions) (string, int64, error) {
+	outputPath = utils.SanitizeFileName(outputPath)
+	absPath, err := filepath.Abs(outputPath)
+	if err != nil {
+		return "", 0, fmt.Errorf("解析路径失败: %w", err)
+	}
+	if err = os.MkdirAll(filepath.Dir(absPath), 0755); err != nil {
+		return "", 0, fmt.Errorf("创建父目录失败: %w", err)
+	}
+	f, err := os.Create(absPath)
+	if err != nil {
+		return "", 0, fmt.Errorf("创建PDF文件失败: %w", err)
+	}
+	size, err := PrintPDF(opts, f)
+	if cerr := f.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("写入PDF文件失败: %w", cerr)
+	}
+	if err != nil {
+		_ = os.Remove(absPath)
+		return "", 0, err
+	}
+	return outputPath, size, nil
+}
@@ -0,0 +1,114 @@
+package browser
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParsePaper(t *testing.T) {
+	cases := []struct {
+		paper string
+		w, h  float64
+	}{
+		{"", 8.27, 11.7},
+		{"A4", 8.27, 11.7},
+		{"letter", 8.5, 11},
+		{"8.5inx11in", 8.5, 11},
+		{"210mmx297mm", 210 / 25.4, 297 / 25.4},
+		{"816pxx1056px", 8.5, 11},
+	}
+	for _, c := range cases {
+		w, h, err := ParsePaper(c.paper)
+		if err != nil || math.Abs(w-c.w) > 1e-9 || math.Abs(h-c.h) > 1e-9 {
+			t.Errorf("ParsePaper(%q) = %v %v %v", c.paper, w, h, err)
+		}
+	}
+	for _, paper := range []string{"B5", "10x", "0inx11in", "10emx10em"} {
+		if _, _, err := ParsePaper(paper); err == nil {
+			t.Errorf("ParsePaper(%q) 应返回错误", paper)
+		}
+	}
+}
+
+func TestParseMargin(t *testing.T) {
+	cases := map[string][4]float64{
+		"1":               {1, 1, 1, 1},
+		"2.54cm":          {1, 1, 1, 1},
+		"0.5in 1in":       {0.5, 1, 0.5, 1},
+		"1in, 2in, 3in":   {1, 2, 3, 2},
+		"1in 2in 3in 4in": {1, 2, 3, 4},
+		"96px 0 0 0":      {1, 0, 0, 0},
+	}
+	for margin, want := range cases {
+		top, right, bottom, left, err := ParseMargin(margin)
+		got := [4]float64{top, right, bottom, left}
+		for i := range got {
+			if err != nil || math.Abs(got[i]-want[i]) > 1e-9 {
+				t.Errorf("ParseMargin(%q) = %v %v", margin, got, err)
+				break
+			}
+		}
+	}
+	for _, margin := range []string{"", "1 2 3 4 5", "-1cm", "abc"} {
+		if _, _, _, _, err := ParseMargin(margin); err == nil {
+			t.Errorf("ParseMargin(%q) 应返回错误", margin)
+		}
+	}
+}
+
+func TestPDFParams(t *testing.T) {
+	params, err := pdfParams(PDFOptions{
+		Landscape:      true,
+		Paper:          "Letter",
+		Margin:         "1cm",
+		Scale:          0.8,
+		FooterTemplate: `<div style="font-size:8px"><span class="pageNumber"></span>/<span class="totalPages"></span></div>`,
+		PageRanges:     " 1-3, 5 ",
+		Background:     true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params["transferMode"] != "ReturnAsStream" || params["landscape"] != true || params["printBackground"] != true ||
+		params["paperWidth"] != 8.5 || params["scale"] != 0.8 || params["pageRanges"] != "1-3, 5" {
+		t.Errorf("参数: %v", params)
+	}
+	if params["displayHeaderFooter"] != true || params["headerTemplate"] != "<span></span>" {
+		t.Errorf("只给页脚时页眉应为空: %v", params)
+	}
+
+	params, _ = pdfParams(PDFOptions{})
+	for _, key := range []string{"displayHeaderFooter", "scale", "marginTop", "pageRanges"} {
+		if _, ok := params[key]; ok {
+			t.Errorf("默认不应设置 %s", key)
+		}
+	}
+	if _, err = pdfParams(PDFOptions{Scale: 3}); err == nil {
+		t.Errorf("scale 超出范围应返回错误")
+	}
+}
+
+func TestReadStream(t *testing.T) {
+	chunks := []map[string]any{
+		{"data": base64.StdEncoding.EncodeToString([]byte("%PDF-1.4\n")), "base64Encoded": true},
+		{"data": "body", "eof": false},
+		{"data": base64.StdEncoding.EncodeToString([]byte("%%EOF")), "base64Encoded": true, "eof": true},
+	}
+	i := 0
+	var buf bytes.Buffer
+	n, err := readStream(func() (map[string]any, error) {
+		i++
+		return chunks[i-1], nil
+	}, &buf)
+	if err != nil || n != int64(buf.Len()) || buf.String() != "%PDF-1.4\nbody%%EOF" || i != 3 {
+		t.Errorf("读取: %d %q %v", n, buf.String(), err)
+	}
+
+	fail := errors.New("连接断开")
+	if _, err = readStream(func() (map[string]any, error) { return nil, fail }, &buf); !errors.Is(err, fail) {
+		t.Errorf("读取失败应返回错误: %v", err)
+	}
+}
@@ -25,7 +25,6 @@ type ChromeProcess struct {
 	Device               string          // 设备
 	Port                 int             // 调试端口
 	PID                  int             // 浏览器进程
-	NextID               int             // 自增消息id
 	NowTab               string          // 当前操作的tab
 	NowTabWSConn         *websocket.Conn // 当前操作的tab的websocket连接
 	NowTabTargetId       string          // 当前操作的tab的TargetId
@@ -35,19 +34,19 @@ type ChromeProcess struct {
 	CloseState           bool            // 关闭状态
 	WebSocketDebuggerUrl string          // 浏览器的debugger调试url
 	BrowserWSConn        *websocket.Conn // 当前浏览器的debugger调试WS连接
+	LastURL              string          // 当前tab最后访问的地址,用于断线恢复
 }
 
 var (
 	chromeInstance *ChromeProcess
-	once           sync.Once
-	mu             sync.RWMutex
-	isInitialized  bool // 标识：是否已完成初始化
+	mu             sync.RWMutex // 单例的替换(启动、关闭、断线恢复)在锁中进行
+	isInitialized  bool         // 标识：是否已完成初始化
 )
 
 // GetChromeInstance 获取Chrome
 func GetChromeInstance() *ChromeProcess {
-	//mu.RLock()
-	//defer mu.RUnlock()
+	mu.RLock()
+	defer mu.RUnlock()
 	return chromeInstance
 }
 
@@ -149,9 +148,10 @@ func Close() error {
 
 	fmt.Printf("[Chrome]浏览器进程已关闭 | PID：%d \n", chromeInstance.PID)
 
+	mu.Lock()
 	chromeInstance = nil
 	isInitialized = false
-	once = sync.Once{}
+	mu.Unlock()
 	return nil
 }
 
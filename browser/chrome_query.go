@@ -0,0 +1,255 @@
+package browser
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+/*
+离线查询html
+
+对 ParseHTMLToDOM 得到的DOM树执行xpath(1.0的常用子集)与css选择器查询, 不需要浏览器;
+查询结果是DOM树中的节点, 可以取节点的文本(NodeText)与html(NodeHTML)
+*/
+
+// domIndex 查询时建立的父节点与文档顺序索引
+type domIndex struct {
+	root   *DOMNode
+	parent map[*DOMNode]*DOMNode
+	order  map[*DOMNode]int
+	nodes  []*DOMNode // 文档顺序的所有节点
+}
+
+func newDOMIndex(root *DOMNode) *domIndex {
+	idx := &domIndex{
+		root:   root,
+		parent: make(map[*DOMNode]*DOMNode),
+		order:  make(map[*DOMNode]int),
+	}
+	var walk func(n *DOMNode)
+	walk = func(n *DOMNode) {
+		idx.order[n] = len(idx.nodes)
+		idx.nodes = append(idx.nodes, n)
+		for _, child := range n.Children {
+			idx.parent[child] = n
+			walk(child)
+		}
+	}
+	walk(root)
+	return idx
+}
+
+// sortNodes 按文档顺序排序并去重
+func (idx *domIndex) sortNodes(nodes []*DOMNode) []*DOMNode {
+	seen := make(map[*DOMNode]bool, len(nodes))
+	res := make([]*DOMNode, 0, len(nodes))
+	for _, n := range nodes {
+		if !seen[n] {
+			seen[n] = true
+			res = append(res, n)
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return idx.order[res[i]] < idx.order[res[j]]
+	})
+	return res
+}
+
+// isElement 是否是元素节点, shadow root 不算元素
+func isElement(n *DOMNode) bool {
+	return n.Type == html.ElementNode && n.TagName != shadowRootTag
+}
+
+// NodeText 节点内所有文本, 文本片段之间按需要补一个空格(中文与标点前后不补)
+func NodeText(n *DOMNode) string {
+	parts := make([]string, 0)
+	var walk func(n *DOMNode)
+	walk = func(n *DOMNode) {
+		switch n.Type {
+		case html.TextNode:
+			if n.Content != "" {
+				parts = append(parts, n.Content)
+			}
+		case html.ElementNode, html.DocumentNode:
+			if n.TagName == "script" || n.TagName == "style" {
+				return
+			}
+			for _, child := range n.Children {
+				walk(child)
+			}
+		}
+	}
+	walk(n)
+	return joinText(parts)
+}
+
+func joinText(parts []string) string {
+	var buf strings.Builder
+	for _, part := range parts {
+		if buf.Len() > 0 {
+			last, _ := lastRune(buf.String())
+			first := []rune(part)[0]
+			if needSpace(last) && needSpace(first) {
+				buf.WriteByte(' ')
+			}
+		}
+		buf.WriteString(part)
+	}
+	return buf.String()
+}
+
+func lastRune(s string) (rune, bool) {
+	r := []rune(s)
+	if len(r) == 0 {
+		return 0, false
+	}
+	return r[len(r)-1], true
+}
+
+// needSpace 字母数字之间的文本片段用空格分开
+func needSpace(r rune) bool {
+	return (unicode.IsLetter(r) || unicode.IsDigit(r)) && !unicode.Is(unicode.Han, r)
+}
+
+// voidElements 没有结束标签的元素
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true, "input": true,
+	"link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+// NodeHTML 节点的html(outerHTML), 属性按名称排序; 解析时去掉了空白文本, 与原html的空白可能不同
+func NodeHTML(n *DOMNode) string {
+	var buf strings.Builder
+	writeNodeHTML(&buf, n, false)
+	return buf.String()
+}
+
+func writeNodeHTML(buf *strings.Builder, n *DOMNode, raw bool) {
+	switch n.Type {
+	case html.TextNode:
+		if raw {
+			buf.WriteString(n.Content)
+		} else {
+			buf.WriteString(html.EscapeString(n.Content))
+		}
+	case html.CommentNode:
+		buf.WriteString("<!--" + n.Content + "-->")
+	case html.DocumentNode:
+		for _, child := range n.Children {
+			writeNodeHTML(buf, child, false)
+		}
+	case html.DoctypeNode:
+		buf.WriteString("<!DOCTYPE " + n.TagName + ">")
+	case html.ElementNode:
+		tag := n.TagName
+		attrs := n.Attributes
+		if tag == shadowRootTag {
+			tag = "template"
+			attrs = map[string]string{"shadowrootmode": "open"}
+		}
+		buf.WriteString("<" + tag)
+		names := make([]string, 0, len(attrs))
+		for k := range attrs {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		for _, k := range names {
+			buf.WriteString(fmt.Sprintf(` %s="%s"`, k, html.EscapeString(attrs[k])))
+		}
+		buf.WriteString(">")
+		if voidElements[tag] {
+			return
+		}
+		rawText := tag == "script" || tag == "style"
+		for _, child := range n.Children {
+			writeNodeHTML(buf, child, rawText)
+		}
+		buf.WriteString("</" + tag + ">")
+	}
+}
+
+// fragmentContext 解析单个元素的html时使用的上下文, 表格等元素不能直接放在body下
+var fragmentContext = map[string]string{
+	"tr": "tbody", "td": "tr", "th": "tr", "thead": "table", "tbody": "table", "tfoot": "table",
+	"caption": "table", "colgroup": "table", "col": "colgroup", "option": "select", "optgroup": "select",
+}
+
+// ParseHTMLNode 把一个元素的html(如 NodeHTML 的结果)解析为DOM树, 元素的XPath为xpath, 子节点的XPath以它开头
+// 用于对查询结果再次查询
+func ParseHTMLNode(outerHTML, tag, xpath string) (*DOMNode, error) {
+	switch tag {
+	case "", "html", "head", "body":
+		root, err := ParseHTMLToDOM(outerHTML)
+		if err != nil {
+			return nil, err
+		}
+		if tag == "" {
+			return root, nil
+		}
+		for _, n := range newDOMIndex(root).nodes {
+			if isElement(n) && n.TagName == tag {
+				return n, nil
+			}
+		}
+		return nil, fmt.Errorf("html中没有找到%s元素", tag)
+	}
+
+	contextTag := "body"
+	if c, ok := fragmentContext[tag]; ok {
+		contextTag = c
+	}
+	context := &html.Node{Type: html.ElementNode, Data: contextTag, DataAtom: atom.Lookup([]byte(contextTag))}
+	nodes, err := html.ParseFragment(strings.NewReader(outerHTML), context)
+	if err != nil {
+		return nil, fmt.Errorf("解析HTML失败: %w", err)
+	}
+	for _, node := range nodes {
+		if node.Type != html.ElementNode || node.Data != tag {
+			continue
+		}
+		// 片段的根节点没有父节点, 直接使用给定的XPath
+		domNode := &DOMNode{Type: node.Type, TagName: node.Data, Attributes: make(map[string]string), XPath: xpath}
+		for _, attr := range node.Attr {
+			domNode.Attributes[attr.Key] = attr.Val
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			if domChild := convertToDOMNode(child, xpath); domChild != nil {
+				domNode.Children = append(domNode.Children, domChild)
+			}
+		}
+		return domNode, nil
+	}
+	return nil, fmt.Errorf("html中没有找到%s元素", tag)
+}
+
+var xpathFuncCallRe = regexp.MustCompile(`^\s*([a-z][a-z-]*)\s*\(`)
+
+// QuerySelector 按定位器查询, 以 / ( ./ 开头、xpath函数调用或带 xpath= 前缀的为xpath, 其他为css(可带 css= 前缀)
+// xpath 的结果可能是节点列表、字符串列表(text()、@属性)、数值、字符串或布尔值, css 的结果是节点列表
+func QuerySelector(root *DOMNode, selector string) (any, error) {
+	parts, err := ParseLocator(selector)
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) != 1 {
+		return nil, fmt.Errorf("离线查询不支持 >>> : %s", selector)
+	}
+	engine := parts[0].Engine
+	// count(...) normalize-space(...) 这样的xpath函数不会是css选择器
+	if m := xpathFuncCallRe.FindStringSubmatch(parts[0].Body); engine == "css" && m != nil && xpathFuncs[m[1]] != nil {
+		engine = "xpath"
+	}
+	switch engine {
+	case "xpath":
+		return QueryXPath(root, parts[0].Body)
+	case "css":
+		return QueryCSS(root, parts[0].Body)
+	}
+	return nil, fmt.Errorf("离线查询只支持xpath与css, 不支持 %s=", engine)
+}
@@ -0,0 +1,687 @@
+package browser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+/*
+css选择器的离线实现
+
+支持: 标签 * #id .class [attr] [attr=v] ~= |= ^= $= *= (加 i 忽略大小写)、组合 空格 > + ~ 、选择器列表 , 、
+伪类 first-child last-child only-child nth-child() nth-last-child() first-of-type last-of-type only-of-type
+nth-of-type() nth-last-of-type() not() is() where() has() contains() empty root checked disabled enabled
+*/
+
+// cssSelector 一个复合选择器序列, combinators[i] 是 parts[i] 与 parts[i+1] 之间的组合符
+type cssSelector struct {
+	parts       []*cssCompound
+	combinators []byte // ' ' '>' '+' '~'
+	relative    byte   // :has() 中以组合符开头的相对选择器
+}
+
+type cssCompound struct {
+	tag     string
+	ids     []string
+	classes []string
+	attrs   []cssAttr
+	pseudos []cssPseudo
+}
+
+type cssAttr struct {
+	name  string
+	op    string
+	value string
+	fold  bool // 忽略大小写
+}
+
+type cssPseudo struct {
+	name string
+	a, b int // nth 的 an+b
+	list []*cssSelector
+	arg  string
+}
+
+// QueryCSS 在root下查询匹配css选择器的元素, 不包括root本身, 按文档顺序
+func QueryCSS(root *DOMNode, selector string) ([]*DOMNode, error) {
+	if root == nil {
+		return nil, fmt.Errorf("html为空")
+	}
+	list, err := compileCSS(selector)
+	if err != nil {
+		return nil, err
+	}
+	idx := newDOMIndex(root)
+	res := make([]*DOMNode, 0)
+	for _, n := range idx.nodes[1:] {
+		if isElement(n) && matchCSSList(idx, list, n) {
+			res = append(res, n)
+		}
+	}
+	return res, nil
+}
+
+func compileCSS(selector string) ([]*cssSelector, error) {
+	p := &cssParser{rs: []rune(strings.TrimSpace(selector))}
+	if len(p.rs) == 0 {
+		return nil, fmt.Errorf("css选择器不能为空")
+	}
+	list, err := p.parseList(false)
+	if err == nil && p.pos < len(p.rs) {
+		err = fmt.Errorf("多余的内容 %q", string(p.rs[p.pos:]))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("css选择器语法错误: %s, %s", selector, err.Error())
+	}
+	return list, nil
+}
+
+type cssParser struct {
+	rs  []rune
+	pos int
+}
+
+func (p *cssParser) peek() rune {
+	if p.pos < len(p.rs) {
+		return p.rs[p.pos]
+	}
+	return 0
+}
+
+func (p *cssParser) skipSpace() bool {
+	start := p.pos
+	for p.pos < len(p.rs) && unicode.IsSpace(p.rs[p.pos]) {
+		p.pos++
+	}
+	return p.pos > start
+}
+
+// parseList 选择器列表, relative 为 true 时(:has 中)可以以组合符开头
+func (p *cssParser) parseList(relative bool) ([]*cssSelector, error) {
+	list := make([]*cssSelector, 0)
+	for {
+		p.skipSpace()
+		sel := &cssSelector{}
+		if relative {
+			if c := p.peek(); c == '>' || c == '+' || c == '~' {
+				sel.relative = byte(c)
+				p.pos++
+				p.skipSpace()
+			}
+		}
+		if err := p.parseComplex(sel); err != nil {
+			return nil, err
+		}
+		list = append(list, sel)
+		p.skipSpace()
+		if p.peek() != ',' {
+			return list, nil
+		}
+		p.pos++
+	}
+}
+
+func (p *cssParser) parseComplex(sel *cssSelector) error {
+	for {
+		compound, err := p.parseCompound()
+		if err != nil {
+			return err
+		}
+		sel.parts = append(sel.parts, compound)
+		hasSpace := p.skipSpace()
+		c := p.peek()
+		switch {
+		case c == '>' || c == '+' || c == '~':
+			p.pos++
+			p.skipSpace()
+			sel.combinators = append(sel.combinators, byte(c))
+		case c == 0 || c == ',' || c == ')':
+			return nil
+		case hasSpace:
+			sel.combinators = append(sel.combinators, ' ')
+		default:
+			return fmt.Errorf("不能识别的字符 %q", c)
+		}
+	}
+}
+
+func (p *cssParser) parseCompound() (*cssCompound, error) {
+	c := &cssCompound{}
+	start := p.pos
+	if p.peek() == '*' {
+		p.pos++
+		c.tag = "*"
+	} else if isCSSIdentStart(p.peek()) {
+		c.tag = strings.ToLower(p.parseIdent())
+	}
+	for {
+		switch p.peek() {
+		case '#':
+			p.pos++
+			id := p.parseIdent()
+			if id == "" {
+				return nil, fmt.Errorf("# 后缺少id")
+			}
+			c.ids = append(c.ids, id)
+		case '.':
+			p.pos++
+			class := p.parseIdent()
+			if class == "" {
+				return nil, fmt.Errorf(". 后缺少class")
+			}
+			c.classes = append(c.classes, class)
+		case '[':
+			attr, err := p.parseAttr()
+			if err != nil {
+				return nil, err
+			}
+			c.attrs = append(c.attrs, attr)
+		case ':':
+			pseudo, err := p.parsePseudo()
+			if err != nil {
+				return nil, err
+			}
+			c.pseudos = append(c.pseudos, pseudo)
+		default:
+			if p.pos == start {
+				if p.peek() == 0 {
+					return nil, fmt.Errorf("选择器不完整")
+				}
+				return nil, fmt.Errorf("不能识别的字符 %q", p.peek())
+			}
+			return c, nil
+		}
+	}
+}
+
+func isCSSIdentStart(r rune) bool {
+	return unicode.IsLetter(r) || r == '_' || r == '-' || r == '\\' || r > 127
+}
+
+func (p *cssParser) parseIdent() string {
+	var buf strings.Builder
+	for p.pos < len(p.rs) {
+		r := p.rs[p.pos]
+		if r == '\\' && p.pos+1 < len(p.rs) {
+			buf.WriteRune(p.rs[p.pos+1])
+			p.pos += 2
+			continue
+		}
+		if !(isCSSIdentStart(r) || unicode.IsDigit(r)) {
+			break
+		}
+		buf.WriteRune(r)
+		p.pos++
+	}
+	return buf.String()
+}
+
+func (p *cssParser) parseString() (string, error) {
+	quote := p.peek()
+	p.pos++
+	var buf strings.Builder
+	for p.pos < len(p.rs) {
+		r := p.rs[p.pos]
+		p.pos++
+		if r == '\\' && p.pos < len(p.rs) {
+			buf.WriteRune(p.rs[p.pos])
+			p.pos++
+			continue
+		}
+		if r == quote {
+			return buf.String(), nil
+		}
+		buf.WriteRune(r)
+	}
+	return "", fmt.Errorf("字符串没有结束引号")
+}
+
+func (p *cssParser) parseAttr() (cssAttr, error) {
+	attr := cssAttr{}
+	p.pos++ // [
+	p.skipSpace()
+	attr.name = strings.ToLower(p.parseIdent())
+	if attr.name == "" {
+		return attr, fmt.Errorf("[ 后缺少属性名")
+	}
+	p.skipSpace()
+	if p.peek() == ']' {
+		p.pos++
+		return attr, nil
+	}
+	for _, op := range []string{"~=", "|=", "^=", "$=", "*=", "="} {
+		if strings.HasPrefix(string(p.rs[p.pos:]), op) {
+			attr.op = op
+			p.pos += len(op)
+			break
+		}
+	}
+	if attr.op == "" {
+		return attr, fmt.Errorf("属性选择器 [%s 的运算符错误", attr.name)
+	}
+	p.skipSpace()
+	if c := p.peek(); c == '"' || c == '\'' {
+		value, err := p.parseString()
+		if err != nil {
+			return attr, err
+		}
+		attr.value = value
+	} else {
+		attr.value = p.parseIdent()
+	}
+	p.skipSpace()
+	if c := p.peek(); c == 'i' || c == 'I' || c == 's' || c == 'S' {
+		attr.fold = c == 'i' || c == 'I'
+		p.pos++
+		p.skipSpace()
+	}
+	if p.peek() != ']' {
+		return attr, fmt.Errorf("属性选择器 [%s 缺少 ]", attr.name)
+	}
+	p.pos++
+	return attr, nil
+}
+
+func (p *cssParser) parsePseudo() (cssPseudo, error) {
+	pseudo := cssPseudo{}
+	p.pos++ // :
+	if p.peek() == ':' {
+		return pseudo, fmt.Errorf("不支持伪元素 ::%s", p.parseIdentAfter())
+	}
+	pseudo.name = strings.ToLower(p.parseIdent())
+	switch pseudo.name {
+	case "first-child", "last-child", "only-child", "first-of-type", "last-of-type", "only-of-type",
+		"empty", "root", "checked", "disabled", "enabled":
+		return pseudo, nil
+	case "nth-child", "nth-last-child", "nth-of-type", "nth-last-of-type":
+		arg, err := p.parseParenArg()
+		if err != nil {
+			return pseudo, err
+		}
+		pseudo.a, pseudo.b, err = parseNth(arg)
+		return pseudo, err
+	case "contains":
+		arg, err := p.parseParenArg()
+		if err != nil {
+			return pseudo, err
+		}
+		arg = strings.TrimSpace(arg)
+		if len(arg) >= 2 && (arg[0] == '"' || arg[0] == '\'') && arg[len(arg)-1] == arg[0] {
+			arg = arg[1 : len(arg)-1]
+		}
+		pseudo.arg = arg
+		return pseudo, nil
+	case "not", "is", "where", "matches", "has":
+		if p.peek() != '(' {
+			return pseudo, fmt.Errorf(":%s 后缺少 (", pseudo.name)
+		}
+		p.pos++
+		list, err := p.parseList(pseudo.name == "has")
+		if err != nil {
+			return pseudo, err
+		}
+		p.skipSpace()
+		if p.peek() != ')' {
+			return pseudo, fmt.Errorf(":%s( 缺少 )", pseudo.name)
+		}
+		p.pos++
+		pseudo.list = list
+		return pseudo, nil
+	}
+	return pseudo, fmt.Errorf("不支持的伪类 :%s", pseudo.name)
+}
+
+func (p *cssParser) parseIdentAfter() string {
+	p.pos++
+	return p.parseIdent()
+}
+
+// parseParenArg 读取括号中的原始内容
+func (p *cssParser) parseParenArg() (string, error) {
+	if p.peek() != '(' {
+		return "", fmt.Errorf("缺少 (")
+	}
+	end := p.pos + 1
+	var quote rune
+	for ; end < len(p.rs); end++ {
+		r := p.rs[end]
+		if quote != 0 {
+			if r == quote {
+				quote = 0
+			}
+			continue
+		}
+		if r == '"' || r == '\'' {
+			quote = r
+		} else if r == ')' {
+			arg := string(p.rs[p.pos+1 : end])
+			p.pos = end + 1
+			return arg, nil
+		}
+	}
+	return "", fmt.Errorf("缺少 )")
+}
+
+// parseNth 解析 an+b、odd、even
+func parseNth(arg string) (int, int, error) {
+	s := strings.ToLower(strings.ReplaceAll(arg, " ", ""))
+	switch s {
+	case "odd":
+		return 2, 1, nil
+	case "even":
+		return 2, 0, nil
+	}
+	i := strings.Index(s, "n")
+	if i < 0 {
+		b, err := strconv.Atoi(s)
+		if err != nil {
+			return 0, 0, fmt.Errorf("nth参数错误: %s", arg)
+		}
+		return 0, b, nil
+	}
+	a := 1
+	switch s[:i] {
+	case "", "+":
+	case "-":
+		a = -1
+	default:
+		v, err := strconv.Atoi(s[:i])
+		if err != nil {
+			return 0, 0, fmt.Errorf("nth参数错误: %s", arg)
+		}
+		a = v
+	}
+	b := 0
+	if rest := s[i+1:]; rest != "" {
+		v, err := strconv.Atoi(rest)
+		if err != nil {
+			return 0, 0, fmt.Errorf("nth参数错误: %s", arg)
+		}
+		b = v
+	}
+	return a, b, nil
+}
+
+// 匹配
+
+func matchCSSList(idx *domIndex, list []*cssSelector, n *DOMNode) bool {
+	for _, sel := range list {
+		if sel.matchAt(idx, n, len(sel.parts)-1) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAt 从右往左匹配, n 匹配 parts[i] 且左边的部分按组合符匹配
+func (s *cssSelector) matchAt(idx *domIndex, n *DOMNode, i int) bool {
+	if !s.parts[i].match(idx, n) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+	switch s.combinators[i-1] {
+	case ' ':
+		for p := parentElement(idx, n); p != nil; p = parentElement(idx, p) {
+			if s.matchAt(idx, p, i-1) {
+				return true
+			}
+		}
+	case '>':
+		if p := parentElement(idx, n); p != nil {
+			return s.matchAt(idx, p, i-1)
+		}
+	case '+':
+		siblings, pos := elementSiblings(idx, n)
+		if pos > 0 {
+			return s.matchAt(idx, siblings[pos-1], i-1)
+		}
+	case '~':
+		siblings, pos := elementSiblings(idx, n)
+		for j := pos - 1; j >= 0; j-- {
+			if s.matchAt(idx, siblings[j], i-1) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func parentElement(idx *domIndex, n *DOMNode) *DOMNode {
+	if p := idx.parent[n]; p != nil && isElement(p) {
+		return p
+	}
+	return nil
+}
+
+// elementSiblings 同一父节点下的元素与n的位置
+func elementSiblings(idx *domIndex, n *DOMNode) ([]*DOMNode, int) {
+	p := idx.parent[n]
+	if p == nil {
+		return []*DOMNode{n}, 0
+	}
+	siblings := make([]*DOMNode, 0, len(p.Children))
+	pos := 0
+	for _, c := range p.Children {
+		if !isElement(c) {
+			continue
+		}
+		if c == n {
+			pos = len(siblings)
+		}
+		siblings = append(siblings, c)
+	}
+	return siblings, pos
+}
+
+func (c *cssCompound) match(idx *domIndex, n *DOMNode) bool {
+	if !isElement(n) {
+		return false
+	}
+	if c.tag != "" && c.tag != "*" && strings.ToLower(n.TagName) != c.tag {
+		return false
+	}
+	for _, id := range c.ids {
+		if n.Attributes["id"] != id {
+			return false
+		}
+	}
+	if len(c.classes) > 0 {
+		classes := strings.Fields(n.Attributes["class"])
+		for _, class := range c.classes {
+			if !containsString(classes, class) {
+				return false
+			}
+		}
+	}
+	for _, attr := range c.attrs {
+		if !attr.match(n) {
+			return false
+		}
+	}
+	for _, pseudo := range c.pseudos {
+		if !pseudo.match(idx, n) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (a cssAttr) match(n *DOMNode) bool {
+	value, ok := "", false
+	for k, v := range n.Attributes {
+		if strings.ToLower(k) == a.name {
+			value, ok = v, true
+			break
+		}
+	}
+	if !ok {
+		return false
+	}
+	want := a.value
+	if a.fold {
+		value, want = strings.ToLower(value), strings.ToLower(want)
+	}
+	switch a.op {
+	case "":
+		return true
+	case "=":
+		return value == want
+	case "~=":
+		return containsString(strings.Fields(value), want)
+	case "|=":
+		return value == want || strings.HasPrefix(value, want+"-")
+	case "^=":
+		return want != "" && strings.HasPrefix(value, want)
+	case "$=":
+		return want != "" && strings.HasSuffix(value, want)
+	case "*=":
+		return want != "" && strings.Contains(value, want)
+	}
+	return false
+}
+
+func (ps cssPseudo) match(idx *domIndex, n *DOMNode) bool {
+	switch ps.name {
+	case "first-child", "last-child", "only-child", "nth-child", "nth-last-child":
+		siblings, pos := elementSiblings(idx, n)
+		return matchNthPosition(ps, pos, len(siblings))
+	case "first-of-type", "last-of-type", "only-of-type", "nth-of-type", "nth-last-of-type":
+		siblings, _ := elementSiblings(idx, n)
+		sameType := make([]*DOMNode, 0, len(siblings))
+		pos := 0
+		for _, s := range siblings {
+			if s.TagName == n.TagName {
+				if s == n {
+					pos = len(sameType)
+				}
+				sameType = append(sameType, s)
+			}
+		}
+		return matchNthPosition(ps, pos, len(sameType))
+	case "not":
+		return !matchCSSList(idx, ps.list, n)
+	case "is", "where", "matches":
+		return matchCSSList(idx, ps.list, n)
+	case "has":
+		return ps.matchHas(idx, n)
+	case "contains":
+		return strings.Contains(NodeText(n), ps.arg)
+	case "empty":
+		return len(n.Children) == 0
+	case "root":
+		return parentElement(idx, n) == nil && n.TagName == "html"
+	case "checked":
+		_, checked := n.Attributes["checked"]
+		_, selected := n.Attributes["selected"]
+		return checked || selected
+	case "disabled":
+		_, ok := n.Attributes["disabled"]
+		return ok
+	case "enabled":
+		_, ok := n.Attributes["disabled"]
+		switch n.TagName {
+		case "input", "button", "select", "textarea", "option", "fieldset":
+			return !ok
+		}
+		return false
+	}
+	return false
+}
+
+// matchNthPosition first last only nth 系列, pos 从0开始
+func matchNthPosition(ps cssPseudo, pos, total int) bool {
+	switch {
+	case strings.HasPrefix(ps.name, "first"):
+		return pos == 0
+	case strings.HasPrefix(ps.name, "last"):
+		return pos == total-1
+	case strings.HasPrefix(ps.name, "only"):
+		return total == 1
+	case strings.HasPrefix(ps.name, "nth-last"):
+		return nthMatch(ps.a, ps.b, total-pos)
+	}
+	return nthMatch(ps.a, ps.b, pos+1)
+}
+
+// nthMatch 是否存在 n>=0 使 a*n+b == index
+func nthMatch(a, b, index int) bool {
+	if a == 0 {
+		return index == b
+	}
+	diff := index - b
+	return diff%a == 0 && diff/a >= 0
+}
+
+// matchHas :has() 的相对选择器, 从n开始从左往右匹配: 默认匹配后代, > 子元素, + 下一个兄弟, ~ 之后的兄弟
+func (ps cssPseudo) matchHas(idx *domIndex, n *DOMNode) bool {
+	for _, sel := range ps.list {
+		relative := sel.relative
+		if relative == 0 {
+			relative = ' '
+		}
+		nodes := relatedElements(idx, []*DOMNode{n}, relative)
+		for i, part := range sel.parts {
+			matched := make([]*DOMNode, 0, len(nodes))
+			for _, c := range nodes {
+				if part.match(idx, c) {
+					matched = append(matched, c)
+				}
+			}
+			if i < len(sel.combinators) {
+				matched = relatedElements(idx, matched, sel.combinators[i])
+			}
+			nodes = matched
+		}
+		if len(nodes) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// relatedElements 按组合符取相关的元素: ' ' 后代, > 子元素, + 下一个兄弟, ~ 之后的兄弟
+func relatedElements(idx *domIndex, nodes []*DOMNode, combinator byte) []*DOMNode {
+	res := make([]*DOMNode, 0)
+	for _, n := range nodes {
+		switch combinator {
+		case ' ':
+			var walk func(n *DOMNode)
+			walk = func(n *DOMNode) {
+				for _, c := range n.Children {
+					if isElement(c) {
+						res = append(res, c)
+					}
+					walk(c)
+				}
+			}
+			walk(n)
+		case '>':
+			for _, c := range n.Children {
+				if isElement(c) {
+					res = append(res, c)
+				}
+			}
+		case '+', '~':
+			siblings, pos := elementSiblings(idx, n)
+			if combinator == '+' && pos+1 < len(siblings) {
+				res = append(res, siblings[pos+1])
+			} else if combinator == '~' {
+				res = append(res, siblings[pos+1:]...)
+			}
+		}
+	}
+	return idx.sortNodes(res)
+}
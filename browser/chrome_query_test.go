@@ -0,0 +1,205 @@
+package browser
+
+import (
+	"strings"
+	"testing"
+)
+
+const queryTestHTML = `<html><body>
+<div id="list" class="list main">
+	<div class="item" data-id="1"><a href="/a/1">First <b>one</b></a><span class="price">10</span></div>
+	<div class="item hot" data-id="2"><a href="/a/2">Second</a><span class="price">25</span></div>
+	<div class="item" data-id="3"><a href="/a/3">第三个</a><span class="price">7</span><em>new</em></div>
+	<p>共<b>3</b>条</p>
+</div>
+<table><tr><td>a</td><td>b</td></tr></table>
+<input type="checkbox" checked><input type="text" disabled>
+</body></html>`
+
+func queryTestDOM(t *testing.T) *DOMNode {
+	root, err := ParseHTMLToDOM(queryTestHTML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+// queryResult 结果转为便于比较的字符串: 元素取 tag#data-id, 其他取字符串
+func queryResult(v any) string {
+	list, ok := v.([]any)
+	if !ok {
+		return xpathString(v)
+	}
+	items := make([]string, 0, len(list))
+	for _, item := range list {
+		if n, ok := item.(*DOMNode); ok {
+			items = append(items, n.TagName+n.Attributes["data-id"])
+		} else {
+			items = append(items, item.(string))
+		}
+	}
+	return strings.Join(items, ",")
+}
+
+func TestQueryXPath(t *testing.T) {
+	root := queryTestDOM(t)
+	tests := []struct {
+		expr     string
+		expected string
+	}{
+		{`//div[@class="item"]`, "div1,div3"},
+		{`//div[contains(@class, "item")]`, "div1,div2,div3"},
+		{`//div[@id='list']/div[2]`, "div2"},
+		{`//div[@id='list']/div[last()]`, "div3"},
+		{`//div[@id='list']/div[position() < 3]/a/@href`, "/a/1,/a/2"},
+		{`//a/text()`, "First,Second,第三个"},
+		{`//a[text()="Second"]/../@data-id`, "2"},
+		{`//span[number(.) > 8]/parent::div`, "div1,div2"},
+		{`//div[em]`, "div3"},
+		{`//div[not(em) and @data-id]`, "div1,div2"},
+		{`(//a)[1]`, "a"},
+		{`//a | //em`, "a,a,a,em"},
+		{`//em/preceding-sibling::*[1]`, "span"},
+		{`//a[starts-with(@href, "/a/")][2]`, ""},
+		{`(//a[starts-with(@href, "/a/")])[2]/following::span[1]`, "span"},
+		{`count(//div[@class])`, "4"},
+		{`sum(//span)`, "42"},
+		{`string(//p)`, "共3条"},
+		{`normalize-space(//div[@data-id=1]/a)`, "First one"},
+		{`//div[@data-id="1"]/a/ancestor::div[@id]/@id`, "list"},
+		{`//td[2]`, "td"},
+		{`//*[@disabled]/@type`, "text"},
+		{`//div[@data-id="2"]/span * 2`, "50"},
+		{`//div[@data-id=2]/span div 5`, "5"},
+	}
+	for _, tt := range tests {
+		res, err := QueryXPath(root, tt.expr)
+		if err != nil {
+			t.Errorf("%s: %v", tt.expr, err)
+			continue
+		}
+		if got := queryResult(res); got != tt.expected {
+			t.Errorf("%s: 期望=%q, 得到=%q", tt.expr, tt.expected, got)
+		}
+	}
+
+	for _, expr := range []string{`//div[`, `//div[@a="x]`, `foo(1)`, `//div]`, `bad::div`} {
+		if _, err := QueryXPath(root, expr); err == nil {
+			t.Errorf("%s: 应该报错", expr)
+		}
+	}
+}
+
+func TestQueryCSS(t *testing.T) {
+	root := queryTestDOM(t)
+	tests := []struct {
+		selector string
+		expected string
+	}{
+		{`.item`, "div1,div2,div3"},
+		{`div.item.hot`, "div2"},
+		{`#list > .item:nth-child(2n+1)`, "div1,div3"},
+		{`[data-id="3"] a`, "a"},
+		{`.item:not(.hot) .price`, "span,span"},
+		{`div[data-id^="2"], div[data-id$="3"]`, "div2,div3"},
+		{`.item:has(em)`, "div3"},
+		{`.item:has(> a b)`, "div1"},
+		{`a + span`, "span,span,span"},
+		{`.hot ~ div`, "div3"},
+		{`.item:last-of-type`, "div3"},
+		{`div:contains("Second")`, "div,div2"},
+		{`[class~=main]`, "div"},
+		{`input:checked`, "input"},
+		{`input:enabled`, "input"},
+		{`td:first-child`, "td"},
+		{`p > b:only-child`, "b"},
+	}
+	for _, tt := range tests {
+		res, err := QueryCSS(root, tt.selector)
+		if err != nil {
+			t.Errorf("%s: %v", tt.selector, err)
+			continue
+		}
+		list := make([]any, 0, len(res))
+		for _, n := range res {
+			list = append(list, n)
+		}
+		if got := queryResult(list); got != tt.expected {
+			t.Errorf("%s: 期望=%q, 得到=%q", tt.selector, tt.expected, got)
+		}
+	}
+
+	for _, selector := range []string{`div >`, `[data-id`, `a::before`, `:unknown`, `div..a`} {
+		if _, err := QueryCSS(root, selector); err == nil {
+			t.Errorf("%s: 应该报错", selector)
+		}
+	}
+}
+
+func TestNodeTextHTML(t *testing.T) {
+	root := queryTestDOM(t)
+	nodes, _ := QueryCSS(root, `[data-id="1"]`)
+	if len(nodes) != 1 {
+		t.Fatalf("nodes: %d", len(nodes))
+	}
+	n := nodes[0]
+	if text := NodeText(n); text != "First one 10" {
+		t.Errorf("text: %q", text)
+	}
+	html := NodeHTML(n)
+	if html != `<div class="item" data-id="1"><a href="/a/1">First<b>one</b></a><span class="price">10</span></div>` {
+		t.Errorf("html: %s", html)
+	}
+
+	// 对结果再次查询: xpath 接在原节点的xpath后面
+	sub, err := ParseHTMLNode(html, n.TagName, n.XPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := QueryXPath(sub, "./a/b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := res.([]any)[0].(*DOMNode)
+	if b.XPath != n.XPath+"/a[1]/b[1]" {
+		t.Errorf("xpath: %s %s", b.XPath, n.XPath)
+	}
+
+	tds, _ := QueryCSS(root, "tr")
+	sub, err = ParseHTMLNode(NodeHTML(tds[0]), "tr", tds[0].XPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cells, _ := QueryCSS(sub, "td"); len(cells) != 2 {
+		t.Errorf("tr中的td: %d", len(cells))
+	}
+}
+
+func TestQuerySelector(t *testing.T) {
+	root := queryTestDOM(t)
+	for selector, expected := range map[string]string{
+		"//em":           "em",
+		"xpath=//em":     "em",
+		"css=.hot a":     "a",
+		"span.price >em": "",
+	} {
+		res, err := QuerySelector(root, selector)
+		if err != nil {
+			t.Errorf("%s: %v", selector, err)
+			continue
+		}
+		if nodes, ok := res.([]*DOMNode); ok {
+			list := make([]any, 0, len(nodes))
+			for _, n := range nodes {
+				list = append(list, n)
+			}
+			res = list
+		}
+		if got := queryResult(res); got != expected {
+			t.Errorf("%s: 期望=%q, 得到=%q", selector, expected, got)
+		}
+	}
+	if _, err := QuerySelector(root, "text=Second"); err == nil {
+		t.Error("text= 应该报错")
+	}
+}
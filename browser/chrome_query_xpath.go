@@ -0,0 +1,1068 @@
+package browser
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+/*
+xpath 1.0 的离线实现
+
+支持: 绝对与相对路径、// . .. * @属性、轴(child descendant descendant-or-self self parent ancestor ancestor-or-self
+following-sibling preceding-sibling following preceding attribute)、text() node() comment()、谓词与位置、| 并集、
+比较与算术运算(and or = != < <= > >= + - * div mod)、
+函数 last position count string concat contains starts-with ends-with normalize-space string-length substring
+substring-before substring-after translate lower-case upper-case not true false boolean number sum floor ceiling round name local-name
+*/
+
+// xpathItem 节点集中的一项: 节点, 或节点的属性(attr不为空)
+type xpathItem struct {
+	node *DOMNode
+	attr string
+}
+
+type xpathNodeSet []xpathItem
+
+type xpathContext struct {
+	idx  *domIndex
+	item xpathItem
+	pos  int
+	size int
+}
+
+type xpathExpr interface {
+	eval(ctx *xpathContext) any
+}
+
+// QueryXPath 在root下执行xpath, 节点集的结果为列表: 元素为 *DOMNode, 文本与属性为字符串;
+// 其他结果(如 count()、string())为 float64、string、bool
+func QueryXPath(root *DOMNode, expr string) (any, error) {
+	if root == nil {
+		return nil, fmt.Errorf("html为空")
+	}
+	e, err := compileXPath(expr)
+	if err != nil {
+		return nil, err
+	}
+	idx := newDOMIndex(root)
+	res, err := evalXPath(idx, e)
+	if err != nil {
+		return nil, err
+	}
+	ns, ok := res.(xpathNodeSet)
+	if !ok {
+		return res, nil
+	}
+	list := make([]any, 0, len(ns))
+	for _, item := range ns {
+		if item.attr == "" && (isElement(item.node) || item.node.Type == html.DocumentNode) {
+			list = append(list, item.node)
+		} else {
+			list = append(list, xpathString(item))
+		}
+	}
+	return list, nil
+}
+
+// evalXPath 以根节点为上下文执行, 表达式中的错误(如未知函数)通过panic传出
+func evalXPath(idx *domIndex, e xpathExpr) (res any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if xe, ok := r.(xpathError); ok {
+				err = xe
+				return
+			}
+			panic(r)
+		}
+	}()
+	return e.eval(&xpathContext{idx: idx, item: xpathItem{node: idx.root}, pos: 1, size: 1}), nil
+}
+
+type xpathError struct{ msg string }
+
+func (e xpathError) Error() string { return e.msg }
+
+// 词法
+
+type xpathToken struct {
+	kind  byte // n:名称 s:字符串 d:数字 o:符号 e:结束
+	value string
+}
+
+func tokenizeXPath(expr string) ([]xpathToken, error) {
+	tokens := make([]xpathToken, 0)
+	rs := []rune(expr)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"' || r == '\'':
+			end := i + 1
+			for end < len(rs) && rs[end] != r {
+				end++
+			}
+			if end >= len(rs) {
+				return nil, fmt.Errorf("xpath字符串没有结束引号: %s", expr)
+			}
+			tokens = append(tokens, xpathToken{'s', string(rs[i+1 : end])})
+			i = end + 1
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(rs) && unicode.IsDigit(rs[i+1])):
+			end := i
+			for end < len(rs) && (unicode.IsDigit(rs[end]) || rs[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, xpathToken{'d', string(rs[i:end])})
+			i = end
+		case unicode.IsLetter(r) || r == '_':
+			end := i
+			for end < len(rs) && (unicode.IsLetter(rs[end]) || unicode.IsDigit(rs[end]) || rs[end] == '_' || rs[end] == '-' ||
+				(rs[end] == '.' && end+1 < len(rs) && rs[end+1] != '.') || (rs[end] == ':' && end+1 < len(rs) && rs[end+1] != ':')) {
+				end++
+			}
+			tokens = append(tokens, xpathToken{'n', string(rs[i:end])})
+			i = end
+		default:
+			matched := false
+			for _, op := range []string{"//", "::", "..", "!=", "<=", ">=", "/", ".", "(", ")", "[", "]", "@", ",", "|", "=", "<", ">", "+", "-", "*"} {
+				if strings.HasPrefix(string(rs[i:]), op) {
+					tokens = append(tokens, xpathToken{'o', op})
+					i += len([]rune(op))
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("xpath中有不支持的字符 %q: %s", r, expr)
+			}
+		}
+	}
+	return append(tokens, xpathToken{kind: 'e'}), nil
+}
+
+// 语法
+
+type xpathParser struct {
+	tokens []xpathToken
+	pos    int
+	expr   string
+}
+
+func compileXPath(expr string) (xpathExpr, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, fmt.Errorf("xpath不能为空")
+	}
+	tokens, err := tokenizeXPath(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &xpathParser{tokens: tokens, expr: expr}
+	e, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("xpath语法错误: %s, %s", expr, err.Error())
+	}
+	return e, nil
+}
+
+func (p *xpathParser) peek() xpathToken { return p.tokens[p.pos] }
+func (p *xpathParser) peekAt(n int) xpathToken {
+	if p.pos+n < len(p.tokens) {
+		return p.tokens[p.pos+n]
+	}
+	return xpathToken{kind: 'e'}
+}
+func (p *xpathParser) next() xpathToken {
+	t := p.tokens[p.pos]
+	if t.kind != 'e' {
+		p.pos++
+	}
+	return t
+}
+func (p *xpathParser) isOp(v string) bool   { t := p.peek(); return t.kind == 'o' && t.value == v }
+func (p *xpathParser) isName(v string) bool { t := p.peek(); return t.kind == 'n' && t.value == v }
+
+func (p *xpathParser) expect(v string) error {
+	if !p.isOp(v) {
+		return fmt.Errorf("第%d个记号应为 %s", p.pos+1, v)
+	}
+	p.next()
+	return nil
+}
+
+func (p *xpathParser) parse() (e xpathExpr, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if xe, ok := r.(xpathError); ok {
+				err = xe
+				return
+			}
+			panic(r)
+		}
+	}()
+	e = p.parseOr()
+	if p.peek().kind != 'e' {
+		return nil, fmt.Errorf("多余的内容 %q", p.peek().value)
+	}
+	return e, nil
+}
+
+func (p *xpathParser) fail(format string, args ...any) {
+	panic(xpathError{fmt.Sprintf(format, args...)})
+}
+
+func (p *xpathParser) parseOr() xpathExpr {
+	left := p.parseAnd()
+	for p.isName("or") {
+		p.next()
+		left = &xpathBinary{op: "or", left: left, right: p.parseAnd()}
+	}
+	return left
+}
+
+func (p *xpathParser) parseAnd() xpathExpr {
+	left := p.parseEquality()
+	for p.isName("and") {
+		p.next()
+		left = &xpathBinary{op: "and", left: left, right: p.parseEquality()}
+	}
+	return left
+}
+
+func (p *xpathParser) parseEquality() xpathExpr {
+	left := p.parseRelational()
+	for p.isOp("=") || p.isOp("!=") {
+		op := p.next().value
+		left = &xpathBinary{op: op, left: left, right: p.parseRelational()}
+	}
+	return left
+}
+
+func (p *xpathParser) parseRelational() xpathExpr {
+	left := p.parseAdditive()
+	for p.isOp("<") || p.isOp("<=") || p.isOp(">") || p.isOp(">=") {
+		op := p.next().value
+		left = &xpathBinary{op: op, left: left, right: p.parseAdditive()}
+	}
+	return left
+}
+
+func (p *xpathParser) parseAdditive() xpathExpr {
+	left := p.parseMultiplicative()
+	for p.isOp("+") || p.isOp("-") {
+		op := p.next().value
+		left = &xpathBinary{op: op, left: left, right: p.parseMultiplicative()}
+	}
+	return left
+}
+
+func (p *xpathParser) parseMultiplicative() xpathExpr {
+	left := p.parseUnary()
+	for p.isOp("*") || p.isName("div") || p.isName("mod") {
+		op := p.next().value
+		left = &xpathBinary{op: op, left: left, right: p.parseUnary()}
+	}
+	return left
+}
+
+func (p *xpathParser) parseUnary() xpathExpr {
+	if p.isOp("-") {
+		p.next()
+		return &xpathNeg{expr: p.parseUnary()}
+	}
+	left := p.parsePath()
+	for p.isOp("|") {
+		p.next()
+		left = &xpathUnion{left: left, right: p.parsePath()}
+	}
+	return left
+}
+
+// xpathNodeTypes 节点类型测试
+var xpathNodeTypes = map[string]bool{"text": true, "node": true, "comment": true, "processing-instruction": true}
+
+// xpathAxes 支持的轴
+var xpathAxes = map[string]bool{
+	"child": true, "descendant": true, "descendant-or-self": true, "self": true, "parent": true,
+	"ancestor": true, "ancestor-or-self": true, "following-sibling": true, "preceding-sibling": true,
+	"following": true, "preceding": true, "attribute": true,
+}
+
+func (p *xpathParser) parsePath() xpathExpr {
+	t := p.peek()
+	if t.kind == 'o' && (t.value == "/" || t.value == "//") {
+		path := &xpathPath{absolute: true}
+		p.next()
+		if t.value == "//" {
+			path.steps = append(path.steps, descendantOrSelfStep())
+			path.steps = append(path.steps, p.parseStep())
+		} else if p.startsStep() {
+			path.steps = append(path.steps, p.parseStep())
+		}
+		p.parseSteps(path)
+		return path
+	}
+	if p.startsStep() {
+		path := &xpathPath{}
+		path.steps = append(path.steps, p.parseStep())
+		p.parseSteps(path)
+		return path
+	}
+
+	// 过滤表达式: 基本表达式与谓词, 后面可以接路径
+	filter := &xpathFilter{primary: p.parsePrimary()}
+	for p.isOp("[") {
+		filter.preds = append(filter.preds, p.parsePredicate())
+	}
+	if !p.isOp("/") && !p.isOp("//") {
+		if len(filter.preds) == 0 {
+			return filter.primary
+		}
+		return filter
+	}
+	path := &xpathPath{filter: filter}
+	p.parseSteps(path)
+	return path
+}
+
+// startsStep 当前记号是否是路径中的一步
+func (p *xpathParser) startsStep() bool {
+	t := p.peek()
+	switch t.kind {
+	case 'o':
+		return t.value == "." || t.value == ".." || t.value == "@" || t.value == "*"
+	case 'n':
+		next := p.peekAt(1)
+		if next.kind == 'o' && next.value == "::" {
+			return true
+		}
+		if next.kind == 'o' && next.value == "(" {
+			return xpathNodeTypes[t.value]
+		}
+		return true
+	}
+	return false
+}
+
+func (p *xpathParser) parseSteps(path *xpathPath) {
+	for p.isOp("/") || p.isOp("//") {
+		if p.next().value == "//" {
+			path.steps = append(path.steps, descendantOrSelfStep())
+		}
+		path.steps = append(path.steps, p.parseStep())
+	}
+}
+
+func descendantOrSelfStep() *xpathStep {
+	return &xpathStep{axis: "descendant-or-self", test: "node"}
+}
+
+func (p *xpathParser) parseStep() *xpathStep {
+	if p.isOp(".") {
+		p.next()
+		return &xpathStep{axis: "self", test: "node"}
+	}
+	if p.isOp("..") {
+		p.next()
+		return &xpathStep{axis: "parent", test: "node"}
+	}
+	step := &xpathStep{axis: "child"}
+	if p.isOp("@") {
+		p.next()
+		step.axis = "attribute"
+	} else if p.peek().kind == 'n' && p.peekAt(1).kind == 'o' && p.peekAt(1).value == "::" {
+		axis := p.next().value
+		if !xpathAxes[axis] {
+			p.fail("不支持的轴 %s", axis)
+		}
+		p.next()
+		step.axis = axis
+	}
+
+	t := p.next()
+	switch {
+	case t.kind == 'o' && t.value == "*":
+		step.test = "*"
+	case t.kind == 'n' && xpathNodeTypes[t.value] && p.isOp("("):
+		p.next()
+		if t.value == "processing-instruction" && p.peek().kind == 's' {
+			p.next()
+		}
+		if err := p.expect(")"); err != nil {
+			p.fail(err.Error())
+		}
+		step.test = t.value
+	case t.kind == 'n':
+		step.test = "name"
+		step.name = strings.ToLower(t.value)
+		if i := strings.LastIndex(step.name, ":"); i >= 0 {
+			step.name = step.name[i+1:] // 忽略命名空间前缀
+		}
+	default:
+		p.fail("第%d个记号应为节点名称: %q", p.pos, t.value)
+	}
+	for p.isOp("[") {
+		step.preds = append(step.preds, p.parsePredicate())
+	}
+	return step
+}
+
+func (p *xpathParser) parsePredicate() xpathExpr {
+	p.next()
+	e := p.parseOr()
+	if err := p.expect("]"); err != nil {
+		p.fail(err.Error())
+	}
+	return e
+}
+
+func (p *xpathParser) parsePrimary() xpathExpr {
+	t := p.next()
+	switch t.kind {
+	case 's':
+		return &xpathLiteral{value: t.value}
+	case 'd':
+		f, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			p.fail("数字格式错误 %s", t.value)
+		}
+		return &xpathLiteral{value: f}
+	case 'n':
+		if !p.isOp("(") {
+			p.fail("%s 后应为 (", t.value)
+		}
+		p.next()
+		fn := &xpathFunc{name: t.value}
+		if _, ok := xpathFuncs[fn.name]; !ok {
+			p.fail("不支持的函数 %s()", fn.name)
+		}
+		for !p.isOp(")") {
+			fn.args = append(fn.args, p.parseOr())
+			if p.isOp(",") {
+				p.next()
+				continue
+			}
+			if !p.isOp(")") {
+				p.fail("函数 %s() 的参数后应为 , 或 )", fn.name)
+			}
+		}
+		p.next()
+		return fn
+	case 'o':
+		if t.value == "(" {
+			e := p.parseOr()
+			if err := p.expect(")"); err != nil {
+				p.fail(err.Error())
+			}
+			return e
+		}
+	}
+	p.fail("不能识别的记号 %q", t.value)
+	return nil
+}
+
+// 表达式
+
+type xpathLiteral struct{ value any }
+
+func (e *xpathLiteral) eval(*xpathContext) any { return e.value }
+
+type xpathNeg struct{ expr xpathExpr }
+
+func (e *xpathNeg) eval(ctx *xpathContext) any { return -xpathNumber(e.expr.eval(ctx)) }
+
+type xpathUnion struct{ left, right xpathExpr }
+
+func (e *xpathUnion) eval(ctx *xpathContext) any {
+	left, ok1 := e.left.eval(ctx).(xpathNodeSet)
+	right, ok2 := e.right.eval(ctx).(xpathNodeSet)
+	if !ok1 || !ok2 {
+		panic(xpathError{"| 两边必须是节点集"})
+	}
+	return sortItems(ctx.idx, append(append(xpathNodeSet{}, left...), right...))
+}
+
+type xpathBinary struct {
+	op          string
+	left, right xpathExpr
+}
+
+func (e *xpathBinary) eval(ctx *xpathContext) any {
+	switch e.op {
+	case "or":
+		return xpathBool(e.left.eval(ctx)) || xpathBool(e.right.eval(ctx))
+	case "and":
+		return xpathBool(e.left.eval(ctx)) && xpathBool(e.right.eval(ctx))
+	case "=", "!=", "<", "<=", ">", ">=":
+		return xpathCompare(e.op, e.left.eval(ctx), e.right.eval(ctx))
+	}
+	a, b := xpathNumber(e.left.eval(ctx)), xpathNumber(e.right.eval(ctx))
+	switch e.op {
+	case "+":
+		return a + b
+	case "-":
+		return a - b
+	case "*":
+		return a * b
+	case "div":
+		return a / b
+	case "mod":
+		return math.Mod(a, b)
+	}
+	return math.NaN()
+}
+
+type xpathFilter struct {
+	primary xpathExpr
+	preds   []xpathExpr
+}
+
+func (e *xpathFilter) eval(ctx *xpathContext) any {
+	ns, ok := e.primary.eval(ctx).(xpathNodeSet)
+	if !ok {
+		panic(xpathError{"谓词只能用于节点集"})
+	}
+	for _, pred := range e.preds {
+		ns = filterItems(ctx.idx, ns, pred)
+	}
+	return ns
+}
+
+type xpathPath struct {
+	absolute bool
+	filter   xpathExpr
+	steps    []*xpathStep
+}
+
+func (e *xpathPath) eval(ctx *xpathContext) any {
+	var ns xpathNodeSet
+	switch {
+	case e.absolute:
+		ns = xpathNodeSet{{node: ctx.idx.root}}
+	case e.filter != nil:
+		res, ok := e.filter.eval(ctx).(xpathNodeSet)
+		if !ok {
+			panic(xpathError{"/ 前必须是节点集"})
+		}
+		ns = res
+	default:
+		ns = xpathNodeSet{ctx.item}
+	}
+	for _, step := range e.steps {
+		next := make(xpathNodeSet, 0)
+		for _, item := range ns {
+			next = append(next, step.apply(ctx.idx, item)...)
+		}
+		ns = sortItems(ctx.idx, next)
+	}
+	return ns
+}
+
+type xpathStep struct {
+	axis  string
+	test  string // name * text node comment processing-instruction
+	name  string
+	preds []xpathExpr
+}
+
+// apply 对一个上下文节点执行这一步, 结果按轴的方向排列后执行谓词
+func (s *xpathStep) apply(idx *domIndex, item xpathItem) xpathNodeSet {
+	candidates := make(xpathNodeSet, 0)
+	add := func(n *DOMNode) {
+		if s.match(n) {
+			candidates = append(candidates, xpathItem{node: n})
+		}
+	}
+	n := item.node
+	if item.attr != "" {
+		switch s.axis {
+		case "self":
+			if s.test == "node" || s.test == "*" || (s.test == "name" && s.name == item.attr) {
+				candidates = append(candidates, item)
+			}
+		case "parent", "ancestor-or-self", "ancestor":
+			if s.axis == "ancestor-or-self" && (s.test == "node" || (s.test == "name" && s.name == item.attr)) {
+				candidates = append(candidates, item)
+			}
+			for p := n; p != nil; p = idx.parent[p] {
+				add(p)
+				if s.axis == "parent" {
+					break
+				}
+			}
+		}
+		return s.filter(idx, candidates)
+	}
+
+	switch s.axis {
+	case "child":
+		for _, c := range n.Children {
+			add(c)
+		}
+	case "descendant", "descendant-or-self":
+		if s.axis == "descendant-or-self" {
+			add(n)
+		}
+		var walk func(n *DOMNode)
+		walk = func(n *DOMNode) {
+			for _, c := range n.Children {
+				add(c)
+				walk(c)
+			}
+		}
+		walk(n)
+	case "self":
+		add(n)
+	case "parent":
+		if p := idx.parent[n]; p != nil {
+			add(p)
+		}
+	case "ancestor", "ancestor-or-self":
+		if s.axis == "ancestor-or-self" {
+			add(n)
+		}
+		for p := idx.parent[n]; p != nil; p = idx.parent[p] {
+			add(p)
+		}
+	case "following-sibling", "preceding-sibling":
+		p := idx.parent[n]
+		if p == nil {
+			break
+		}
+		pos := 0
+		for i, c := range p.Children {
+			if c == n {
+				pos = i
+			}
+		}
+		if s.axis == "following-sibling" {
+			for _, c := range p.Children[pos+1:] {
+				add(c)
+			}
+		} else {
+			for i := pos - 1; i >= 0; i-- {
+				add(p.Children[i])
+			}
+		}
+	case "following":
+		last := n
+		for len(last.Children) > 0 {
+			last = last.Children[len(last.Children)-1]
+		}
+		for _, c := range idx.nodes[idx.order[last]+1:] {
+			add(c)
+		}
+	case "preceding":
+		ancestors := make(map[*DOMNode]bool)
+		for p := idx.parent[n]; p != nil; p = idx.parent[p] {
+			ancestors[p] = true
+		}
+		for i := idx.order[n] - 1; i >= 0; i-- {
+			if c := idx.nodes[i]; !ancestors[c] {
+				add(c)
+			}
+		}
+	case "attribute":
+		if !isElement(n) {
+			break
+		}
+		names := make([]string, 0, len(n.Attributes))
+		for k := range n.Attributes {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		for _, k := range names {
+			if s.test == "node" || s.test == "*" || (s.test == "name" && s.name == strings.ToLower(k)) {
+				candidates = append(candidates, xpathItem{node: n, attr: k})
+			}
+		}
+	}
+	return s.filter(idx, candidates)
+}
+
+func (s *xpathStep) filter(idx *domIndex, ns xpathNodeSet) xpathNodeSet {
+	for _, pred := range s.preds {
+		ns = filterItems(idx, ns, pred)
+	}
+	return ns
+}
+
+// match 节点测试
+func (s *xpathStep) match(n *DOMNode) bool {
+	switch s.test {
+	case "node":
+		return true
+	case "*":
+		return isElement(n)
+	case "name":
+		return isElement(n) && strings.ToLower(n.TagName) == s.name
+	case "text":
+		return n.Type == html.TextNode
+	case "comment":
+		return n.Type == html.CommentNode
+	}
+	return false
+}
+
+// filterItems 按谓词过滤, 谓词为数字时表示位置
+func filterItems(idx *domIndex, ns xpathNodeSet, pred xpathExpr) xpathNodeSet {
+	res := make(xpathNodeSet, 0, len(ns))
+	for i, item := range ns {
+		v := pred.eval(&xpathContext{idx: idx, item: item, pos: i + 1, size: len(ns)})
+		if f, ok := v.(float64); ok {
+			if f == float64(i+1) {
+				res = append(res, item)
+			}
+		} else if xpathBool(v) {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
+// sortItems 按文档顺序排序并去重, 属性排在所属元素之后
+func sortItems(idx *domIndex, ns xpathNodeSet) xpathNodeSet {
+	seen := make(map[xpathItem]bool, len(ns))
+	res := make(xpathNodeSet, 0, len(ns))
+	for _, item := range ns {
+		if !seen[item] {
+			seen[item] = true
+			res = append(res, item)
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		oi, oj := idx.order[res[i].node], idx.order[res[j].node]
+		if oi != oj {
+			return oi < oj
+		}
+		return res[i].attr < res[j].attr
+	})
+	return res
+}
+
+// 类型转换
+
+func xpathString(v any) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case bool:
+		if val {
+			return "true"
+		}
+		return "false"
+	case float64:
+		if math.IsNaN(val) {
+			return "NaN"
+		}
+		if val == math.Trunc(val) && math.Abs(val) < 1e15 {
+			return strconv.FormatInt(int64(val), 10)
+		}
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case xpathNodeSet:
+		if len(val) == 0 {
+			return ""
+		}
+		return xpathString(val[0])
+	case xpathItem:
+		if val.attr != "" {
+			return val.node.Attributes[val.attr]
+		}
+		switch val.node.Type {
+		case html.TextNode, html.CommentNode:
+			return val.node.Content
+		}
+		return NodeText(val.node)
+	}
+	return ""
+}
+
+func xpathNumber(v any) float64 {
+	switch val := v.(type) {
+	case float64:
+		return val
+	case bool:
+		if val {
+			return 1
+		}
+		return 0
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(xpathString(v)), 64)
+	if err != nil {
+		return math.NaN()
+	}
+	return f
+}
+
+func xpathBool(v any) bool {
+	switch val := v.(type) {
+	case bool:
+		return val
+	case float64:
+		return val != 0 && !math.IsNaN(val)
+	case string:
+		return val != ""
+	case xpathNodeSet:
+		return len(val) > 0
+	}
+	return false
+}
+
+// xpathCompare 比较运算, 节点集与其他值比较时任意一个节点满足即为true
+func xpathCompare(op string, a, b any) bool {
+	if ns, ok := a.(xpathNodeSet); ok {
+		if _, isBool := b.(bool); isBool {
+			return compareValues(op, xpathBool(ns), b)
+		}
+		for _, item := range ns {
+			if xpathCompare(op, xpathString(item), b) {
+				return true
+			}
+		}
+		return false
+	}
+	if ns, ok := b.(xpathNodeSet); ok {
+		if _, isBool := a.(bool); isBool {
+			return compareValues(op, a, xpathBool(ns))
+		}
+		for _, item := range ns {
+			if xpathCompare(op, a, xpathString(item)) {
+				return true
+			}
+		}
+		return false
+	}
+	return compareValues(op, a, b)
+}
+
+func compareValues(op string, a, b any) bool {
+	if op == "=" || op == "!=" {
+		var eq bool
+		_, aBool := a.(bool)
+		_, bBool := b.(bool)
+		_, aNum := a.(float64)
+		_, bNum := b.(float64)
+		switch {
+		case aBool || bBool:
+			eq = xpathBool(a) == xpathBool(b)
+		case aNum || bNum:
+			eq = xpathNumber(a) == xpathNumber(b)
+		default:
+			eq = xpathString(a) == xpathString(b)
+		}
+		return eq == (op == "=")
+	}
+	x, y := xpathNumber(a), xpathNumber(b)
+	switch op {
+	case "<":
+		return x < y
+	case "<=":
+		return x <= y
+	case ">":
+		return x > y
+	case ">=":
+		return x >= y
+	}
+	return false
+}
+
+// 函数
+
+type xpathFunc struct {
+	name string
+	args []xpathExpr
+}
+
+func (e *xpathFunc) eval(ctx *xpathContext) any {
+	return xpathFuncs[e.name](ctx, e.args)
+}
+
+// argString 第i个参数的字符串值, 没有时为上下文节点的字符串值
+func argString(ctx *xpathContext, args []xpathExpr, i int) string {
+	if i < len(args) {
+		return xpathString(args[i].eval(ctx))
+	}
+	return xpathString(ctx.item)
+}
+
+func argNodeSet(ctx *xpathContext, args []xpathExpr, name string) xpathNodeSet {
+	if len(args) == 0 {
+		return xpathNodeSet{ctx.item}
+	}
+	ns, ok := args[0].eval(ctx).(xpathNodeSet)
+	if !ok {
+		panic(xpathError{name + "() 的参数必须是节点集"})
+	}
+	return ns
+}
+
+func needArgs(name string, args []xpathExpr, min, max int) {
+	if len(args) < min || (max >= 0 && len(args) > max) {
+		panic(xpathError{fmt.Sprintf("%s() 的参数个数错误", name)})
+	}
+}
+
+var xpathFuncs map[string]func(ctx *xpathContext, args []xpathExpr) any
+
+func init() {
+	xpathFuncs = map[string]func(ctx *xpathContext, args []xpathExpr) any{
+		"last":     func(ctx *xpathContext, args []xpathExpr) any { return float64(ctx.size) },
+		"position": func(ctx *xpathContext, args []xpathExpr) any { return float64(ctx.pos) },
+		"count": func(ctx *xpathContext, args []xpathExpr) any {
+			needArgs("count", args, 1, 1)
+			return float64(len(argNodeSet(ctx, args, "count")))
+		},
+		"string": func(ctx *xpathContext, args []xpathExpr) any {
+			needArgs("string", args, 0, 1)
+			return argString(ctx, args, 0)
+		},
+		"concat": func(ctx *xpathContext, args []xpathExpr) any {
+			needArgs("concat", args, 2, -1)
+			var buf strings.Builder
+			for i := range args {
+				buf.WriteString(argString(ctx, args, i))
+			}
+			return buf.String()
+		},
+		"contains": func(ctx *xpathContext, args []xpathExpr) any {
+			needArgs("contains", args, 2, 2)
+			return strings.Contains(argString(ctx, args, 0), argString(ctx, args, 1))
+		},
+		"starts-with": func(ctx *xpathContext, args []xpathExpr) any {
+			needArgs("starts-with", args, 2, 2)
+			return strings.HasPrefix(argString(ctx, args, 0), argString(ctx, args, 1))
+		},
+		"ends-with": func(ctx *xpathContext, args []xpathExpr) any {
+			needArgs("ends-with", args, 2, 2)
+			return strings.HasSuffix(argString(ctx, args, 0), argString(ctx, args, 1))
+		},
+		"normalize-space": func(ctx *xpathContext, args []xpathExpr) any {
+			needArgs("normalize-space", args, 0, 1)
+			return strings.Join(strings.Fields(argString(ctx, args, 0)), " ")
+		},
+		"string-length": func(ctx *xpathContext, args []xpathExpr) any {
+			needArgs("string-length", args, 0, 1)
+			return float64(len([]rune(argString(ctx, args, 0))))
+		},
+		"substring": func(ctx *xpathContext, args []xpathExpr) any {
+			needArgs("substring", args, 2, 3)
+			rs := []rune(argString(ctx, args, 0))
+			start := math.Round(xpathNumber(args[1].eval(ctx)))
+			end := math.Inf(1)
+			if len(args) == 3 {
+				end = start + math.Round(xpathNumber(args[2].eval(ctx)))
+			}
+			var buf strings.Builder
+			for i, r := range rs {
+				if p := float64(i + 1); p >= start && p < end {
+					buf.WriteRune(r)
+				}
+			}
+			return buf.String()
+		},
+		"substring-before": func(ctx *xpathContext, args []xpathExpr) any {
+			needArgs("substring-before", args, 2, 2)
+			s, sep := argString(ctx, args, 0), argString(ctx, args, 1)
+			if i := strings.Index(s, sep); i >= 0 {
+				return s[:i]
+			}
+			return ""
+		},
+		"substring-after": func(ctx *xpathContext, args []xpathExpr) any {
+			needArgs("substring-after", args, 2, 2)
+			s, sep := argString(ctx, args, 0), argString(ctx, args, 1)
+			if i := strings.Index(s, sep); i >= 0 {
+				return s[i+len(sep):]
+			}
+			return ""
+		},
+		"translate": func(ctx *xpathContext, args []xpathExpr) any {
+			needArgs("translate", args, 3, 3)
+			from, to := []rune(argString(ctx, args, 1)), []rune(argString(ctx, args, 2))
+			var buf strings.Builder
+			for _, r := range argString(ctx, args, 0) {
+				i := -1
+				for j, f := range from {
+					if f == r {
+						i = j
+						break
+					}
+				}
+				switch {
+				case i < 0:
+					buf.WriteRune(r)
+				case i < len(to):
+					buf.WriteRune(to[i])
+				}
+			}
+			return buf.String()
+		},
+		"lower-case": func(ctx *xpathContext, args []xpathExpr) any {
+			needArgs("lower-case", args, 1, 1)
+			return strings.ToLower(argString(ctx, args, 0))
+		},
+		"upper-case": func(ctx *xpathContext, args []xpathExpr) any {
+			needArgs("upper-case", args, 1, 1)
+			return strings.ToUpper(argString(ctx, args, 0))
+		},
+		"not": func(ctx *xpathContext, args []xpathExpr) any {
+			needArgs("not", args, 1, 1)
+			return !xpathBool(args[0].eval(ctx))
+		},
+		"true":  func(ctx *xpathContext, args []xpathExpr) any { return true },
+		"false": func(ctx *xpathContext, args []xpathExpr) any { return false },
+		"boolean": func(ctx *xpathContext, args []xpathExpr) any {
+			needArgs("boolean", args, 1, 1)
+			return xpathBool(args[0].eval(ctx))
+		},
+		"number": func(ctx *xpathContext, args []xpathExpr) any {
+			needArgs("number", args, 0, 1)
+			if len(args) == 0 {
+				return xpathNumber(ctx.item)
+			}
+			return xpathNumber(args[0].eval(ctx))
+		},
+		"sum": func(ctx *xpathContext, args []xpathExpr) any {
+			needArgs("sum", args, 1, 1)
+			total := 0.0
+			for _, item := range argNodeSet(ctx, args, "sum") {
+				total += xpathNumber(item)
+			}
+			return total
+		},
+		"floor": func(ctx *xpathContext, args []xpathExpr) any {
+			needArgs("floor", args, 1, 1)
+			return math.Floor(xpathNumber(args[0].eval(ctx)))
+		},
+		"ceiling": func(ctx *xpathContext, args []xpathExpr) any {
+			needArgs("ceiling", args, 1, 1)
+			return math.Ceil(xpathNumber(args[0].eval(ctx)))
+		},
+		"round": func(ctx *xpathContext, args []xpathExpr) any {
+			needArgs("round", args, 1, 1)
+			return math.Floor(xpathNumber(args[0].eval(ctx)) + 0.5)
+		},
+		"name":       xpathName,
+		"local-name": xpathName,
+	}
+}
+
+func xpathName(ctx *xpathContext, args []xpathExpr) any {
+	needArgs("name", args, 0, 1)
+	ns := argNodeSet(ctx, args, "name")
+	if len(ns) == 0 {
+		return ""
+	}
+	if ns[0].attr != "" {
+		return ns[0].attr
+	}
+	if isElement(ns[0].node) {
+		return ns[0].node.TagName
+	}
+	return ""
+}
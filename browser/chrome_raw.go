@@ -0,0 +1,94 @@
+package browser
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+直接下发cdp指令
+
+任意协议方法通过当前的cdp连接发送, 返回解码后的result; session 指定发送到哪个会话:
+tab(默认) 当前页签的session, browser 浏览器级连接, 其他值作为sessionId
+事件收集: 在指定时长内收集匹配的事件(如 Network.*), 需要先开启对应的域(如 Network.enable)
+*/
+
+// DefaultRawTimeout 直接下发cdp指令等待回复的默认超时时间
+var DefaultRawTimeout = 30 * time.Second
+
+// maxRawEvents 事件收集的最大条数, 超出后丢弃
+const maxRawEvents = 10000
+
+// RawEvent 收集到的cdp事件
+type RawEvent struct {
+	Method    string
+	SessionId string
+	Params    map[string]any
+	Time      time.Time
+}
+
+// RawCall 发送任意cdp指令, 返回指令回复中的result
+func RawCall(method string, params map[string]any, session string, timeout time.Duration) (map[string]any, error) {
+	if !strings.Contains(method, ".") {
+		return nil, fmt.Errorf("cdp方法格式错误: %s, 应为 Domain.method", method)
+	}
+	if timeout <= 0 {
+		timeout = DefaultRawTimeout
+	}
+	log.Printf("[Chrome]下发cdp指令: %s session=%s", method, session)
+	switch session {
+	case "", "tab":
+		if !DefaultNowTab(true) {
+			return nil, notReadyErr()
+		}
+		return cdpCall(chromeInstance.NowTabWSConn, chromeInstance.NowTabSession, method, params, timeout)
+	case "browser":
+		conn, err := ensureBrowserWS()
+		if err != nil {
+			return nil, err
+		}
+		return cdpCall(conn, "", method, params, timeout)
+	default:
+		if !DefaultNowTab(true) {
+			return nil, notReadyErr()
+		}
+		return cdpCall(chromeInstance.NowTabWSConn, session, method, params, timeout)
+	}
+}
+
+// EventCollector 收集匹配的cdp事件
+type EventCollector struct {
+	mu       sync.Mutex
+	events   []RawEvent
+	dropped  int
+	listenId int
+}
+
+// CollectEvents 开始收集事件, pattern 为事件名, 支持 Domain.* 与 *
+func CollectEvents(pattern string) *EventCollector {
+	c := &EventCollector{}
+	c.listenId = OnEvent(pattern, func(method, sessionId string, params map[string]any) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if len(c.events) >= maxRawEvents {
+			c.dropped++
+			return
+		}
+		c.events = append(c.events, RawEvent{Method: method, SessionId: sessionId, Params: params, Time: time.Now()})
+	})
+	return c
+}
+
+// Stop 停止收集并返回收集到的事件
+func (c *EventCollector) Stop() []RawEvent {
+	OffEvent(c.listenId)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.dropped > 0 {
+		log.Printf("[Chrome]事件超过%d条, 丢弃了%d条", maxRawEvents, c.dropped)
+	}
+	return c.events
+}
@@ -0,0 +1,238 @@
+package browser
+
+import (
+	"ChromeBot/utils"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+/*
+录屏
+
+Page.startScreencast 开始后, 页面有变化时浏览器推送 Page.screencastFrame 事件(jpeg图片与时间戳),
+每一帧都要用 Page.screencastFrameAck 确认后才会推送下一帧; 帧保存在内存中, stop 时按扩展名编码保存(见 chrome_video.go)
+keep 大于0时只保留最近 keep 时长的帧, 语句出错时把这些帧保存到 on_error 的位置, 用于任务失败后回看出错前的操作
+内存中的帧数据最多 MaxRecordBytes(256MB, 默认设置下约10分钟), 超过时丢弃最早的帧, 更长的任务请用 keep
+录屏跟随开始时的tab, 切换tab后需要重新开始
+*/
+
+// RecordOptions 录屏选项
+type RecordOptions struct {
+	FPS       int           // 帧率上限, 超过的帧丢弃
+	MaxWidth  int           // 帧的最大宽度, 超过时浏览器按比例缩小
+	MaxHeight int           // 帧的最大高度
+	Quality   int           // jpeg质量 1-100
+	Keep      time.Duration // 大于0时只保留最近这段时间的帧
+	OnError   string        // 语句出错时保存最近的帧的位置
+}
+
+const (
+	DefaultRecordFPS       = 5
+	DefaultRecordMaxWidth  = 1280
+	DefaultRecordMaxHeight = 720
+	DefaultRecordQuality   = 60
+	MaxRecordBytes         = 256 << 20 // 内存中保存的帧数据的大小上限, 超过时丢弃最早的帧
+)
+
+// frameBuffer 按帧率上限、保留时长与大小上限保存帧
+type frameBuffer struct {
+	fps      int
+	keep     time.Duration
+	maxBytes int // 帧数据的大小上限, 0为不限制
+	size     int // 当前帧数据的大小
+	dropped  int // 超过大小上限丢弃的帧数
+	frames   []VideoFrame
+	pending  *VideoFrame // 距上一帧太近的最新一帧, 之后没有新帧时作为最后一帧, 避免丢掉最终的画面
+}
+
+func (b *frameBuffer) add(f VideoFrame) {
+	// 时间戳是浮点数, 留1毫秒的误差
+	if n := len(b.frames); n > 0 && b.fps > 0 && f.Time-b.frames[n-1].Time < 1/float64(b.fps)-0.001 {
+		b.pending = &f
+		return
+	}
+	b.pending = nil
+	b.frames = append(b.frames, f)
+	b.size += len(f.Data)
+	cut := 0
+	for cut < len(b.frames)-1 && b.keep > 0 && f.Time-b.frames[cut].Time > b.keep.Seconds() {
+		b.size -= len(b.frames[cut].Data)
+		cut++
+	}
+	for cut < len(b.frames)-1 && b.maxBytes > 0 && b.size > b.maxBytes {
+		b.size -= len(b.frames[cut].Data)
+		b.dropped++
+		cut++
+	}
+	if cut > 0 {
+		b.frames = append(b.frames[:0], b.frames[cut:]...)
+	}
+}
+
+// list 当前保存的帧的副本
+func (b *frameBuffer) list() []VideoFrame {
+	res := make([]VideoFrame, len(b.frames), len(b.frames)+1)
+	copy(res, b.frames)
+	if b.pending != nil {
+		res = append(res, *b.pending)
+	}
+	return res
+}
+
+type videoRecorder struct {
+	mu        sync.Mutex
+	listenId  int
+	conn      *websocket.Conn
+	session   string
+	path      string
+	opts      RecordOptions
+	buf       *frameBuffer
+	lastSaved float64 // 出错保存时最后一帧的时间, 没有新的帧时不重复保存
+}
+
+var recorder = &videoRecorder{}
+
+// RecordStart 开始录制当前tab, path 为空时只在出错时保存
+func RecordStart(path string, opts RecordOptions) error {
+	if !DefaultNowTab(true) {
+		return notReadyErr()
+	}
+	if opts.FPS <= 0 {
+		opts.FPS = DefaultRecordFPS
+	}
+	if opts.MaxWidth <= 0 {
+		opts.MaxWidth = DefaultRecordMaxWidth
+	}
+	if opts.MaxHeight <= 0 {
+		opts.MaxHeight = DefaultRecordMaxHeight
+	}
+	if opts.Quality <= 0 || opts.Quality > 100 {
+		opts.Quality = DefaultRecordQuality
+	}
+	// 只保留最近的帧但没有指定出错保存位置时, 保存到录屏文件旁边
+	if opts.OnError == "" && opts.Keep > 0 && path != "" {
+		ext := filepath.Ext(path)
+		opts.OnError = strings.TrimSuffix(path, ext) + "_error" + ext
+	}
+	if path == "" && opts.OnError == "" {
+		return fmt.Errorf("需要保存位置或 on_error 的保存位置")
+	}
+
+	recorder.mu.Lock()
+	if recorder.listenId != 0 {
+		recorder.mu.Unlock()
+		return fmt.Errorf("已经在录屏, 请先 stop")
+	}
+	conn, session := chromeInstance.NowTabWSConn, chromeInstance.NowTabSession
+	recorder.conn, recorder.session = conn, session
+	recorder.path, recorder.opts, recorder.lastSaved = path, opts, 0
+	recorder.buf = &frameBuffer{fps: opts.FPS, keep: opts.Keep, maxBytes: MaxRecordBytes}
+	recorder.listenId = OnEvent("Page.screencastFrame", recorder.onFrame)
+	recorder.mu.Unlock()
+
+	_, err := cdpCall(conn, session, "Page.startScreencast", map[string]any{
+		"format":    "jpeg",
+		"quality":   opts.Quality,
+		"maxWidth":  opts.MaxWidth,
+		"maxHeight": opts.MaxHeight,
+	}, 6*time.Second)
+	if err != nil {
+		recorder.mu.Lock()
+		OffEvent(recorder.listenId)
+		recorder.listenId = 0
+		recorder.mu.Unlock()
+		return fmt.Errorf("开始录屏失败: %w", err)
+	}
+	log.Printf("[Chrome]开始录屏, 帧率上限%d, 最大尺寸%dx%d", opts.FPS, opts.MaxWidth, opts.MaxHeight)
+	return nil
+}
+
+func (r *videoRecorder) onFrame(method, sessionId string, params map[string]any) {
+	r.mu.Lock()
+	recording, conn := r.listenId != 0 && sessionId == r.session, r.conn
+	r.mu.Unlock()
+	if !recording {
+		return
+	}
+	// 确认后浏览器才会推送下一帧, 回调在ws读协程中执行不能等待回复
+	if err := cdpSend(conn, sessionId, "Page.screencastFrameAck", map[string]any{"sessionId": params["sessionId"]}); err != nil {
+		utils.Debugf("确认录屏帧失败: %s", err.Error())
+	}
+	data, err := base64.StdEncoding.DecodeString(mapStr(params, "data"))
+	if err != nil || len(data) == 0 {
+		return
+	}
+	metadata, _ := params["metadata"].(map[string]any)
+	timestamp, _ := metadata["timestamp"].(float64)
+	if timestamp == 0 {
+		timestamp = float64(time.Now().UnixNano()) / 1e9
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.listenId == 0 || r.buf == nil {
+		return
+	}
+	dropped := r.buf.dropped
+	r.buf.add(VideoFrame{Data: data, Time: timestamp})
+	if dropped == 0 && r.buf.dropped > 0 {
+		log.Printf("[Chrome]录屏的帧超过%dMB, 开始丢弃最早的帧, 长时间录屏请使用 keep", MaxRecordBytes>>20)
+	}
+}
+
+// RecordStop 停止录屏并保存, 返回保存的路径与帧数; 开始时没有给保存位置则只停止
+func RecordStop() (string, int, error) {
+	recorder.mu.Lock()
+	if recorder.listenId == 0 {
+		recorder.mu.Unlock()
+		return "", 0, fmt.Errorf("没有在录屏")
+	}
+	OffEvent(recorder.listenId)
+	recorder.listenId = 0
+	frames := recorder.buf.list()
+	conn, session, path, fps := recorder.conn, recorder.session, recorder.path, recorder.opts.FPS
+	recorder.buf = nil
+	recorder.mu.Unlock()
+
+	if _, err := cdpCall(conn, session, "Page.stopScreencast", nil, 6*time.Second); err != nil {
+		log.Println("[Chrome]停止录屏出现错误:", err.Error())
+	}
+	if path == "" {
+		log.Printf("[Chrome]停止录屏, 共%d帧", len(frames))
+		return "", len(frames), nil
+	}
+	saved, err := SaveVideo(path, frames, fps)
+	if err != nil {
+		return "", len(frames), err
+	}
+	return saved, len(frames), nil
+}
+
+// RecordSaveOnError 语句出错时保存最近的帧, 不停止录屏; 没有在录屏、没有 on_error 或没有新的帧时返回空
+func RecordSaveOnError() (string, error) {
+	recorder.mu.Lock()
+	if recorder.listenId == 0 || recorder.opts.OnError == "" {
+		recorder.mu.Unlock()
+		return "", nil
+	}
+	frames := recorder.buf.list()
+	if len(frames) == 0 || frames[len(frames)-1].Time == recorder.lastSaved {
+		recorder.mu.Unlock()
+		return "", nil
+	}
+	recorder.lastSaved = frames[len(frames)-1].Time
+	onError, fps := recorder.opts.OnError, recorder.opts.FPS
+	recorder.mu.Unlock()
+
+	// 多次出错时按时间区分文件
+	ext := filepath.Ext(onError)
+	path := strings.TrimSuffix(onError, ext) + "_" + time.Now().Format("20060102_150405") + ext
+	return SaveVideo(path, frames, fps)
+}
@@ -5,16 +5,15 @@ import (
 	"fmt"
 	"log"
 	"time"
-
-	"github.com/gorilla/websocket"
 )
 
 func OpenUrl(url string) (string, error) {
 	if !DefaultNowTab(false) {
-		return "", nil
+		return "", notReadyErr()
 	}
 
-	chromeInstance.NextID++
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 	  "id": %d,
 	  "method": "Page.navigate",
@@ -22,8 +21,8 @@ func OpenUrl(url string) (string, error) {
 	      "url": "%s"
 	  },
 		"sessionId":"%s"
-	}`, chromeInstance.NextID, utils.FixURLProtocol(url), chromeInstance.NowTabSession)
-	err := chromeInstance.NowTabWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	}`, reqID, utils.FixURLProtocol(url), chromeInstance.NowTabSession)
+	err := wsWrite(chromeInstance.NowTabWSConn, []byte(message))
 	if err != nil {
 		log.Println("发送消息失败:", err)
 		return "", fmt.Errorf("发送消息失败")
@@ -36,18 +35,19 @@ func OpenUrl(url string) (string, error) {
 
 	for {
 		select {
-		case msg, ok := <-messageQueue:
+		case msg, ok := <-respCh:
 			if !ok {
 				log.Println("消息队列已关闭")
 				return "", fmt.Errorf("消息队列已关闭")
 			}
 			utils.Debug("OpenUrl 收到的消息 -> ", msg.Content)
-			if chromeInstance.NextID == msg.ID {
+			if reqID == msg.ID {
 				utils.Debug("OpenUrl 匹配到消息 -> ", msg.ID)
 
 				select {
 				case session := <-NowPageLoadEventFired:
 					utils.Debug("页面已完全加载 session = ", session)
+					chromeInstance.LastURL = utils.FixURLProtocol(url)
 					return msg.Content, nil
 				case <-time.After(6 * time.Second):
 					return "", fmt.Errorf("页面加载超时")
@@ -0,0 +1,272 @@
+package browser
+
+import (
+	"ChromeBot/utils"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+请求拦截规则
+
+当前tab开启 Fetch 域拦截所有请求, 收到 Fetch.requestPaused 后按地址匹配规则(通配 *、正则 /.../、包含, 见 utils.MatchURLPattern):
+block 拦截请求(请求失败), mock 直接返回给定的响应, modify 修改请求头、地址等后继续, 代码块规则由回调决定如何处理;
+后添加的规则优先, 没有匹配的规则时请求原样继续
+事件回调在ws读协程中执行不能等待回复, 所以固定的规则通过 cdpSend 下发不等待回复(回复没有登记, 收到后丢弃);
+代码块规则在新的协程中执行, 执行完后用 cdpCall 下发并等待回复, 下发失败时能输出原因
+*/
+
+// RouteRequest 被拦截的请求
+type RouteRequest struct {
+	URL          string
+	Method       string
+	ResourceType string
+	Headers      map[string]string
+	PostData     string
+}
+
+// RouteResult 请求的处理方式
+type RouteResult struct {
+	Action   string            // continue(默认) block mock modify
+	Status   int               // mock 的状态码, 默认200
+	Body     string            // mock 的响应体
+	Headers  map[string]string // mock 时为响应头, modify 时为要设置的请求头(值为空时删除该请求头)
+	URL      string            // modify 时改写的地址
+	Method   string            // modify 时改写的请求方法
+	PostData string            // modify 时改写的请求体
+}
+
+// RouteRule 拦截规则, Handler 不为空时由 Handler 决定处理方式, 否则使用 Result
+type RouteRule struct {
+	Pattern string
+	Result  RouteResult
+	Handler func(req RouteRequest) RouteResult
+}
+
+type router struct {
+	mu       sync.Mutex
+	rules    []*RouteRule
+	sessions map[string]bool // 已开启Fetch的tab session
+	listenId int
+}
+
+var routes = &router{sessions: make(map[string]bool)}
+
+// AddRoute 添加拦截规则并在当前tab开启拦截
+func AddRoute(rule *RouteRule) error {
+	if rule.Pattern == "" {
+		return fmt.Errorf("拦截规则的地址匹配不能为空")
+	}
+	if rule.Handler == nil {
+		switch rule.Result.Action {
+		case "block", "mock", "modify", "continue":
+		default:
+			return fmt.Errorf("不支持的拦截操作: %s, 支持 block mock modify", rule.Result.Action)
+		}
+	}
+	if !DefaultNowTab(true) {
+		return notReadyErr()
+	}
+	routes.mu.Lock()
+	routes.rules = append(routes.rules, rule)
+	if routes.listenId == 0 {
+		routes.listenId = OnEvent("Fetch.requestPaused", routes.onPaused)
+	}
+	session := chromeInstance.NowTabSession
+	enabled := routes.sessions[session]
+	routes.mu.Unlock()
+
+	log.Printf("[Chrome]添加拦截规则: %s %s", rule.Pattern, rule.Result.Action)
+	if enabled {
+		return nil
+	}
+	_, err := tabCall("Fetch.enable", map[string]any{
+		"patterns": []map[string]any{{"urlPattern": "*", "requestStage": "Request"}},
+	})
+	if err != nil {
+		return err
+	}
+	routes.mu.Lock()
+	routes.sessions[session] = true
+	routes.mu.Unlock()
+	return nil
+}
+
+// RemoveRoute 删除地址匹配为pattern的规则, 返回删除的条数
+func RemoveRoute(pattern string) int {
+	routes.mu.Lock()
+	defer routes.mu.Unlock()
+	rules := make([]*RouteRule, 0, len(routes.rules))
+	for _, r := range routes.rules {
+		if r.Pattern != pattern {
+			rules = append(rules, r)
+		}
+	}
+	n := len(routes.rules) - len(rules)
+	routes.rules = rules
+	return n
+}
+
+// ClearRoutes 删除所有规则并关闭当前tab的拦截
+func ClearRoutes() error {
+	routes.mu.Lock()
+	routes.rules = nil
+	if routes.listenId != 0 {
+		OffEvent(routes.listenId)
+		routes.listenId = 0
+	}
+	sessions := routes.sessions
+	routes.sessions = make(map[string]bool)
+	routes.mu.Unlock()
+
+	if chromeInstance != nil && sessions[chromeInstance.NowTabSession] {
+		_, err := tabCall("Fetch.disable", nil)
+		return err
+	}
+	return nil
+}
+
+// match 后添加的规则优先
+func (r *router) match(url string) *RouteRule {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := len(r.rules) - 1; i >= 0; i-- {
+		if utils.MatchURLPattern(r.rules[i].Pattern, url) {
+			return r.rules[i]
+		}
+	}
+	return nil
+}
+
+func (r *router) onPaused(method, sessionId string, params map[string]any) {
+	requestId := mapStr(params, "requestId")
+	request, _ := params["request"].(map[string]any)
+	req := RouteRequest{
+		URL:          mapStr(request, "url"),
+		Method:       mapStr(request, "method"),
+		ResourceType: mapStr(params, "resourceType"),
+		Headers:      headerMap(request["headers"]),
+		PostData:     mapStr(request, "postData"),
+	}
+	rule := r.match(req.URL)
+	switch {
+	case rule == nil:
+		r.send(sessionId, requestId, req, RouteResult{}, false)
+	case rule.Handler != nil:
+		go func() {
+			r.send(sessionId, requestId, req, rule.Handler(req), true)
+		}()
+	default:
+		r.send(sessionId, requestId, req, rule.Result, false)
+	}
+}
+
+// send 按处理方式下发 Fetch 指令, wait 为true时等待回复, 只能在ws读协程之外使用
+func (r *router) send(sessionId, requestId string, req RouteRequest, res RouteResult, wait bool) {
+	method, params := routeCommand(requestId, req, res)
+	if res.Action != "" && res.Action != "continue" {
+		log.Printf("[Chrome]拦截请求 %s: %s %s", res.Action, req.Method, req.URL)
+	}
+	var err error
+	if wait {
+		_, err = cdpCall(chromeInstance.NowTabWSConn, sessionId, method, params, 6*time.Second)
+	} else {
+		err = cdpSend(chromeInstance.NowTabWSConn, sessionId, method, params)
+	}
+	if err != nil {
+		log.Printf("[Chrome]处理拦截的请求失败: %s %s", req.URL, err.Error())
+	}
+}
+
+// routeCommand 处理方式对应的 Fetch 指令与参数
+func routeCommand(requestId string, req RouteRequest, res RouteResult) (string, map[string]any) {
+	switch res.Action {
+	case "block":
+		return "Fetch.failRequest", map[string]any{"requestId": requestId, "errorReason": "BlockedByClient"}
+
+	case "mock":
+		status := res.Status
+		if status <= 0 {
+			status = 200
+		}
+		headers := map[string]string{
+			"Content-Type":                mockContentType(res.Body),
+			"Access-Control-Allow-Origin": "*",
+		}
+		for k, v := range res.Headers {
+			for name := range headers {
+				if strings.EqualFold(name, k) {
+					delete(headers, name)
+				}
+			}
+			headers[k] = v
+		}
+		return "Fetch.fulfillRequest", map[string]any{
+			"requestId":       requestId,
+			"responseCode":    status,
+			"responseHeaders": headerEntries(headers),
+			"body":            base64.StdEncoding.EncodeToString([]byte(res.Body)),
+		}
+
+	case "modify":
+		params := map[string]any{"requestId": requestId}
+		if res.URL != "" {
+			params["url"] = res.URL
+		}
+		if res.Method != "" {
+			params["method"] = res.Method
+		}
+		if res.PostData != "" {
+			params["postData"] = base64.StdEncoding.EncodeToString([]byte(res.PostData))
+		}
+		if len(res.Headers) > 0 {
+			headers := make(map[string]string, len(req.Headers))
+			for k, v := range req.Headers {
+				headers[k] = v
+			}
+			for k, v := range res.Headers {
+				for name := range headers {
+					if strings.EqualFold(name, k) {
+						delete(headers, name)
+					}
+				}
+				if v != "" {
+					headers[k] = v
+				}
+			}
+			params["headers"] = headerEntries(headers)
+		}
+		return "Fetch.continueRequest", params
+	}
+	return "Fetch.continueRequest", map[string]any{"requestId": requestId}
+}
+
+// mockContentType 根据响应体猜测类型
+func mockContentType(body string) string {
+	trimmed := strings.TrimSpace(body)
+	if json.Valid([]byte(trimmed)) && (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) {
+		return "application/json; charset=utf-8"
+	}
+	return http.DetectContentType([]byte(body))
+}
+
+// headerEntries 头信息转为 Fetch 指令的 [{name, value}], 按名称排序
+func headerEntries(headers map[string]string) []map[string]string {
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	res := make([]map[string]string, 0, len(names))
+	for _, k := range names {
+		res = append(res, map[string]string{"name": k, "value": headers[k]})
+	}
+	return res
+}
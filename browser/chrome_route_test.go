@@ -0,0 +1,56 @@
+package browser
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestRouteCommand(t *testing.T) {
+	req := RouteRequest{URL: "http://a.com/api", Method: "GET", Headers: map[string]string{"Accept": "*/*", "Cookie": "a=1"}}
+
+	method, params := routeCommand("1", req, RouteResult{})
+	if method != "Fetch.continueRequest" || len(params) != 1 {
+		t.Errorf("continue: %s %v", method, params)
+	}
+
+	method, params = routeCommand("1", req, RouteResult{Action: "block"})
+	if method != "Fetch.failRequest" || params["errorReason"] != "BlockedByClient" {
+		t.Errorf("block: %s %v", method, params)
+	}
+
+	method, params = routeCommand("1", req, RouteResult{Action: "mock", Body: `{"list":[]}`, Headers: map[string]string{"content-type": "text/json"}})
+	if method != "Fetch.fulfillRequest" || params["responseCode"] != 200 {
+		t.Fatalf("mock: %s %v", method, params)
+	}
+	body, _ := base64.StdEncoding.DecodeString(params["body"].(string))
+	if string(body) != `{"list":[]}` {
+		t.Errorf("mock body: %s", body)
+	}
+	headers := params["responseHeaders"].([]map[string]string)
+	if len(headers) != 2 || headers[0]["name"] != "Access-Control-Allow-Origin" || headers[1]["value"] != "text/json" {
+		t.Errorf("mock headers: %v", headers)
+	}
+
+	method, params = routeCommand("1", req, RouteResult{Action: "modify", URL: "http://b.com/api", Headers: map[string]string{"cookie": "", "X-Token": "t"}})
+	if method != "Fetch.continueRequest" || params["url"] != "http://b.com/api" {
+		t.Fatalf("modify: %s %v", method, params)
+	}
+	headers = params["headers"].([]map[string]string)
+	if len(headers) != 2 || headers[0]["name"] != "Accept" || headers[1]["name"] != "X-Token" {
+		t.Errorf("modify headers: %v", headers)
+	}
+}
+
+func TestMockContentType(t *testing.T) {
+	cases := map[string]string{
+		`{"a":1}`:             "application/json; charset=utf-8",
+		` [1,2]`:              "application/json; charset=utf-8",
+		`<html><body></body>`: "text/html; charset=utf-8",
+		`hello`:               "text/plain; charset=utf-8",
+	}
+	for body, want := range cases {
+		if got := mockContentType(body); got != want {
+			t.Errorf("%q: got %s, want %s", body, got, want)
+		}
+	}
+}
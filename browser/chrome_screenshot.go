@@ -7,135 +7,22 @@ import (
 	"fmt"
 	gt "github.com/mangenotwork/gathertool"
 	"log"
+	"math"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
-// ScreenshotResult 截图结果结构体
-type ScreenshotResult struct {
-	Success bool `json:"success"` // 是否成功
-	//Base64Str string `json:"base64Str"` // 截图base64字符串
-	Width    int    `json:"width"`    // 截图宽度
-	Height   int    `json:"height"`   // 截图高度
-	FilePath string `json:"filePath"` // 保存的文件路径（可选）
-	Error    string `json:"error"`    // 错误信息
-}
-
-func CaptureFullPageScreenshot(outputPath string) (*ScreenshotResult, error) {
-	result := &ScreenshotResult{Success: false}
-
-	if !DefaultNowTab(true) {
-		return result, nil
-	}
-
-	// 步骤1：先获取页面完整尺寸（宽高）
-	pageSize, err := getPageFullSize()
-	if err != nil {
-		result.Error = fmt.Sprintf("获取页面尺寸失败: %v", err)
-		return result, fmt.Errorf("获取页面尺寸失败: %w", err)
-	}
-	result.Width = gt.Any2Int(pageSize["width"])
-	result.Height = gt.Any2Int(pageSize["height"])
-	utils.Debugf("页面完整尺寸：宽=%dpx，高=%dpx", result.Width, result.Height)
-
-	// 步骤2：构造 Page.captureScreenshot 请求（关键参数）
-	chromeInstance.NextID++
-	screenshotMsg := map[string]interface{}{
-		"id":     chromeInstance.NextID,
-		"method": "Page.captureScreenshot",
-		"params": map[string]interface{}{
-			"format":                "png", // 截图格式（png/jpeg/webp）
-			"quality":               100,   // 质量（仅jpeg/webp有效）
-			"captureBeyondViewport": true,  // 核心：捕获视口外内容（全屏）
-			"fromSurface":           true,  // 从渲染表面捕获（更清晰）
-			"clip": map[string]interface{}{ // 截图区域（全屏）
-				"x":      0,
-				"y":      0,
-				"width":  result.Width,
-				"height": result.Height,
-				"scale":  1.0, // 缩放比例
-			},
-		},
-		"sessionId": chromeInstance.NowTabSession,
-	}
-
-	// 步骤3：发送截图请求
-	err = chromeInstance.NowTabWSConn.WriteJSON(screenshotMsg)
-	if err != nil {
-		result.Error = fmt.Sprintf("发送截图请求失败: %v", err)
-		return result, fmt.Errorf("发送截图请求失败: %w", err)
-	}
-	msgStr, _ := json.Marshal(screenshotMsg)
-	utils.Debugf("发送截图请求: %s", string(msgStr))
-
-	// 步骤4：等待并解析截图响应
-	timeout := 10 * time.Second // 截图超时设为10秒（全屏截图可能耗时）
-	timer := time.NewTimer(timeout)
-	defer timer.Stop()
-
-	for {
-		select {
-		case respMsg, ok := <-messageQueue:
-			if !ok {
-				result.Error = "消息队列已关闭"
-				return result, fmt.Errorf("消息队列已关闭")
-			}
-
-			//log.Println("收到截图响应 -> ", respMsg.Content)
-
-			if chromeInstance.NextID == respMsg.ID {
-				// 检查响应错误
-				errObj, err := gt.JsonFind(respMsg.Content, "/error")
-				if err == nil && errObj != nil {
-					errMsg, _ := gt.JsonFind(respMsg.Content, "/error/message")
-					result.Error = fmt.Sprintf("截图执行失败: %s", gt.Any2String(errMsg))
-					return result, fmt.Errorf("截图执行失败: %s", gt.Any2String(errMsg))
-				}
-
-				// 解析base64截图数据
-				base64Data, err := gt.JsonFind(respMsg.Content, "/result/data")
-				if err != nil {
-					result.Error = fmt.Sprintf("解析截图base64失败: %v", err)
-					return result, fmt.Errorf("解析截图base64失败: %w", err)
-				}
-				//result.Base64Str = gt.Any2String(base64Data)
-				result.Success = true
-
-				// 步骤5：如果指定了保存路径，将base64保存为图片文件
-				if outputPath != "" {
-
-					outputPath = utils.SanitizeFileName(outputPath)
-
-					err = saveBase64ToImage(gt.Any2String(base64Data), outputPath)
-					if err != nil {
-						result.Error = fmt.Sprintf("保存截图文件失败: %v", err)
-						return result, fmt.Errorf("保存截图文件失败: %w", err)
-					}
-					result.FilePath = outputPath
-					fmt.Printf("[Chrome]截图已保存到: %s \n", outputPath)
-				}
-
-				return result, nil
-			} else {
-				log.Println("忽略非当前截图请求的响应")
-			}
-
-		case <-timer.C:
-			result.Error = "截图请求超时（10秒）"
-			return result, fmt.Errorf("截图请求超时（10秒）")
-		}
-	}
-}
-
 // getPageFullSize 获取页面完整尺寸（宽高）
 func getPageFullSize() (map[string]interface{}, error) {
 	// 截图前获取尺寸等待500ms
 	time.Sleep(500 * time.Millisecond)
-	chromeInstance.NextID++
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	// 执行JS获取页面完整宽高
 	sizeMsg := map[string]interface{}{
-		"id":     chromeInstance.NextID,
+		"id":     reqID,
 		"method": "Runtime.evaluate",
 		"params": map[string]interface{}{
 			"expression": `({
@@ -160,7 +47,7 @@ func getPageFullSize() (map[string]interface{}, error) {
 	msgStr, _ := json.Marshal(sizeMsg)
 	utils.Debugf("发送: %s", string(msgStr))
 
-	err := chromeInstance.NowTabWSConn.WriteJSON(sizeMsg)
+	err := wsWriteJSON(chromeInstance.NowTabWSConn, sizeMsg)
 	if err != nil {
 		return nil, fmt.Errorf("发送获取尺寸请求失败: %w", err)
 	}
@@ -171,11 +58,11 @@ func getPageFullSize() (map[string]interface{}, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return nil, fmt.Errorf("消息队列关闭")
 			}
-			if chromeInstance.NextID == respMsg.ID {
+			if reqID == respMsg.ID {
 
 				utils.Debug("收到尺寸回复 : ", respMsg.Content)
 
@@ -219,3 +106,231 @@ func saveBase64ToImage(base64Str, outputPath string) error {
 
 	return nil
 }
+
+// ScreenshotOptions 截图选项
+type ScreenshotOptions struct {
+	Element        string   // 只截取该定位器匹配到的元素
+	Viewport       bool     // 只截取当前视口, 默认截取整个页面
+	Format         string   // png(默认) jpeg webp
+	Quality        int      // jpeg、webp 的质量 1-100, 默认80
+	OmitBackground bool     // 透明背景, 页面没有设置背景色时生效(png、webp)
+	Mask           []string // 涂黑的区域(元素定位器), 用于遮挡时间、广告等动态内容
+	Timeout        time.Duration
+}
+
+// DefaultScreenshotQuality jpeg、webp 的默认质量
+const DefaultScreenshotQuality = 80
+
+// ScreenshotFormat 按文件扩展名推断截图格式, 不能推断时返回png
+func ScreenshotFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jpg", ".jpeg":
+		return "jpeg"
+	case ".webp":
+		return "webp"
+	}
+	return "png"
+}
+
+// screenshotMaskAttr 遮挡层的属性, 截图后按它删除
+const screenshotMaskAttr = "data-chromebot-mask"
+
+// screenshotMaskJS 在每个匹配到的元素上覆盖黑色的遮挡层, 返回遮挡的元素数量
+const screenshotMaskJS = `(args) => {
+    const L = __LOCATOR__;
+    let n = 0;
+    for (const sel of args.selectors) {
+        for (const el of L.all(sel)) {
+            const r = el.getBoundingClientRect();
+            if (r.width === 0 && r.height === 0) continue;
+            const d = document.createElement('div');
+            d.setAttribute(args.attr, '');
+            d.style.cssText = 'position:absolute;background:#000;pointer-events:none;z-index:2147483647;margin:0;padding:0;border:0;' +
+                'left:' + (r.left + window.scrollX) + 'px;top:' + (r.top + window.scrollY) + 'px;width:' + r.width + 'px;height:' + r.height + 'px';
+            document.documentElement.appendChild(d);
+            n++;
+        }
+    }
+    return n;
+}`
+
+// screenshotUnmaskJS 删除遮挡层
+const screenshotUnmaskJS = `(args) => document.querySelectorAll('[' + args.attr + ']').forEach(d => d.remove())`
+
+// applyScreenshotMask 覆盖遮挡层, 返回删除遮挡层的函数
+func applyScreenshotMask(selectors []string) (func(), error) {
+	for _, sel := range selectors {
+		if err := ValidateLocator(sel); err != nil {
+			return nil, err
+		}
+	}
+	value, err := frameEval(jsCall(screenshotMaskJS, map[string]any{"selectors": selectors, "attr": screenshotMaskAttr}), false, 6*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	utils.Debugf("[Chrome]截图遮挡了%v个元素", value)
+	return func() {
+		if _, err := frameEval(jsCall(screenshotUnmaskJS, map[string]any{"attr": screenshotMaskAttr}), false, 6*time.Second); err != nil {
+			log.Println("[Chrome]删除截图遮挡层失败:", err.Error())
+		}
+	}, nil
+}
+
+// elementClip 元素在页面中的区域, 会先等待元素可见并滚动到视口中
+func elementClip(selector string, timeout time.Duration) (map[string]any, error) {
+	if err := waitActionable(selector, actionHover, timeout); err != nil {
+		return nil, err
+	}
+	sessionId, objectId, err := locatorObjectId(selector)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = cdpCall(chromeInstance.NowTabWSConn, sessionId, "DOM.scrollIntoViewIfNeeded", map[string]any{"objectId": objectId}, 6*time.Second); err != nil {
+		utils.Debug("scrollIntoViewIfNeeded:", err.Error())
+	}
+	box, err := cdpCall(chromeInstance.NowTabWSConn, sessionId, "DOM.getBoxModel", map[string]any{"objectId": objectId}, 6*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	model, _ := box["model"].(map[string]any)
+	quad, _ := model["border"].([]any)
+	if len(quad) != 8 {
+		return nil, fmt.Errorf("获取元素位置失败: %s", selector)
+	}
+	minX, minY, maxX, maxY := math.MaxFloat64, math.MaxFloat64, -math.MaxFloat64, -math.MaxFloat64
+	for i := 0; i < 8; i += 2 {
+		x, _ := quad[i].(float64)
+		y, _ := quad[i+1].(float64)
+		minX, maxX = math.Min(minX, x), math.Max(maxX, x)
+		minY, maxY = math.Min(minY, y), math.Max(maxY, y)
+	}
+	if maxX-minX < 1 || maxY-minY < 1 {
+		return nil, fmt.Errorf("元素的尺寸为0: %s", selector)
+	}
+
+	// 跨进程的iframe坐标相对于iframe
+	if sessionId != chromeInstance.NowTabSession {
+		offset, err := frameOffset()
+		if err != nil {
+			return nil, err
+		}
+		minX, maxX = minX+offset.X, maxX+offset.X
+		minY, maxY = minY+offset.Y, maxY+offset.Y
+	}
+
+	// getBoxModel 是相对视口的坐标, clip 是相对页面的坐标
+	metrics, err := tabCall("Page.getLayoutMetrics", nil)
+	if err != nil {
+		return nil, err
+	}
+	viewport, _ := metrics["cssVisualViewport"].(map[string]any)
+	pageX, _ := viewport["pageX"].(float64)
+	pageY, _ := viewport["pageY"].(float64)
+	return map[string]any{
+		"x":      minX + pageX,
+		"y":      minY + pageY,
+		"width":  maxX - minX,
+		"height": maxY - minY,
+		"scale":  1.0,
+	}, nil
+}
+
+// Screenshot 按选项截图, 返回图片数据
+func Screenshot(opts ScreenshotOptions) ([]byte, error) {
+	if !DefaultNowTab(true) {
+		return nil, notReadyErr()
+	}
+	if opts.Format == "" {
+		opts.Format = "png"
+	}
+	if opts.Format == "jpg" {
+		opts.Format = "jpeg"
+	}
+	if opts.Format != "png" && opts.Format != "jpeg" && opts.Format != "webp" {
+		return nil, fmt.Errorf("截图格式只支持 png、jpeg、webp: %s", opts.Format)
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultActionTimeout
+	}
+	if opts.OmitBackground && opts.Format == "jpeg" {
+		fmt.Println("[Chrome]jpeg 不支持透明背景, 忽略 omit_background")
+		opts.OmitBackground = false
+	}
+
+	params := map[string]any{
+		"format":      opts.Format,
+		"fromSurface": true,
+	}
+	if opts.Format != "png" {
+		quality := opts.Quality
+		if quality <= 0 {
+			quality = DefaultScreenshotQuality
+		}
+		if quality > 100 {
+			quality = 100
+		}
+		params["quality"] = quality
+	}
+
+	switch {
+	case opts.Element != "":
+		clip, err := elementClip(opts.Element, opts.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		params["clip"] = clip
+		params["captureBeyondViewport"] = true
+	case !opts.Viewport:
+		pageSize, err := getPageFullSize()
+		if err != nil {
+			return nil, fmt.Errorf("获取页面尺寸失败: %w", err)
+		}
+		params["clip"] = map[string]any{
+			"x":      0,
+			"y":      0,
+			"width":  gt.Any2Int(pageSize["width"]),
+			"height": gt.Any2Int(pageSize["height"]),
+			"scale":  1.0,
+		}
+		params["captureBeyondViewport"] = true
+	}
+
+	if len(opts.Mask) > 0 {
+		removeMask, err := applyScreenshotMask(opts.Mask)
+		if err != nil {
+			return nil, err
+		}
+		defer removeMask()
+	}
+	if opts.OmitBackground {
+		if _, err := tabCall("Emulation.setDefaultBackgroundColorOverride", map[string]any{
+			"color": map[string]any{"r": 0, "g": 0, "b": 0, "a": 0},
+		}); err != nil {
+			return nil, err
+		}
+		defer func() {
+			if _, err := tabCall("Emulation.setDefaultBackgroundColorOverride", map[string]any{}); err != nil {
+				log.Println("[Chrome]恢复页面背景失败:", err.Error())
+			}
+		}()
+	}
+
+	res, err := cdpCall(chromeInstance.NowTabWSConn, chromeInstance.NowTabSession, "Page.captureScreenshot", params, 30*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("截图执行失败: %w", err)
+	}
+	data, err := base64.StdEncoding.DecodeString(mapStr(res, "data"))
+	if err != nil {
+		return nil, fmt.Errorf("解析截图base64失败: %w", err)
+	}
+	return data, nil
+}
+
+// SaveScreenshot 保存截图到文件, 会创建父目录
+func SaveScreenshot(data []byte, outputPath string) (string, error) {
+	outputPath = utils.SanitizeFileName(outputPath)
+	if err := saveBase64ToImage(base64.StdEncoding.EncodeToString(data), outputPath); err != nil {
+		return "", fmt.Errorf("保存截图文件失败: %w", err)
+	}
+	return outputPath, nil
+}
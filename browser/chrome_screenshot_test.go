@@ -0,0 +1,20 @@
+package browser
+
+import (
+	"testing"
+)
+
+func TestScreenshotFormat(t *testing.T) {
+	for path, want := range map[string]string{
+		"a.png":      "png",
+		"D:\\b.JPG":  "jpeg",
+		"c.jpeg":     "jpeg",
+		"out/d.webp": "webp",
+		"e":          "png",
+		"f.gif":      "png",
+	} {
+		if got := ScreenshotFormat(path); got != want {
+			t.Errorf("ScreenshotFormat(%s) = %s, want %s", path, got, want)
+		}
+	}
+}
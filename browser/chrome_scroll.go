@@ -7,8 +7,6 @@ import (
 	"fmt"
 	gt "github.com/mangenotwork/gathertool"
 	"log"
-	"strconv"
-	"strings"
 	"time"
 )
 
@@ -33,8 +31,7 @@ type ScrollElementParam struct {
 
 // ScrollByPixel 按像素滚动
 func ScrollByPixel(x, y int) error {
-	jsPixel := strings.ReplaceAll(chromeScrollPixelJS, "__SCROLL_X__", strconv.Itoa(x))
-	jsPixel = strings.ReplaceAll(jsPixel, "__SCROLL_Y__", strconv.Itoa(y))
+	jsPixel := jsCall(chromeScrollPixelJS, map[string]any{"x": x, "y": y})
 	res, err := scroll(jsPixel)
 	log.Printf("[Chrome]滚动结果: %v", res)
 	return err
@@ -42,9 +39,15 @@ func ScrollByPixel(x, y int) error {
 
 // ScrollToElement 按元素滚动
 func ScrollToElement(xPath string) error {
-	xPath = "'" + strings.ReplaceAll(xPath, "\"", "\\\"") + "'"
-	jsElement := strings.ReplaceAll(chromeScrollElementJS, "__SCROLL_XPATH__", xPath)
-	jsElement = strings.ReplaceAll(jsElement, "__SCROLL_IS_SMOOTH__", strconv.FormatBool(true))
+	return ScrollToElementWithTimeout(xPath, DefaultActionTimeout)
+}
+
+// ScrollToElementWithTimeout 按元素滚动, 先在timeout内等待元素出现在DOM中
+func ScrollToElementWithTimeout(xPath string, timeout time.Duration) error {
+	if err := waitActionable(xPath, actionScroll, timeout); err != nil {
+		return err
+	}
+	jsElement := jsCall(chromeScrollElementJS, map[string]any{"xpath": xPath, "smooth": true})
 	res, err := scroll(jsElement)
 	log.Printf("[Chrome]滚动结果: %v", res)
 	return err
@@ -60,22 +63,28 @@ type ScrollResult struct {
 
 func scroll(js string) (*ScrollResult, error) {
 	if !DefaultNowTab(true) {
-		return &ScrollResult{Success: false}, nil
+		return &ScrollResult{Success: false}, notReadyErr()
+	}
+
+	sessionId, contextId, err := nowFrameContext()
+	if err != nil {
+		return &ScrollResult{Success: false}, err
 	}
 
-	chromeInstance.NextID++
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	msg := map[string]interface{}{
-		"id":     chromeInstance.NextID,
+		"id":     reqID,
 		"method": "Runtime.evaluate",
-		"params": map[string]interface{}{
+		"params": withContextId(map[string]interface{}{
 			"expression":    js,
 			"returnByValue": true, // 必须：返回完整的对象结构
 			"awaitPromise":  false,
-		},
-		"sessionId": chromeInstance.NowTabSession,
+		}, contextId),
+		"sessionId": sessionId,
 	}
 
-	err := chromeInstance.NowTabWSConn.WriteJSON(msg)
+	err = wsWriteJSON(chromeInstance.NowTabWSConn, msg)
 	if err != nil {
 		return nil, fmt.Errorf("发送滚动消息失败: %w", err)
 	}
@@ -88,14 +97,14 @@ func scroll(js string) (*ScrollResult, error) {
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				return nil, fmt.Errorf("消息队列已关闭")
 			}
 			utils.Debug("收到滚动响应 -> ", respMsg.Content)
 
 			// 匹配当前请求的响应
-			if chromeInstance.NextID == respMsg.ID {
+			if reqID == respMsg.ID {
 
 				resultJson, err := gt.JsonFind(respMsg.Content, "/result/result/value")
 				if err != nil {
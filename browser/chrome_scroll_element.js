@@ -1,4 +1,5 @@
-(() => {
+(args) => {
+    const __cbLocator = __LOCATOR__;
     // 定义返回结果结构
     const result = {
         success: false,
@@ -6,9 +7,9 @@
         message: ""
     };
     try {
-        // 独立参数变量（替换原JSON解析）
-        const xpath = __SCROLL_XPATH__;
-        const isSmooth = __SCROLL_IS_SMOOTH__;
+        // 参数由调用方以JSON传入
+        const xpath = args.xpath;
+        const isSmooth = args.smooth;
 
         // 验证参数合法性
         if (!xpath || typeof xpath !== 'string') {
@@ -23,13 +24,7 @@
         }
 
         // 查找目标元素
-        const element = document.evaluate(
-            xpath,
-            document,
-            null,
-            XPathResult.FIRST_ORDERED_NODE_TYPE,
-            null
-        ).singleNodeValue;
+        const element = __cbLocator.first(xpath);
 
         // 检查元素是否存在
         if (!element) {
@@ -59,4 +54,4 @@
         result.stack = error.stack; // 可选：保留堆栈信息用于调试
     }
     return result;
-})()
\ No newline at end of file
+}
@@ -1,4 +1,4 @@
-(() => {
+(args) => {
     const result = {
         success: false,
         error: null,
@@ -11,8 +11,8 @@
 
     try {
         // 独立参数
-        const targetX = __SCROLL_X__;
-        const targetY = __SCROLL_Y__;
+        const targetX = args.x;
+        const targetY = args.y;
 
         // 1. 参数校验
         if (typeof targetX !== 'number' || typeof targetY !== 'number') {
@@ -84,4 +84,4 @@
     }
 
     return result;
-})()
\ No newline at end of file
+}
@@ -0,0 +1,358 @@
+package browser
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+/*
+稳定定位器生成
+
+纯位置的xpath(/html[1]/body[1]/div[3]/a[1])在页面多了一个横幅后就会失效, 为元素按稳定程度生成候选定位器:
+1. id、测试属性(data-testid等)、name、aria-label
+2. 其他 data-* 属性、placeholder、title、alt、链接地址
+3. 稳定的class(排除生成的哈希class与状态class)
+4. 元素文本
+5. 以有上述锚点的祖先元素为起点的相对路径
+6. 位置xpath兜底
+候选在元素所在的范围(页面或shadow root)中必须唯一匹配该元素, 第一个是推荐的定位器, 其余的用于定位失败时备用
+*/
+
+// maxSelectorCandidates 返回的候选数量上限(不含位置xpath)
+const maxSelectorCandidates = 5
+
+// anchorAttrs 优先作为锚点的属性, 按优先级排序
+var anchorAttrs = []string{"id", "data-testid", "data-test-id", "data-test", "data-qa", "data-cy", "name", "aria-label"}
+
+// extraAttrs 次一级的属性, 其他 data-* 属性排在它们前面
+var extraAttrs = []string{"placeholder", "title", "alt", "for", "href"}
+
+var (
+	// 生成的值: 长数字、哈希、uuid、框架自动生成的前缀
+	generatedValueRe = regexp.MustCompile(`\d{4,}|[0-9a-f]{8,}|^:|^(ember|ext-|yui_|gwt-|j_id|jsx-|css-|sc-|mui-|__|react-|ng-)`)
+	// 哈希class: 最后一段5个字符以上且同时有字母与数字, 如 Button_root__3xT9k
+	hashClassRe = regexp.MustCompile(`(^|[-_])([a-zA-Z0-9]{5,})$`)
+	hasDigitRe  = regexp.MustCompile(`\d`)
+	hasLetterRe = regexp.MustCompile(`[a-zA-Z]`)
+)
+
+// stateClasses 随交互变化的class
+var stateClasses = map[string]bool{
+	"active": true, "selected": true, "hover": true, "focus": true, "focused": true, "open": true, "opened": true,
+	"show": true, "hide": true, "hidden": true, "disabled": true, "current": true, "checked": true, "visible": true,
+	"in": true, "fade": true, "collapsed": true, "expanded": true, "loading": true, "loaded": true,
+}
+
+// stableValue 属性值是否稳定(不是自动生成的)
+func stableValue(v string) bool {
+	v = strings.TrimSpace(v)
+	if v == "" || len([]rune(v)) > 60 || strings.ContainsAny(v, "\n\t") {
+		return false
+	}
+	return !generatedValueRe.MatchString(strings.ToLower(v))
+}
+
+// stableClass class是否稳定
+func stableClass(c string) bool {
+	if !stableValue(c) || stateClasses[strings.ToLower(c)] || strings.HasPrefix(c, "is-") || strings.HasPrefix(c, "has-") {
+		return false
+	}
+	if m := hashClassRe.FindStringSubmatch(c); m != nil && hasDigitRe.MatchString(m[2]) && hasLetterRe.MatchString(m[2]) {
+		return false
+	}
+	return true
+}
+
+// xpathQuote xpath的字符串字面量, 同时有单双引号时用 concat
+func xpathQuote(s string) string {
+	if !strings.Contains(s, "'") {
+		return "'" + s + "'"
+	}
+	if !strings.Contains(s, `"`) {
+		return `"` + s + `"`
+	}
+	parts := strings.Split(s, "'")
+	quoted := make([]string, 0, len(parts)*2)
+	for i, p := range parts {
+		if i > 0 {
+			quoted = append(quoted, `"'"`)
+		}
+		if p != "" {
+			quoted = append(quoted, "'"+p+"'")
+		}
+	}
+	return "concat(" + strings.Join(quoted, ", ") + ")"
+}
+
+// xpathTag 元素在xpath中的名称, 带命名空间等特殊名称用 *
+func xpathTag(n *DOMNode) string {
+	for _, r := range n.TagName {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return "*"
+		}
+	}
+	return n.TagName
+}
+
+func classPredicate(c string) string {
+	return fmt.Sprintf("contains(concat(' ', normalize-space(@class), ' '), %s)", xpathQuote(" "+c+" "))
+}
+
+// selectorText 用于定位的元素文本, 太长或没有文本时为空
+func selectorText(n *DOMNode) string {
+	text := strings.Join(strings.Fields(NodeText(n)), " ")
+	if len([]rune(text)) > 40 {
+		return ""
+	}
+	return text
+}
+
+// selectorScope 元素所在范围(页面或shadow root)去掉其中shadow root内容后的副本, 与浏览器中的查询范围一致
+type selectorScope struct {
+	root   *DOMNode
+	copies map[*DOMNode]*DOMNode // 原节点 -> 副本
+}
+
+func newSelectorScope(root *DOMNode) *selectorScope {
+	s := &selectorScope{copies: make(map[*DOMNode]*DOMNode)}
+	var clone func(n *DOMNode) *DOMNode
+	clone = func(n *DOMNode) *DOMNode {
+		c := &DOMNode{Type: n.Type, TagName: n.TagName, Content: n.Content, Attributes: n.Attributes, XPath: n.XPath}
+		s.copies[n] = c
+		for _, child := range n.Children {
+			if child.TagName == shadowRootTag {
+				continue
+			}
+			c.Children = append(c.Children, clone(child))
+		}
+		return c
+	}
+	s.root = clone(root)
+	return s
+}
+
+// unique xpath是否在范围内只匹配到该元素
+func (s *selectorScope) unique(xpath string, target *DOMNode) bool {
+	res, err := QueryXPath(s.root, xpath)
+	if err != nil {
+		return false
+	}
+	list, ok := res.([]any)
+	return ok && len(list) == 1 && list[0] == s.copies[target]
+}
+
+// attrCandidates 元素自身属性的候选, anchorOnly 只用锚点属性
+func attrCandidates(n *DOMNode, anchorOnly bool) []string {
+	tag := xpathTag(n)
+	res := make([]string, 0)
+	for _, attr := range anchorAttrs {
+		if v, ok := n.Attributes[attr]; ok && stableValue(v) {
+			res = append(res, fmt.Sprintf("//%s[@%s=%s]", tag, attr, xpathQuote(v)))
+		}
+	}
+	if anchorOnly {
+		return res
+	}
+	dataAttrs := make([]string, 0)
+	for k := range n.Attributes {
+		if strings.HasPrefix(k, "data-") && !containsString(anchorAttrs, k) {
+			dataAttrs = append(dataAttrs, k)
+		}
+	}
+	sort.Strings(dataAttrs)
+	for _, attr := range append(dataAttrs, extraAttrs...) {
+		v, ok := n.Attributes[attr]
+		if !ok || !stableValue(v) || attr == "href" && (strings.HasPrefix(v, "javascript:") || v == "#") {
+			continue
+		}
+		res = append(res, fmt.Sprintf("//%s[@%s=%s]", tag, attr, xpathQuote(v)))
+	}
+	return res
+}
+
+// classCandidates 稳定class的候选, 单个class与两个class组合
+func classCandidates(n *DOMNode) []string {
+	classes := make([]string, 0)
+	for _, c := range strings.Fields(n.Attributes["class"]) {
+		if stableClass(c) && !containsString(classes, c) {
+			classes = append(classes, c)
+		}
+	}
+	tag := xpathTag(n)
+	res := make([]string, 0)
+	for _, c := range classes {
+		res = append(res, fmt.Sprintf("//%s[%s]", tag, classPredicate(c)))
+	}
+	for i := 0; i < len(classes); i++ {
+		for j := i + 1; j < len(classes); j++ {
+			res = append(res, fmt.Sprintf("//%s[%s and %s]", tag, classPredicate(classes[i]), classPredicate(classes[j])))
+		}
+	}
+	return res
+}
+
+// relativePath 从祖先到元素的位置路径, 如 /ul[1]/li[3]/a[1]
+func relativePath(path []*DOMNode) string {
+	var buf strings.Builder
+	for i := 1; i < len(path); i++ {
+		n := path[i]
+		index := 1
+		for _, s := range path[i-1].Children {
+			if s == n {
+				break
+			}
+			if isElement(s) && s.TagName == n.TagName {
+				index++
+			}
+		}
+		buf.WriteString(fmt.Sprintf("/%s[%d]", xpathTag(n), index))
+	}
+	return buf.String()
+}
+
+// scopeSelectors 元素在所在范围内的候选, path 是从范围根节点到元素的路径
+func scopeSelectors(path []*DOMNode) []string {
+	scope := newSelectorScope(path[0])
+	target := path[len(path)-1]
+	res := make([]string, 0)
+	add := func(xpath string) bool {
+		if len(res) >= maxSelectorCandidates || containsString(res, xpath) || !scope.unique(xpath, target) {
+			return false
+		}
+		res = append(res, xpath)
+		return true
+	}
+
+	for _, xpath := range attrCandidates(target, false) {
+		add(xpath)
+	}
+	for _, xpath := range classCandidates(target) {
+		add(xpath)
+	}
+	// 文本按范围内可见的内容计算, 不含 shadow root 内的文本
+	text := selectorText(scope.copies[target])
+	if text != "" {
+		add(fmt.Sprintf("//%s[normalize-space(.)=%s]", xpathTag(target), xpathQuote(text)))
+	}
+	// 自定义元素(如 shadow root 的宿主)的标签名通常是唯一的
+	if strings.Contains(target.TagName, "-") {
+		add("//" + xpathTag(target))
+	}
+
+	// 以最近的有锚点的祖先为起点
+	for i := len(path) - 2; i > 0 && len(res) < maxSelectorCandidates; i-- {
+		anchors := attrCandidates(path[i], true)
+		found := false
+		for _, anchor := range anchors {
+			if !scope.unique(anchor, path[i]) {
+				continue
+			}
+			if text != "" && add(fmt.Sprintf("%s//%s[normalize-space(.)=%s]", anchor, xpathTag(target), xpathQuote(text))) {
+				found = true
+			}
+			if add(anchor + relativePath(path[i:])) {
+				found = true
+			}
+			if found {
+				break
+			}
+		}
+		if found {
+			break
+		}
+	}
+
+	// 位置xpath兜底, shadow root 内取 >>> 之后的部分
+	positional := target.XPath
+	if i := strings.LastIndex(positional, " >>> "); i >= 0 {
+		positional = positional[i+len(" >>> "):]
+	}
+	if !containsString(res, positional) {
+		res = append(res, positional)
+	}
+	return res
+}
+
+// domPath 从根节点到目标节点的路径
+func domPath(root, target *DOMNode) []*DOMNode {
+	if root == target {
+		return []*DOMNode{root}
+	}
+	for _, child := range root.Children {
+		if p := domPath(child, target); p != nil {
+			return append([]*DOMNode{root}, p...)
+		}
+	}
+	return nil
+}
+
+// GenerateSelectors 为DOM树中的元素生成候选定位器(xpath), 按稳定程度排序, 都在离线的DOM树中验证过唯一;
+// shadow root 内的元素, 宿主用其最稳定的定位器, 以 >>> 连接
+func GenerateSelectors(root, target *DOMNode) []string {
+	path := domPath(root, target)
+	if path == nil || !isElement(target) {
+		return nil
+	}
+	// 按 shadow root 切分范围
+	prefix := ""
+	start := 0
+	for i, n := range path {
+		if n.TagName != shadowRootTag || i == 0 {
+			continue
+		}
+		host := scopeSelectors(path[start:i])
+		prefix += host[0] + " >>> "
+		start = i
+	}
+	res := scopeSelectors(path[start:])
+	for i := range res {
+		res[i] = prefix + res[i]
+	}
+	return res
+}
+
+// FindNodeByXPath 按DOM树中记录的位置xpath查找节点
+func FindNodeByXPath(root *DOMNode, xpath string) *DOMNode {
+	if root == nil {
+		return nil
+	}
+	if root.XPath == xpath {
+		return root
+	}
+	for _, child := range root.Children {
+		if n := FindNodeByXPath(child, xpath); n != nil {
+			return n
+		}
+	}
+	return nil
+}
+
+// SelectorsByXPath 为位置xpath对应的元素生成候选定位器, 找不到元素时只返回该xpath
+func SelectorsByXPath(root *DOMNode, xpath string) []string {
+	if n := FindNodeByXPath(root, xpath); n != nil {
+		if res := GenerateSelectors(root, n); len(res) > 0 {
+			return res
+		}
+	}
+	return []string{xpath}
+}
+
+// VerifySelectors 在当前操作的页面中验证候选定位器, 只保留唯一匹配的; 全部不唯一或无法验证时返回原列表
+func VerifySelectors(selectors []string) []string {
+	res := make([]string, 0, len(selectors))
+	for _, selector := range selectors {
+		match, err := MatchLocator(selector)
+		if err != nil {
+			return selectors
+		}
+		if match.Count == 1 {
+			res = append(res, selector)
+		}
+	}
+	if len(res) == 0 {
+		return selectors
+	}
+	return res
+}
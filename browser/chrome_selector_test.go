@@ -0,0 +1,141 @@
+package browser
+
+import (
+	"testing"
+)
+
+func TestGenerateSelectors(t *testing.T) {
+	page := `<html><body>
+<div class="banner">广告</div>
+<div id="app">
+  <form>
+    <input name="q" class="search-input css-1x2y3z" placeholder="搜索">
+    <button class="btn btn-primary active" data-testid="submit">搜索</button>
+  </form>
+  <ul class="menu">
+    <li><a href="/home">首页</a></li>
+    <li><a href="/news">新闻</a></li>
+    <li><a>更多</a></li>
+    <li><a>更多</a></li>
+  </ul>
+  <span id="ember123">统计</span>
+  <p>It's "quoted"</p>
+</div>
+</body></html>`
+	root, err := ParseHTMLToDOM(page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	find := func(xpath string) *DOMNode {
+		res, err := QueryXPath(root, xpath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		list := queryNodes(res)
+		if len(list) == 0 {
+			t.Fatalf("没有找到 %s", xpath)
+		}
+		return list[0].(*DOMNode)
+	}
+
+	cases := []struct {
+		xpath string
+		want  []string
+	}{
+		{"//button", []string{
+			"//button[@data-testid='submit']",
+			"//button[contains(concat(' ', normalize-space(@class), ' '), ' btn ')]",
+			"//button[contains(concat(' ', normalize-space(@class), ' '), ' btn-primary ')]",
+			"//button[contains(concat(' ', normalize-space(@class), ' '), ' btn ') and contains(concat(' ', normalize-space(@class), ' '), ' btn-primary ')]",
+			"//button[normalize-space(.)='搜索']",
+			"/html[1]/body[1]/div[2]/form[1]/button[1]",
+		}},
+		{"//input", []string{
+			"//input[@name='q']",
+			"//input[@placeholder='搜索']",
+			"//input[contains(concat(' ', normalize-space(@class), ' '), ' search-input ')]",
+			"//div[@id='app']/form[1]/input[1]",
+			"/html[1]/body[1]/div[2]/form[1]/input[1]",
+		}},
+		{"//a[@href='/news']", []string{
+			"//a[@href='/news']",
+			"//a[normalize-space(.)='新闻']",
+			"//div[@id='app']//a[normalize-space(.)='新闻']",
+			"//div[@id='app']/ul[1]/li[2]/a[1]",
+			"/html[1]/body[1]/div[2]/ul[1]/li[2]/a[1]",
+		}},
+		// 文本不唯一时用锚点加位置
+		{"(//a)[4]", []string{
+			"//div[@id='app']/ul[1]/li[4]/a[1]",
+			"/html[1]/body[1]/div[2]/ul[1]/li[4]/a[1]",
+		}},
+		// 自动生成的id不作为锚点
+		{"//span", []string{
+			"//span[normalize-space(.)='统计']",
+			"//div[@id='app']//span[normalize-space(.)='统计']",
+			"//div[@id='app']/span[1]",
+			"/html[1]/body[1]/div[2]/span[1]",
+		}},
+		{"//p", []string{
+			`//p[normalize-space(.)=concat('It', "'", 's "quoted"')]`,
+			`//div[@id='app']//p[normalize-space(.)=concat('It', "'", 's "quoted"')]`,
+			"//div[@id='app']/p[1]",
+			"/html[1]/body[1]/div[2]/p[1]",
+		}},
+	}
+	for _, c := range cases {
+		got := GenerateSelectors(root, find(c.xpath))
+		if len(got) != len(c.want) {
+			t.Errorf("%s:\n got %q\nwant %q", c.xpath, got, c.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Errorf("%s [%d]:\n got %s\nwant %s", c.xpath, i, got[i], c.want[i])
+			}
+			// 每个候选都唯一匹配该元素
+			if res, err := QueryXPath(root, got[i]); err != nil || len(queryNodes(res)) != 1 {
+				t.Errorf("%s 不唯一: %v", got[i], err)
+			}
+		}
+	}
+
+	// 多了一个横幅后, 生成的定位器仍然能找到元素, 位置xpath失效
+	moved, err := ParseHTMLToDOM(`<html><body><div>新横幅</div>` + page[len(`<html><body>`):])
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, _ := QueryXPath(moved, GenerateSelectors(root, find("//input"))[0])
+	if list := queryNodes(res); len(list) != 1 || list[0].(*DOMNode).Attributes["name"] != "q" {
+		t.Errorf("加了横幅后定位失败")
+	}
+}
+
+func TestStableValue(t *testing.T) {
+	for v, want := range map[string]bool{
+		"search":         true,
+		"login-btn":      true,
+		"ember123":       false,
+		":r1:":           false,
+		"a1b2c3d4e5f6":   false,
+		"item-20231019":  false,
+		"css-1x2y3z":     false,
+		"":               false,
+		"user_name_form": true,
+	} {
+		if got := stableValue(v); got != want {
+			t.Errorf("stableValue(%q) = %v, want %v", v, got, want)
+		}
+	}
+	for c, want := range map[string]bool{
+		"btn":                true,
+		"active":             false,
+		"is-open":            false,
+		"Button_root__3xT9k": false,
+		"nav-item":           true,
+	} {
+		if got := stableClass(c); got != want {
+			t.Errorf("stableClass(%q) = %v, want %v", c, got, want)
+		}
+	}
+}
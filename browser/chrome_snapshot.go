@@ -0,0 +1,296 @@
+package browser
+
+import (
+	"ChromeBot/utils"
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+/*
+截图快照对比(视觉回归)
+
+第一次运行把截图保存为基准图 <脚本目录>/__snapshots__/<name>.png, 之后的运行与基准图逐像素对比:
+1. 颜色差按YIQ色彩空间计算(接近人眼感知), 小于 PixelThreshold 的视为相同
+2. 抗锯齿产生的差异(像素位于边缘且两张图中都有大量相同的邻居)不计入差异
+3. 差异像素占比不超过 threshold 为通过
+有差异时写出差异图 <name>.diff.png(差异标红, 抗锯齿标黄, 其余变淡)与本次截图 <name>.actual.png
+动态区域用 mask 涂黑, 基准图与本次截图使用相同的 mask
+*/
+
+// SnapshotDir 基准图目录, 相对脚本目录
+var SnapshotDir = "__snapshots__"
+
+// PixelThreshold 单个像素的颜色差阈值(0~1), 越小越敏感
+var PixelThreshold = 0.1
+
+// DefaultSnapshotThreshold 允许的差异像素占比
+const DefaultSnapshotThreshold = 0.01
+
+var (
+	diffColor = color.NRGBA{R: 255, A: 255}
+	aaColor   = color.NRGBA{R: 255, G: 255, A: 255}
+)
+
+// DiffResult 图片对比结果
+type DiffResult struct {
+	Width       int
+	Height      int
+	DiffPixels  int // 差异像素数量, 不含抗锯齿
+	AAPixels    int // 抗锯齿差异像素数量
+	Ratio       float64
+	SizeChanged bool
+}
+
+// DiffImages 对比两张图片, 返回结果与差异图; 尺寸不同时按较大的尺寸对比, 多出的部分视为差异
+func DiffImages(base, actual image.Image, pixelThreshold float64) (*DiffResult, *image.NRGBA) {
+	bb, ab := base.Bounds(), actual.Bounds()
+	w, h := max(bb.Dx(), ab.Dx()), max(bb.Dy(), ab.Dy())
+	img1, img2 := toNRGBA(base, w, h), toNRGBA(actual, w, h)
+	out := image.NewNRGBA(image.Rect(0, 0, w, h))
+	res := &DiffResult{Width: w, Height: h, SizeChanged: bb.Dx() != ab.Dx() || bb.Dy() != ab.Dy()}
+
+	maxDelta := 35215 * pixelThreshold * pixelThreshold
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			pos := img1.PixOffset(x, y)
+			delta := colorDelta(img1.Pix, img2.Pix, pos, pos, false)
+			if math.Abs(delta) > maxDelta {
+				if antialiased(img1, x, y, img2) || antialiased(img2, x, y, img1) {
+					out.SetNRGBA(x, y, aaColor)
+					res.AAPixels++
+				} else {
+					out.SetNRGBA(x, y, diffColor)
+					res.DiffPixels++
+				}
+				continue
+			}
+			// 相同的像素变淡显示
+			p := img1.Pix[pos : pos+4]
+			v := uint8(blend(rgb2y(float64(p[0]), float64(p[1]), float64(p[2])), 0.1*float64(p[3])/255))
+			out.SetNRGBA(x, y, color.NRGBA{R: v, G: v, B: v, A: 255})
+		}
+	}
+	if w*h > 0 {
+		res.Ratio = float64(res.DiffPixels) / float64(w*h)
+	}
+	return res, out
+}
+
+// toNRGBA 转为 w*h 的NRGBA图片, 超出原图的部分透明
+func toNRGBA(img image.Image, w, h int) *image.NRGBA {
+	if n, ok := img.(*image.NRGBA); ok && n.Rect.Min == (image.Point{}) && n.Rect.Dx() == w && n.Rect.Dy() == h {
+		return n
+	}
+	n := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(n, img.Bounds().Sub(img.Bounds().Min), img, img.Bounds().Min, draw.Src)
+	return n
+}
+
+func rgb2y(r, g, b float64) float64 { return r*0.29889531 + g*0.58662247 + b*0.11448223 }
+func rgb2i(r, g, b float64) float64 { return r*0.59597799 - g*0.27417610 - b*0.32180189 }
+func rgb2q(r, g, b float64) float64 { return r*0.21147017 - g*0.52261711 + b*0.31114694 }
+
+// blend 与白色背景按透明度混合
+func blend(c, a float64) float64 { return 255 + (c-255)*a }
+
+// colorDelta 两个像素的颜色差(YIQ), yOnly 只比较亮度; 第一个更亮时为负
+func colorDelta(pix1, pix2 []uint8, k, m int, yOnly bool) float64 {
+	r1, g1, b1, a1 := float64(pix1[k]), float64(pix1[k+1]), float64(pix1[k+2]), float64(pix1[k+3])
+	r2, g2, b2, a2 := float64(pix2[m]), float64(pix2[m+1]), float64(pix2[m+2]), float64(pix2[m+3])
+	if a1 == a2 && r1 == r2 && g1 == g2 && b1 == b2 {
+		return 0
+	}
+	if a1 < 255 {
+		a1 /= 255
+		r1, g1, b1 = blend(r1, a1), blend(g1, a1), blend(b1, a1)
+	}
+	if a2 < 255 {
+		a2 /= 255
+		r2, g2, b2 = blend(r2, a2), blend(g2, a2), blend(b2, a2)
+	}
+	y1, y2 := rgb2y(r1, g1, b1), rgb2y(r2, g2, b2)
+	y := y1 - y2
+	if yOnly {
+		return y
+	}
+	i := rgb2i(r1, g1, b1) - rgb2i(r2, g2, b2)
+	q := rgb2q(r1, g1, b1) - rgb2q(r2, g2, b2)
+	delta := 0.5053*y*y + 0.299*i*i + 0.1957*q*q
+	if y1 > y2 {
+		return -delta
+	}
+	return delta
+}
+
+// antialiased 像素是否是抗锯齿产生的: 周围亮度有明显的最亮与最暗的邻居, 且它们在两张图中都处在大片相同颜色中
+func antialiased(img *image.NRGBA, x1, y1 int, img2 *image.NRGBA) bool {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	x0, y0 := max(x1-1, 0), max(y1-1, 0)
+	x2, y2 := min(x1+1, w-1), min(y1+1, h-1)
+	pos := img.PixOffset(x1, y1)
+	zeroes := 0
+	if x1 == x0 || x1 == x2 || y1 == y0 || y1 == y2 {
+		zeroes = 1
+	}
+	minDelta, maxDelta := 0.0, 0.0
+	var minX, minY, maxX, maxY int
+	for x := x0; x <= x2; x++ {
+		for y := y0; y <= y2; y++ {
+			if x == x1 && y == y1 {
+				continue
+			}
+			delta := colorDelta(img.Pix, img.Pix, pos, img.PixOffset(x, y), true)
+			if delta == 0 {
+				zeroes++
+				if zeroes > 2 {
+					return false
+				}
+			} else if delta < minDelta {
+				minDelta, minX, minY = delta, x, y
+			} else if delta > maxDelta {
+				maxDelta, maxX, maxY = delta, x, y
+			}
+		}
+	}
+	if minDelta == 0 || maxDelta == 0 {
+		return false
+	}
+	return (hasManySiblings(img, minX, minY) && hasManySiblings(img2, minX, minY)) ||
+		(hasManySiblings(img, maxX, maxY) && hasManySiblings(img2, maxX, maxY))
+}
+
+// hasManySiblings 像素周围是否有3个以上颜色完全相同的邻居
+func hasManySiblings(img *image.NRGBA, x1, y1 int) bool {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	x0, y0 := max(x1-1, 0), max(y1-1, 0)
+	x2, y2 := min(x1+1, w-1), min(y1+1, h-1)
+	pos := img.PixOffset(x1, y1)
+	zeroes := 0
+	if x1 == x0 || x1 == x2 || y1 == y0 || y1 == y2 {
+		zeroes = 1
+	}
+	for x := x0; x <= x2; x++ {
+		for y := y0; y <= y2; y++ {
+			if x == x1 && y == y1 {
+				continue
+			}
+			pos2 := img.PixOffset(x, y)
+			if bytes.Equal(img.Pix[pos:pos+4], img.Pix[pos2:pos2+4]) {
+				zeroes++
+			}
+			if zeroes > 2 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SnapshotResult 快照对比结果
+type SnapshotResult struct {
+	Name       string
+	Pass       bool
+	New        bool // 第一次运行, 保存了基准图
+	Updated    bool // 按要求更新了基准图
+	Ratio      float64
+	DiffPixels int
+	Threshold  float64
+	Baseline   string
+	Actual     string // 有差异时本次截图的路径
+	Diff       string // 有差异时差异图的路径
+	Size       string // 尺寸变化的说明
+}
+
+var snapshotNameRe = regexp.MustCompile(`[^\p{L}\p{N}._-]+`)
+
+// snapshotPath 基准图路径
+func snapshotPath(name, suffix string) string {
+	return filepath.Join(utils.ScriptDir, SnapshotDir, snapshotNameRe.ReplaceAllString(name, "_")+suffix+".png")
+}
+
+// CompareSnapshot 把截图与基准图对比, 没有基准图或 update 为true时保存为基准图
+func CompareSnapshot(name string, data []byte, threshold float64, update bool) (*SnapshotResult, error) {
+	if name == "" {
+		return nil, fmt.Errorf("快照需要 name")
+	}
+	if threshold < 0 {
+		threshold = DefaultSnapshotThreshold
+	}
+	res := &SnapshotResult{Name: name, Threshold: threshold, Baseline: snapshotPath(name, "")}
+	actual, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("解析截图失败: %w", err)
+	}
+
+	baseData, err := os.ReadFile(res.Baseline)
+	if os.IsNotExist(err) || update {
+		if err = writeSnapshotFile(res.Baseline, data); err != nil {
+			return nil, err
+		}
+		res.Pass, res.New, res.Updated = true, !update, update
+		// 旧的差异图已经没有意义
+		_ = os.Remove(snapshotPath(name, ".diff"))
+		_ = os.Remove(snapshotPath(name, ".actual"))
+		return res, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取基准图失败: %w", err)
+	}
+	base, err := png.Decode(bytes.NewReader(baseData))
+	if err != nil {
+		return nil, fmt.Errorf("解析基准图失败: %s %w", res.Baseline, err)
+	}
+
+	diff, diffImg := DiffImages(base, actual, PixelThreshold)
+	res.Ratio, res.DiffPixels = diff.Ratio, diff.DiffPixels
+	res.Pass = diff.Ratio <= threshold
+	if diff.SizeChanged {
+		res.Size = fmt.Sprintf("尺寸从 %dx%d 变为 %dx%d", base.Bounds().Dx(), base.Bounds().Dy(), actual.Bounds().Dx(), actual.Bounds().Dy())
+	}
+	if diff.DiffPixels == 0 {
+		_ = os.Remove(snapshotPath(name, ".diff"))
+		_ = os.Remove(snapshotPath(name, ".actual"))
+		return res, nil
+	}
+
+	res.Diff, res.Actual = snapshotPath(name, ".diff"), snapshotPath(name, ".actual")
+	var buf bytes.Buffer
+	if err = png.Encode(&buf, diffImg); err != nil {
+		return nil, fmt.Errorf("生成差异图失败: %w", err)
+	}
+	if err = writeSnapshotFile(res.Diff, buf.Bytes()); err != nil {
+		return nil, err
+	}
+	if err = writeSnapshotFile(res.Actual, data); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func writeSnapshotFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建快照目录失败: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("写入快照文件失败: %w", err)
+	}
+	return nil
+}
+
+// Snapshot 按选项截图(png)并与基准图对比
+func Snapshot(name string, threshold float64, update bool, opts ScreenshotOptions) (*SnapshotResult, error) {
+	opts.Format = "png"
+	data, err := Screenshot(opts)
+	if err != nil {
+		return nil, err
+	}
+	return CompareSnapshot(name, data, threshold, update)
+}
@@ -0,0 +1,136 @@
+package browser
+
+import (
+	"ChromeBot/utils"
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"testing"
+)
+
+// testImage 20x20 白底, 中间10x10的黑色方块
+func testImage() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 20, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 20; x++ {
+			c := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+			if x >= 5 && x < 15 && y >= 5 && y < 15 {
+				c = color.NRGBA{A: 255}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestDiffImages(t *testing.T) {
+	res, out := DiffImages(testImage(), testImage(), PixelThreshold)
+	if res.DiffPixels != 0 || res.AAPixels != 0 || res.Ratio != 0 || out.Bounds().Dx() != 20 {
+		t.Errorf("相同图片: %+v", res)
+	}
+
+	// 方块右边缘多了一列灰色(抗锯齿), 不计入差异
+	aa := testImage()
+	for y := 5; y < 15; y++ {
+		aa.SetNRGBA(15, y, color.NRGBA{R: 128, G: 128, B: 128, A: 255})
+	}
+	res, out = DiffImages(testImage(), aa, PixelThreshold)
+	if res.DiffPixels != 0 || res.AAPixels == 0 {
+		t.Errorf("抗锯齿: %+v", res)
+	}
+	if out.NRGBAAt(15, 10) != aaColor {
+		t.Errorf("抗锯齿像素应标黄: %v", out.NRGBAAt(15, 10))
+	}
+
+	// 右上角多了一个红色的块
+	changed := testImage()
+	for y := 0; y < 3; y++ {
+		for x := 17; x < 20; x++ {
+			changed.SetNRGBA(x, y, color.NRGBA{R: 255, A: 255})
+		}
+	}
+	res, out = DiffImages(testImage(), changed, PixelThreshold)
+	if res.DiffPixels != 9 || res.Ratio != 9.0/400 {
+		t.Errorf("改变的块: %+v", res)
+	}
+	if out.NRGBAAt(18, 1) != diffColor || out.NRGBAAt(0, 0) == diffColor {
+		t.Errorf("差异图标记错误")
+	}
+
+	// 极小的颜色差异在阈值内
+	slight := testImage()
+	slight.SetNRGBA(0, 0, color.NRGBA{R: 250, G: 252, B: 255, A: 255})
+	if res, _ = DiffImages(testImage(), slight, PixelThreshold); res.DiffPixels != 0 {
+		t.Errorf("阈值内的差异: %+v", res)
+	}
+
+	// 尺寸不同, 多出的部分是差异
+	bigger := image.NewNRGBA(image.Rect(0, 0, 20, 25))
+	copy(bigger.Pix, testImage().Pix)
+	for y := 20; y < 25; y++ {
+		for x := 0; x < 20; x++ {
+			bigger.SetNRGBA(x, y, color.NRGBA{A: 255})
+		}
+	}
+	res, _ = DiffImages(testImage(), bigger, PixelThreshold)
+	if !res.SizeChanged || res.Height != 25 || res.DiffPixels != 100 {
+		t.Errorf("尺寸变化: %+v", res)
+	}
+}
+
+func TestCompareSnapshot(t *testing.T) {
+	old := utils.ScriptDir
+	utils.ScriptDir = t.TempDir()
+	defer func() { utils.ScriptDir = old }()
+
+	encode := func(img image.Image) []byte {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+
+	res, err := CompareSnapshot("首页/home", encode(testImage()), DefaultSnapshotThreshold, false)
+	if err != nil || !res.New || !res.Pass {
+		t.Fatalf("第一次: %+v %v", res, err)
+	}
+	if _, err = os.Stat(res.Baseline); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err = CompareSnapshot("首页/home", encode(testImage()), DefaultSnapshotThreshold, false)
+	if err != nil || res.New || !res.Pass || res.Diff != "" {
+		t.Fatalf("相同: %+v %v", res, err)
+	}
+
+	changed := testImage()
+	for x := 0; x < 20; x++ {
+		changed.SetNRGBA(x, 0, color.NRGBA{B: 255, A: 255})
+	}
+	res, err = CompareSnapshot("首页/home", encode(changed), DefaultSnapshotThreshold, false)
+	if err != nil || res.Pass || res.DiffPixels != 20 {
+		t.Fatalf("改变: %+v %v", res, err)
+	}
+	for _, path := range []string{res.Diff, res.Actual} {
+		if _, err = os.Stat(path); err != nil {
+			t.Errorf("缺少文件: %s", path)
+		}
+	}
+
+	// 放宽阈值后通过
+	if res, _ = CompareSnapshot("首页/home", encode(changed), 0.1, false); !res.Pass {
+		t.Errorf("阈值0.1应通过: %+v", res)
+	}
+
+	// 更新基准图后删除差异图
+	res, err = CompareSnapshot("首页/home", encode(changed), DefaultSnapshotThreshold, true)
+	if err != nil || !res.Updated || !res.Pass {
+		t.Fatalf("更新: %+v %v", res, err)
+	}
+	if _, err = os.Stat(snapshotPath("首页/home", ".diff")); !os.IsNotExist(err) {
+		t.Errorf("更新后应删除差异图")
+	}
+}
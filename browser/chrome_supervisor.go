@@ -0,0 +1,270 @@
+package browser
+
+import (
+	"ChromeBot/utils"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+/*
+断线守护
+
+tab的ws连接断开(浏览器崩溃、网络异常、tab被意外关闭)后由守护接管:
+1. 浏览器进程还在: 按退避时间(500ms起,翻倍,最大8s)重新连接原来的tab, 原tab不存在则连接第一个tab
+2. 浏览器进程已退出: 开启了relaunch时使用相同的 窗口大小/代理/UserPath/设备 重新启动浏览器并打开断线前的地址;
+   未开启则清理单例, 并记录可恢复错误
+重连次数与relaunch互不影响: recover=0 relaunch 表示浏览器还在时不重连, 进程退出后重新启动
+恢复期间的chrome操作会等待恢复结束; 恢复失败后脚本可通过 chrome health as=h 获取状态与错误进行处理
+*/
+
+// RecoverableError 可恢复的错误, 浏览器断线后未能自动恢复时返回, 脚本可以重新 chrome init 后继续
+type RecoverableError struct {
+	Reason  string // 原因
+	LastURL string // 断线前的地址
+}
+
+func (e *RecoverableError) Error() string {
+	return fmt.Sprintf("浏览器连接已断开且未能恢复(%s), 断线前地址: %s, 请重新执行chrome init", e.Reason, e.LastURL)
+}
+
+// RecoverInfo 断线守护的状态
+type RecoverInfo struct {
+	Recovering   bool   // 是否正在恢复
+	RecoverTimes int    // 累计成功恢复次数
+	LastError    string // 最后一次恢复失败的错误
+	LastURL      string // 断线前的地址
+}
+
+var (
+	recoverMaxRetry = 5     // 重连最大次数, 0 表示不重连
+	recoverRelaunch = false // 进程退出后是否重新启动浏览器
+	recoverMu       sync.Mutex
+	recoverDone     chan struct{} // 不为nil表示正在恢复, 恢复结束后关闭
+	recoverInfo     RecoverInfo
+	recoverLastErr  error
+)
+
+// SetRecover 设置断线守护, maxRetry:重连最大次数,0表示不重连; relaunch:浏览器进程退出后是否重新启动, 与maxRetry无关
+func SetRecover(maxRetry int, relaunch bool) {
+	recoverMu.Lock()
+	defer recoverMu.Unlock()
+	if maxRetry < 0 {
+		maxRetry = 0
+	}
+	recoverMaxRetry = maxRetry
+	recoverRelaunch = relaunch
+}
+
+// LastRecoverError 最后一次未能恢复的错误, 重新初始化后清空
+func LastRecoverError() error {
+	recoverMu.Lock()
+	defer recoverMu.Unlock()
+	return recoverLastErr
+}
+
+// GetRecoverInfo 获取断线守护的状态
+func GetRecoverInfo() RecoverInfo {
+	recoverMu.Lock()
+	defer recoverMu.Unlock()
+	info := recoverInfo
+	info.Recovering = recoverDone != nil
+	if recoverLastErr != nil {
+		info.LastError = recoverLastErr.Error()
+	}
+	return info
+}
+
+// clearRecoverError 重新初始化浏览器后清空错误
+func clearRecoverError() {
+	recoverMu.Lock()
+	defer recoverMu.Unlock()
+	recoverLastErr = nil
+}
+
+// waitRecover 正在恢复时等待恢复结束
+func waitRecover() {
+	recoverMu.Lock()
+	done := recoverDone
+	recoverMu.Unlock()
+	if done == nil {
+		return
+	}
+	fmt.Println("[Chrome]浏览器连接恢复中, 等待...")
+	select {
+	case <-done:
+	case <-time.After(2 * time.Minute):
+		fmt.Println("[Chrome]等待浏览器连接恢复超时")
+	}
+}
+
+// superviseReconnect tab的ws断开后重连或恢复浏览器
+func superviseReconnect() {
+	recoverMu.Lock()
+	if recoverDone != nil {
+		recoverMu.Unlock()
+		return
+	}
+	maxRetry, relaunch := recoverMaxRetry, recoverRelaunch
+	done := make(chan struct{})
+	recoverDone = done
+	recoverMu.Unlock()
+
+	err := reconnect(maxRetry, relaunch)
+
+	recoverMu.Lock()
+	if err != nil {
+		recoverLastErr = err
+	} else {
+		recoverInfo.RecoverTimes++
+	}
+	recoverDone = nil
+	recoverMu.Unlock()
+	close(done)
+}
+
+func reconnect(maxRetry int, relaunch bool) error {
+	c := GetChromeInstance()
+	if c == nil {
+		return nil
+	}
+	lastURL := c.LastURL
+	recoverMu.Lock()
+	recoverInfo.LastURL = lastURL
+	recoverMu.Unlock()
+
+	backoff := 500 * time.Millisecond
+	for i := 0; i < maxRetry; i++ {
+		time.Sleep(backoff)
+		if backoff < 8*time.Second {
+			backoff *= 2
+		}
+
+		if GetChromeInstance() != c || c.CloseState {
+			// 期间被主动关闭或重新初始化了
+			return nil
+		}
+
+		isRun, err := isProcessRunning(c.PID)
+		if err != nil {
+			fmt.Println("控制谷歌似乎断开了,检查进程错误, err = ", err.Error())
+		}
+		fmt.Printf("[Chrome]第%d次重连 pid = %d | isRun = %v\n", i+1, c.PID, isRun)
+		if !isRun {
+			break
+		}
+
+		targetId, wsUrl, err := findTabWs(c.NowTabTargetId)
+		if err != nil {
+			log.Println("[Chrome]重连获取tab失败, err = ", err)
+			continue
+		}
+		if err = connNowTab(targetId, wsUrl); err != nil {
+			continue
+		}
+		if targetId != c.NowTabTargetId && lastURL != "" {
+			// 原tab已丢失, 在新的tab上还原页面
+			_, _ = OpenUrl(lastURL)
+		}
+		fmt.Println("[Chrome]重连tab成功")
+		return nil
+	}
+
+	if GetChromeInstance() != c || c.CloseState {
+		return nil
+	}
+
+	isRun, _ := isProcessRunning(c.PID)
+	if isRun {
+		if maxRetry == 0 {
+			return &RecoverableError{Reason: "未开启重连", LastURL: lastURL}
+		}
+		return &RecoverableError{Reason: fmt.Sprintf("重连%d次失败", maxRetry), LastURL: lastURL}
+	}
+
+	fmt.Println("[Chrome]浏览器进程被关闭了")
+	mu.Lock()
+	if chromeInstance != c {
+		// 期间被重新初始化了
+		mu.Unlock()
+		return nil
+	}
+	chromeInstance = nil
+	isInitialized = false
+	mu.Unlock()
+
+	if !relaunch {
+		return &RecoverableError{Reason: "浏览器进程已退出", LastURL: lastURL}
+	}
+
+	fmt.Println("[Chrome]重新启动浏览器进行恢复...")
+	if err := launchChrome(c.WindowSize, c.Proxy, c.UserPath, c.Device, false); err != nil {
+		return &RecoverableError{Reason: "重新启动浏览器失败: " + err.Error(), LastURL: lastURL}
+	}
+	targetId, wsUrl, err := GetFirstTabWs()
+	if err != nil {
+		return &RecoverableError{Reason: "重新启动后获取tab失败: " + err.Error(), LastURL: lastURL}
+	}
+	if err = connNowTab(targetId, wsUrl); err != nil {
+		return &RecoverableError{Reason: "重新启动后连接tab失败: " + err.Error(), LastURL: lastURL}
+	}
+	if lastURL != "" {
+		fmt.Println("[Chrome]还原页面 url = ", lastURL)
+		if _, err = OpenUrl(lastURL); err != nil {
+			utils.Debug("还原页面出现错误: ", err)
+		}
+	}
+	fmt.Println("[Chrome]浏览器已恢复")
+	return nil
+}
+
+// findTabWs 通过 /json/list 查找tab的ws地址, 找不到时返回第一个tab
+func findTabWs(targetId string) (string, string, error) {
+	tabList, err := getTabList()
+	if err != nil {
+		return "", "", err
+	}
+	for _, v := range tabList {
+		if v["id"] == targetId {
+			return targetId, v["webSocketDebuggerUrl"], nil
+		}
+	}
+	if len(tabList) == 0 {
+		return "", "", fmt.Errorf("没有可用的tab")
+	}
+	return tabList[0]["id"], tabList[0]["webSocketDebuggerUrl"], nil
+}
+
+// notReadyErr 浏览器不可操作时的错误, 断线未能恢复时返回 RecoverableError
+func notReadyErr() error {
+	if err := LastRecoverError(); err != nil {
+		return err
+	}
+	return fmt.Errorf("浏览器未初始化")
+}
+
+// Health 浏览器连接状态
+func Health() map[string]any {
+	info := GetRecoverInfo()
+	res := map[string]any{
+		"connected":     false,
+		"pid":           int64(0),
+		"port":          int64(0),
+		"tab":           "",
+		"url":           info.LastURL,
+		"recovering":    info.Recovering,
+		"recover_times": int64(info.RecoverTimes),
+		"error":         info.LastError,
+	}
+	if c := chromeInstance; c != nil {
+		res["connected"] = c.NowTabWSConn != nil
+		res["pid"] = int64(c.PID)
+		res["port"] = int64(c.Port)
+		res["tab"] = c.NowTabTargetId
+		if c.LastURL != "" {
+			res["url"] = c.LastURL
+		}
+	}
+	return res
+}
@@ -8,7 +8,6 @@ import (
 	"log"
 	"time"
 
-	"github.com/gorilla/websocket"
 	gt "github.com/mangenotwork/gathertool"
 )
 
@@ -48,6 +47,37 @@ func getAllTabData() (map[string]string, error) {
 	return res, nil
 }
 
+// getTabList 获取所有page类型的tab, 顺序与 /json/list 一致
+func getTabList() ([]map[string]string, error) {
+	res := make([]map[string]string, 0)
+	tabUrl := fmt.Sprintf("http://127.0.0.1:%d/json/list", chromeInstance.Port)
+
+	var e2r gt.Err2Retry = true
+	ctx, err := gt.Get(tabUrl, gt.RetryTimes(2), e2r, gt.ReqTimeOutMs(2000))
+	if err != nil {
+		return res, err
+	}
+
+	dataArr := make([]map[string]interface{}, 0)
+	err = json.Unmarshal([]byte(ctx.RespBodyString()), &dataArr)
+	if err != nil {
+		return res, err
+	}
+
+	for _, v := range dataArr {
+		if gt.Any2String(v["type"]) != "page" || gt.Any2String(v["url"]) == "chrome://omnibox-popup.top-chrome/" {
+			continue
+		}
+		res = append(res, map[string]string{
+			"id":                   gt.Any2String(v["id"]),
+			"title":                gt.Any2String(v["title"]),
+			"url":                  gt.Any2String(v["url"]),
+			"webSocketDebuggerUrl": gt.Any2String(v["webSocketDebuggerUrl"]),
+		})
+	}
+	return res, nil
+}
+
 // GetAllTab 查看所有的页签
 func GetAllTab() (map[string]string, error) {
 	if !DefaultNowTab(false) {
@@ -78,15 +108,16 @@ R:
 		return "", nil
 	}
 
-	chromeInstance.NextID++
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 	   "id": %d,
 	   "method": "Target.createTarget",
 	   "params": {
 	       "url": "chrome://newtab/"
 	   }
-	}`, chromeInstance.NextID)
-	err := chromeInstance.NowTabWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	}`, reqID)
+	err := wsWrite(chromeInstance.NowTabWSConn, []byte(message))
 	if err != nil {
 		log.Println("发送消息失败:", err)
 		chromeInstance.NowTabWSConn = nil
@@ -101,13 +132,13 @@ R:
 
 	for {
 		select {
-		case respMsg, ok := <-messageQueue:
+		case respMsg, ok := <-respCh:
 			if !ok {
 				log.Println("消息队列已关闭")
 				return "", fmt.Errorf("消息队列已关闭")
 			}
 			utils.Debug("收到的消息 -> ", respMsg.Content)
-			if chromeInstance.NextID == respMsg.ID {
+			if reqID == respMsg.ID {
 				result, err := gt.Json2Map(respMsg.Content)
 				if err != nil {
 					log.Println("回复内容解析错误")
@@ -120,6 +151,7 @@ R:
 						if hasMap {
 							targetId, targetIdHas := resultDataMap["targetId"]
 							if targetIdHas {
+								claimTab(targetId.(string))
 								SelectTab(targetId.(string))
 								return targetId.(string), nil
 							}
@@ -172,32 +204,11 @@ func SelectTab(targetId string) {
 	for _, v := range dataArr {
 		if targetId == v["id"].(string) {
 			has = true
-			chromeInstance.NowTabTargetId = targetId
-			chromeInstance.NowTabWSUrl = v["webSocketDebuggerUrl"].(string)
 			chromeInstance.NowTab = v["title"].(string)
 
 			// go func() { ConnTabDone <- struct{}{} }()
 
-			// 默认第一个Tab,并连接Chrome DevTools WebSocket
-			wsConn, err := ConnTab()
-			if err != nil {
-				fmt.Println("[Chrome] 默认连接第一个Tab出现错误, err : ", err)
-			}
-			chromeInstance.NowTabWSConn = wsConn
-
-			session, err := GetSession()
-			if err != nil {
-				fmt.Println("[Chrome] 连接Tab的session出现错误, err : ", err)
-			}
-			log.Println("获取到 session = ", session)
-			chromeInstance.NowTabSession = session
-
-			// 启动页面监听
-			err = PageEnable()
-			if err != nil {
-				log.Println("页面加载失败")
-			}
-
+			_ = connNowTab(targetId, v["webSocketDebuggerUrl"].(string))
 		}
 
 	}
@@ -219,80 +230,7 @@ func NowTabInfo() {
 
 // NowTabClose 关闭当前标签页
 func NowTabClose() {
-	if !DefaultNowTab(false) {
-		return
-	}
-
-	tabList, _ := GetAllTab()
-	utils.Debug("tabList len = ", len(tabList))
-	if len(tabList) <= 1 {
-		log.Println("[Chrome]当前页签小于等于1个，不允许被关闭")
-		return
-	}
-
-	chromeInstance.NextID++
-	message := fmt.Sprintf(`{
-	   "id": %d,
-	   "method": "Target.closeTarget",
-	   "params": {
-	      "targetId": "%s"
-	   }
-	}`, chromeInstance.NextID, chromeInstance.NowTabTargetId)
-	err := chromeInstance.NowTabWSConn.WriteMessage(websocket.TextMessage, []byte(message))
-	if err != nil {
-		log.Println("发送消息失败:", err)
-		return
-	}
-	utils.Debugf("发送消息: %s", message)
-
-	timeout := 6 * time.Second
-	timer := time.NewTimer(timeout)
-	defer timer.Stop() // 重要：确保计时器被清理
-
-	for {
-		select {
-		case msg, ok := <-messageQueue:
-			if !ok {
-				log.Println("消息队列已关闭")
-				return
-			}
-			utils.Debug("收到的消息 -> ", msg.Content)
-			if chromeInstance.NextID == msg.ID {
-				result, err := gt.Json2Map(msg.Content)
-				if err != nil {
-					log.Println("回复内容解析错误")
-				} else {
-					utils.Debug("Target.closeTarget 回复消息 : ", result)
-
-					resultData, resultDataOK := result["result"]
-					if resultDataOK {
-						resultDataMap, hasMap := resultData.(map[string]any)
-						if hasMap {
-							success, successHas := resultDataMap["success"]
-							if successHas && success.(bool) {
-
-								log.Println("Target.closeTarget 关闭成功, 切换tab")
-								for tableItemKey, _ := range tabList {
-									if tableItemKey != chromeInstance.NowTabTargetId {
-										SelectTab(tableItemKey)
-									}
-								}
-
-							}
-						}
-					}
-
-					return
-				}
-
-				return
-			} else {
-				log.Println("不是自己的消息")
-			}
-
-		case <-timer.C:
-			log.Println("6秒未收到消息")
-			return
-		}
+	if err := CloseTab(""); err != nil {
+		log.Println("[Chrome]关闭当前tab错误: ", err.Error())
 	}
 }
@@ -5,11 +5,8 @@ import (
 	"ChromeBot/utils"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
 	"os"
-	"strings"
-	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -19,7 +16,15 @@ import (
 // DefaultNowTab 默认当前交互Tab
 // isOP 是否是操作， 点击，输入，截图
 func DefaultNowTab(isOP bool) bool {
+	if chromeInstance == nil || chromeInstance.NowTabWSConn == nil {
+		waitRecover() // 断线恢复中则等待恢复结束
+	}
+
 	if chromeInstance == nil {
+		if err := LastRecoverError(); err != nil {
+			fmt.Println("[Chrome]", err.Error())
+			return false
+		}
 		fmt.Println("[Chrome]未初始化浏览器进程,请执行chrome init命令进行初始化")
 		return false
 	}
@@ -60,6 +65,12 @@ func DefaultNowTab(isOP bool) bool {
 			os.Exit(0)
 		}
 	}
+	_ = connNowTab(targetId, webSocketDebuggerUrl)
+	return true
+}
+
+// connNowTab 连接指定的tab作为当前操作的tab: 建立ws连接、创建session并启动页面监听
+func connNowTab(targetId, webSocketDebuggerUrl string) error {
 	chromeInstance.NowTabTargetId = targetId
 	chromeInstance.NowTabWSUrl = webSocketDebuggerUrl
 
@@ -67,6 +78,7 @@ func DefaultNowTab(isOP bool) bool {
 	wsConn, err := ConnTab()
 	if err != nil {
 		fmt.Println("[Chrome] 默认连接第一个Tab出现错误, err : ", err)
+		return err
 	}
 	chromeInstance.NowTabWSConn = wsConn
 
@@ -82,7 +94,15 @@ func DefaultNowTab(isOP bool) bool {
 	if err != nil {
 		log.Println("页面加载失败")
 	}
-	return true
+
+	// 跟踪frame的执行上下文
+	enableFrameTracking()
+
+	// 启动tab登记表
+	if err = ensureTabRegistry(); err != nil {
+		utils.Debug("启动tab登记表失败: ", err)
+	}
+	return nil
 }
 
 func ConnTab() (*websocket.Conn, error) {
@@ -93,7 +113,8 @@ func ConnTab() (*websocket.Conn, error) {
 	utils.Debug("建立连接: ", chromeInstance.NowTabWSUrl)
 	conn, _, err := websocket.DefaultDialer.Dial(chromeInstance.NowTabWSUrl, nil)
 	if err != nil {
-		log.Fatal("连接失败:", err)
+		log.Println("连接失败:", err)
+		return nil, err
 	}
 	// 启动一个goroutine来接收服务器消息
 
@@ -132,31 +153,16 @@ func ConnTab() (*websocket.Conn, error) {
 				_, message, err := conn.ReadMessage()
 
 				if err != nil {
-					if err != io.EOF && !strings.Contains(err.Error(), "unexpected EOF") {
+					if !isWSClosedErr(err) {
 						//log.Println("接收消息失败:", err)
 						time.Sleep(1 * time.Second) // 避免太快阻塞了
 						continue
 
 					} else {
-						log.Println("控制谷歌似乎断开了 p = ", chromeInstance.Port, " ,err = ", err)
-						chromeInstance.NowTabWSConn = nil
-
-						// 检查4次
-						for i := 0; i < 4; i++ {
-							time.Sleep(2 * time.Second)
-							// 检查是否进程被关闭
-							isRun, err := isProcessRunning(chromeInstance.PID)
-							if err != nil {
-								fmt.Println("控制谷歌似乎断开了,检查进程错误, err = ", err.Error())
-							}
-							fmt.Println("控制谷歌似乎断开了 pid = ", chromeInstance.PID, " | isRun = ", isRun)
-							if !isRun {
-								fmt.Println("[Chrome]浏览器进程被关闭了,请重新初始化！")
-								chromeInstance = nil
-								isInitialized = false
-								once = sync.Once{}
-								break
-							}
+						log.Println("控制谷歌似乎断开了 err = ", err)
+						if c := chromeInstance; c != nil && !c.CloseState && c.NowTabWSConn == conn {
+							c.NowTabWSConn = nil
+							go superviseReconnect() // 断线交给守护进行重连或恢复
 						}
 
 						time.Sleep(1 * time.Second)
@@ -166,58 +172,7 @@ func ConnTab() (*websocket.Conn, error) {
 
 				}
 
-				msgDebug := string(message)
-				if len(msgDebug) > 4000 {
-					msgDebug = msgDebug[0:4000] + " --> 太多了省略 ..."
-				}
-
-				utils.Debugf("=====> 收到服务器回复: %s", msgDebug)
-
-				// getRequestImg(string(message))  // 监听到图片资源
-
-				result, err := gt.Json2Map(string(message))
-				if err != nil {
-					gt.Error("回复内容解析错误")
-				} else {
-
-					// 提取sessionId
-					sessionId, sessionIdOK := result["sessionId"].(string)
-					if !sessionIdOK {
-						sessionId = ""
-					}
-
-					// 监听页面加载事件
-					method, methodOK := result["method"].(string)
-					if methodOK {
-						// 关键修改5：优化通道发送逻辑，避免阻塞
-						var sendFlag bool
-						if method == "Page.loadEventFired" {
-							sendFlag = true
-						}
-
-						if sendFlag && sessionId != "" {
-							// 使用select+default，避免NowPageLoadEventFired无缓冲时阻塞
-							select {
-							case NowPageLoadEventFired <- sessionId:
-								utils.Debugf("发送页面加载事件，sessionId: %s", sessionId)
-							default:
-								utils.Debugf("NowPageLoadEventFired通道阻塞，跳过发送: %s", sessionId)
-							}
-						}
-					}
-
-					id, ok := result["id"].(float64)
-					//gt.Info(id, ok)
-					if !ok {
-						//gt.Error("回复消息没有id")
-					} else {
-						messageQueue <- mess{
-							ID:      int(id),
-							Content: string(message),
-						}
-					}
-
-				}
+				handleWSMessage(message)
 
 			}
 		}
@@ -283,7 +238,8 @@ func GetSession() (string, error) {
 }
 
 func attachToTarget() (string, error) {
-	chromeInstance.NextID++
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 	   "id": %d,
 	   "method": "Target.attachToTarget",
@@ -291,8 +247,8 @@ func attachToTarget() (string, error) {
 	       "targetId": "%s",
         	"flatten": true
 	   }
-	}`, chromeInstance.NextID, chromeInstance.NowTabTargetId)
-	err := chromeInstance.NowTabWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	}`, reqID, chromeInstance.NowTabTargetId)
+	err := wsWrite(chromeInstance.NowTabWSConn, []byte(message))
 	if err != nil {
 		log.Println("[Chrome]发送消息失败:", err)
 		return "", fmt.Errorf("发送消息失败")
@@ -305,13 +261,13 @@ func attachToTarget() (string, error) {
 
 	for {
 		select {
-		case msg, ok := <-messageQueue:
+		case msg, ok := <-respCh:
 			if !ok {
 				gt.Info("消息队列已关闭")
 				return "", fmt.Errorf("消息队列已关闭")
 			}
 			utils.Debug("收到的消息 -> ", msg.Content)
-			if chromeInstance.NextID == msg.ID {
+			if reqID == msg.ID {
 				result, err := gt.Json2Map(msg.Content)
 				if err != nil {
 					gt.Error("回复内容解析错误")
@@ -347,7 +303,8 @@ func activateTarget() (string, error) {
 		// todo
 	}
 
-	chromeInstance.NextID++
+	reqID, respCh := nextReply()
+	defer dropReply(reqID)
 	message := fmt.Sprintf(`{
 	   "id": %d,
 	   "method": "Target.activateTarget",
@@ -355,8 +312,8 @@ func activateTarget() (string, error) {
 	       "targetId": "%s",
         	"flatten": true
 	   }
-	}`, chromeInstance.NextID, chromeInstance.NowTabTargetId)
-	err := chromeInstance.NowTabWSConn.WriteMessage(websocket.TextMessage, []byte(message))
+	}`, reqID, chromeInstance.NowTabTargetId)
+	err := wsWrite(chromeInstance.NowTabWSConn, []byte(message))
 	if err != nil {
 		log.Println("[Chrome]发送消息失败:", err)
 		return "", fmt.Errorf("发送消息失败")
@@ -369,13 +326,13 @@ func activateTarget() (string, error) {
 
 	for {
 		select {
-		case msg, ok := <-messageQueue:
+		case msg, ok := <-respCh:
 			if !ok {
 				utils.Debug("消息队列已关闭")
 				return "", fmt.Errorf("消息队列已关闭")
 			}
 			utils.Debug("收到的消息 -> ", msg.Content)
-			if chromeInstance.NextID == msg.ID {
+			if reqID == msg.ID {
 				result, err := gt.Json2Map(msg.Content)
 				if err != nil {
 					utils.Debug("回复内容解析错误")
@@ -0,0 +1,329 @@
+package browser
+
+import (
+	"ChromeBot/utils"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+/*
+tab登记表
+
+通过浏览器级连接的 Target.setDiscoverTargets 订阅 Target.targetCreated/targetDestroyed/targetInfoChanged 事件维护,
+tab按创建顺序排列, 顺序不会因为访问而改变; index 从1开始
+新打开的tab(如链接弹出的窗口)会记入待领取列表, 供 WaitNewTab 使用;
+每个chrome指令(等待新tab除外)开始时用 MarkNewTabs 记录起点, WaitNewTab 只领取起点之后打开的tab,
+这样 点击 -> 等待新tab 能拿到点击弹出的tab, 而不会拿到更早弹出且没人领取的tab
+*/
+
+// TabInfo tab信息
+type TabInfo struct {
+	Index    int    // 顺序, 从1开始
+	TargetId string // 稳定的id
+	Title    string // 标题
+	URL      string // 地址
+	OpenerId string // 由哪个tab打开的
+	Created  time.Time
+}
+
+type tabRegistry struct {
+	mu        sync.Mutex
+	conn      *websocket.Conn // 订阅事件所用的浏览器连接
+	tabs      []*TabInfo
+	unclaimed []string      // 登记表启动后新打开且未被领取的tab
+	since     time.Time     // 只领取这之后打开的tab, 见 MarkNewTabs
+	newTab    chan struct{} // 有新tab时通知
+	listenId  int
+}
+
+var tabs = &tabRegistry{newTab: make(chan struct{}, 1)}
+
+// ensureTabRegistry 启动tab登记表, 浏览器连接变化后重新订阅
+func ensureTabRegistry() error {
+	conn, err := ensureBrowserWS()
+	if err != nil {
+		return err
+	}
+
+	tabs.mu.Lock()
+	if tabs.conn == conn {
+		tabs.mu.Unlock()
+		return nil
+	}
+	tabs.conn = conn
+	if tabs.listenId == 0 {
+		tabs.listenId = OnEvent("Target.*", tabs.onTargetEvent)
+	}
+	tabs.mu.Unlock()
+
+	// 先按当前的顺序登记已有的tab并清理已不存在的, 再订阅变化
+	res, err := cdpCall(conn, "", "Target.getTargets", nil, 6*time.Second)
+	if err != nil {
+		return err
+	}
+	infos, _ := res["targetInfos"].([]any)
+	tabs.mu.Lock()
+	exist := make(map[string]bool)
+	for _, v := range infos {
+		if info, ok := v.(map[string]any); ok {
+			exist[mapStr(info, "targetId")] = true
+			tabs.upsert(info, false)
+		}
+	}
+	for _, t := range tabs.snapshotLocked() {
+		if !exist[t.TargetId] {
+			tabs.remove(t.TargetId)
+		}
+	}
+	tabs.mu.Unlock()
+
+	_, err = cdpCall(conn, "", "Target.setDiscoverTargets", map[string]any{"discover": true}, 6*time.Second)
+	return err
+}
+
+func isTabTarget(info map[string]any) bool {
+	return mapStr(info, "type") == "page" && mapStr(info, "url") != "chrome://omnibox-popup.top-chrome/"
+}
+
+// upsert 登记或更新tab, 需持有锁
+func (r *tabRegistry) upsert(info map[string]any, isNew bool) {
+	if !isTabTarget(info) {
+		return
+	}
+	targetId := mapStr(info, "targetId")
+	for _, t := range r.tabs {
+		if t.TargetId == targetId {
+			t.Title = mapStr(info, "title")
+			t.URL = mapStr(info, "url")
+			return
+		}
+	}
+	r.tabs = append(r.tabs, &TabInfo{
+		TargetId: targetId,
+		Title:    mapStr(info, "title"),
+		URL:      mapStr(info, "url"),
+		OpenerId: mapStr(info, "openerId"),
+		Created:  time.Now(),
+	})
+	if isNew {
+		r.unclaimed = append(r.unclaimed, targetId)
+		select {
+		case r.newTab <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (r *tabRegistry) remove(targetId string) {
+	for i, t := range r.tabs {
+		if t.TargetId == targetId {
+			r.tabs = append(r.tabs[:i], r.tabs[i+1:]...)
+			break
+		}
+	}
+	r.claim(targetId)
+}
+
+// claim 从待领取列表中移除, 需持有锁
+func (r *tabRegistry) claim(targetId string) {
+	for i, id := range r.unclaimed {
+		if id == targetId {
+			r.unclaimed = append(r.unclaimed[:i], r.unclaimed[i+1:]...)
+			return
+		}
+	}
+}
+
+func (r *tabRegistry) onTargetEvent(method, sessionId string, params map[string]any) {
+	if sessionId != "" {
+		return // 只处理浏览器级的事件
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch method {
+	case "Target.targetCreated":
+		info, _ := params["targetInfo"].(map[string]any)
+		r.upsert(info, true)
+	case "Target.targetInfoChanged":
+		info, _ := params["targetInfo"].(map[string]any)
+		r.upsert(info, false)
+		if c := chromeInstance; c != nil && mapStr(info, "targetId") == c.NowTabTargetId {
+			c.NowTab = mapStr(info, "title")
+		}
+	case "Target.targetDestroyed":
+		r.remove(mapStr(params, "targetId"))
+	}
+}
+
+// snapshot 复制当前的tab列表
+func (r *tabRegistry) snapshot() []TabInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.snapshotLocked()
+}
+
+func (r *tabRegistry) snapshotLocked() []TabInfo {
+	res := make([]TabInfo, 0, len(r.tabs))
+	for i, t := range r.tabs {
+		item := *t
+		item.Index = i + 1
+		res = append(res, item)
+	}
+	return res
+}
+
+// TabList 获取所有tab, 按打开顺序
+func TabList() ([]TabInfo, error) {
+	if !DefaultNowTab(false) {
+		return nil, notReadyErr()
+	}
+	if err := ensureTabRegistry(); err != nil {
+		return nil, err
+	}
+	return tabs.snapshot(), nil
+}
+
+// FindTab 查找tab, key可以是 序号(从1开始)、targetId、地址匹配(见 utils.MatchURLPattern)
+func FindTab(key string) (TabInfo, error) {
+	list, err := TabList()
+	if err != nil {
+		return TabInfo{}, err
+	}
+	if n, err := strconv.Atoi(key); err == nil {
+		if n < 1 || n > len(list) {
+			return TabInfo{}, fmt.Errorf("tab序号超出范围: %d, 当前共%d个tab", n, len(list))
+		}
+		return list[n-1], nil
+	}
+	for _, t := range list {
+		if t.TargetId == key {
+			return t, nil
+		}
+	}
+	for _, t := range list {
+		if utils.MatchURLPattern(key, t.URL) {
+			return t, nil
+		}
+	}
+	return TabInfo{}, fmt.Errorf("未匹配到tab: %s", key)
+}
+
+// SwitchTab 切换当前操作的tab
+func SwitchTab(key string) (TabInfo, error) {
+	t, err := FindTab(key)
+	if err != nil {
+		return t, err
+	}
+	if t.TargetId != chromeInstance.NowTabTargetId {
+		tabs.mu.Lock()
+		tabs.claim(t.TargetId)
+		tabs.mu.Unlock()
+		SelectTab(t.TargetId)
+	}
+	log.Printf("[Chrome]当前tab: %d %s %s", t.Index, t.Title, t.URL)
+	return t, nil
+}
+
+// CloseTab 关闭tab, targetId为空时关闭当前tab; 关闭的是当前tab时切换到前一个tab
+func CloseTab(targetId string) error {
+	list, err := TabList()
+	if err != nil {
+		return err
+	}
+	if targetId == "" {
+		targetId = chromeInstance.NowTabTargetId
+	}
+	idx := -1
+	for i, t := range list {
+		if t.TargetId == targetId {
+			idx = i
+		}
+	}
+	if idx == -1 {
+		return fmt.Errorf("未匹配到tab: %s", targetId)
+	}
+	if len(list) <= 1 {
+		return fmt.Errorf("当前页签小于等于1个，不允许被关闭")
+	}
+
+	res, err := browserCall("Target.closeTarget", map[string]any{"targetId": targetId})
+	if err != nil {
+		return err
+	}
+	if success, _ := res["success"].(bool); !success {
+		return fmt.Errorf("关闭tab失败: %s", targetId)
+	}
+
+	if targetId == chromeInstance.NowTabTargetId {
+		next := list[0]
+		if idx > 0 {
+			next = list[idx-1]
+		} else {
+			next = list[1]
+		}
+		log.Println("[Chrome]关闭的是当前tab, 切换到 ", next.TargetId)
+		SelectTab(next.TargetId)
+	}
+	return nil
+}
+
+// MarkNewTabs 记录起点, WaitNewTab 只领取起点之后打开的tab
+func MarkNewTabs() {
+	tabs.mu.Lock()
+	tabs.since = time.Now()
+	tabs.mu.Unlock()
+}
+
+// popNew 取出起点之后打开的第一个待领取的tab, 起点之前打开的直接丢弃, 需持有锁
+func (r *tabRegistry) popNew() string {
+	for len(r.unclaimed) > 0 {
+		targetId := r.unclaimed[0]
+		r.unclaimed = r.unclaimed[1:]
+		for _, t := range r.tabs {
+			if t.TargetId == targetId && !t.Created.Before(r.since) {
+				return targetId
+			}
+		}
+	}
+	return ""
+}
+
+// WaitNewTab 等待新打开的tab(如点击链接弹出的窗口)并切换过去; 起点(上一个chrome指令开始时)之后已打开且未被领取的tab会直接返回
+func WaitNewTab(timeout time.Duration) (TabInfo, error) {
+	if _, err := TabList(); err != nil {
+		return TabInfo{}, err
+	}
+	deadline := time.After(timeout)
+	for {
+		tabs.mu.Lock()
+		targetId := tabs.popNew()
+		tabs.mu.Unlock()
+
+		if targetId != "" {
+			t, err := FindTab(targetId)
+			if err != nil {
+				continue // 已经被关闭了
+			}
+			SelectTab(t.TargetId)
+			return t, nil
+		}
+
+		select {
+		case <-tabs.newTab:
+		case <-deadline:
+			return TabInfo{}, fmt.Errorf("等待新tab超时: %v", timeout)
+		}
+	}
+}
+
+// claimTab 标记tab已被领取, 自己创建的tab不算新弹出的tab
+func claimTab(targetId string) {
+	tabs.mu.Lock()
+	defer tabs.mu.Unlock()
+	tabs.claim(targetId)
+}
@@ -0,0 +1,30 @@
+package browser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTabRegistryPopNew(t *testing.T) {
+	r := &tabRegistry{newTab: make(chan struct{}, 1)}
+	r.upsert(map[string]any{"targetId": "old", "type": "page", "url": "https://a.com/"}, true)
+
+	r.since = time.Now().Add(time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	r.upsert(map[string]any{"targetId": "new1", "type": "page", "url": "https://b.com/"}, true)
+	r.upsert(map[string]any{"targetId": "new2", "type": "page", "url": "https://c.com/"}, true)
+
+	// 起点之前打开的tab不会被领取
+	if id := r.popNew(); id != "new1" {
+		t.Fatalf("popNew = %q, want new1", id)
+	}
+	if id := r.popNew(); id != "new2" {
+		t.Fatalf("popNew = %q, want new2", id)
+	}
+	if id := r.popNew(); id != "" {
+		t.Fatalf("popNew = %q, want empty", id)
+	}
+	if len(r.unclaimed) != 0 {
+		t.Errorf("待领取列表没有清空: %v", r.unclaimed)
+	}
+}
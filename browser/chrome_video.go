@@ -0,0 +1,294 @@
+package browser
+
+import (
+	"ChromeBot/utils"
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+/*
+录屏编码, 纯Go实现不依赖ffmpeg
+
+- gif: 每一帧按帧之间的时间设置时长, 颜色量化为固定的 3-3-2 调色板(256色), 文件较大但编码很快
+- avi: MJPEG视频, 录到的jpeg帧直接写入AVI容器, 按固定帧率补帧, 常见播放器都能播放
+- mjpeg: jpeg帧直接拼接的MJPEG流, 可以用 ffplay、VLC 播放
+
+webm、webp、mp4 需要 VP8/VP9/H264 编码器, 纯Go没有, 这些扩展名改为保存avi
+*/
+
+// VideoFrame 录屏的一帧, jpeg数据与时间(秒)
+type VideoFrame struct {
+	Data []byte
+	Time float64
+}
+
+// VideoFormat 按扩展名选择编码格式, 第二个返回值为false表示不支持该扩展名, 使用avi
+func VideoFormat(path string) (string, bool) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gif":
+		return "gif", true
+	case ".avi":
+		return "avi", true
+	case ".mjpeg", ".mjpg":
+		return "mjpeg", true
+	}
+	return "avi", false
+}
+
+// resampleFrames 按固定帧率取帧, 每个时刻取此刻显示的帧, 用于没有逐帧时长的格式
+func resampleFrames(frames []VideoFrame, fps int) []VideoFrame {
+	if len(frames) == 0 || fps <= 0 {
+		return frames
+	}
+	start, end := frames[0].Time, frames[len(frames)-1].Time
+	n := int((end-start)*float64(fps)) + 1
+	res := make([]VideoFrame, 0, n)
+	j := 0
+	for i := 0; i < n; i++ {
+		t := start + float64(i)/float64(fps)
+		for j+1 < len(frames) && frames[j+1].Time <= t {
+			j++
+		}
+		res = append(res, VideoFrame{Data: frames[j].Data, Time: t})
+	}
+	return res
+}
+
+// palette332 红绿各8级蓝4级的固定调色板, 下标可以直接由颜色计算
+var palette332 = func() color.Palette {
+	p := make(color.Palette, 256)
+	for i := range p {
+		p[i] = color.RGBA{
+			R: uint8((i >> 5 & 7) * 255 / 7),
+			G: uint8((i >> 2 & 7) * 255 / 7),
+			B: uint8((i & 3) * 255 / 3),
+			A: 255,
+		}
+	}
+	return p
+}()
+
+// quantize332 把图片转为 palette332 的调色板图片
+func quantize332(img image.Image) *image.Paletted {
+	b := img.Bounds()
+	rgba, ok := img.(*image.RGBA)
+	if !ok {
+		rgba = image.NewRGBA(b)
+		draw.Draw(rgba, b, img, b.Min, draw.Src)
+	}
+	out := image.NewPaletted(image.Rect(0, 0, b.Dx(), b.Dy()), palette332)
+	for y := 0; y < b.Dy(); y++ {
+		src := rgba.Pix[y*rgba.Stride : y*rgba.Stride+b.Dx()*4]
+		dst := out.Pix[y*out.Stride : y*out.Stride+b.Dx()]
+		for x := range dst {
+			r, g, bl := int(src[x*4]), int(src[x*4+1]), int(src[x*4+2])
+			dst[x] = uint8((r*7+127)/255<<5 | (g*7+127)/255<<2 | (bl*3+127)/255)
+		}
+	}
+	return out
+}
+
+// EncodeGIF 编码为动图, 每一帧显示到下一帧的时间, 最后一帧显示1秒
+func EncodeGIF(w io.Writer, frames []VideoFrame) error {
+	anim := &gif.GIF{}
+	for i, f := range frames {
+		img, err := jpeg.Decode(bytes.NewReader(f.Data))
+		if err != nil {
+			return fmt.Errorf("解析第%d帧失败: %w", i+1, err)
+		}
+		p := quantize332(img)
+		anim.Image = append(anim.Image, p)
+		delay := 100
+		if i+1 < len(frames) {
+			delay = int((frames[i+1].Time-f.Time)*100 + 0.5)
+		}
+		anim.Delay = append(anim.Delay, min(max(delay, 2), 65535))
+		anim.Config.Width = max(anim.Config.Width, p.Rect.Dx())
+		anim.Config.Height = max(anim.Config.Height, p.Rect.Dy())
+	}
+	anim.Config.ColorModel = palette332
+	return gif.EncodeAll(w, anim)
+}
+
+// EncodeMJPEG 按固定帧率把jpeg帧直接拼接
+func EncodeMJPEG(w io.Writer, frames []VideoFrame, fps int) error {
+	for _, f := range resampleFrames(frames, fps) {
+		if _, err := w.Write(f.Data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// aviWriter 按小端写AVI的各个字段
+type aviWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (a *aviWriter) fourcc(s string) {
+	if a.err == nil {
+		_, a.err = io.WriteString(a.w, s)
+	}
+}
+
+func (a *aviWriter) u32(vals ...uint32) {
+	for _, v := range vals {
+		if a.err == nil {
+			a.err = binary.Write(a.w, binary.LittleEndian, v)
+		}
+	}
+}
+
+func (a *aviWriter) u16(vals ...uint16) {
+	for _, v := range vals {
+		if a.err == nil {
+			a.err = binary.Write(a.w, binary.LittleEndian, v)
+		}
+	}
+}
+
+func (a *aviWriter) bytes(b []byte) {
+	if a.err == nil {
+		_, a.err = a.w.Write(b)
+	}
+}
+
+// EncodeAVI 编码为MJPEG的AVI视频(AVI 1.0, 单个文件不超过1GB)
+func EncodeAVI(w io.Writer, frames []VideoFrame, fps int) error {
+	if len(frames) == 0 {
+		return fmt.Errorf("没有帧")
+	}
+	if fps <= 0 {
+		fps = DefaultRecordFPS
+	}
+	frames = resampleFrames(frames, fps)
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(frames[0].Data))
+	if err != nil {
+		return fmt.Errorf("解析第1帧失败: %w", err)
+	}
+	width, height := uint32(cfg.Width), uint32(cfg.Height)
+
+	total, maxFrame := int64(4), 0
+	for _, f := range frames {
+		total += 8 + int64(len(f.Data)) + int64(len(f.Data)%2)
+		maxFrame = max(maxFrame, len(f.Data))
+	}
+	const hdrlSize = 4 + (8 + 56) + (8 + 4 + (8 + 56) + (8 + 40))
+	total += 4 + (8 + hdrlSize) + 8 + (8 + 16*int64(len(frames)))
+	if total > 1<<30 {
+		return fmt.Errorf("视频超过1GB, 请缩短录屏时间或降低帧率")
+	}
+	n, riffSize := uint32(len(frames)), uint32(total)
+	moviSize := riffSize - (4 + (8 + hdrlSize) + 8 + (8 + 16*n))
+
+	bw := bufio.NewWriter(w)
+	a := &aviWriter{w: bw}
+	a.fourcc("RIFF")
+	a.u32(riffSize)
+	a.fourcc("AVI ")
+
+	a.fourcc("LIST")
+	a.u32(hdrlSize)
+	a.fourcc("hdrl")
+	a.fourcc("avih")
+	a.u32(56)
+	a.u32(uint32(1000000/fps), uint32(maxFrame*fps), 0, 0x10, n, 0, 1, uint32(maxFrame), width, height, 0, 0, 0, 0)
+
+	a.fourcc("LIST")
+	a.u32(4 + (8 + 56) + (8 + 40))
+	a.fourcc("strl")
+	a.fourcc("strh")
+	a.u32(56)
+	a.fourcc("vids")
+	a.fourcc("MJPG")
+	a.u32(0)
+	a.u16(0, 0)
+	a.u32(0, 1, uint32(fps), 0, n, uint32(maxFrame), 0xFFFFFFFF, 0)
+	a.u16(0, 0, uint16(width), uint16(height))
+	a.fourcc("strf")
+	a.u32(40)
+	a.u32(40, width, height)
+	a.u16(1, 24)
+	a.fourcc("MJPG")
+	a.u32(width*height*3, 0, 0, 0, 0)
+
+	a.fourcc("LIST")
+	a.u32(moviSize)
+	a.fourcc("movi")
+	for _, f := range frames {
+		a.fourcc("00dc")
+		a.u32(uint32(len(f.Data)))
+		a.bytes(f.Data)
+		if len(f.Data)%2 == 1 {
+			a.bytes([]byte{0})
+		}
+	}
+
+	// 索引中的偏移相对 movi 标识的位置
+	a.fourcc("idx1")
+	a.u32(16 * n)
+	offset := uint32(4)
+	for _, f := range frames {
+		a.fourcc("00dc")
+		a.u32(0x10, offset, uint32(len(f.Data)))
+		offset += 8 + uint32(len(f.Data)) + uint32(len(f.Data)%2)
+	}
+	if a.err != nil {
+		return a.err
+	}
+	return bw.Flush()
+}
+
+// SaveVideo 把帧编码保存到文件, 格式按扩展名, 不支持的扩展名改为avi; 返回实际保存的路径
+func SaveVideo(outputPath string, frames []VideoFrame, fps int) (string, error) {
+	if len(frames) == 0 {
+		return "", fmt.Errorf("没有录到画面")
+	}
+	format, ok := VideoFormat(outputPath)
+	if !ok {
+		ext := filepath.Ext(outputPath)
+		avi := strings.TrimSuffix(outputPath, ext) + ".avi"
+		fmt.Printf("[Chrome]不支持编码为 %s, 改为保存MJPEG视频: %s\n", ext, avi)
+		outputPath = avi
+	}
+	outputPath = utils.SanitizeFileName(outputPath)
+	absPath, err := filepath.Abs(outputPath)
+	if err != nil {
+		return "", fmt.Errorf("解析路径失败: %w", err)
+	}
+	if err = os.MkdirAll(filepath.Dir(absPath), 0755); err != nil {
+		return "", fmt.Errorf("创建父目录失败: %w", err)
+	}
+	f, err := os.Create(absPath)
+	if err != nil {
+		return "", fmt.Errorf("创建视频文件失败: %w", err)
+	}
+	switch format {
+	case "gif":
+		err = EncodeGIF(f, frames)
+	case "mjpeg":
+		err = EncodeMJPEG(f, frames, fps)
+	default:
+		err = EncodeAVI(f, frames, fps)
+	}
+	if cerr := f.Close(); err == nil && cerr != nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(absPath)
+		return "", fmt.Errorf("保存视频失败: %w", err)
+	}
+	return outputPath, nil
+}
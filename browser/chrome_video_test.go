@@ -0,0 +1,159 @@
+package browser
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testJPEG 纯色的jpeg帧
+func testJPEG(t *testing.T, c color.Color, w, h int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 90}); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestFrameBuffer(t *testing.T) {
+	b := &frameBuffer{fps: 5}
+	for _, ts := range []float64{10, 10.1, 10.15, 10.2, 10.5, 10.55} {
+		b.add(VideoFrame{Time: ts})
+	}
+	times := func(frames []VideoFrame) []float64 {
+		res := make([]float64, 0, len(frames))
+		for _, f := range frames {
+			res = append(res, f.Time)
+		}
+		return res
+	}
+	// 超过帧率上限的帧丢弃, 最后一帧保留
+	if got := times(b.list()); len(got) != 4 || got[1] != 10.2 || got[2] != 10.5 || got[3] != 10.55 {
+		t.Errorf("帧率上限: %v", got)
+	}
+
+	b = &frameBuffer{fps: 8, keep: 2 * time.Second}
+	for i := 0; i < 40; i++ {
+		b.add(VideoFrame{Time: float64(i) * 0.125})
+	}
+	got := times(b.list())
+	if len(got) != 17 || got[0] != 2.875 || got[len(got)-1] != 4.875 {
+		t.Errorf("只保留最近2秒: %d %v", len(got), got)
+	}
+
+	// 超过大小上限时丢弃最早的帧
+	b = &frameBuffer{fps: 8, maxBytes: 10}
+	for i := 0; i < 10; i++ {
+		b.add(VideoFrame{Data: []byte("abcd"), Time: float64(i) * 0.125})
+	}
+	got = times(b.list())
+	if len(got) != 2 || got[0] != 1 || b.size != 8 || b.dropped != 8 {
+		t.Errorf("大小上限: %v size=%d dropped=%d", got, b.size, b.dropped)
+	}
+}
+
+func TestResampleFrames(t *testing.T) {
+	a, b := []byte("a"), []byte("b")
+	res := resampleFrames([]VideoFrame{{Data: a, Time: 1}, {Data: b, Time: 1.5}}, 4)
+	if len(res) != 3 || string(res[0].Data) != "a" || string(res[1].Data) != "a" || string(res[2].Data) != "b" {
+		t.Errorf("补帧: %v", res)
+	}
+	if res = resampleFrames([]VideoFrame{{Data: a, Time: 3}}, 5); len(res) != 1 {
+		t.Errorf("单帧: %v", res)
+	}
+}
+
+func TestEncodeGIF(t *testing.T) {
+	frames := []VideoFrame{
+		{Data: testJPEG(t, color.White, 32, 24), Time: 1},
+		{Data: testJPEG(t, color.RGBA{R: 255, A: 255}, 32, 24), Time: 1.3},
+		{Data: testJPEG(t, color.Black, 32, 24), Time: 1.301},
+	}
+	var buf bytes.Buffer
+	if err := EncodeGIF(&buf, frames); err != nil {
+		t.Fatal(err)
+	}
+	anim, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(anim.Image) != 3 || anim.Config.Width != 32 || anim.Config.Height != 24 {
+		t.Fatalf("帧数与尺寸: %d %dx%d", len(anim.Image), anim.Config.Width, anim.Config.Height)
+	}
+	if anim.Delay[0] != 30 || anim.Delay[1] != 2 || anim.Delay[2] != 100 {
+		t.Errorf("帧时长: %v", anim.Delay)
+	}
+	r, g, b, _ := anim.Image[1].At(5, 5).RGBA()
+	if r>>8 < 240 || g>>8 > 20 || b>>8 > 20 {
+		t.Errorf("颜色: %d %d %d", r>>8, g>>8, b>>8)
+	}
+}
+
+func TestEncodeAVI(t *testing.T) {
+	frames := []VideoFrame{
+		{Data: testJPEG(t, color.White, 33, 17), Time: 0},
+		{Data: testJPEG(t, color.Black, 33, 17), Time: 1},
+	}
+	var buf bytes.Buffer
+	if err := EncodeAVI(&buf, frames, 4); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	le := binary.LittleEndian
+	if string(data[0:4]) != "RIFF" || string(data[8:12]) != "AVI " || int(le.Uint32(data[4:8])) != len(data)-8 {
+		t.Fatalf("RIFF头: %q %d %d", data[:12], le.Uint32(data[4:8]), len(data))
+	}
+	// avih: 每帧微秒数、总帧数、宽高
+	avih := bytes.Index(data, []byte("avih")) + 8
+	if le.Uint32(data[avih:]) != 250000 || le.Uint32(data[avih+16:]) != 5 || le.Uint32(data[avih+32:]) != 33 || le.Uint32(data[avih+36:]) != 17 {
+		t.Errorf("avih: %v", data[avih:avih+40])
+	}
+	movi := bytes.Index(data, []byte("movi"))
+	idx := bytes.Index(data, []byte("idx1"))
+	if movi < 0 || idx < 0 || int(le.Uint32(data[movi-4:])) != idx-movi {
+		t.Fatalf("movi: %d %d", movi, idx)
+	}
+	if le.Uint32(data[idx+4:]) != 5*16 {
+		t.Errorf("索引大小: %d", le.Uint32(data[idx+4:]))
+	}
+	// 每条索引的偏移指向对应的帧
+	for i := 0; i < 5; i++ {
+		entry := data[idx+8+i*16:]
+		offset, size := int(le.Uint32(entry[8:])), int(le.Uint32(entry[12:]))
+		chunk := data[movi+offset:]
+		if string(chunk[:4]) != "00dc" || int(le.Uint32(chunk[4:])) != size {
+			t.Fatalf("第%d帧索引错误", i)
+		}
+		if _, err := jpeg.DecodeConfig(bytes.NewReader(chunk[8 : 8+size])); err != nil {
+			t.Errorf("第%d帧: %v", i, err)
+		}
+	}
+}
+
+func TestSaveVideo(t *testing.T) {
+	frames := []VideoFrame{{Data: testJPEG(t, color.White, 8, 8), Time: 0}}
+	dir := t.TempDir()
+	path, err := SaveVideo(filepath.Join(dir, "run.webm"), frames, 5)
+	if err != nil || filepath.Ext(path) != ".avi" {
+		t.Fatalf("webm 应改为 avi: %s %v", path, err)
+	}
+	if _, err = os.Stat(path); err != nil {
+		t.Error(err)
+	}
+	if _, err = SaveVideo(filepath.Join(dir, "run.gif"), nil, 5); err == nil {
+		t.Error("没有帧应返回错误")
+	}
+}
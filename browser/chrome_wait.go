@@ -0,0 +1,343 @@
+package browser
+
+import (
+	"ChromeBot/utils"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+/*
+自动等待
+
+点击、输入、滚动到元素等操作前, 在超时时间内反复检查元素直到可操作:
+1. 定位器只匹配到一个元素(不带前缀的xpath取第一个)
+2. 元素在DOM中(attached)
+3. 可见: 非 display:none / visibility:hidden / opacity:0, 尺寸不为0
+4. 稳定: 位置与尺寸不在变化(动画结束)
+5. 可用: 非 disabled; 输入时还要求可编辑
+6. 未被遮挡: 元素中心点没有被弹层等其他元素覆盖(点击)
+超时后返回最后一次观察到的元素状态
+
+等待命令
+waitfor(元素状态)、waiturl(地址)、waitidle(网络空闲)、waittext(页面文本)、waitjs(js条件), 超时返回false与最后状态
+*/
+
+// DefaultActionTimeout 操作等待元素可操作的默认超时时间
+var DefaultActionTimeout = 10 * time.Second
+
+// actionPollInterval 检查间隔
+const actionPollInterval = 150 * time.Millisecond
+
+// Actionability 需要满足的条件
+type Actionability struct {
+	Visible  bool // 可见且稳定
+	Enabled  bool
+	Editable bool
+	Hit      bool // 未被遮挡
+}
+
+var (
+	actionClick  = Actionability{Visible: true, Enabled: true, Hit: true}
+	actionInput  = Actionability{Visible: true, Enabled: true, Editable: true}
+	actionScroll = Actionability{}
+)
+
+// ElementState 元素的可操作状态
+type ElementState struct {
+	Count      int      `json:"count"`
+	Strict     bool     `json:"strict"`
+	Candidates []string `json:"candidates"`
+	Element    string   `json:"element"`
+	Attached   bool     `json:"attached"`
+	Visible    bool     `json:"visible"`
+	Stable     bool     `json:"stable"`
+	Enabled    bool     `json:"enabled"`
+	Editable   bool     `json:"editable"`
+	Covered    string   `json:"covered"` // 遮挡元素的描述
+	Reason     string   `json:"reason"`  // 不可见或不稳定的原因
+}
+
+// ready 是否满足条件, 不满足时返回原因
+func (s ElementState) ready(need Actionability) (bool, string) {
+	switch {
+	case s.Count == 0:
+		return false, "未匹配到元素"
+	case s.Strict && s.Count > 1:
+		return false, fmt.Sprintf("匹配到%d个元素, 候选: %s", s.Count, strings.Join(s.Candidates, " | "))
+	case !s.Attached:
+		return false, "元素已不在DOM中"
+	}
+	if need.Visible {
+		if !s.Visible {
+			return false, "元素不可见(" + s.Reason + ")"
+		}
+		if !s.Stable {
+			return false, "元素不稳定(" + s.Reason + ")"
+		}
+	}
+	if need.Enabled && !s.Enabled {
+		return false, "元素不可用(disabled)"
+	}
+	if need.Editable && !s.Editable {
+		return false, "元素不可编辑"
+	}
+	if need.Hit && s.Covered != "" {
+		return false, "元素被遮挡: " + s.Covered
+	}
+	return true, ""
+}
+
+// String 状态描述, 用于超时错误
+func (s ElementState) String() string {
+	if s.Count == 0 {
+		return "匹配到0个元素"
+	}
+	if s.Strict && s.Count > 1 {
+		return fmt.Sprintf("匹配到%d个元素, 候选: %s", s.Count, strings.Join(s.Candidates, " | "))
+	}
+	yes := func(b bool) string {
+		if b {
+			return "是"
+		}
+		return "否"
+	}
+	res := fmt.Sprintf("元素%s attached=%s visible=%s stable=%s enabled=%s editable=%s",
+		s.Element, yes(s.Attached), yes(s.Visible), yes(s.Stable), yes(s.Enabled), yes(s.Editable))
+	if s.Reason != "" {
+		res += " 原因=" + s.Reason
+	}
+	if s.Covered != "" {
+		res += " 被遮挡=" + s.Covered
+	}
+	return res
+}
+
+// elementState 检查一次元素状态
+func elementState(selector string, need Actionability) (ElementState, error) {
+	selectorJson, _ := json.Marshal(selector)
+	optsJson, _ := json.Marshal(map[string]bool{"hit": need.Hit, "editable": need.Editable})
+	js := fmt.Sprintf("(%s).actionability(%s, %s)", strings.TrimSpace(chromeLocatorJS), string(selectorJson), string(optsJson))
+	value, err := frameEval(js, true, 6*time.Second)
+	if err != nil {
+		return ElementState{}, err
+	}
+	state := ElementState{}
+	b, _ := json.Marshal(value)
+	if err = json.Unmarshal(b, &state); err != nil {
+		return state, fmt.Errorf("解析元素状态失败: %w", err)
+	}
+	return state, nil
+}
+
+// waitActionable 等待元素可操作, 超时返回最后一次观察到的状态
+func waitActionable(selector string, need Actionability, timeout time.Duration) error {
+	if err := ValidateLocator(selector); err != nil {
+		return err
+	}
+	if timeout <= 0 {
+		timeout = DefaultActionTimeout
+	}
+	deadline := time.Now().Add(timeout)
+	var (
+		last    ElementState
+		lastErr error
+		reason  string
+	)
+	for i := 0; ; i++ {
+		last, lastErr = elementState(selector, need)
+		if lastErr == nil {
+			var ok bool
+			if ok, reason = last.ready(need); ok {
+				return nil
+			}
+		} else if isContextLost(lastErr) {
+			// 页面跳转中执行上下文会被销毁, 继续等待
+			reason = lastErr.Error()
+		} else {
+			return lastErr
+		}
+		if i == 0 {
+			log.Printf("[Chrome]等待元素可操作: %s (%s)", selector, reason)
+		}
+		if time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(actionPollInterval)
+	}
+	if lastErr != nil {
+		return fmt.Errorf("等待元素可操作超时(%v): %s, 最后错误: %s", timeout, selector, lastErr.Error())
+	}
+	return fmt.Errorf("等待元素可操作超时(%v): %s, %s; 最后状态: %s", timeout, selector, reason, last.String())
+}
+
+// DefaultWaitTimeout 等待命令(waitfor、waiturl、waitidle、waittext、waitjs)的默认超时时间
+var DefaultWaitTimeout = 30 * time.Second
+
+// waitPollInterval 等待命令的检查间隔
+const waitPollInterval = 100 * time.Millisecond
+
+// pollUntil 在timeout内反复执行check直到返回true; check返回错误时立即结束
+// 超时返回 false 与描述最后状态的错误
+func pollUntil(timeout time.Duration, what string, check func() (bool, string, error)) (bool, error) {
+	if timeout <= 0 {
+		timeout = DefaultWaitTimeout
+	}
+	deadline := time.Now().Add(timeout)
+	last := ""
+	for {
+		ok, state, err := check()
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return true, nil
+		}
+		last = state
+		if time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(waitPollInterval)
+	}
+	if last != "" {
+		return false, fmt.Errorf("等待%s超时(%v), 最后状态: %s", what, timeout, last)
+	}
+	return false, fmt.Errorf("等待%s超时(%v)", what, timeout)
+}
+
+// contextLostErrors 页面跳转、frame被移除时执行上下文被销毁的错误
+var contextLostErrors = []string{
+	"Cannot find context with specified id",
+	"Cannot find default execution context",
+	"Execution context was destroyed",
+	"Inspected target navigated or closed",
+	"No frame with given id found",
+	"已不存在或未加载完成", // 当前frame的上下文不存在, 见 nowFrameContext
+}
+
+// isContextLost 页面跳转中执行上下文被销毁等可以继续等待的错误; 其他错误(js异常、未就绪、连接断开、session失效等)应立即返回
+func isContextLost(err error) bool {
+	msg := err.Error()
+	for _, s := range contextLostErrors {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// WaitFor 等待元素达到指定状态, state: visible(默认) hidden attached detached
+func WaitFor(selector, state string, timeout time.Duration) (bool, error) {
+	if err := ValidateLocator(selector); err != nil {
+		return false, err
+	}
+	if state == "" {
+		state = "visible"
+	}
+	switch state {
+	case "visible", "hidden", "attached", "detached":
+	default:
+		return false, fmt.Errorf("不支持的元素状态: %s, 支持 visible hidden attached detached", state)
+	}
+	selectorJson, _ := json.Marshal(selector)
+	js := fmt.Sprintf("(%s).stateOf(%s)", strings.TrimSpace(chromeLocatorJS), string(selectorJson))
+
+	return pollUntil(timeout, fmt.Sprintf("元素 %s %s", selector, state), func() (bool, string, error) {
+		value, err := frameEval(js, false, 6*time.Second)
+		if err != nil {
+			if isContextLost(err) {
+				return false, err.Error(), nil
+			}
+			return false, "", err
+		}
+		res, _ := value.(map[string]any)
+		attached, _ := res["attached"].(bool)
+		visible, _ := res["visible"].(bool)
+		now := fmt.Sprintf("attached=%v visible=%v", attached, visible)
+		switch state {
+		case "visible":
+			return visible, now, nil
+		case "hidden":
+			return !visible, now, nil
+		case "attached":
+			return attached, now, nil
+		default:
+			return !attached, now, nil
+		}
+	})
+}
+
+// WaitURL 等待当前tab的地址匹配, 支持通配 *、正则 /.../ 与包含匹配(见 utils.MatchURLPattern), 包括单页应用的路由变化
+func WaitURL(pattern string, timeout time.Duration) (bool, error) {
+	if pattern == "" {
+		return false, fmt.Errorf("地址匹配不能为空")
+	}
+	return pollUntil(timeout, "地址匹配 "+pattern, func() (bool, string, error) {
+		res, err := tabCall("Runtime.evaluate", map[string]any{
+			"expression":    "location.href",
+			"returnByValue": true,
+		})
+		if err != nil {
+			return false, err.Error(), nil
+		}
+		obj, _ := res["result"].(map[string]any)
+		href := mapStr(obj, "value")
+		return utils.MatchURLPattern(pattern, href), "当前地址 " + href, nil
+	})
+}
+
+// WaitIdle 等待网络空闲: 没有进行中的请求并持续quiet时长
+func WaitIdle(quiet, timeout time.Duration) (bool, error) {
+	if err := ensureNetwork(); err != nil {
+		return false, err
+	}
+	return pollUntil(timeout, fmt.Sprintf("网络空闲%v", quiet), func() (bool, string, error) {
+		idle, inflight := network.idleFor()
+		if inflight > 0 {
+			return false, fmt.Sprintf("%d个请求进行中", inflight), nil
+		}
+		return idle >= quiet, fmt.Sprintf("已空闲%v", idle.Truncate(time.Millisecond)), nil
+	})
+}
+
+// WaitText 等待当前frame的页面文本中出现text
+func WaitText(text string, timeout time.Duration) (bool, error) {
+	if text == "" {
+		return false, fmt.Errorf("等待的文本不能为空")
+	}
+	textJson, _ := json.Marshal(text)
+	js := fmt.Sprintf("(document.body ? document.body.innerText : '').includes(%s)", string(textJson))
+	return pollUntil(timeout, "文本 "+text, func() (bool, string, error) {
+		value, err := frameEval(js, false, 6*time.Second)
+		if err != nil {
+			return false, err.Error(), nil
+		}
+		ok, _ := value.(bool)
+		return ok, "页面中没有该文本", nil
+	})
+}
+
+// WaitJS 等待js条件为真, expression 可以是表达式(window.ready)或函数(() => window.ready), 支持返回Promise
+func WaitJS(expression string, timeout time.Duration) (bool, error) {
+	if strings.TrimSpace(expression) == "" {
+		return false, fmt.Errorf("js条件不能为空")
+	}
+	js := fmt.Sprintf(`(async () => {
+    const __cbCond = (%s);
+    return !!(typeof __cbCond === 'function' ? await __cbCond() : await __cbCond);
+})()`, expression)
+	return pollUntil(timeout, "js条件 "+expression, func() (bool, string, error) {
+		value, err := frameEval(js, true, 6*time.Second)
+		if err != nil {
+			// 语法错误不会自己恢复, 其他异常(如变量还未定义)继续等待
+			if strings.Contains(err.Error(), "SyntaxError") {
+				return false, "", err
+			}
+			return false, err.Error(), nil
+		}
+		ok, _ := value.(bool)
+		return ok, "条件为false", nil
+	})
+}
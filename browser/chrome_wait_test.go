@@ -0,0 +1,63 @@
+package browser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestElementStateReady(t *testing.T) {
+	ok := ElementState{Count: 1, Strict: true, Attached: true, Visible: true, Stable: true, Enabled: true, Editable: true}
+	if ready, reason := ok.ready(actionClick); !ready {
+		t.Fatalf("期望可点击: %s", reason)
+	}
+
+	cases := []struct {
+		state ElementState
+		need  Actionability
+		want  string
+	}{
+		{ElementState{}, actionClick, "未匹配到元素"},
+		{ElementState{Count: 2, Strict: true, Candidates: []string{"<a>1", "<a>2"}}, actionClick, "匹配到2个元素"},
+		{ElementState{Count: 1, Attached: true, Reason: "display:none"}, actionClick, "不可见(display:none)"},
+		{ElementState{Count: 1, Attached: true, Visible: true, Stable: true, Enabled: true, Covered: `<div class="mask">`}, actionClick, "被遮挡"},
+		{ElementState{Count: 1, Attached: true, Visible: true, Stable: true, Enabled: true}, actionInput, "不可编辑"},
+	}
+	for _, c := range cases {
+		ready, reason := c.state.ready(c.need)
+		if ready || !strings.Contains(reason, c.want) {
+			t.Errorf("got %v %q, want %q", ready, reason, c.want)
+		}
+	}
+
+	// 不带前缀的xpath匹配到多个元素时取第一个
+	bare := ElementState{Count: 3, Attached: true}
+	if ready, reason := bare.ready(actionScroll); !ready {
+		t.Errorf("期望可滚动: %s", reason)
+	}
+}
+
+func TestIsContextLost(t *testing.T) {
+	lost := []string{
+		"Runtime.evaluate 执行错误: Cannot find context with specified id",
+		"Runtime.callFunctionOn 执行错误: Execution context was destroyed.",
+		"frame F1 已不存在或未加载完成, 可使用 chrome frame=main 返回主页面",
+	}
+	for _, msg := range lost {
+		if !isContextLost(errors.New(msg)) {
+			t.Errorf("应继续等待: %s", msg)
+		}
+	}
+	permanent := []string{
+		"js执行异常: ReferenceError: x is not defined",
+		"浏览器未初始化",
+		"发送消息失败",
+		"消息队列已关闭",
+		"Runtime.evaluate 执行错误: Session with given id not found.",
+	}
+	for _, msg := range permanent {
+		if isContextLost(errors.New(msg)) {
+			t.Errorf("应立即返回: %s", msg)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package browser
 
 import (
+	"ChromeBot/utils"
 	"fmt"
 	"log"
 	"regexp"
@@ -38,11 +39,30 @@ func MatchDemoContentOP(htmlText, contentText string) string {
 		return ""
 	}
 
+	return MatchDemoContentOPByDOM(domRoot, contentText)
+}
+
+// MatchDemoContentOPByDOM 在DOM树中匹配标签内容获取到可交互的xpath, shadow root 内的元素返回穿透的xpath
+func MatchDemoContentOPByDOM(domRoot *DOMNode, contentText string) string {
 	// 打印DOM树（含XPath和属性）
 	fmt.Println("MatchDemoContentOP  解析后的DOM树（含XPath和所有属性）：")
+	selectors := MatchDemoContentSelectorsByDOM(domRoot, contentText)
+	if len(selectors) == 0 {
+		return ""
+	}
+	return selectors[0]
+}
+
+// MatchDemoContentSelectorsByDOM 在DOM树中匹配标签内容, 返回元素按稳定程度排序的候选定位器, 第一个是推荐的
+func MatchDemoContentSelectorsByDOM(domRoot *DOMNode, contentText string) []string {
 	xpath := MatchContentDOM(domRoot, contentText)
 	fmt.Println("MatchDemoContentOP 匹配到的xpath = ", xpath)
-	return RemoveNodeSuffix(xpath)
+	if xpath == "" {
+		return nil
+	}
+	selectors := SelectorsByXPath(domRoot, RemoveNodeSuffix(xpath))
+	utils.Debugf("MatchDemoContentOP 候选定位器 = %v", selectors)
+	return selectors
 }
 
 // RemoveNodeSuffix 移除XPath末尾的节点类型后缀（text()/comment()/*），保留元素节点路径
@@ -81,11 +101,35 @@ func GetInputFirstXpath(htmlText string) string {
 		fmt.Printf("html解析失败: %v\n", err)
 		return ""
 	}
+	return GetInputFirstXpathByDOM(domRoot)
+}
+
+// GetInputFirstXpathByDOM 在DOM树中获取第一个能输入的标签的xpath
+func GetInputFirstXpathByDOM(domRoot *DOMNode) string {
 	xpathList := make([]string, 0)
 	MatchInput(domRoot, &xpathList)
 	log.Println("MatchInput xpathList len = ", len(xpathList))
 	if len(xpathList) < 1 {
 		return ""
 	}
-	return xpathList[0]
+	return SelectorsByXPath(domRoot, xpathList[0])[0]
+}
+
+// GetInputFirstSelectorsByDOM 在DOM树中获取第一个能输入的标签的候选定位器, 第一个是推荐的
+func GetInputFirstSelectorsByDOM(domRoot *DOMNode) []string {
+	xpathList := make([]string, 0)
+	MatchInput(domRoot, &xpathList)
+	if len(xpathList) < 1 {
+		return nil
+	}
+	return SelectorsByXPath(domRoot, xpathList[0])
+}
+
+// NowTabDOM 获取当前操作的页面的DOM树, 会进入open的shadow root
+func NowTabDOM() (*DOMNode, error) {
+	root, err := DOMGetDocumentPierce()
+	if err != nil {
+		return nil, err
+	}
+	return ParseCDPNodeToDOM(root), nil
 }
@@ -11,6 +11,7 @@ import (
 type ChromeStmt struct {
 	StartPos Position
 	Args     []Expression
+	Body     *BlockStmt // 参数后的代码块, 由指令决定何时执行, 如 route ... handler { ... }
 }
 
 func (c *ChromeStmt) Pos() Position { return c.StartPos }
@@ -19,6 +20,9 @@ func (c *ChromeStmt) String() string {
 	for i, arg := range c.Args {
 		args[i] = arg.String()
 	}
+	if c.Body != nil {
+		return fmt.Sprintf("chrome %s %s", strings.Join(args, " "), c.Body.String())
+	}
 	return fmt.Sprintf("chrome %s ", strings.Join(args, " "))
 }
 func (c *ChromeStmt) stmtNode() {}
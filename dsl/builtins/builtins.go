@@ -52,4 +52,9 @@ func RegisterBuiltins(interp *interpreter.Interpreter) {
 		interp.Global().SetFunc(name, fn)
 	}
 
+	// 注册 html查询
+	for name, fn := range htmlFn {
+		interp.Global().SetFunc(name, fn)
+	}
+
 }
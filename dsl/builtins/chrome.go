@@ -4,8 +4,10 @@ import (
 	"ChromeBot/browser"
 	"ChromeBot/dsl/interpreter"
 	"ChromeBot/utils"
+	"encoding/json"
 	"fmt"
 	"log"
+	"math"
 	"strings"
 	"time"
 
@@ -44,6 +46,101 @@ var chromeSupport = map[string]bool{
 	"cdp":         true,
 	"params":      true,
 	"cdpfn":       true,
+	"recover":     true,
+	"relaunch":    true,
+	"heal":        true,
+	"health":      true,
+	"list":        true,
+	"switch":      true,
+	"wait_new":    true,
+	"timeout":     true,
+	"frame":       true,
+	"waitfor":     true,
+	"state":       true,
+	"waiturl":     true,
+	"waitidle":    true,
+	"waittext":    true,
+	"waitjs":      true,
+	"type":        true,
+	"delay":       true,
+	"press":       true,
+	"hover":       true,
+	"dblclick":    true,
+	"rightclick":  true,
+	"drag":        true,
+	"drop":        true,
+	"mouse":       true,
+	"x":           true,
+	"y":           true,
+	"human":       true,
+	"js":          true,
+	"jsfile":      true,
+	"args":        true,
+	"raw":         true,
+	"method":      true,
+	"session":     true,
+	"raw_events":  true,
+	"duration":    true,
+	"netlog":      true,
+	"start":       true,
+	"stop":        true,
+	"body":        true,
+	"route":       true,
+	"url":         true,
+	"action":      true,
+	"status":      true,
+	"header":      true,
+	"handler":     true,
+	"clear":       true,
+	"capture":     true,
+	"all":         true,
+
+	// 翻页与无限滚动
+	"paginate":        true,
+	"infinite_scroll": true,
+	"next":            true,
+	"max":             true,
+	"until":           true,
+	"item":            true,
+	"key":             true,
+	"each":            true,
+
+	// 截图选项
+	"element":         true,
+	"viewport":        true,
+	"format":          true,
+	"quality":         true,
+	"omit_background": true,
+	"mask":            true,
+
+	// 截图快照对比
+	"snapshot":  true,
+	"name":      true,
+	"threshold": true,
+	"update":    true,
+
+	// 导出PDF
+	"pdf":             true,
+	"landscape":       true,
+	"paper":           true,
+	"margin":          true,
+	"scale":           true,
+	"header_template": true,
+	"footer_template": true,
+	"page_ranges":     true,
+	"background":      true,
+
+	// 录屏
+	"record_video": true,
+	"fps":          true,
+	"max_size":     true,
+	"keep":         true,
+	"on_error":     true,
+
+	// 文件下载
+	"download": true,
+	"dir":      true,
+	"rename":   true,
 }
 
 func hasChromeSupport(cmd string) bool {
@@ -62,18 +159,82 @@ proxy : 设置浏览器代理与init参数一起用 <值类型是字符串>
 userpath : 设置浏览器在本机的隔离目录与init参数一起用,对应浏览器的--user-data-dir，建议隔离 <值类型是字符串>
 new : 设置浏览器新建一个隔离环境与init参数一起用；与userPath同时在时，优先使用userPath
 tab : 页签, 值有get:获取；set:指定哪个标签切换到指定的页签; new：新建一个页签；1<number>:第一个页签；select：返回当前选中的页签; 注意: 如果是没有选中页签下文操作默认当前浏览器的页签进行操作 <值类型是指定的字符串>
+
+	tab list to=tabs : 按打开顺序获取所有页签存入变量, 每项为字典 {index, id, title, url, opener, active}
+	tab switch=<序号|id|地址匹配> : 切换页签, 地址匹配支持通配 *、正则 /.../ 与包含匹配
+	tab close=<id> : 关闭指定页签, 不给值时关闭当前页签
+	tab wait_new as=t timeout=10000 : 等待上一个指令开始后新打开的页签(如链接弹出的窗口)并切换过去, timeout单位毫秒
+
 req :  请求网址， 值为网址 <值类型是字符串>
+frame : 切换操作的frame(iframe), 值为 iframe元素的定位器(以//或/html开头的xpath、或带css=等前缀)、frame的name、或地址匹配; main 返回主页面; 切换后点击、输入、检查、html等操作都在该frame中执行 <值类型是字符串>
 （ dom : 获取当前页面html的dom树 - 改为函数 ）
 click : 点击操作，值为xpath <值类型是字符串>
+hover、dblclick、rightclick : 原生鼠标悬停、双击、右键, 值为定位器, 发送真实鼠标事件(isTrusted为true)
+drag=<定位器> drop=<定位器> : 原生鼠标拖放, 支持HTML5拖拽
+mouse=move|click|dblclick|rightclick x= y= : 在页面坐标处执行鼠标操作, 默认move
+human : 与鼠标操作一起使用, 鼠标沿曲线分步移动模拟真人轨迹
 xpath : 当前选中的xpath, 输入的时候用
+
+	click、xpath、check、scrollxpath、frame 的值是定位器, 支持以下前缀, 不带前缀时以 / ( 开头为xpath, 其他为css:
+	css=#kw  xpath=//div  text=百度一下(包含) text="百度一下"(完全匹配) text=/正则/
+	role=button[name="Submit"]  label=邮箱  placeholder=请输入  testid=login-btn
+	用 >>> 穿透 open 的 shadow root, 如 css=my-app >>> input[name=q]
+	除不带前缀的xpath取匹配到的第一个外, 定位器匹配到多个元素时报错并列出候选
+	click、xpath、scrollxpath 与鼠标操作的定位器可以带备用 click=["//button[@id='buy']", "text=立即购买"], 按顺序使用第一个匹配到元素的
+	init 带 heal 时, 操作成功后元素的特征按页面与定位器记录到 <脚本名>.locators.json, 定位器都失效时使用最相似的元素并输出漂移警告与建议的定位器
+	查找定位器的时间算在操作的 timeout 内
+	记录的元素有文本时, 文本差别大的元素(如 立即购买 与 删除账户)不会被使用
+
 input : 输入操作，输入内容  <值类型是字符串>
+type : 键盘逐字输入, 与xpath一起用时先聚焦该元素, 中文等通过输入法事件上屏, delay 为每个字符的间隔毫秒 <值类型是字符串>
+press : 按键, 支持组合键如 Enter、Control+A、Shift+Tab, 与xpath一起用时先聚焦该元素 <值类型是字符串>
 check : 检查操作，检查页面是否存在指定xpath  <值类型是字符串>
+js : 在当前frame中执行js代码, 代码是异步函数的函数体, 用return返回结果, 只有一个表达式时可以省略return; 结合as获取结果, 对象转为字典、数组转为列表, Promise会等待完成
+jsfile : 执行本地js文件, 用法同js, 出错时调用栈中显示文件名与行号
+args : 与js、jsfile一起用, 传入js的变量名, 多个用逗号分隔, 在js中是同名的变量, 也可以用 args.变量名 获取
 wait : 默认会执行等待页面加载完成，这个参数给定操作时候设置等待的时间  <值类型是数值类型>
 pause : 默认会执行等待页面加载完成，这个参数给定操作时候设置等待的时间  <值类型是数值类型>
+waitfor=<定位器> state=visible|hidden|attached|detached : 等待元素达到指定状态, state默认visible
+waiturl=<地址匹配> : 等待当前页签地址匹配(通配 *、正则 /.../、包含), 单页应用的路由变化也能等到
+waitidle=500 : 等待网络空闲, 即没有进行中的请求并持续500毫秒
+waittext="文本" : 等待页面中出现文本
+waitjs="() => window.ready" : 等待js条件为真, 可以是表达式或函数, 支持Promise
+
+	以上等待默认超时30秒, 可用timeout修改; 结合as获取结果, 满足为true超时为false; 没有as时超时按指令出错处理
+
+timeout : 等待类操作的超时时间,单位毫秒; click、input、scrollxpath 会自动等待元素可操作(唯一、可见、稳定、可用、未被遮挡), 默认10秒 <值类型是数值类型>
 scroll : 滚动操作，滚动页面  正数往下，负数往上 <值类型是数值类型>  注意: 该滚动存在局限性只针对根节点进行滚动，嵌套容器要想精确请使用 scrollxpath
 scrollpixel : scroll by pixel 滚动操作,滚动到指定坐标， 值为(x,y)如(2000, 500)   注意: 该滚动存在局限性只针对根节点进行滚动, 嵌套容器要想精确请使用 scrollxpath
 scrollxpath : 滚动操作,滚动到指定xpath <值类型是字符串>
 screenshot : 截图操作，浏览器截图操作  值为保存位置  <值类型是字符串>
+
+	screenshot="out.png" element=<定位器> viewport=true format=png|jpeg|webp quality=80 omit_background=true mask=[定位器...] as=img
+	element 只截取元素, viewport 只截取视口, mask 把元素涂黑, as 把base64赋值给变量(可以不给保存位置)
+
+snapshot : 截图快照对比, 第一次运行保存基准图 __snapshots__/<name>.png, 之后与基准图对比(忽略抗锯齿)
+
+	snapshot name="home" threshold=0.01 update element=<定位器> viewport=true mask=[定位器...] as=r
+	差异像素占比不超过threshold为通过, 有差异时写出 <name>.diff.png 与 <name>.actual.png; 支持 element、viewport、mask; update 更新基准图
+	r 为字典 {pass, ratio, diff_pixels, new, baseline, diff, actual}
+
+pdf : 导出PDF, 值为保存位置, 大文档分块读取不会占用大量内存
+
+	pdf="out.pdf" landscape paper=A4|Letter|210mmx297mm margin="1cm 2cm" scale=0.8 background=true page_ranges="1-5, 8"
+	header_template="<div>..</div>" footer_template="<div><span class='pageNumber'></span>/<span class='totalPages'></span></div>" as=path
+
+record_video : 录屏, 页面的画面编码为 gif 或 MJPEG视频(avi、mjpeg), 其他扩展名(webm等)保存为avi
+
+	record_video start="run.gif" fps=5 max_size="1280x720" quality=60 keep=30 on_error="fail.gif"
+	record_video stop as=path
+	fps 帧率上限, max_size 画面的最大尺寸, keep 只保留最近的秒数, on_error 语句出错时保存最近的录屏(不停止录屏)
+	内存中最多保存256MB的帧(默认设置下约10分钟), 超过时丢弃最早的帧
+
+download : 文件下载, 先设置下载目录再触发下载, wait 按顺序等待下一个下载完成
+
+	download dir="./dl"
+	download wait as=f timeout=60000 rename="report_{date}{ext}"
+	f 为字典 {path, suggested_filename, size, mime, url}; rename 支持 {name} {ext} {date} {time} {n}, 默认用建议的文件名
+
 html: 将页面的html存入到指定变量-如果变量未声明这里会自动声明变量  <值类型是字符串>
 to : 将当前操作返回值存入到指定变量-如果变量未声明这里会自动声明变量  <值类型是字符串>
 save : 将将当前操作的页面html存入到指定文件  <值类型是字符串>
@@ -86,6 +247,24 @@ device : 设置浏览器启动设备与init参数一起用， 目前支持: ipho
 
 cdp=<域> params=<jsonStr>: 发送 cdp指令  params是指令所需的参数要求是json字符串  详细见下文 runCDP()
 cdpfn=<方法名> params=<jsonStr>: 发送封装好了的cdp方法，一般是针对特定场景的补充  params是指令所需的函数参数要求是json字符串  详细见下文  runCDPFN()
+raw method=<Domain.method> params={...} session=tab|browser as=r : 直接下发任意cdp指令, 返回结果字典; params 可以是json、json字符串或字典变量; session 默认tab, browser为浏览器级连接, 其他值作为sessionId
+raw_events="Network.*" duration=5000 as=evts : 在duration毫秒内收集匹配的事件, 每项为字典 {method, session, params, time}; 与 raw method= 一起用时先开始收集再下发指令, as 为 {result, events}
+netlog start : 开始记录当前页签的网络请求(包括XHR、fetch), 重新start会清空之前的记录
+netlog stop as=reqs : 停止记录, 每项为字典 {id, url, method, type, status, status_text, mime, request_headers, response_headers, post_data, size, time, from_cache, redirect, error}; 加 body 时获取响应体(body、base64字段)
+netlog save="out.har" : 把记录保存为HAR 1.2文件(包括响应体), 可与stop一起用
+route url="*.png" action=block : 拦截请求; action=mock body= status= header={...} 直接返回给定的响应; action=modify header={...} 修改请求头后继续; action=off 删除该地址的规则
+route url="*api*" handler { ... } : 代码块决定如何处理, 代码块中 request 为请求字典, 修改 action、url、headers 等字段; 代码块中不能使用chrome指令
+route clear : 删除所有拦截规则
+capture url="*api/list*" as=data : 等待下一个指令中地址匹配的XHR、fetch响应, data 为字典 {url, method, status, headers, mime, body, json}, json 是解析后的响应体; timeout 默认10000毫秒
+capture url="*api/list*" all as=list : 收集之后所有匹配的响应, 每个指令执行完后把已完成的追加到list(不等待进行中的请求)
+capture wait : 等待进行中的匹配请求完成(最多timeout)并更新list, 不停止捕获
+capture stop : 等待进行中的匹配请求完成后停止捕获
+paginate next=<定位器> max=50 item=<条目选择器> key=<去重key> as=list each { ... } : 自动翻页, 每页加载完成后执行代码块, 代码块中 page 是页码, html 是页面html, items 是本页新的条目; 下一页按钮不存在、不可用或点击后内容没有变化时结束; key 为 text、@属性 或条目内的选择器, 默认按条目的html去重; as 为所有新条目
+infinite_scroll until=<数量|定位器|no-growth> max=50 item= key= as=list each { ... } : 滚动到底部加载, 条目达到数量、出现定位器的元素或页面不再增长时结束; 用法同paginate
+recover : 设置断线重连的最大次数与init参数一起用，默认5次，0表示不重连 <值类型是数值类型>
+relaunch : 浏览器进程退出后使用相同的配置重新启动并打开断线前的地址，与init参数一起用，与recover的次数无关(recover=0 relaunch 只在进程退出时重新启动)
+heal : 开启自愈定位器, 与init参数一起用, 操作成功后记录元素特征到 <脚本名>.locators.json, 定位器都失效时查找相似元素
+health : 获取浏览器连接状态，结合as使用，断线未能恢复时error字段会有错误信息
 
 语法
 chrome click=`//*[@id="chat-submit-button"]` xpath=`//*[@id="chat-textarea"]` input=`aaaa`
@@ -101,12 +280,20 @@ chrome close  // 关闭浏览器
 */
 func registerChrome(interp *interpreter.Interpreter) {
 
+	// 语句出错时保存最近的录屏
+	interp.OnError(chromeRecordOnError)
+
 	interp.Global().SetFunc("chrome", func(args []interpreter.Value) (interpreter.Value, error) {
 		utils.Debug("执行 chrome 的操作，参数是 ", args, len(args))
 
 		argsStr := make([]string, 0)
+		var block *interpreter.Block // 指令后的代码块
 		for i, arg := range args {
 			utils.Debugf("参数 %d %v %T\n", i, arg, arg)
+			if b, ok := arg.(*interpreter.Block); ok {
+				block = b
+				continue
+			}
 			argsStr = append(argsStr, arg.(string))
 		}
 
@@ -162,8 +349,91 @@ func registerChrome(interp *interpreter.Interpreter) {
 			opNumber++
 		}
 
+		_, hasRaw := argMap["raw"]
+		_, hasRawEvents := argMap["raw_events"]
+		if (hasRaw || hasRawEvents) && opNumber == 0 {
+			op.opType = opRaw
+			for _, key := range []string{"method", "session", "raw_events", "duration"} {
+				if val, ok := argMap[key]; ok {
+					op.arg[key] = val
+				}
+			}
+			opNumber++
+		}
+
+		if val, ok := argMap["netlog"]; ok && opNumber == 0 {
+			op.opType = opNetlog
+			action := val
+			for _, key := range []string{"start", "stop"} {
+				if _, has := argMap[key]; has && action == "" {
+					action = key
+				}
+			}
+			if save, has := argMap["save"]; has {
+				op.arg["save"] = save
+				if action == "" {
+					action = "save"
+				}
+			}
+			_, op.arg["body"] = argMap["body"]
+			op.arg["arg"] = action
+			opNumber++
+		}
+
+		if val, ok := argMap["route"]; ok && opNumber == 0 {
+			op.opType = opRoute
+			if _, has := argMap["clear"]; has {
+				val = "clear"
+			}
+			op.arg["arg"] = val
+			for _, key := range []string{"url", "action", "body", "status", "header"} {
+				if v, has := argMap[key]; has {
+					op.arg[key] = v
+				}
+			}
+			if block != nil {
+				op.arg["handler"] = block.Isolated()
+			} else if _, has := argMap["handler"]; has {
+				fmt.Println("[Chrome]route handler 后需要代码块 { ... }")
+			}
+			opNumber++
+		}
+
+		if val, ok := argMap["capture"]; ok && opNumber == 0 {
+			op.opType = opCapture
+			for _, action := range []string{"stop", "wait"} {
+				if _, has := argMap[action]; has {
+					val = action
+				}
+			}
+			op.arg["arg"] = val
+			if v, has := argMap["url"]; has {
+				op.arg["url"] = v
+			}
+			_, op.arg["all"] = argMap["all"]
+			opNumber++
+		}
+
+		for _, kind := range []string{"paginate", "infinite_scroll"} {
+			if _, ok := argMap[kind]; ok && opNumber == 0 {
+				op.opType = opPaginate
+				op.arg["kind"] = kind
+				for _, key := range []string{"next", "max", "until", "item", "key"} {
+					if v, has := argMap[key]; has {
+						op.arg[key] = v
+					}
+				}
+				if block != nil {
+					op.arg["each"] = block
+				} else if _, has := argMap["each"]; has {
+					fmt.Println("[Chrome]" + kind + " each 后需要代码块 { ... }")
+				}
+				opNumber++
+			}
+		}
+
 		if val, ok := argMap["params"]; ok {
-			if op.opType == opCDP || op.opType == opCDPFN {
+			if op.opType == opCDP || op.opType == opCDPFN || op.opType == opRaw {
 				op.arg["params"] = val
 			}
 		}
@@ -173,7 +443,8 @@ func registerChrome(interp *interpreter.Interpreter) {
 			opNumber++
 		}
 
-		if _, ok := argMap["close"]; ok && opNumber == 0 {
+		_, hasTab := argMap["tab"]
+		if _, ok := argMap["close"]; ok && opNumber == 0 && !hasTab {
 			op.opType = opClose
 			opNumber++
 		}
@@ -208,9 +479,44 @@ func registerChrome(interp *interpreter.Interpreter) {
 			}
 		}
 
+		if val, ok := argMap["recover"]; ok {
+			if op.opType == opInit {
+				op.arg["recover"] = val
+			}
+		}
+
+		if _, ok := argMap["relaunch"]; ok {
+			if op.opType == opInit {
+				op.arg["relaunch"] = 1
+			}
+		}
+
+		if _, ok := argMap["heal"]; ok {
+			if op.opType == opInit {
+				op.arg["heal"] = 1
+			}
+		}
+
+		if _, ok := argMap["health"]; ok && opNumber == 0 {
+			op.opType = opHealth
+			opNumber++
+		}
+
 		if val, ok := argMap["tab"]; ok && opNumber == 0 {
 			op.opType = opTable
 			op.arg["arg"] = val
+			for _, sub := range []string{"list", "switch", "close", "wait_new"} {
+				if subVal, subOK := argMap[sub]; subOK {
+					op.arg["sub"] = sub
+					op.arg["subVal"] = subVal
+				}
+			}
+			opNumber++
+		}
+
+		if val, ok := argMap["frame"]; ok && opNumber == 0 {
+			op.opType = opFrame
+			op.arg["arg"] = val
 			opNumber++
 		}
 
@@ -232,8 +538,39 @@ func registerChrome(interp *interpreter.Interpreter) {
 			opNumber++
 		}
 
+		if val, ok := argMap["type"]; ok && opNumber == 0 {
+			op.opType = opTypeText
+			op.arg["input"] = val
+			opNumber++
+		}
+
+		if val, ok := argMap["press"]; ok && opNumber == 0 {
+			op.opType = opPress
+			op.arg["arg"] = val
+			opNumber++
+		}
+
+		for _, kind := range []string{"hover", "dblclick", "rightclick", "drag", "mouse"} {
+			if val, ok := argMap[kind]; ok && opNumber == 0 {
+				op.opType = opMouse
+				op.arg["kind"] = kind
+				op.arg["arg"] = val
+				opNumber++
+			}
+		}
+
+		for _, key := range []string{"drop", "x", "y", "human"} {
+			if val, ok := argMap[key]; ok {
+				op.arg[key] = val
+			}
+		}
+
+		if val, ok := argMap["delay"]; ok {
+			op.arg["delay"] = val
+		}
+
 		if val, ok := argMap["xpath"]; ok {
-			if op.opType == opInput {
+			if op.opType == opInput || op.opType == opTypeText || op.opType == opPress {
 				op.arg["xpath"] = val
 			}
 		}
@@ -244,6 +581,32 @@ func registerChrome(interp *interpreter.Interpreter) {
 			opNumber++
 		}
 
+		for _, kind := range []string{"js", "jsfile"} {
+			if val, ok := argMap[kind]; ok && opNumber == 0 {
+				op.opType = opJS
+				op.arg["kind"] = kind
+				op.arg["arg"] = val
+				opNumber++
+			}
+		}
+
+		if val, ok := argMap["args"]; ok {
+			op.arg["args"] = val
+		}
+
+		for _, kind := range []string{"waitfor", "waiturl", "waitidle", "waittext", "waitjs"} {
+			if val, ok := argMap[kind]; ok && opNumber == 0 {
+				op.opType = opWaitCond
+				op.arg["kind"] = kind
+				op.arg["arg"] = val
+				opNumber++
+			}
+		}
+
+		if val, ok := argMap["state"]; ok {
+			op.arg["state"] = val
+		}
+
 		if val, ok := argMap["wait"]; ok && opNumber == 0 {
 			wait = gt.Any2Int(val)
 		}
@@ -282,6 +645,64 @@ func registerChrome(interp *interpreter.Interpreter) {
 		if val, ok := argMap["screenshot"]; ok && opNumber == 0 {
 			op.opType = opScreenshot
 			op.arg["arg"] = val
+			for _, key := range []string{"element", "viewport", "format", "quality", "omit_background", "mask"} {
+				if v, has := argMap[key]; has {
+					op.arg[key] = v
+				}
+			}
+			opNumber++
+		}
+
+		if _, ok := argMap["snapshot"]; ok && opNumber == 0 {
+			op.opType = opSnapshot
+			for _, key := range []string{"name", "threshold", "update", "element", "viewport", "mask"} {
+				if v, has := argMap[key]; has {
+					op.arg[key] = v
+				}
+			}
+			opNumber++
+		}
+
+		if val, ok := argMap["pdf"]; ok && opNumber == 0 {
+			op.opType = opPDF
+			op.arg["arg"] = val
+			for _, key := range []string{"landscape", "paper", "margin", "scale", "header_template", "footer_template", "page_ranges", "background"} {
+				if v, has := argMap[key]; has {
+					op.arg[key] = v
+				}
+			}
+			opNumber++
+		}
+
+		if _, ok := argMap["record_video"]; ok && opNumber == 0 {
+			op.opType = opRecord
+			op.arg["arg"] = ""
+			if val, has := argMap["start"]; has {
+				op.arg["arg"] = "start"
+				op.arg["path"] = val
+			} else if _, has := argMap["stop"]; has {
+				op.arg["arg"] = "stop"
+			}
+			for _, key := range []string{"fps", "max_size", "quality", "keep", "on_error"} {
+				if v, has := argMap[key]; has {
+					op.arg[key] = v
+				}
+			}
+			opNumber++
+		}
+
+		if _, ok := argMap["download"]; ok && opNumber == 0 {
+			op.opType = opDownload
+			op.arg["arg"] = ""
+			if val, has := argMap["dir"]; has {
+				op.arg["arg"] = "dir"
+				op.arg["dir"] = val
+			} else if _, has := argMap["wait"]; has {
+				op.arg["arg"] = "wait"
+			}
+			if v, has := argMap["rename"]; has {
+				op.arg["rename"] = v
+			}
 			opNumber++
 		}
 
@@ -305,6 +726,10 @@ func registerChrome(interp *interpreter.Interpreter) {
 			op.arg["as"] = val
 		}
 
+		if val, ok := argMap["timeout"]; ok {
+			op.arg["timeout"] = val
+		}
+
 		utils.Debugf("执行 : %v", op)
 		if ChromeWait > 0 { // todo 以后更好的方案 ，  解决执行脚本命令之间操作太快
 			time.Sleep(time.Duration(ChromeWait) * time.Second)
@@ -315,6 +740,16 @@ func registerChrome(interp *interpreter.Interpreter) {
 			time.Sleep(time.Duration(wait) * time.Second)
 		}
 
+		// tab wait_new 只领取上一个指令开始后打开的tab
+		if op.opType != opTable || op.arg["sub"] != "wait_new" {
+			browser.MarkNewTabs()
+		}
+
+		// capture 之后的指令执行完后取出捕获的接口响应
+		if op.opType != opCapture && len(chromeCaptures) > 0 {
+			defer chromeCaptureAfter(interp)
+		}
+
 		switch op.opType {
 
 		case opInfo:
@@ -359,9 +794,27 @@ func registerChrome(interp *interpreter.Interpreter) {
 			if val, ok := op.arg["device"]; ok {
 				device = val.(string)
 			}
+			recoverTimes := 5
+			if val, ok := op.arg["recover"]; ok {
+				recoverTimes = gt.Any2Int(val)
+			}
+			_, relaunch := op.arg["relaunch"]
+			browser.SetRecover(recoverTimes, relaunch)
+			_, browser.LocatorFingerprint = op.arg["heal"]
 
 			browser.ChromeInit(windowSize, proxy, userPath, device, isNew)
 
+		case opHealth:
+			health := browser.Health()
+			fmt.Println("[Chrome]连接状态: ", health)
+			if asArg, ok := op.arg["as"]; ok {
+				rse := make(interpreter.DictType)
+				for k, v := range health {
+					rse[k] = v
+				}
+				interp.Global().SetVar(asArg.(string), rse)
+			}
+
 		case opClose:
 			fmt.Println("[Chrome]关闭浏览器...")
 			err := browser.Close()
@@ -400,10 +853,48 @@ func registerChrome(interp *interpreter.Interpreter) {
 		case opTable:
 			fmt.Println("[Chrome]tab操作...")
 			arg := op.arg["arg"].(string)
+			sub, _ := op.arg["sub"].(string)
+			subVal := chromeArgVal(interp, gt.Any2String(op.arg["subVal"]))
+
+			switch sub {
+			case "list":
+				arg = "list"
+			case "switch":
+				arg = subVal
+			case "close":
+				if err := browser.CloseTab(subVal); err != nil {
+					fmt.Println("[Chrome]关闭tab错误: ", err.Error())
+				}
+				return nil, nil
+			case "wait_new":
+				t, err := browser.WaitNewTab(chromeTimeout(op, 10*time.Second))
+				if err != nil {
+					fmt.Println("[Chrome]等待新tab错误: ", err.Error())
+					return nil, nil
+				}
+				fmt.Printf("[Chrome]切换到新tab: %d %s %s\n", t.Index, t.Title, t.URL)
+				if asArg, ok := op.arg["as"]; ok {
+					interp.Global().SetVar(asArg.(string), tabToDict(t))
+				}
+				return nil, nil
+			}
+
 			if arg == "list" { // 获取所有tab
-				_, err := browser.GetAllTab()
+				list, err := browser.TabList()
 				if err != nil {
 					log.Println("[Chrome]获取tab错误: ", err.Error())
+					break
+				}
+				rse := make([]interpreter.Value, 0, len(list))
+				for _, t := range list {
+					fmt.Printf("[Chrome]tab %d | id: %s | title: %s | url: %s\n", t.Index, t.TargetId, t.Title, t.URL)
+					rse = append(rse, tabToDict(t))
+				}
+				if toArg, ok := op.arg["to"]; ok {
+					interp.Global().SetVar(toArg.(string), rse)
+				}
+				if asArg, ok := op.arg["as"]; ok {
+					interp.Global().SetVar(asArg.(string), rse)
 				}
 				break
 			}
@@ -423,7 +914,30 @@ func registerChrome(interp *interpreter.Interpreter) {
 				break
 			}
 			log.Println("arg = ", arg)
-			browser.SelectTab(arg)
+			t, err := browser.SwitchTab(arg)
+			if err != nil {
+				fmt.Println("[Chrome]切换tab错误: ", err.Error())
+				break
+			}
+			if asArg, ok := op.arg["as"]; ok {
+				interp.Global().SetVar(asArg.(string), tabToDict(t))
+			}
+
+		case opFrame:
+			fmt.Println("[Chrome]切换frame操作...")
+			key := chromeArgVal(interp, op.arg["arg"].(string))
+			frame, err := browser.SwitchFrame(key)
+			if err != nil {
+				chromeOpError(interp, "[Chrome]切换frame出现错误:", err)
+				break
+			}
+			if asArg, ok := op.arg["as"]; ok {
+				interp.Global().SetVar(asArg.(string), interpreter.DictType{
+					"id":   frame.FrameId,
+					"name": frame.Name,
+					"url":  frame.URL,
+				})
+			}
 
 		case opReq:
 			fmt.Println("[Chrome]请求操作...")
@@ -438,7 +952,7 @@ func registerChrome(interp *interpreter.Interpreter) {
 			fmt.Println("[Chrome]请求 url = ", reqUrl)
 			rse, err := browser.OpenUrl(reqUrl)
 			if err != nil {
-				fmt.Println("[Chrome]请求操作出现错误:", err.Error())
+				chromeOpError(interp, "[Chrome]请求操作出现错误:", err)
 			}
 			if asArg, ok := op.arg["as"]; ok {
 				interp.Global().SetVar(asArg.(string), interpreter.Value(rse))
@@ -447,49 +961,37 @@ func registerChrome(interp *interpreter.Interpreter) {
 		case opClick:
 			fmt.Println("[Chrome]点击操作...")
 
-			xPath := op.arg["arg"].(string)
-			// 匹配一下判断arg是不是变量
-			xPathVal, xPathValOK := interp.Global().GetVar(xPath)
-			if xPathValOK {
-				xPath = xPathVal.(string)
-			}
-
-			_, err := utils.ValidateXPathPureNative(xPath)
+			timeout := chromeTimeout(op, browser.DefaultActionTimeout)
+			loc, err := browser.HealLocator(chromeLocatorArg(interp, op.arg["arg"].(string)), timeout)
 			if err != nil {
-				fmt.Println("[Chrome]点击操作警告: ", err.Error())
+				chromeOpError(interp, "[Chrome]点击操作出现错误:", err)
 				break
 			}
 
-			fmt.Println("[Chrome]点击的Xpath = ", xPath)
-			err = browser.Click(xPath)
+			fmt.Println("[Chrome]点击的Xpath = ", loc.Selector)
+			err = browser.ClickWithTimeout(loc.Selector, loc.Timeout)
 			if err != nil {
-				fmt.Println("[Chrome]点击操作出现错误:", err.Error())
+				chromeOpError(interp, "[Chrome]点击操作出现错误:", err)
+				break
 			}
+			loc.Remember()
 
 		case opInput:
 			fmt.Println("[Chrome]输入操作...")
 
 			xPath, ok := op.arg["xpath"].(string)
-			if !ok {
-				xPath = ""
-			}
-			// 匹配一下判断arg是不是变量
-			xPathVal, xPathValOK := interp.Global().GetVar(xPath)
-			if xPathValOK {
-				xPath = xPathVal.(string)
-			}
-			fmt.Println("[Chrome]输入的Xpath = ", xPath)
-
-			if xPath == "" {
+			if !ok || xPath == "" {
 				fmt.Println("[Chrome]输入操作警告: 未设置Xpath无法执行操作")
 				break
 			}
 
-			_, err := utils.ValidateXPathPureNative(xPath)
+			timeout := chromeTimeout(op, browser.DefaultActionTimeout)
+			loc, err := browser.HealLocator(chromeLocatorArg(interp, xPath), timeout)
 			if err != nil {
-				fmt.Println("[Chrome]输入操作警告: ", err.Error())
+				chromeOpError(interp, "[Chrome]输入操作出现错误:", err)
 				break
 			}
+			fmt.Println("[Chrome]输入的Xpath = ", loc.Selector)
 
 			inputText := op.arg["input"].(string)
 			inputTextVal, inputTextValOK := interp.Global().GetVar(inputText)
@@ -498,9 +1000,287 @@ func registerChrome(interp *interpreter.Interpreter) {
 			}
 			fmt.Println("[Chrome]输入内容 = ", inputText)
 
-			err = browser.Input(xPath, inputText)
+			err = browser.InputWithTimeout(loc.Selector, inputText, loc.Timeout)
+			if err != nil {
+				chromeOpError(interp, "[Chrome]输入操作出现错误:", err)
+				break
+			}
+			loc.Remember()
+
+		case opTypeText:
+			xPath, _ := op.arg["xpath"].(string)
+			timeout := chromeTimeout(op, browser.DefaultActionTimeout)
+			var loc *browser.HealedLocator
+			if xPath != "" {
+				var err error
+				if loc, err = browser.HealLocator(chromeLocatorArg(interp, xPath), timeout); err != nil {
+					chromeOpError(interp, "[Chrome]键盘输入出现错误:", err)
+					break
+				}
+				xPath, timeout = loc.Selector, loc.Timeout
+			}
+			text := chromeArgVal(interp, op.arg["input"].(string))
+			delay := time.Duration(gt.Any2Int(op.arg["delay"])) * time.Millisecond
+			fmt.Println("[Chrome]键盘输入 = ", text)
+			err := browser.TypeText(xPath, text, delay, timeout)
+			if err != nil {
+				chromeOpError(interp, "[Chrome]键盘输入出现错误:", err)
+				break
+			}
+			loc.Remember()
+
+		case opPress:
+			xPath, _ := op.arg["xpath"].(string)
+			timeout := chromeTimeout(op, browser.DefaultActionTimeout)
+			var loc *browser.HealedLocator
+			if xPath != "" {
+				var err error
+				if loc, err = browser.HealLocator(chromeLocatorArg(interp, xPath), timeout); err != nil {
+					chromeOpError(interp, "[Chrome]按键出现错误:", err)
+					break
+				}
+				xPath, timeout = loc.Selector, loc.Timeout
+			}
+			keys := op.arg["arg"].(string)
+			fmt.Println("[Chrome]按键 = ", keys)
+			err := browser.PressKey(xPath, keys, timeout)
+			if err != nil {
+				chromeOpError(interp, "[Chrome]按键出现错误:", err)
+				break
+			}
+			loc.Remember()
+
+		case opMouse:
+			kind := op.arg["kind"].(string)
+			target := chromeArgVal(interp, op.arg["arg"].(string))
+			humanArg, human := op.arg["human"]
+			if human {
+				human = humanArg != "false" // human 与 human=true 都开启
+			}
+			timeout := chromeTimeout(op, browser.DefaultActionTimeout)
+			var loc *browser.HealedLocator
+			if kind != "mouse" {
+				var err error
+				if loc, err = browser.HealLocator(chromeLocatorArg(interp, op.arg["arg"].(string)), timeout); err != nil {
+					chromeOpError(interp, "[Chrome]鼠标操作出现错误:", err)
+					break
+				}
+				target, timeout = loc.Selector, loc.Timeout
+			}
+			var err error
+			switch kind {
+			case "hover":
+				err = browser.Hover(target, human, timeout)
+			case "dblclick":
+				err = browser.DblClick(target, human, timeout)
+			case "rightclick":
+				err = browser.RightClick(target, human, timeout)
+			case "drag":
+				drop, _ := op.arg["drop"].(string)
+				drop = chromeArgVal(interp, drop)
+				if drop == "" {
+					fmt.Println("[Chrome]拖放操作警告: 未设置drop")
+					break
+				}
+				err = browser.DragAndDrop(target, drop, human, timeout)
+			case "mouse":
+				xArg, _ := op.arg["x"].(string)
+				yArg, _ := op.arg["y"].(string)
+				x := gt.Any2Float64(chromeArgVal(interp, xArg))
+				y := gt.Any2Float64(chromeArgVal(interp, yArg))
+				err = browser.MouseAction(target, x, y, human)
+			}
+			if err != nil {
+				chromeOpError(interp, "[Chrome]鼠标操作出现错误:", err)
+				break
+			}
+			loc.Remember()
+
+		case opWaitCond:
+			kind := op.arg["kind"].(string)
+			val := chromeArgVal(interp, op.arg["arg"].(string))
+			timeout := chromeTimeout(op, browser.DefaultWaitTimeout)
+			fmt.Printf("[Chrome]等待操作 %s=%s timeout=%v\n", kind, val, timeout)
+			var (
+				ok  bool
+				err error
+			)
+			switch kind {
+			case "waitfor":
+				state, _ := op.arg["state"].(string)
+				ok, err = browser.WaitFor(val, state, timeout)
+			case "waiturl":
+				ok, err = browser.WaitURL(val, timeout)
+			case "waitidle":
+				quiet := gt.Any2Int(val)
+				if quiet <= 0 {
+					quiet = 500
+				}
+				ok, err = browser.WaitIdle(time.Duration(quiet)*time.Millisecond, timeout)
+			case "waittext":
+				ok, err = browser.WaitText(val, timeout)
+			case "waitjs":
+				ok, err = browser.WaitJS(val, timeout)
+			}
+			// 有as时由脚本判断结果, 否则超时或出错按指令错误处理
+			if asArg, asOK := op.arg["as"]; asOK {
+				if err != nil {
+					fmt.Println("[Chrome]等待操作:", err.Error())
+				}
+				interp.Global().SetVar(asArg.(string), interpreter.Value(ok))
+				break
+			}
+			if err == nil && !ok {
+				err = fmt.Errorf("%s=%s 条件未满足", kind, val)
+			}
+			if err != nil {
+				chromeOpError(interp, "[Chrome]等待操作出现错误:", err)
+			}
+
+		case opRaw:
+			method, _ := op.arg["method"].(string)
+			pattern, _ := op.arg["raw_events"].(string)
+			var collector *browser.EventCollector
+			if pattern != "" {
+				collector = browser.CollectEvents(pattern)
+			}
+			var (
+				result interpreter.Value
+				err    error
+			)
+			if method != "" {
+				paramsArg, _ := op.arg["params"].(string)
+				var params map[string]any
+				params, err = chromeRawParams(interp, paramsArg)
+				if err == nil {
+					session, _ := op.arg["session"].(string)
+					var res map[string]any
+					if res, err = browser.RawCall(method, params, session, chromeTimeout(op, browser.DefaultRawTimeout)); err == nil {
+						result = jsToValue(res)
+					}
+				}
+				if err != nil {
+					chromeOpError(interp, "[Chrome]下发cdp指令出现错误:", err)
+				}
+			}
+			if collector != nil {
+				durationArg, _ := op.arg["duration"].(string)
+				duration := gt.Any2Int(chromeArgVal(interp, durationArg))
+				if duration <= 0 {
+					duration = 3000
+				}
+				time.Sleep(time.Duration(duration) * time.Millisecond)
+				events := make([]interpreter.Value, 0)
+				for _, e := range collector.Stop() {
+					events = append(events, interpreter.DictType{
+						"method":  e.Method,
+						"session": e.SessionId,
+						"params":  jsToValue(e.Params),
+						"time":    e.Time.UnixMilli(),
+					})
+				}
+				fmt.Printf("[Chrome]收集到%d个事件\n", len(events))
+				if method != "" {
+					result = interpreter.DictType{"result": result, "events": events}
+				} else {
+					result = events
+				}
+			}
+			if asArg, asOK := op.arg["as"]; asOK {
+				interp.Global().SetVar(asArg.(string), result)
+			}
+
+		case opNetlog:
+			action := op.arg["arg"].(string)
+			if action == "start" {
+				if err := browser.NetlogStart(); err != nil {
+					chromeOpError(interp, "[Chrome]开始记录网络请求出现错误:", err)
+				}
+				break
+			}
+			if action != "stop" && action != "save" {
+				fmt.Println("[Chrome]netlog 参数错误, 支持 start stop save")
+				break
+			}
+			var entries []browser.NetEntry
+			if action == "stop" {
+				entries = browser.NetlogStop()
+			} else {
+				entries = browser.NetlogEntries()
+			}
+			save, _ := op.arg["save"].(string)
+			if op.arg["body"].(bool) || save != "" {
+				browser.NetlogLoadBodies(entries)
+			}
+			if save != "" {
+				if err := browser.SaveHAR(chromeArgVal(interp, save), entries); err != nil {
+					chromeOpError(interp, "[Chrome]保存HAR出现错误:", err)
+				}
+			}
+			if asArg, asOK := op.arg["as"]; asOK {
+				list := make([]interpreter.Value, 0, len(entries))
+				for i := range entries {
+					list = append(list, netEntryToDict(&entries[i]))
+				}
+				interp.Global().SetVar(asArg.(string), list)
+			}
+
+		case opRoute:
+			if err := chromeRoute(interp, op); err != nil {
+				chromeOpError(interp, "[Chrome]拦截规则出现错误:", err)
+			}
+
+		case opCapture:
+			if err := chromeCaptureCmd(interp, op); err != nil {
+				chromeOpError(interp, "[Chrome]捕获接口响应出现错误:", err)
+			}
+
+		case opPaginate:
+			if err := chromePaginate(interp, op); err != nil {
+				chromeOpError(interp, "[Chrome]翻页出现错误:", err)
+			}
+
+		case opSnapshot:
+			if err := chromeSnapshot(interp, op); err != nil {
+				chromeOpError(interp, "[Chrome]截图快照对比出现错误:", err)
+			}
+
+		case opPDF:
+			if err := chromePDF(interp, op); err != nil {
+				chromeOpError(interp, "[Chrome]导出PDF出现错误:", err)
+			}
+
+		case opRecord:
+			if err := chromeRecordVideo(interp, op); err != nil {
+				chromeOpError(interp, "[Chrome]录屏出现错误:", err)
+			}
+
+		case opDownload:
+			if err := chromeDownload(interp, op); err != nil {
+				chromeOpError(interp, "[Chrome]下载出现错误:", err)
+			}
+
+		case opJS:
+			kind := op.arg["kind"].(string)
+			code := op.arg["arg"].(string)
+			argNames, _ := op.arg["args"].(string)
+			jsArgs, err := chromeJSArgs(interp, argNames)
+			if err != nil {
+				chromeOpError(interp, "[Chrome]执行js出现错误:", err)
+				break
+			}
+			timeout := chromeTimeout(op, browser.DefaultEvalTimeout)
+			var res any
+			if kind == "jsfile" {
+				res, err = browser.EvalJSFile(chromeArgVal(interp, code), jsArgs, timeout)
+			} else {
+				res, err = browser.EvalJS(code, jsArgs, timeout)
+			}
 			if err != nil {
-				fmt.Println("[Chrome]输入操作出现错误:", err.Error())
+				chromeOpError(interp, "[Chrome]执行js出现错误:", err)
+			}
+			if asArg, asOK := op.arg["as"]; asOK {
+				interp.Global().SetVar(asArg.(string), jsToValue(res))
 			}
 
 		case opCheck:
@@ -508,7 +1288,7 @@ func registerChrome(interp *interpreter.Interpreter) {
 			inputText := op.arg["arg"].(string)
 			has, err := browser.Check(inputText)
 			if err != nil {
-				fmt.Println("[Chrome]检查操作出现错误:", err.Error())
+				chromeOpError(interp, "[Chrome]检查操作出现错误:", err)
 			}
 			fmt.Printf("[Chrome]检查操作xPath: %s , %v", inputText, has)
 			if asArg, ok := op.arg["as"]; ok {
@@ -544,36 +1324,27 @@ func registerChrome(interp *interpreter.Interpreter) {
 				err = browser.ScrollByPixel(gt.Any2Int(op.arg["x"]), gt.Any2Int(op.arg["y"]))
 
 			case 2:
-				xpath := op.arg["xpath"].(string)
-				_, err = utils.ValidateXPathPureNative(xpath)
-				if err != nil {
-					fmt.Println("[Chrome]滚动操作警告: ", err.Error())
+				timeout := chromeTimeout(op, browser.DefaultActionTimeout)
+				var loc *browser.HealedLocator
+				if loc, err = browser.HealLocator(chromeLocatorArg(interp, op.arg["xpath"].(string)), timeout); err != nil {
 					break
 				}
-				log.Println("滚动到Xpath = ", xpath)
-				err = browser.ScrollToElement(xpath)
+				log.Println("滚动到Xpath = ", loc.Selector)
+				if err = browser.ScrollToElementWithTimeout(loc.Selector, loc.Timeout); err == nil {
+					loc.Remember()
+				}
 			}
 
 			if err != nil {
-				fmt.Println("[Chrome]滚动操作出现错误:", err.Error())
+				chromeOpError(interp, "[Chrome]滚动操作出现错误:", err)
 			}
 
 		case opScreenshot:
 			fmt.Println("[Chrome]截图操作...")
-			savePath := op.arg["arg"].(string)
-			// 匹配一下判断arg是不是变量
-			savePathVal, savePathValOK := interp.Global().GetVar(savePath)
-			if savePathValOK {
-				savePath = savePathVal.(string)
-			}
-			fmt.Println("[Chrome]存储的 path = ", savePath)
-
-			res, err := browser.CaptureFullPageScreenshot(savePath)
-			if err != nil {
+			if err := chromeScreenshot(interp, op); err != nil {
 				log.Println("[Chrome]截图操作错误: ", err.Error())
 				return nil, fmt.Errorf("[Chrome]截图操作错误: %s", err.Error())
 			}
-			utils.Debugf("[Chrome]截图结果: %v", res)
 
 		case opHtml:
 			fmt.Println("[Chrome]将当前页面的html赋值到变量操作...")
@@ -623,6 +1394,23 @@ var (
 	opScreenshot chromeOPType = "screenshot" // 截图操作
 	opHtml       chromeOPType = "html"       // 将当前页面的html赋值到变量操作
 	opSave       chromeOPType = "save"       // 将当前页面的html保存到本地
+	opHealth     chromeOPType = "health"     // 获取浏览器连接状态
+	opFrame      chromeOPType = "frame"      // 切换操作的frame
+	opWaitCond   chromeOPType = "waitcond"   // 等待条件满足: 元素状态、地址、网络空闲、文本、js条件
+	opTypeText   chromeOPType = "type"       // 键盘逐字输入
+	opPress      chromeOPType = "press"      // 按键
+	opMouse      chromeOPType = "mouse"      // 原生鼠标操作: 悬停、双击、右键、拖放、坐标
+	opJS         chromeOPType = "js"         // 执行js代码或js文件
+	opRaw        chromeOPType = "raw"        // 直接下发任意cdp指令与收集事件
+	opNetlog     chromeOPType = "netlog"     // 记录网络请求与导出HAR
+	opRoute      chromeOPType = "route"      // 请求拦截规则: 拦截、模拟响应、修改请求
+	opCapture    chromeOPType = "capture"    // 捕获接口响应
+	opPaginate   chromeOPType = "paginate"   // 自动翻页与无限滚动
+	opSnapshot   chromeOPType = "snapshot"   // 截图快照对比
+	opPDF        chromeOPType = "pdf"        // 导出PDF
+
+	opRecord   chromeOPType = "record_video" // 录屏
+	opDownload chromeOPType = "download"     // 文件下载
 )
 
 type chromeOperation struct {
@@ -703,8 +1491,12 @@ func processArgs(interp *interpreter.Interpreter, args []string) []string {
 					return result
 				}
 				utils.Debug("函数运行结果 ", rse)
-				// 拼接成完整字符串（前元素 + ( + 拼接内容 + )）
+				// 拼接成完整字符串（前元素 + ( + 拼接内容 + )）, 列表结果(如候选定位器)转为json
 				fullStr := fmt.Sprintf("%s=%s", prevList[0], rse)
+				if list, isList := rse.([]interpreter.Value); isList {
+					b, _ := json.Marshal(valueToJS(list))
+					fullStr = fmt.Sprintf("%s=%s", prevList[0], b)
+				}
 				utils.Debug("fullStr = ", fullStr)
 
 				result = append(result, fullStr)
@@ -726,3 +1518,168 @@ func processArgs(interp *interpreter.Interpreter, args []string) []string {
 
 	return result
 }
+
+// chromeLocatorArg 定位器参数: 可以是变量, 可以带备用定位器, 如 click=["//button[@id='buy']", "text=立即购买"] 或值为列表的变量
+func chromeLocatorArg(interp *interpreter.Interpreter, arg string) []string {
+	if val, ok := interp.Global().GetVar(arg); ok {
+		if list, isList := val.([]interpreter.Value); isList {
+			res := make([]string, 0, len(list))
+			for _, item := range list {
+				res = append(res, gt.Any2String(item))
+			}
+			return res
+		}
+		arg = gt.Any2String(val)
+	}
+	return browser.ParseLocatorList(arg)
+}
+
+// chromeArgVal 参数是变量时取变量的值
+func chromeArgVal(interp *interpreter.Interpreter, arg string) string {
+	if val, ok := interp.Global().GetVar(arg); ok {
+		return gt.Any2String(val)
+	}
+	return arg
+}
+
+// chromeJSArgs 取 args="a,b" 中变量的值作为js参数, 在js代码中是同名的变量
+func chromeJSArgs(interp *interpreter.Interpreter, names string) (map[string]any, error) {
+	res := make(map[string]any)
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		val, ok := interp.Global().GetVar(name)
+		if !ok {
+			return nil, fmt.Errorf("js参数变量未定义: %s", name)
+		}
+		res[name] = valueToJS(val)
+	}
+	return res, nil
+}
+
+// chromeRawParams 解析 raw 指令的参数: json字符串, 或值为字典、json字符串的变量
+func chromeRawParams(interp *interpreter.Interpreter, arg string) (map[string]any, error) {
+	if arg == "" {
+		return map[string]any{}, nil
+	}
+	if val, ok := interp.Global().GetVar(arg); ok {
+		if dict, isDict := val.(interpreter.DictType); isDict {
+			return valueToJS(dict).(map[string]any), nil
+		}
+		arg = gt.Any2String(val)
+	}
+	params := make(map[string]any)
+	if err := json.Unmarshal([]byte(arg), &params); err != nil {
+		return nil, fmt.Errorf("params 不是合法的json对象: %s", arg)
+	}
+	return params, nil
+}
+
+// valueToJS 把DSL的值转换成可以JSON编码的值, 字典的键转为字符串
+func valueToJS(v interpreter.Value) any {
+	switch val := v.(type) {
+	case interpreter.DictType:
+		res := make(map[string]any, len(val))
+		for k, item := range val {
+			res[fmt.Sprint(k)] = valueToJS(item)
+		}
+		return res
+	case []interpreter.Value:
+		res := make([]any, len(val))
+		for i, item := range val {
+			res[i] = valueToJS(item)
+		}
+		return res
+	}
+	return v
+}
+
+// jsToValue 把js返回的JSON值转换成DSL的值: 对象转为字典, 数组转为列表, 整数转为int64
+func jsToValue(v any) interpreter.Value {
+	switch val := v.(type) {
+	case map[string]any:
+		res := make(interpreter.DictType, len(val))
+		for k, item := range val {
+			res[k] = jsToValue(item)
+		}
+		return res
+	case []any:
+		res := make([]interpreter.Value, len(val))
+		for i, item := range val {
+			res[i] = jsToValue(item)
+		}
+		return res
+	case float64:
+		// 超出 2^53 的整数在js中已经不精确, 保持浮点
+		if val == math.Trunc(val) && math.Abs(val) <= 1<<53 {
+			return int64(val)
+		}
+		return val
+	}
+	return v
+}
+
+// chromeOpError 输出指令的错误并执行出错回调(如保存录屏)
+func chromeOpError(interp *interpreter.Interpreter, msg string, err error) {
+	fmt.Println(msg, err.Error())
+	interp.ReportError(msg + " " + err.Error())
+}
+
+// chromeTimeout 获取 timeout= 参数(毫秒), 未设置时用默认值
+func chromeTimeout(op *chromeOperation, def time.Duration) time.Duration {
+	if val, ok := op.arg["timeout"]; ok {
+		if ms := gt.Any2Int(val); ms > 0 {
+			return time.Duration(ms) * time.Millisecond
+		}
+	}
+	return def
+}
+
+func netEntryToDict(e *browser.NetEntry) interpreter.DictType {
+	headers := func(h map[string]string) interpreter.DictType {
+		res := make(interpreter.DictType, len(h))
+		for k, v := range h {
+			res[k] = v
+		}
+		return res
+	}
+	res := interpreter.DictType{
+		"id":               e.RequestId,
+		"url":              e.URL,
+		"method":           e.Method,
+		"type":             e.Type,
+		"status":           int64(e.Status),
+		"status_text":      e.StatusText,
+		"mime":             e.MimeType,
+		"request_headers":  headers(e.RequestHeaders),
+		"response_headers": headers(e.ResponseHeaders),
+		"post_data":        e.PostData,
+		"size":             int64(e.EncodedLength),
+		"time":             int64(e.Duration()),
+		"from_cache":       e.FromCache,
+		"redirect":         e.RedirectURL,
+		"error":            e.Error,
+	}
+	if e.BodyLoaded {
+		res["body"] = e.Body
+		res["base64"] = e.Base64
+	}
+	return res
+}
+
+func tabToDict(t browser.TabInfo) interpreter.DictType {
+	nowTabId := ""
+	if c := browser.GetChromeInstance(); c != nil {
+		nowTabId = c.NowTabTargetId
+	}
+	return interpreter.DictType{
+		"index":  int64(t.Index),
+		"id":     t.TargetId,
+		"title":  t.Title,
+		"url":    t.URL,
+		"opener": t.OpenerId,
+		"active": t.TargetId == nowTabId,
+	}
+}
@@ -0,0 +1,138 @@
+package builtins
+
+import (
+	"ChromeBot/browser"
+	"ChromeBot/dsl/interpreter"
+	"fmt"
+	"time"
+)
+
+// chromeCapture 已开始的接口捕获, 在之后的chrome指令执行完后取出结果
+type chromeCapture struct {
+	capture *browser.Capture
+	as      string
+	all     bool // 收集所有匹配的响应直到 capture stop, 每个指令执行完后只收集已完成的, 不等待
+	timeout time.Duration
+	list    []interpreter.Value
+}
+
+// chromeCaptures 只在执行脚本的协程中访问(隔离执行的代码块如route handler中不能使用chrome指令)
+var chromeCaptures []*chromeCapture
+
+// chrome capture url=<地址匹配> as=data timeout=10000 : 等待下一个指令中匹配的响应
+// chrome capture url=<地址匹配> all as=list : 收集之后所有匹配的响应, 每个指令执行完后把已完成的追加到变量
+// chrome capture wait [url=<地址匹配>] : 等待进行中的匹配请求完成(最多timeout)并更新变量, 不停止捕获
+// chrome capture stop [url=<地址匹配>] : 等待进行中的匹配请求完成并停止捕获
+func chromeCaptureCmd(interp *interpreter.Interpreter, op *chromeOperation) error {
+	urlArg, _ := op.arg["url"].(string)
+	pattern := chromeArgVal(interp, urlArg)
+	switch op.arg["arg"] {
+	case "wait":
+		remain := make([]*chromeCapture, 0, len(chromeCaptures))
+		for _, c := range chromeCaptures {
+			if pattern != "" && c.capture.Pattern != pattern {
+				remain = append(remain, c)
+				continue
+			}
+			if c.all {
+				c.collect(interp, c.timeout)
+				remain = append(remain, c)
+				continue
+			}
+			c.next(interp)
+		}
+		chromeCaptures = remain
+		return nil
+
+	case "stop":
+		remain := make([]*chromeCapture, 0)
+		for _, c := range chromeCaptures {
+			if pattern != "" && c.capture.Pattern != pattern {
+				remain = append(remain, c)
+				continue
+			}
+			if c.all {
+				c.collect(interp, c.timeout)
+			}
+			c.capture.Stop()
+		}
+		chromeCaptures = remain
+		return nil
+	}
+
+	if pattern == "" {
+		return fmt.Errorf("capture 需要 url=<地址匹配>")
+	}
+	as, _ := op.arg["as"].(string)
+	if as == "" {
+		return fmt.Errorf("capture 需要 as=<变量名>")
+	}
+	capture, err := browser.StartCapture(pattern)
+	if err != nil {
+		return err
+	}
+	c := &chromeCapture{
+		capture: capture,
+		as:      as,
+		all:     op.arg["all"] == true,
+		timeout: chromeTimeout(op, browser.DefaultCaptureTimeout),
+		list:    make([]interpreter.Value, 0),
+	}
+	if c.all {
+		interp.Global().SetVar(as, c.list)
+	}
+	chromeCaptures = append(chromeCaptures, c)
+	return nil
+}
+
+// chromeCaptureAfter 在capture之后的指令执行完后调用: 等待下一个匹配的响应, 或收集已完成的响应(不等待进行中的)
+func chromeCaptureAfter(interp *interpreter.Interpreter) {
+	remain := make([]*chromeCapture, 0, len(chromeCaptures))
+	for _, c := range chromeCaptures {
+		if c.all {
+			c.collect(interp, 0)
+			remain = append(remain, c)
+			continue
+		}
+		c.next(interp)
+	}
+	chromeCaptures = remain
+}
+
+// next 等待下一个匹配的响应存入变量并停止捕获, 超时时变量为nil
+func (c *chromeCapture) next(interp *interpreter.Interpreter) {
+	res, err := c.capture.Next(c.timeout)
+	c.capture.Stop()
+	if err != nil {
+		fmt.Println("[Chrome]捕获接口响应出现错误:", err.Error())
+		interp.Global().SetVar(c.as, nil)
+		return
+	}
+	fmt.Printf("[Chrome]捕获到接口响应: %d %s\n", res.Status, res.URL)
+	interp.Global().SetVar(c.as, capturedToDict(res))
+}
+
+// collect 把已完成的响应追加到变量, wait 大于0时先等待进行中的匹配请求完成
+func (c *chromeCapture) collect(interp *interpreter.Interpreter, wait time.Duration) {
+	for _, res := range c.capture.Take(wait) {
+		c.list = append(c.list, capturedToDict(res))
+	}
+	fmt.Printf("[Chrome]已捕获%d个接口响应: %s\n", len(c.list), c.capture.Pattern)
+	interp.Global().SetVar(c.as, c.list)
+}
+
+func capturedToDict(res *browser.Captured) interpreter.DictType {
+	headers := make(interpreter.DictType, len(res.Headers))
+	for k, v := range res.Headers {
+		headers[k] = v
+	}
+	return interpreter.DictType{
+		"url":     res.URL,
+		"method":  res.Method,
+		"status":  int64(res.Status),
+		"headers": headers,
+		"mime":    res.MimeType,
+		"body":    res.Body,
+		"json":    jsToValue(res.JSON),
+	}
+}
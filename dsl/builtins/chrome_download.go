@@ -0,0 +1,44 @@
+package builtins
+
+import (
+	"ChromeBot/browser"
+	"ChromeBot/dsl/interpreter"
+	"fmt"
+	"time"
+)
+
+// chrome download dir="./dl"
+// chrome download wait as=f timeout=60000 rename="report_{date}{ext}"
+func chromeDownload(interp *interpreter.Interpreter, op *chromeOperation) error {
+	switch op.arg["arg"].(string) {
+	case "dir":
+		dir, err := browser.DownloadDir(chromeArgVal(interp, op.arg["dir"].(string)))
+		if err != nil {
+			return err
+		}
+		fmt.Println("[Chrome]下载文件将保存到:", dir)
+		return nil
+
+	case "wait":
+		rename := ""
+		if val, ok := op.arg["rename"].(string); ok {
+			rename = chromeArgVal(interp, val)
+		}
+		info, err := browser.WaitDownload(chromeTimeout(op, 60*time.Second), rename)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("[Chrome]下载完成: %s (%d字节)\n", info.Path, info.Size)
+		if as, ok := op.arg["as"].(string); ok {
+			interp.Global().SetVar(as, interpreter.DictType{
+				"path":               info.Path,
+				"suggested_filename": info.SuggestedFilename,
+				"size":               info.Size,
+				"mime":               info.MimeType,
+				"url":                info.URL,
+			})
+		}
+		return nil
+	}
+	return fmt.Errorf("download 参数错误, 支持 dir=<目录> wait")
+}
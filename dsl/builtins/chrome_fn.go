@@ -15,6 +15,9 @@ var chromeFn = map[string]interpreter.Function{
 	"MatchDemoContentOP":       chromeMatchDemoContentOP,       // 获取匹配到标签内容的xpath, 能用于操作的xpath
 	"NowTabMatchDemoContentOP": chromeNowTabMatchDemoContentOP, // 获取当前操作的页面匹配到标签内容的xpath, 能用于操作的xpath
 	"NowTabGetInputFirstXpath": chromeNowTabGetInputFirstXpath, // 获取当前操作的页面匹配到能输入的标签的xpath，返回匹配到的第一个
+	"HTMLSelectors":            chromeHTMLSelectors,            // HTMLSelectors(html, match_text) 获取匹配到标签内容的元素的候选定位器列表, 按稳定程度排序
+	"NowTabSelectors":          chromeNowTabSelectors,          // NowTabSelectors(match_text) 获取当前操作的页面匹配到标签内容的元素的候选定位器列表, 只保留在页面中唯一的
+	"NowTabInputSelectors":     chromeNowTabInputSelectors,     // NowTabInputSelectors() 获取当前操作的页面第一个能输入的标签的候选定位器列表
 	"NowTabGetPointHTML":       chromeNowTabGetPointHTML,       // NowTabGetPointHTML(label, attr, val)  获取指定位置的HTML， 用标签， 标签属性， 属性值来定位
 	"NowTabGetPointIDHTML":     chromeNowTabGetPointIDHTML,     // NowTabGetPointIDHTML(label, val) 获取指定位置的HTML， 用标签， 标签属性为id， 属性值来定位
 	"NowTabGetPointClassHTML":  chromeNowTabGetPointClassHTML,  // NowTabGetPointClassHTML(label, val) 获取指定位置的HTML， 用标签， 标签属性为class， 属性值来定位
@@ -89,6 +92,11 @@ func chromeNowTabMatchDemoContentOP(args []interpreter.Value) (interpreter.Value
 		return nil, fmt.Errorf("NowTabMatchDemoContentOP(match_text) 参数要求是字符串 ")
 	}
 
+	// 优先使用穿透shadow root的DOM树, 获取失败再解析当前页面的html
+	if domRoot, err := browser.NowTabDOM(); err == nil {
+		return chromeFirstSelector(browser.VerifySelectors(browser.MatchDemoContentSelectorsByDOM(domRoot, matchText))), nil
+	}
+
 	// 获取当前页面
 	htmlText, err := browser.GetHtml()
 	if err != nil {
@@ -101,6 +109,11 @@ func chromeNowTabMatchDemoContentOP(args []interpreter.Value) (interpreter.Value
 }
 
 func chromeNowTabGetInputFirstXpath(args []interpreter.Value) (interpreter.Value, error) {
+	// 优先使用穿透shadow root的DOM树, 获取失败再解析当前页面的html
+	if domRoot, err := browser.NowTabDOM(); err == nil {
+		return chromeFirstSelector(browser.VerifySelectors(browser.GetInputFirstSelectorsByDOM(domRoot))), nil
+	}
+
 	// 获取当前页面
 	htmlText, err := browser.GetHtml()
 	if err != nil {
@@ -112,6 +125,65 @@ func chromeNowTabGetInputFirstXpath(args []interpreter.Value) (interpreter.Value
 	return xpath, nil
 }
 
+// chromeFirstSelector 候选定位器中推荐的一个, 没有时返回空字符串
+func chromeFirstSelector(selectors []string) string {
+	if len(selectors) == 0 {
+		return ""
+	}
+	return selectors[0]
+}
+
+// chromeSelectorList 候选定位器转为DSL的列表
+func chromeSelectorList(selectors []string) []interpreter.Value {
+	list := make([]interpreter.Value, 0, len(selectors))
+	for _, selector := range selectors {
+		list = append(list, selector)
+	}
+	return list
+}
+
+func chromeHTMLSelectors(args []interpreter.Value) (interpreter.Value, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("HTMLSelectors(html, match_text) 需要两个参数")
+	}
+	htmlText, ok := args[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("HTMLSelectors(html, match_text) html 要求是字符串")
+	}
+	matchText, ok := args[1].(string)
+	if !ok {
+		return nil, fmt.Errorf("HTMLSelectors(html, match_text) match_text 要求是字符串")
+	}
+	domRoot, err := browser.ParseHTMLToDOM(htmlText)
+	if err != nil {
+		return nil, err
+	}
+	return chromeSelectorList(browser.MatchDemoContentSelectorsByDOM(domRoot, matchText)), nil
+}
+
+func chromeNowTabSelectors(args []interpreter.Value) (interpreter.Value, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("NowTabSelectors(match_text) 需要一个参数")
+	}
+	matchText, ok := args[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("NowTabSelectors(match_text) 参数要求是字符串")
+	}
+	domRoot, err := browser.NowTabDOM()
+	if err != nil {
+		return nil, err
+	}
+	return chromeSelectorList(browser.VerifySelectors(browser.MatchDemoContentSelectorsByDOM(domRoot, matchText))), nil
+}
+
+func chromeNowTabInputSelectors(args []interpreter.Value) (interpreter.Value, error) {
+	domRoot, err := browser.NowTabDOM()
+	if err != nil {
+		return nil, err
+	}
+	return chromeSelectorList(browser.VerifySelectors(browser.GetInputFirstSelectorsByDOM(domRoot))), nil
+}
+
 func chromeNowTabGetPointHTML(args []interpreter.Value) (interpreter.Value, error) {
 	if len(args) != 3 {
 		return nil, fmt.Errorf("NowTabGetPointHTML(label, attr, val) 需要三个参数")
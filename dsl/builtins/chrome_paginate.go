@@ -0,0 +1,179 @@
+package builtins
+
+import (
+	"ChromeBot/browser"
+	"ChromeBot/dsl/interpreter"
+	"fmt"
+	"strconv"
+
+	gt "github.com/mangenotwork/gathertool"
+)
+
+// chromeDefaultMaxPages 翻页、滚动的默认最大页数
+const chromeDefaultMaxPages = 50
+
+// chrome paginate next=<定位器> max=50 item=<条目选择器> key=<去重key> as=list each { ... }
+// chrome infinite_scroll until=<数量|定位器|no-growth> max=50 item=<条目选择器> key=<去重key> as=list each { ... }
+// 每页加载完成后执行代码块, 代码块中 page 是页码(从1开始), html 是页面html, items 是本页新的条目(节点字典列表, 需要item=)
+func chromePaginate(interp *interpreter.Interpreter, op *chromeOperation) error {
+	kind := op.arg["kind"].(string)
+	block, _ := op.arg["each"].(*interpreter.Block)
+
+	maxPages := chromeDefaultMaxPages
+	if val, ok := op.arg["max"].(string); ok {
+		if n := gt.Any2Int(chromeArgVal(interp, val)); n > 0 {
+			maxPages = n
+		}
+	}
+
+	var items *browser.ItemSet
+	if val, ok := op.arg["item"].(string); ok {
+		key, _ := op.arg["key"].(string)
+		set, err := browser.NewItemSet(chromeArgVal(interp, val), chromeArgVal(interp, key))
+		if err != nil {
+			return err
+		}
+		items = set
+	}
+
+	next, timeout := "", chromeTimeout(op, browser.DefaultActionTimeout)
+	untilCount, untilSel := 0, ""
+	if kind == "paginate" {
+		nextArg, _ := op.arg["next"].(string)
+		if next = chromeArgVal(interp, nextArg); next == "" {
+			return fmt.Errorf("paginate 需要 next=<下一页按钮的定位器>")
+		}
+		if err := browser.ValidateLocator(next); err != nil {
+			return err
+		}
+	} else {
+		timeout = chromeTimeout(op, browser.DefaultScrollSettle)
+		untilArg, _ := op.arg["until"].(string)
+		until := chromeArgVal(interp, untilArg)
+		if n, err := strconv.Atoi(until); err == nil {
+			if items == nil {
+				return fmt.Errorf("until=<数量> 需要 item=<条目选择器>")
+			}
+			untilCount = n
+		} else if until != "" && until != "no-growth" {
+			if err := browser.ValidateLocator(until); err != nil {
+				return err
+			}
+			untilSel = until
+		}
+	}
+
+	all := make([]interpreter.Value, 0)
+	pages := 0
+	reason, err := chromePageLoop(maxPages, func(page int) (string, error) {
+		html, err := browser.GetHtml()
+		if err != nil {
+			return "", err
+		}
+		vars := map[string]interpreter.Value{"page": int64(page), "html": html}
+		if items != nil {
+			nodes, err := items.New(html)
+			if err != nil {
+				return "", err
+			}
+			if page > 1 && len(nodes) == 0 {
+				return "没有新的条目", nil
+			}
+			list := htmlQueryValue(nodes).([]interpreter.Value)
+			vars["items"] = list
+			all = append(all, list...)
+			fmt.Printf("[Chrome]%s 第%d页, 新条目%d个\n", kind, page, len(nodes))
+		} else {
+			fmt.Printf("[Chrome]%s 第%d页\n", kind, page)
+		}
+		pages = page
+
+		if block != nil {
+			// 代码块中可以执行chrome指令, 执行期间释放chrome锁
+			chromeLock.Unlock()
+			_, err := block.Run(vars)
+			chromeLock.Lock()
+			if err == interpreter.ErrBlockBreak {
+				return "代码块中break", nil
+			}
+			if err != nil {
+				return "", err
+			}
+		}
+
+		if untilCount > 0 && items.Total >= untilCount {
+			return fmt.Sprintf("条目数量达到%d", untilCount), nil
+		}
+		if untilSel != "" {
+			visible, err := browser.LocatorVisible(untilSel)
+			if err != nil {
+				return "", err
+			}
+			if visible {
+				return "出现 " + untilSel, nil
+			}
+		}
+		return "", nil
+	}, func() (string, error) {
+		if kind == "paginate" {
+			state, err := browser.GetPagerState(next)
+			if err != nil {
+				return "", err
+			}
+			if !state.Found {
+				return "没有下一页按钮", nil
+			}
+			if state.Disabled || !state.Visible {
+				return "下一页按钮不可用", nil
+			}
+		}
+		before, err := browser.GetPageSignature()
+		if err != nil {
+			return "", err
+		}
+		if kind == "paginate" {
+			err = browser.ClickWithTimeout(next, timeout)
+		} else {
+			err = browser.ScrollToBottom()
+		}
+		if err != nil {
+			return "", err
+		}
+		changed, err := browser.WaitPageChange(before, kind != "paginate", timeout)
+		if err != nil {
+			return "", err
+		}
+		if !changed {
+			return "页面内容没有变化", nil
+		}
+		return "", nil
+	})
+	if err != nil {
+		reason = "出现错误"
+	}
+
+	if items != nil {
+		fmt.Printf("[Chrome]%s 结束(%s), 共%d页, %d个条目\n", kind, reason, pages, items.Total)
+	} else {
+		fmt.Printf("[Chrome]%s 结束(%s), 共%d页\n", kind, reason, pages)
+	}
+	if as, ok := op.arg["as"].(string); ok {
+		interp.Global().SetVar(as, all)
+	}
+	return err
+}
+
+// chromePageLoop 处理当前页, 然后翻到下一页, 直到处理或翻页返回结束原因
+func chromePageLoop(maxPages int, handle func(page int) (string, error), next func() (string, error)) (string, error) {
+	for page := 1; ; page++ {
+		if reason, err := handle(page); reason != "" || err != nil {
+			return reason, err
+		}
+		if page >= maxPages {
+			return fmt.Sprintf("达到最大页数%d", maxPages), nil
+		}
+		if reason, err := next(); reason != "" || err != nil {
+			return reason, err
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package builtins
+
+import (
+	"ChromeBot/browser"
+	"ChromeBot/dsl/interpreter"
+	"fmt"
+
+	gt "github.com/mangenotwork/gathertool"
+)
+
+// chrome pdf="out.pdf" landscape paper=A4|Letter margin="1cm" scale=0.8 header_template="<div>..</div>" footer_template="..."
+// page_ranges="1-5" background=true as=path
+func chromePDF(interp *interpreter.Interpreter, op *chromeOperation) error {
+	savePath := chromeArgVal(interp, op.arg["arg"].(string))
+	if savePath == "" {
+		return fmt.Errorf("pdf 需要保存位置")
+	}
+
+	opts := browser.PDFOptions{
+		Landscape:  chromeFlagArg(interp, op, "landscape"),
+		Background: chromeFlagArg(interp, op, "background"),
+		Timeout:    chromeTimeout(op, browser.DefaultPDFTimeout),
+	}
+	if val, ok := op.arg["paper"].(string); ok {
+		opts.Paper = chromeArgVal(interp, val)
+	}
+	if val, ok := op.arg["margin"].(string); ok {
+		opts.Margin = chromeArgVal(interp, val)
+	}
+	if val, ok := op.arg["scale"].(string); ok {
+		opts.Scale = gt.Any2Float64(chromeArgVal(interp, val))
+	}
+	if val, ok := op.arg["header_template"].(string); ok {
+		opts.HeaderTemplate = chromeArgVal(interp, val)
+	}
+	if val, ok := op.arg["footer_template"].(string); ok {
+		opts.FooterTemplate = chromeArgVal(interp, val)
+	}
+	if val, ok := op.arg["page_ranges"].(string); ok {
+		opts.PageRanges = chromeArgVal(interp, val)
+	}
+
+	path, size, err := browser.SavePDF(savePath, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("[Chrome]PDF已保存到: %s (%d字节)\n", path, size)
+	if as, ok := op.arg["as"].(string); ok {
+		interp.Global().SetVar(as, path)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package builtins
+
+import (
+	"ChromeBot/browser"
+	"ChromeBot/dsl/interpreter"
+	"fmt"
+	"strings"
+	"time"
+
+	gt "github.com/mangenotwork/gathertool"
+)
+
+// chrome record_video start="run.gif" fps=5 max_size="1280x720" quality=60 keep=30 on_error="fail.gif"
+// chrome record_video stop as=path
+func chromeRecordVideo(interp *interpreter.Interpreter, op *chromeOperation) error {
+	switch op.arg["arg"].(string) {
+	case "start":
+		opts := browser.RecordOptions{}
+		if val, ok := op.arg["fps"].(string); ok {
+			opts.FPS = gt.Any2Int(chromeArgVal(interp, val))
+		}
+		if val, ok := op.arg["max_size"].(string); ok {
+			wh := strings.Split(strings.ToLower(chromeArgVal(interp, val)), "x")
+			if len(wh) != 2 {
+				return fmt.Errorf("max_size 格式是 宽x高, 如 1280x720")
+			}
+			opts.MaxWidth, opts.MaxHeight = gt.Any2Int(strings.TrimSpace(wh[0])), gt.Any2Int(strings.TrimSpace(wh[1]))
+		}
+		if val, ok := op.arg["quality"].(string); ok {
+			opts.Quality = gt.Any2Int(chromeArgVal(interp, val))
+		}
+		if val, ok := op.arg["keep"].(string); ok {
+			opts.Keep = time.Duration(gt.Any2Float64(chromeArgVal(interp, val)) * float64(time.Second))
+		}
+		if val, ok := op.arg["on_error"].(string); ok {
+			opts.OnError = chromeArgVal(interp, val)
+		}
+		path, _ := op.arg["path"].(string)
+		return browser.RecordStart(chromeArgVal(interp, path), opts)
+
+	case "stop":
+		path, frames, err := browser.RecordStop()
+		if err != nil {
+			return err
+		}
+		if path != "" {
+			fmt.Printf("[Chrome]录屏已保存到: %s (%d帧)\n", path, frames)
+		}
+		if as, ok := op.arg["as"].(string); ok {
+			interp.Global().SetVar(as, path)
+		}
+		return nil
+	}
+	return fmt.Errorf("record_video 参数错误, 支持 start=<保存位置> stop")
+}
+
+// chromeRecordOnError 语句出错时保存最近的录屏
+func chromeRecordOnError(errMsg string) {
+	path, err := browser.RecordSaveOnError()
+	if err != nil {
+		fmt.Println("[Chrome]保存出错录屏失败:", err.Error())
+		return
+	}
+	if path != "" {
+		fmt.Printf("[Chrome]出错前的录屏已保存到: %s\n", path)
+	}
+}
@@ -0,0 +1,158 @@
+package builtins
+
+import (
+	"ChromeBot/browser"
+	"ChromeBot/dsl/interpreter"
+	"encoding/json"
+	"fmt"
+
+	gt "github.com/mangenotwork/gathertool"
+)
+
+// chrome route url=<地址匹配> action=block|mock|modify|off body= status= header={...}
+// chrome route url=<地址匹配> handler { ... }
+// chrome route clear
+func chromeRoute(interp *interpreter.Interpreter, op *chromeOperation) error {
+	if op.arg["arg"] == "clear" {
+		fmt.Println("[Chrome]清除所有拦截规则")
+		return browser.ClearRoutes()
+	}
+	urlArg, _ := op.arg["url"].(string)
+	pattern := chromeArgVal(interp, urlArg)
+	if pattern == "" {
+		return fmt.Errorf("route 需要 url=<地址匹配>")
+	}
+
+	if block, ok := op.arg["handler"].(*interpreter.Block); ok {
+		return browser.AddRoute(&browser.RouteRule{Pattern: pattern, Handler: routeHandler(block)})
+	}
+
+	action, _ := op.arg["action"].(string)
+	if action == "off" {
+		n := browser.RemoveRoute(pattern)
+		fmt.Printf("[Chrome]删除拦截规则 %s, 共%d条\n", pattern, n)
+		return nil
+	}
+	res := browser.RouteResult{Action: action}
+	if status, has := op.arg["status"].(string); has {
+		res.Status = gt.Any2Int(chromeArgVal(interp, status))
+	}
+	if body, has := op.arg["body"].(string); has {
+		res.Body = chromeBodyArg(interp, body)
+	}
+	if header, has := op.arg["header"].(string); has {
+		headers, err := chromeHeaderArg(interp, header)
+		if err != nil {
+			return err
+		}
+		res.Headers = headers
+	}
+	return browser.AddRoute(&browser.RouteRule{Pattern: pattern, Result: res})
+}
+
+// chromeBodyArg 响应体参数: 字典与列表变量转为json, 其他变量取字符串值
+func chromeBodyArg(interp *interpreter.Interpreter, arg string) string {
+	if val, ok := interp.Global().GetVar(arg); ok {
+		return routeBody(val)
+	}
+	return arg
+}
+
+func routeBody(val interpreter.Value) string {
+	switch val.(type) {
+	case interpreter.DictType, []interpreter.Value:
+		b, _ := json.Marshal(valueToJS(val))
+		return string(b)
+	case nil:
+		return ""
+	}
+	return gt.Any2String(val)
+}
+
+// chromeHeaderArg 头信息参数: json对象、或值为字典、json字符串的变量
+func chromeHeaderArg(interp *interpreter.Interpreter, arg string) (map[string]string, error) {
+	obj, err := chromeRawParams(interp, arg)
+	if err != nil {
+		return nil, fmt.Errorf("header 不是合法的json对象: %s", arg)
+	}
+	headers := make(map[string]string, len(obj))
+	for k, v := range obj {
+		headers[k] = gt.Any2String(v)
+	}
+	return headers, nil
+}
+
+// routeHandler 代码块规则: 代码块中的 request 变量是被拦截的请求,
+// 修改 request 的字段决定如何处理: action(continue block mock modify), mock 时用 status、body、response_headers,
+// 修改 url、method、post_data、headers 时按修改后的请求继续
+func routeHandler(block *interpreter.Block) func(req browser.RouteRequest) browser.RouteResult {
+	return func(req browser.RouteRequest) browser.RouteResult {
+		headers := make(interpreter.DictType, len(req.Headers))
+		for k, v := range req.Headers {
+			headers[k] = v
+		}
+		request := interpreter.DictType{
+			"url":              req.URL,
+			"method":           req.Method,
+			"type":             req.ResourceType,
+			"headers":          headers,
+			"post_data":        req.PostData,
+			"action":           "continue",
+			"status":           int64(200),
+			"body":             "",
+			"response_headers": interpreter.DictType{},
+		}
+		if _, err := block.Run(map[string]interpreter.Value{"request": request}); err != nil {
+			fmt.Println("[Chrome]route handler 出现错误:", err.Error())
+			return browser.RouteResult{}
+		}
+
+		res := browser.RouteResult{Action: gt.Any2String(request["action"])}
+		switch res.Action {
+		case "block":
+			return res
+		case "mock":
+			res.Status = gt.Any2Int(request["status"])
+			res.Body = routeBody(request["body"])
+			res.Headers = dictStrings(request["response_headers"])
+			return res
+		}
+
+		// 修改过请求时按 modify 继续
+		if url := gt.Any2String(request["url"]); url != req.URL {
+			res.URL = url
+		}
+		if method := gt.Any2String(request["method"]); method != req.Method {
+			res.Method = method
+		}
+		if postData := gt.Any2String(request["post_data"]); postData != req.PostData {
+			res.PostData = postData
+		}
+		newHeaders := dictStrings(request["headers"])
+		res.Headers = make(map[string]string)
+		for k, v := range newHeaders {
+			if req.Headers[k] != v {
+				res.Headers[k] = v
+			}
+		}
+		for k := range req.Headers {
+			if _, has := newHeaders[k]; !has {
+				res.Headers[k] = "" // 删除
+			}
+		}
+		if res.URL != "" || res.Method != "" || res.PostData != "" || len(res.Headers) > 0 {
+			res.Action = "modify"
+		}
+		return res
+	}
+}
+
+// dictStrings 字典转为 map[string]string
+func dictStrings(v interpreter.Value) map[string]string {
+	res := make(map[string]string)
+	dict, _ := v.(interpreter.DictType)
+	for k, item := range dict {
+		res[gt.Any2String(k)] = gt.Any2String(item)
+	}
+	return res
+}
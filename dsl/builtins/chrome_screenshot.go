@@ -0,0 +1,115 @@
+package builtins
+
+import (
+	"ChromeBot/browser"
+	"ChromeBot/dsl/interpreter"
+	"encoding/base64"
+	"fmt"
+
+	gt "github.com/mangenotwork/gathertool"
+)
+
+// chrome screenshot="out.png" element=<定位器> viewport=true format=jpeg|webp quality=80 omit_background=true mask=[定位器...] as=img
+// 不给保存位置时只把base64赋值给as
+func chromeScreenshot(interp *interpreter.Interpreter, op *chromeOperation) error {
+	savePath := chromeArgVal(interp, op.arg["arg"].(string))
+	as, hasAs := op.arg["as"].(string)
+	if savePath == "" && !hasAs {
+		return fmt.Errorf("screenshot 需要保存位置或 as=<变量>")
+	}
+
+	opts := chromeScreenshotOptions(interp, op)
+	opts.OmitBackground = chromeFlagArg(interp, op, "omit_background")
+	if val, ok := op.arg["format"].(string); ok {
+		opts.Format = chromeArgVal(interp, val)
+	} else if savePath != "" {
+		opts.Format = browser.ScreenshotFormat(savePath)
+	}
+	if val, ok := op.arg["quality"].(string); ok {
+		opts.Quality = gt.Any2Int(chromeArgVal(interp, val))
+	}
+
+	data, err := browser.Screenshot(opts)
+	if err != nil {
+		return err
+	}
+	if savePath != "" {
+		path, err := browser.SaveScreenshot(data, savePath)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("[Chrome]截图已保存到: %s \n", path)
+	}
+	if hasAs {
+		interp.Global().SetVar(as, base64.StdEncoding.EncodeToString(data))
+	}
+	return nil
+}
+
+// chromeScreenshotOptions 截图与快照共用的参数 element、viewport、mask、timeout
+func chromeScreenshotOptions(interp *interpreter.Interpreter, op *chromeOperation) browser.ScreenshotOptions {
+	opts := browser.ScreenshotOptions{
+		Viewport: chromeFlagArg(interp, op, "viewport"),
+		Timeout:  chromeTimeout(op, browser.DefaultActionTimeout),
+	}
+	if val, ok := op.arg["element"].(string); ok {
+		opts.Element = chromeArgVal(interp, val)
+	}
+	if val, ok := op.arg["mask"].(string); ok {
+		opts.Mask = chromeLocatorArg(interp, val)
+	}
+	return opts
+}
+
+// chrome snapshot name="home" threshold=0.01 update element=<定位器> viewport=true mask=[定位器...] as=r
+func chromeSnapshot(interp *interpreter.Interpreter, op *chromeOperation) error {
+	nameArg, _ := op.arg["name"].(string)
+	name := chromeArgVal(interp, nameArg)
+	if name == "" {
+		return fmt.Errorf("snapshot 需要 name=<快照名>")
+	}
+	threshold := browser.DefaultSnapshotThreshold
+	if val, ok := op.arg["threshold"].(string); ok {
+		threshold = gt.Any2Float64(chromeArgVal(interp, val))
+	}
+
+	res, err := browser.Snapshot(name, threshold, chromeFlagArg(interp, op, "update"), chromeScreenshotOptions(interp, op))
+	if err != nil {
+		return err
+	}
+	switch {
+	case res.New:
+		fmt.Printf("[Chrome]快照 %s 没有基准图, 已保存为基准图: %s\n", name, res.Baseline)
+	case res.Updated:
+		fmt.Printf("[Chrome]快照 %s 已更新基准图: %s\n", name, res.Baseline)
+	case res.Pass:
+		fmt.Printf("[Chrome]快照 %s 通过, 差异%.4f%%(%d像素) 阈值%.4f%%\n", name, res.Ratio*100, res.DiffPixels, threshold*100)
+	default:
+		fmt.Printf("[Chrome]快照 %s 未通过, 差异%.4f%%(%d像素) 超过阈值%.4f%%, 差异图: %s\n", name, res.Ratio*100, res.DiffPixels, threshold*100, res.Diff)
+	}
+	if res.Size != "" {
+		fmt.Printf("[Chrome]快照 %s %s\n", name, res.Size)
+	}
+	if as, ok := op.arg["as"].(string); ok {
+		interp.Global().SetVar(as, interpreter.DictType{
+			"pass":        res.Pass,
+			"ratio":       res.Ratio,
+			"diff_pixels": int64(res.DiffPixels),
+			"new":         res.New,
+			"baseline":    res.Baseline,
+			"diff":        res.Diff,
+			"actual":      res.Actual,
+		})
+	}
+	return nil
+}
+
+// chromeFlagArg 开关参数: key 与 key=true 开启, key=false 关闭
+func chromeFlagArg(interp *interpreter.Interpreter, op *chromeOperation, key string) bool {
+	val, ok := op.arg[key].(string)
+	if !ok {
+		return false
+	}
+	val = chromeArgVal(interp, val)
+	return val != "false" && val != "0"
+}
@@ -0,0 +1,320 @@
+package builtins
+
+import (
+	"ChromeBot/browser"
+	"ChromeBot/dsl/interpreter"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// html查询, 不需要浏览器; 节点是字典 {tag, attrs, text, html, xpath}, 可以链式调用 css(h, ".item").find("a").attr("href")
+var htmlFn = map[string]interpreter.Function{
+	"xpath": htmlXpath, // xpath(html, expr) 用xpath查询html, 返回节点列表; text()、@属性返回字符串列表, count()等返回值
+	"css":   htmlCss,   // css(html, selector) 用css选择器查询html, 返回节点列表
+	"text":  htmlText,  // text(node) 节点的文本, 参数是节点列表时返回文本列表, 是html字符串时返回整个页面的文本
+	"attr":  htmlAttr,  // attr(node, name) 节点的属性值, 没有时返回空字符串, 参数是节点列表时返回属性值列表
+	"find":  htmlFind,  // find(node, sub) 在节点内查询, sub 是xpath或css选择器(同chrome指令的定位器), 参数是节点列表时合并结果
+
+	// extract(html, schema, 可选参数url) 按规则提取记录列表, url 是页面地址, 用于把相对地址转为绝对地址
+	// schema {
+	// 		row: 每一行的选择器, 没有时整个页面作为一行
+	// 		fields: {字段名: 选择器 或 {sel: 选择器, attr: 属性, re: 正则, type: string|int|float|bool|url|html, all: 取所有, default: 没有取到时的值}}
+	// }
+	"extract": htmlExtract,
+}
+
+func htmlXpath(args []interpreter.Value) (interpreter.Value, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("xpath(html, expr) 需要两个参数")
+	}
+	expr, ok := args[1].(string)
+	if !ok {
+		return nil, fmt.Errorf("xpath(html, expr) expr 要求是字符串")
+	}
+	root, err := htmlQueryRoot(args[0])
+	if err != nil {
+		return nil, fmt.Errorf("xpath(html, expr) %s", err.Error())
+	}
+	res, err := browser.QueryXPath(root, expr)
+	if err != nil {
+		return nil, err
+	}
+	return htmlQueryValue(res), nil
+}
+
+func htmlCss(args []interpreter.Value) (interpreter.Value, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("css(html, selector) 需要两个参数")
+	}
+	selector, ok := args[1].(string)
+	if !ok {
+		return nil, fmt.Errorf("css(html, selector) selector 要求是字符串")
+	}
+	root, err := htmlQueryRoot(args[0])
+	if err != nil {
+		return nil, fmt.Errorf("css(html, selector) %s", err.Error())
+	}
+	nodes, err := browser.QueryCSS(root, selector)
+	if err != nil {
+		return nil, err
+	}
+	return htmlQueryValue(nodes), nil
+}
+
+func htmlText(args []interpreter.Value) (interpreter.Value, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("text(node) 需要一个参数")
+	}
+	switch v := args[0].(type) {
+	case interpreter.DictType:
+		text, _ := v["text"].(string)
+		return text, nil
+	case []interpreter.Value:
+		list := make([]interpreter.Value, 0, len(v))
+		for _, item := range v {
+			text, err := htmlText([]interpreter.Value{item})
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, text)
+		}
+		return list, nil
+	case string:
+		root, err := browser.ParseHTMLToDOM(v)
+		if err != nil {
+			return nil, err
+		}
+		return browser.NodeText(root), nil
+	}
+	return nil, fmt.Errorf("text(node) 参数要求是节点、节点列表或html字符串")
+}
+
+func htmlAttr(args []interpreter.Value) (interpreter.Value, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("attr(node, name) 需要两个参数")
+	}
+	name, ok := args[1].(string)
+	if !ok {
+		return nil, fmt.Errorf("attr(node, name) name 要求是字符串")
+	}
+	switch v := args[0].(type) {
+	case interpreter.DictType:
+		attrs, _ := v["attrs"].(interpreter.DictType)
+		val, ok := attrs[name]
+		if !ok {
+			return "", nil
+		}
+		return val, nil
+	case []interpreter.Value:
+		list := make([]interpreter.Value, 0, len(v))
+		for _, item := range v {
+			val, err := htmlAttr([]interpreter.Value{item, name})
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, val)
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("attr(node, name) 参数要求是节点或节点列表")
+}
+
+func htmlFind(args []interpreter.Value) (interpreter.Value, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("find(node, sub) 需要两个参数")
+	}
+	sub, ok := args[1].(string)
+	if !ok {
+		return nil, fmt.Errorf("find(node, sub) sub 要求是字符串")
+	}
+	if list, ok := args[0].([]interpreter.Value); ok {
+		res := make([]interpreter.Value, 0)
+		for _, item := range list {
+			found, err := htmlFind([]interpreter.Value{item, sub})
+			if err != nil {
+				return nil, err
+			}
+			if foundList, ok := found.([]interpreter.Value); ok {
+				res = append(res, foundList...)
+			} else {
+				res = append(res, found)
+			}
+		}
+		return res, nil
+	}
+	root, err := htmlQueryRoot(args[0])
+	if err != nil {
+		return nil, fmt.Errorf("find(node, sub) %s", err.Error())
+	}
+	res, err := browser.QuerySelector(root, sub)
+	if err != nil {
+		return nil, err
+	}
+	return htmlQueryValue(res), nil
+}
+
+// htmlQueryRoot 查询的根: html字符串解析整个页面, 节点字典重新解析节点的html, 结果的xpath接在节点的xpath后面
+func htmlQueryRoot(v interpreter.Value) (*browser.DOMNode, error) {
+	switch val := v.(type) {
+	case string:
+		return browser.ParseHTMLToDOM(val)
+	case interpreter.DictType:
+		outerHTML, _ := val["html"].(string)
+		tag, _ := val["tag"].(string)
+		xpath, _ := val["xpath"].(string)
+		if outerHTML == "" {
+			return nil, fmt.Errorf("节点没有html")
+		}
+		return browser.ParseHTMLNode(outerHTML, tag, xpath)
+	}
+	return nil, fmt.Errorf("参数要求是html字符串或节点")
+}
+
+// htmlQueryValue 查询结果转为DSL的值, 元素转为节点字典
+func htmlQueryValue(res any) interpreter.Value {
+	switch v := res.(type) {
+	case []*browser.DOMNode:
+		list := make([]interpreter.Value, 0, len(v))
+		for _, n := range v {
+			list = append(list, htmlNodeDict(n))
+		}
+		return list
+	case []any:
+		list := make([]interpreter.Value, 0, len(v))
+		for _, item := range v {
+			if n, ok := item.(*browser.DOMNode); ok {
+				list = append(list, htmlNodeDict(n))
+			} else {
+				list = append(list, item)
+			}
+		}
+		return list
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			return int64(v)
+		}
+		return v
+	}
+	return res
+}
+
+func htmlNodeDict(n *browser.DOMNode) interpreter.DictType {
+	attrs := make(interpreter.DictType, len(n.Attributes))
+	for k, v := range n.Attributes {
+		attrs[k] = v
+	}
+	return interpreter.DictType{
+		"tag":   n.TagName,
+		"attrs": attrs,
+		"text":  browser.NodeText(n),
+		"html":  browser.NodeHTML(n),
+		"xpath": n.XPath,
+	}
+}
+
+func htmlExtract(args []interpreter.Value) (interpreter.Value, error) {
+	if len(args) != 2 && len(args) != 3 {
+		return nil, fmt.Errorf("extract(html, schema, 可选参数url) 需要两个或三个参数")
+	}
+	root, err := htmlQueryRoot(args[0])
+	if err != nil {
+		return nil, fmt.Errorf("extract(html, schema) %s", err.Error())
+	}
+	schemaDict, ok := args[1].(interpreter.DictType)
+	if !ok {
+		return nil, fmt.Errorf("extract(html, schema) schema 要求是字典")
+	}
+	schema, err := htmlExtractSchema(schemaDict)
+	if err != nil {
+		return nil, fmt.Errorf("extract(html, schema) %s", err.Error())
+	}
+	if len(args) == 3 {
+		base, ok := args[2].(string)
+		if !ok {
+			return nil, fmt.Errorf("extract(html, schema, url) url 要求是字符串")
+		}
+		schema.Base = base
+	}
+
+	records, report, err := browser.Extract(root, schema)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("[Extract]提取了%d行\n", report.Rows)
+	reasons := make(map[string]string)
+	for _, m := range report.Misses {
+		if _, ok := reasons[m.Field]; !ok {
+			reasons[m.Field] = m.Reason
+		}
+	}
+	misses := report.FieldMisses()
+	for _, f := range schema.Fields {
+		rows := misses[f.Name]
+		if len(rows) == 0 {
+			continue
+		}
+		rowStr := make([]string, 0, len(rows))
+		for i, row := range rows {
+			if i == 10 {
+				rowStr = append(rowStr, "...")
+				break
+			}
+			rowStr = append(rowStr, fmt.Sprint(row))
+		}
+		fmt.Printf("[Extract]字段 %s 有%d行没有取到值(%s): 第%s行\n", f.Name, len(rows), reasons[f.Name], strings.Join(rowStr, ","))
+	}
+
+	list := make([]interpreter.Value, 0, len(records))
+	for _, record := range records {
+		dict := make(interpreter.DictType, len(record))
+		for k, v := range record {
+			if values, ok := v.([]any); ok {
+				dict[k] = htmlQueryValue(values)
+			} else {
+				dict[k] = v
+			}
+		}
+		list = append(list, dict)
+	}
+	return list, nil
+}
+
+// htmlExtractSchema 字典转为提取规则
+func htmlExtractSchema(dict interpreter.DictType) (*browser.ExtractSchema, error) {
+	schema := &browser.ExtractSchema{Fields: make([]*browser.ExtractField, 0)}
+	if row, ok := dict["row"]; ok {
+		if schema.Row, ok = row.(string); !ok {
+			return nil, fmt.Errorf("row 要求是字符串")
+		}
+	}
+	fields, ok := dict["fields"].(interpreter.DictType)
+	if !ok || len(fields) == 0 {
+		return nil, fmt.Errorf("schema 需要 fields 字典")
+	}
+	for k, v := range fields {
+		field := &browser.ExtractField{Name: fmt.Sprint(k)}
+		switch rule := v.(type) {
+		case string:
+			field.Sel = rule
+		case interpreter.DictType:
+			for key, target := range map[string]*string{"sel": &field.Sel, "attr": &field.Attr, "re": &field.Re, "type": &field.Type} {
+				if val, ok := rule[key]; ok {
+					if *target, ok = val.(string); !ok {
+						return nil, fmt.Errorf("字段 %s 的 %s 要求是字符串", field.Name, key)
+					}
+				}
+			}
+			if all, ok := rule["all"]; ok {
+				if field.All, ok = all.(bool); !ok {
+					return nil, fmt.Errorf("字段 %s 的 all 要求是布尔值", field.Name)
+				}
+			}
+			field.Default = rule["default"]
+		default:
+			return nil, fmt.Errorf("字段 %s 要求是选择器字符串或字典", field.Name)
+		}
+		schema.Fields = append(schema.Fields, field)
+	}
+	return schema, nil
+}
@@ -5,11 +5,17 @@ import (
 	"ChromeBot/utils"
 	"fmt"
 	"strings"
+	"sync"
 )
 
 // 解析 chrome 关键字语法
 func (i *Interpreter) evaluateChromeStmt(expr *ast.ChromeStmt, ctx *Context, hang int) Value {
 	utils.Debug("evaluateChromeStmt args = ", expr.Args)
+	if i.isolated {
+		// chrome指令共用浏览器的当前tab、frame等状态, 不能与脚本并发执行
+		i.errors = append(i.errors, fmt.Errorf("隔离执行的代码块(如route handler)中不能使用chrome指令"))
+		return nil
+	}
 	fn, ok := ctx.GetFunc("chrome")
 	if !ok {
 		i.errors = append(i.errors, fmt.Errorf("未定义Chrome"))
@@ -19,16 +25,132 @@ func (i *Interpreter) evaluateChromeStmt(expr *ast.ChromeStmt, ctx *Context, han
 	for idx, arg := range expr.Args {
 		args[idx] = i.evaluateExpr(arg, ctx, hang)
 	}
+	if expr.Body != nil {
+		args = append(args, &Block{interp: i, body: expr.Body, ctx: ctx, hang: hang})
+	}
 
 	result, err := fn(args)
 	if err != nil {
 		i.errors = append(i.errors, fmt.Errorf("Chrome调用错误: %v", err))
+		i.ReportError(err.Error())
 		return nil
 	}
 
 	return result
 }
 
+// Block 指令后的代码块, 作为最后一个参数传给指令, 由指令决定何时执行
+type Block struct {
+	interp *Interpreter
+	body   *ast.BlockStmt
+	ctx    *Context
+	hang   int
+
+	// 隔离执行时的变量与函数快照
+	isolated bool
+	vars     map[string]Value
+	funcs    map[string]Function
+	mu       sync.Mutex
+}
+
+// Run 在指令所在的作用域中执行代码块, vars 为代码块中可以使用的变量; 只能在执行脚本的协程中调用
+// 与 for...in 的一次迭代相同: vars 同时设置到指令所在作用域以便内层代码块访问, 代码块中新建的变量同步到指令所在作用域
+func (b *Block) Run(vars map[string]Value) (Value, error) {
+	if b.isolated {
+		return b.runIsolated(vars)
+	}
+	blockCtx := NewContext(b.ctx)
+	for k, v := range vars {
+		blockCtx.SetVar(k, v)
+		b.ctx.SetVar(k, v)
+	}
+	errNum := len(b.interp.errors)
+	res := b.interp.runBlockStmts(b.body, blockCtx, b.hang)
+	if blockCtx.hasReturn {
+		b.ctx.hasReturn = true
+		b.ctx.returnVal = blockCtx.returnVal
+	}
+	for k, v := range blockCtx.variables {
+		if _, has := vars[k]; !has {
+			b.ctx.SetVar(k, v)
+		}
+	}
+	if blockCtx.hasBreak || blockCtx.hasReturn {
+		return res, ErrBlockBreak
+	}
+	if len(b.interp.errors) > errNum {
+		return res, b.interp.errors[len(b.interp.errors)-1]
+	}
+	return res, nil
+}
+
+// runBlockStmts 在ctx中依次执行代码块的语句, 遇到 return break continue 时停止
+func (i *Interpreter) runBlockStmts(block *ast.BlockStmt, ctx *Context, hang int) Value {
+	for _, stmt := range block.Stmts {
+		switch stmt.(type) {
+		case *ast.BreakStmt:
+			ctx.hasBreak = true
+			return nil
+		case *ast.ContinueStmt:
+			ctx.hasContinue = true
+			return nil
+		}
+		_ = i.evaluateStmt(stmt, ctx, hang)
+		if ctx.hasReturn {
+			return *ctx.returnVal
+		}
+		if ctx.hasBreak || ctx.hasContinue {
+			return nil
+		}
+	}
+	return nil
+}
+
+// ErrBlockBreak 代码块中执行了break
+var ErrBlockBreak = fmt.Errorf("break")
+
+// Isolated 返回在其他协程中执行的代码块: 使用当前变量与函数的快照和独立的解释器, 与脚本互不影响, 多次执行依次进行
+// 用于事件回调(如请求拦截), 必须在执行脚本的协程中调用; 代码块中不能使用chrome指令
+func (b *Block) Isolated() *Block {
+	vars := make(map[string]Value)
+	funcs := make(map[string]Function)
+	// 由外到内复制, 内层作用域的同名变量覆盖外层
+	chain := make([]*Context, 0)
+	for c := b.ctx; c != nil; c = c.parent {
+		chain = append(chain, c)
+	}
+	for idx := len(chain) - 1; idx >= 0; idx-- {
+		for k, v := range chain[idx].variables {
+			vars[k] = v
+		}
+		for k, fn := range chain[idx].functions {
+			funcs[k] = fn
+		}
+	}
+	return &Block{body: b.body, hang: b.hang, isolated: true, vars: vars, funcs: funcs}
+}
+
+func (b *Block) runIsolated(vars map[string]Value) (Value, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ctx := NewContext(nil)
+	for k, v := range b.vars {
+		ctx.variables[k] = v
+	}
+	for k, fn := range b.funcs {
+		ctx.functions[k] = fn
+	}
+	for k, v := range vars {
+		ctx.variables[k] = v
+	}
+	interp := &Interpreter{global: ctx, errors: []error{}, isolated: true}
+	res := interp.runBlockStmts(b.body, ctx, b.hang)
+	if len(interp.errors) > 0 {
+		return res, interp.errors[len(interp.errors)-1]
+	}
+	return res, nil
+}
+
 // 解析 http 关键字语法
 func (i *Interpreter) evaluateHttpStmt(expr *ast.HttpStmt, ctx *Context, hang int) Value {
 	utils.Debug("evaluateHttpStmt args = ", expr.Args)
@@ -208,8 +208,10 @@ func (c *Context) GetFunc(name string) (Function, bool) {
 
 // Interpreter 解释器
 type Interpreter struct {
-	global *Context
-	errors []error
+	global     *Context
+	errors     []error
+	errorHooks []func(errMsg string) // 语句出错时的回调
+	isolated   bool                  // 隔离执行代码块的解释器, 与脚本并发执行, 不能使用chrome指令
 }
 
 // NewInterpreter 创建解释器
@@ -295,8 +297,21 @@ func (i *Interpreter) evaluateStmt(stmt ast.Statement, ctx *Context, hang int) V
 	return nil
 }
 
+// OnError 注册语句出错时的回调, 用于保存出错现场(如录屏); 出错退出前也会执行
+func (i *Interpreter) OnError(fn func(errMsg string)) {
+	i.errorHooks = append(i.errorHooks, fn)
+}
+
+// ReportError 执行语句出错时的回调
+func (i *Interpreter) ReportError(errMsg string) {
+	for _, fn := range i.errorHooks {
+		fn(errMsg)
+	}
+}
+
 func (i *Interpreter) ErrorShow(hang int, errMsg string) {
 	fmt.Println("[ERROR] len:", hang, " | ", errMsg)
+	i.ReportError(errMsg)
 	if !IsREPL {
 		os.Exit(0)
 	}
@@ -304,6 +319,7 @@ func (i *Interpreter) ErrorShow(hang int, errMsg string) {
 
 func (i *Interpreter) ErrorMessage(errMsg string) {
 	fmt.Println("[ERROR]", errMsg)
+	i.ReportError(errMsg)
 	if !IsREPL {
 		os.Exit(0)
 	}
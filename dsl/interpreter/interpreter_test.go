@@ -1389,3 +1389,86 @@ if (10 > 1) {
 }
 
 */
+
+func TestChromeBlock(t *testing.T) {
+	input := `
+var sum = 0;
+chrome each {
+	sum = sum + item;
+	if item == 3 {
+		break;
+	}
+}
+`
+	program := parser.New(lexer.New(input)).ParseProgram()
+	interp := NewInterpreter()
+
+	var isolated *Block
+	runs := 0
+	interp.Global().SetFunc("chrome", func(args []Value) (Value, error) {
+		block, ok := args[len(args)-1].(*Block)
+		if !ok {
+			return nil, fmt.Errorf("最后一个参数不是代码块: %T", args[len(args)-1])
+		}
+		isolated = block.Isolated()
+		for _, item := range []int64{1, 2, 3, 4} {
+			runs++
+			if _, err := block.Run(map[string]Value{"item": item}); err != nil {
+				if err == ErrBlockBreak {
+					break
+				}
+				return nil, err
+			}
+		}
+		return nil, nil
+	})
+	if _, err := interp.Interpret(program); err != nil {
+		t.Fatalf("解释器错误: %v", err)
+	}
+	if runs != 3 {
+		t.Errorf("代码块执行次数错误。期望=3, 得到=%d", runs)
+	}
+	sum, _ := interp.Global().GetVar("sum")
+	testIntegerObject(t, sum, 6)
+
+	// 隔离执行: 使用变量快照, 不影响脚本中的变量
+	if _, err := isolated.Run(map[string]Value{"item": int64(10)}); err != nil && err != ErrBlockBreak {
+		t.Fatalf("隔离执行错误: %v", err)
+	}
+	sum, _ = interp.Global().GetVar("sum")
+	testIntegerObject(t, sum, 6)
+}
+
+func TestIsolatedBlockNoChrome(t *testing.T) {
+	input := `
+chrome route="*" handler {
+	chrome click="css=#buy";
+}
+`
+	program := parser.New(lexer.New(input)).ParseProgram()
+	interp := NewInterpreter()
+
+	var isolated *Block
+	calls := 0
+	interp.Global().SetFunc("chrome", func(args []Value) (Value, error) {
+		calls++
+		if block, ok := args[len(args)-1].(*Block); ok {
+			isolated = block.Isolated()
+		}
+		return nil, nil
+	})
+	if _, err := interp.Interpret(program); err != nil {
+		t.Fatalf("解释器错误: %v", err)
+	}
+	if isolated == nil {
+		t.Fatal("没有收到代码块")
+	}
+
+	_, err := isolated.Run(map[string]Value{"request": DictType{}})
+	if err == nil || !strings.Contains(err.Error(), "chrome指令") {
+		t.Fatalf("隔离执行的代码块中使用chrome指令应报错, 得到: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("隔离执行的代码块中的chrome指令不应执行, 调用次数=%d", calls)
+	}
+}
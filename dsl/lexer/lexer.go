@@ -473,6 +473,7 @@ func (l *Lexer) readFloat() string {
 }
 
 func (l *Lexer) readString() string {
+	quote := l.ch              // 开始的引号, 字符串以相同的引号结束, 中间可以有其他引号
 	position := l.position + 1 // 跳过开始的引号
 
 	for {
@@ -483,7 +484,7 @@ func (l *Lexer) readString() string {
 			break
 		}
 
-		if l.ch == '"' || l.ch == '\'' || l.ch == '`' {
+		if l.ch == quote {
 			break
 		}
 
@@ -495,7 +496,7 @@ func (l *Lexer) readString() string {
 
 	}
 
-	if l.ch == '"' || l.ch == '\'' || l.ch == '`' {
+	if l.ch == quote {
 		// 正常结束，有右引号
 		str := l.input[position:l.position]
 		return unescapeString(str)
@@ -518,7 +519,7 @@ func unescapeString(s string) string {
 			case '\\':
 				result.WriteByte('\\')
 			case '"', '\'', '`':
-				result.WriteByte('"')
+				result.WriteByte(s[i])
 			case 'n':
 				result.WriteByte('\n')
 			case 't':
@@ -251,6 +251,42 @@ func TestNextTokenComplexString(t *testing.T) {
 	}
 }
 
+func TestNextTokenMixedQuoteString(t *testing.T) {
+	// 字符串以开始的引号结束, 中间可以有其他引号, 如xpath与js代码
+	input := "chrome click=`//*[@id=\"kw\"]` js=\"document.querySelector('#kw').value\" '\\'a\\''"
+
+	tests := []struct {
+		expectedType    TokenType
+		expectedLiteral string
+	}{
+		{TokenChrome, "chrome"},
+		{TokenIdent, "click"},
+		{TokenAssign, "="},
+		{TokenString, `//*[@id="kw"]`},
+		{TokenIdent, "js"},
+		{TokenAssign, "="},
+		{TokenString, "document.querySelector('#kw').value"},
+		{TokenString, "'a'"},
+		{TokenEOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("测试[%d] - 类型错误。期望=%q, 得到=%q",
+				i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("测试[%d] - 字面量错误。期望=%q, 得到=%q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
 func TestNextTokenChainCall(t *testing.T) {
 	input := `print("aa").print("bb").upper()`
 
@@ -4,6 +4,7 @@ import (
 	"ChromeBot/dsl/ast"
 	"ChromeBot/dsl/lexer"
 	"ChromeBot/utils"
+	"encoding/json"
 	"strings"
 )
 
@@ -29,15 +30,24 @@ func (p *Parser) parseChromeStatement() *ast.ChromeStmt {
 	p.nextToken()
 	utils.Debugf("跳过chrome后: %v", p.curTok)
 
-	var args []ast.Expression
+	var (
+		args []ast.Expression
+		body *ast.BlockStmt
+	)
 	startLine := p.curTok.Line
 
 	// 读取chrome参数
 	for p.curTok.Line == startLine && !p.curTokenIs(lexer.TokenEOF) {
 		utils.Debugf("解析参数，当前token: %v", p.curTok)
 
+		// 参数后的代码块, 如 chrome route url="*/api/*" handler { ... }
+		if p.curTokenIs(lexer.TokenLBrace) {
+			body = p.parseBlockStatement()
+			break
+		}
+
 		// 构建参数字符串
-		argStr := p.readChromeArgs()
+		argStr := p.readChromeArgs(true)
 		if len(argStr) != 0 {
 			for _, arg := range argStr {
 				args = append(args, &ast.String{
@@ -62,10 +72,12 @@ func (p *Parser) parseChromeStatement() *ast.ChromeStmt {
 	return &ast.ChromeStmt{
 		StartPos: startPos,
 		Args:     args,
+		Body:     body,
 	}
 }
 
-func (p *Parser) readChromeArgs() []string {
+// readChromeArgs 读取一行中的参数, block 为 true 时遇到不在等号后的 { 停止(参数后的代码块)
+func (p *Parser) readChromeArgs(block bool) []string {
 	var args []string
 	startLine := p.curTok.Line
 	var currentArg strings.Builder
@@ -95,6 +107,17 @@ func (p *Parser) readChromeArgs() []string {
 			continue
 		}
 
+		if block && !inKeyValue && token.Type == lexer.TokenLBrace {
+			break
+		}
+
+		// 等号后是 { 或 [ 时读取整个json, 如 params={"a": 1}
+		if inKeyValue && (token.Type == lexer.TokenLBrace || token.Type == lexer.TokenLBracket) {
+			currentArg.WriteString(p.readChromeJSON())
+			inKeyValue = false
+			continue
+		}
+
 		// 普通token
 		if currentArg.Len() == 0 {
 			// 参数开始
@@ -121,6 +144,34 @@ func (p *Parser) readChromeArgs() []string {
 	return args
 }
 
+// readChromeJSON 从 { 或 [ 读到对应的 } 或 ], 还原成json字符串; 字符串重新加上引号
+func (p *Parser) readChromeJSON() string {
+	var sb strings.Builder
+	depth := 0
+	startLine := p.curTok.Line
+	for p.curTok.Line == startLine && !p.curTokenIs(lexer.TokenEOF) {
+		token := p.curTok
+		switch token.Type {
+		case lexer.TokenLBrace, lexer.TokenLBracket:
+			depth++
+			sb.WriteString(token.Literal)
+		case lexer.TokenRBrace, lexer.TokenRBracket:
+			depth--
+			sb.WriteString(token.Literal)
+		case lexer.TokenString:
+			str, _ := json.Marshal(token.Literal)
+			sb.Write(str)
+		default:
+			sb.WriteString(token.Literal)
+		}
+		p.nextToken()
+		if depth == 0 {
+			break
+		}
+	}
+	return sb.String()
+}
+
 // http 语法解析 http arg1 arg2=123 ...
 func (p *Parser) parseHttpStatement() *ast.HttpStmt {
 	if !p.checkDepth() {
@@ -150,7 +201,7 @@ func (p *Parser) parseHttpStatement() *ast.HttpStmt {
 		utils.Debugf("解析参数，当前token: %v", p.curTok)
 
 		// 构建参数字符串
-		argStr := p.readChromeArgs()
+		argStr := p.readChromeArgs(false)
 		if len(argStr) != 0 {
 			for _, arg := range argStr {
 				args = append(args, &ast.String{
@@ -206,7 +257,7 @@ func (p *Parser) parseHostStatement() *ast.HostStmt {
 		utils.Debugf("解析参数，当前token: %v", p.curTok)
 
 		// 构建参数字符串
-		argStr := p.readChromeArgs()
+		argStr := p.readChromeArgs(false)
 		if len(argStr) != 0 {
 			for _, arg := range argStr {
 				args = append(args, &ast.String{
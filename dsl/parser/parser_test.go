@@ -1053,3 +1053,60 @@ func testStringLiteral(t *testing.T, exp ast.Expression, value string) bool {
 
 	return true
 }
+
+func TestChromeJSONArg(t *testing.T) {
+	input := `chrome raw method="Network.setExtraHTTPHeaders" params={"headers": {"X-Token": "a b", "n": [1, -2, true]}} as=r`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.ChromeStmt)
+	if !ok {
+		t.Fatalf("不是 *ast.ChromeStmt。得到=%T", program.Statements[0])
+	}
+	want := []string{"raw", "method=Network.setExtraHTTPHeaders", `params={"headers":{"X-Token":"a b","n":[1,-2,true]}}`, "as=r"}
+	if len(stmt.Args) != len(want) {
+		t.Fatalf("参数个数错误。期望=%d, 得到=%d %v", len(want), len(stmt.Args), stmt.Args)
+	}
+	for i, arg := range stmt.Args {
+		if got := arg.(*ast.String).Value; got != want[i] {
+			t.Errorf("参数[%d]错误。期望=%q, 得到=%q", i, want[i], got)
+		}
+	}
+}
+
+func TestChromeBlock(t *testing.T) {
+	input := `chrome route url="*/api/*" handler {
+    if request["method"] == "POST" {
+        request["action"] = "block"
+    }
+}
+chrome req="https://example.com"`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements 不包含 2 条语句。得到=%d", len(program.Statements))
+	}
+	stmt, ok := program.Statements[0].(*ast.ChromeStmt)
+	if !ok {
+		t.Fatalf("不是 *ast.ChromeStmt。得到=%T", program.Statements[0])
+	}
+	if len(stmt.Args) != 3 || stmt.Args[2].(*ast.String).Value != "handler" {
+		t.Errorf("参数错误: %v", stmt.Args)
+	}
+	if stmt.Body == nil || len(stmt.Body.Stmts) != 1 {
+		t.Fatalf("代码块解析错误: %v", stmt.Body)
+	}
+	if _, ok := stmt.Body.Stmts[0].(*ast.IfStmt); !ok {
+		t.Errorf("代码块中的语句不是 *ast.IfStmt。得到=%T", stmt.Body.Stmts[0])
+	}
+	if next, ok := program.Statements[1].(*ast.ChromeStmt); !ok || next.Body != nil {
+		t.Errorf("第二条语句解析错误: %v", program.Statements[1])
+	}
+}
@@ -85,6 +85,7 @@ func Run() {
 
 		utils.RunMode = "Script"
 		utils.ScriptDir = filepath.Dir(filename)
+		utils.ScriptFile = filename
 
 		runScript(string(source))
 
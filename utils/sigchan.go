@@ -5,3 +5,4 @@ import "os"
 var SigChan = make(chan os.Signal, 1)
 var RunMode = "REPL"
 var ScriptDir = ""
+var ScriptFile = ""
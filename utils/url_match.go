@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// MatchURLPattern 地址匹配
+// 支持三种写法:
+// 1. 正则: 以 / 开头和结尾, 如 /api\/list\?page=\d+/
+// 2. 通配: 含有 * 或 ?, * 匹配任意字符, ? 匹配单个字符, 如 */api/list*
+// 3. 其他: 包含匹配
+func MatchURLPattern(pattern, s string) bool {
+	if pattern == "" {
+		return false
+	}
+	if len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return false
+		}
+		return re.MatchString(s)
+	}
+	if strings.ContainsAny(pattern, "*?") {
+		return globToRegexp(pattern).MatchString(s)
+	}
+	return strings.Contains(s, pattern)
+}
+
+// globToRegexp 通配转为正则, 整串匹配
+func globToRegexp(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
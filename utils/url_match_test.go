@@ -0,0 +1,28 @@
+package utils
+
+import "testing"
+
+func TestMatchURLPattern(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		url     string
+		want    bool
+	}{
+		{"*/api/list*", "https://a.com/api/list?page=1", true},
+		{"*/api/list*", "https://a.com/api/detail", false},
+		{"https://a.com/*", "https://a.com/x/y", true},
+		{"https://a.com/?", "https://a.com/x", true},
+		{"baidu.com", "https://www.baidu.com/s?wd=1", true},
+		{"baidu.com", "https://www.douyin.com", false},
+		{`/page=\d+$/`, "https://a.com/list?page=12", true},
+		{`/page=\d+$/`, "https://a.com/list?page=x", false},
+		{"*.png", "https://a.com/logo.png", true},
+		{"*.png", "https://a.com/logo.png.js", false},
+		{"", "https://a.com", false},
+	}
+	for _, c := range testCases {
+		if got := MatchURLPattern(c.pattern, c.url); got != c.want {
+			t.Errorf("MatchURLPattern(%q, %q) = %v, want %v", c.pattern, c.url, got, c.want)
+		}
+	}
+}